@@ -2,17 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/littleironwaltz/bluesky-mcp/configs/fallbacks"
+	"github.com/littleironwaltz/bluesky-mcp/internal/anonymize"
 	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
 	"github.com/littleironwaltz/bluesky-mcp/internal/handlers"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/community"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/identity"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -22,12 +33,20 @@ import (
 type App struct {
 	server      *echo.Echo
 	config      config.Config
+	mode        config.Mode
 	shutdownWg  sync.WaitGroup
 	healthySrv  *http.Server
 	healthyStop chan struct{}
 }
 
 func main() {
+	// With --check-config, only validate the configuration
+	checkConfigOnly := flag.Bool("check-config", false, "Validate the configuration, print a summary and exit")
+	flag.Parse()
+	if *checkConfigOnly {
+		os.Exit(checkConfig(os.Stdout, os.Stderr))
+	}
+
 	// Initialize application
 	app := &App{
 		healthyStop: make(chan struct{}),
@@ -39,11 +58,22 @@ func main() {
 
 	// Load configuration
 	app.config = config.LoadConfig()
+
+	// Size the outbound connection pool, and fall back to HTTP/1.1 if requested
+	apiclient.SetClientOptions(apiclient.ClientOptionsFromEnv())
+	apiclient.SetRetryTracing(apiclient.RetryTracingFromEnv())
+	apiclient.SetRequestCoalescing(apiclient.RequestCoalescingFromEnv())
 	
-	// Validate configuration
-	if err := config.ValidateConfig(app.config); err != nil {
+	// Decide between live and mock mode; in auto mode a missing login starts read-only
+	mode, err := config.ResolveMode(app.config)
+	if err != nil {
 		log.Fatalf("Configuration error: %v", err)
 	}
+	app.mode = mode
+	if mode == config.ModeMock {
+		handlers.SetReadOnly(true)
+		log.Println("Warning: No credentials in use, starting in read-only mode; write methods are disabled")
+	}
 
 	// Register backup credentials if available from environment
 	backupID := os.Getenv("BSKY_BACKUP_ID")
@@ -56,6 +86,16 @@ func main() {
 		log.Println("Registered backup credentials")
 	}
 	
+	// A session cache without a key would keep tokens in plain text, so it stays off
+	if app.config.SessionCache.File != "" && app.config.SessionCache.Key == "" {
+		log.Println("Warning: Session cache file set without a key; sessions are not persisted")
+	}
+
+	// Refresh sessions ahead of expiry by a fixed time or a part of their
+	// lifetime, and stop using them early enough for a server clock that is ahead
+	auth.SetRefreshLead(auth.RefreshLeadFromEnv())
+	auth.SetClockSkew(auth.ClockSkewFromEnv())
+
 	// Initialize the auth token manager to ensure it's ready
 	tokenManager := auth.GetTokenManager(app.config)
 	
@@ -64,6 +104,79 @@ func main() {
 		log.Printf("Warning: Failed to initialize fallbacks: %v\n", err)
 	}
 
+	// Optionally confirm the credentials work before accepting requests
+	if err := checkStartupAuth(app.config, mode, os.Getenv("BSKY_STARTUP_CHECK")); err != nil {
+		log.Fatalf("Startup check failed: %v", err)
+	}
+
+	// Enforce the configured post limit, Bluesky's own by default
+	post.SetServiceLimits(post.ServiceLimitsFromEnv())
+
+	// Keep resolved identities across restarts
+	identity.SetCacheOptions(identity.CacheOptionsFromEnv())
+
+	// Build the service caches from the configured sizes, lifetimes and persistence
+	feed.SetCacheOptions(app.config.Caches.Feed)
+	community.SetCacheOptions(app.config.Caches.Community)
+
+	// Configure duplicate post detection for submissions
+	post.SetDuplicateCheck(post.DuplicateCheckOptionsFromEnv())
+
+	// Pace record writes to stay within Bluesky's write limits
+	writeRate := post.WriteRateOptionsFromEnv()
+	post.SetWriteRate(writeRate.Rate, writeRate.Burst)
+
+	// Stream analyzed posts to a sink if one is configured
+	if sink, err := feed.PostSinkFromEnv(); err != nil {
+		log.Printf("Warning: Failed to open the post sink, analyzed posts are not emitted: %v\n", err)
+	} else if sink != nil {
+		feed.SetPostSink(sink)
+		log.Println("Emitting analyzed posts to the post sink")
+	}
+
+	// Derive the pseudonyms of anonymized results from a fixed key, so they
+	// survive restarts; without one they change with every start
+	anonymize.SetKey(anonymize.KeyFromEnv())
+
+	// Announce successful submissions to a webhook if one is configured
+	post.SetWebhook(post.WebhookOptionsFromEnv())
+
+	// Apply per-request timeouts for outbound service calls if configured
+	if timeout, err := time.ParseDuration(os.Getenv("BSKY_SUBMIT_TIMEOUT")); err == nil {
+		post.SetSubmitTimeout(timeout)
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("BSKY_COMMUNITY_TIMEOUT")); err == nil {
+		community.SetRequestTimeout(timeout)
+	}
+
+	// Bound how many users a multi-user community request reads at once, and for how long each
+	community.SetFanOutOptions(community.FanOutOptionsFromEnv())
+
+	// Enable the retry queue for failed post submissions if requested
+	if os.Getenv("BSKY_RETRY_QUEUE") == "true" && mode == config.ModeLive {
+		if err := post.EnableRetryQueue(app.config, post.DefaultRetryQueueOptions); err != nil {
+			log.Printf("Warning: Failed to enable retry queue: %v\n", err)
+		} else {
+			log.Println("Enabled retry queue for failed posts")
+		}
+	}
+
+	// Serve repeated identical requests from the result cache for the configured methods
+	if value := os.Getenv("BSKY_RESULT_CACHE_TTLS"); value != "" {
+		ttls, err := handlers.ParseResultCacheTTLs(value)
+		if err != nil {
+			log.Fatalf("Configuration error: %v", err)
+		}
+		handlers.SetResultCacheTTLs(ttls)
+	}
+
+	// Restore rate limits from the previous run so a restart does not reset them
+	if path := os.Getenv("BSKY_RATE_LIMIT_FILE"); path != "" {
+		if err := handlers.LoadRateLimitState(path); err != nil {
+			log.Printf("Warning: Failed to load rate limit state: %v\n", err)
+		}
+	}
+
 	// Initialize API server
 	if err := app.initServer(); err != nil {
 		log.Fatalf("Failed to initialize server: %v", err)
@@ -92,16 +205,77 @@ func main() {
 	log.Println("Server stopped")
 }
 
+// checkConfig validates the configuration the server would start with,
+// printing a summary of it to stdout and its problems to stderr. It returns
+// the process exit code, 1 if the configuration is invalid.
+func checkConfig(stdout, stderr io.Writer) int {
+	cfg, err := config.Check("")
+	fmt.Fprint(stdout, cfg.Summary())
+
+	var checkErr *config.CheckError
+	if errors.As(err, &checkErr) {
+		fmt.Fprintf(stderr, "Invalid configuration:\n  - %s\n", strings.Join(checkErr.Problems, "\n  - "))
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	fmt.Fprintln(stdout, "Configuration is valid.")
+	return 0
+}
+
+// checkStartupAuth runs the startup authentication check selected by policy:
+// "off" (or empty) skips it, "log" reports the outcome and "require" also
+// returns an error when authentication fails so the server refuses to start.
+func checkStartupAuth(cfg config.Config, mode config.Mode, policy string) error {
+	switch policy {
+	case "", "off":
+		return nil
+	case "log", "require":
+	default:
+		return fmt.Errorf("invalid BSKY_STARTUP_CHECK %q (allowed: off, log, require)", policy)
+	}
+
+	if mode != config.ModeLive {
+		log.Println("Skipping startup authentication check: no credentials in use")
+		return nil
+	}
+
+	info, err := auth.GetTokenManager(cfg).Probe(cfg, auth.DefaultProbeTimeout)
+	if err != nil {
+		if policy == "require" {
+			return fmt.Errorf("unable to authenticate with Bluesky: %w", err)
+		}
+		log.Printf("Warning: Startup authentication check failed: %v\n", err)
+		return nil
+	}
+
+	log.Printf("Startup authentication check succeeded as %s (%s credentials)", info.Handle, info.CredentialSet)
+	return nil
+}
+
 // initServer initializes the Echo server
 func (a *App) initServer() error {
 	// Set up Echo
 	a.server = echo.New()
+
+	// Decide how client addresses are read, honoring trusted proxies
+	extractor, err := handlers.NewIPExtractor(a.config.Access)
+	if err != nil {
+		return err
+	}
+	if extractor != nil {
+		a.server.IPExtractor = extractor
+	}
 	
 	// Middleware
 	a.server.Use(middleware.Recover())
-	a.server.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "${time_rfc3339} ${remote_ip} ${method} ${uri} ${status} ${latency_human}\n",
-	}))
+	logOptions, err := handlers.RequestLogOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	a.server.Use(handlers.NewRequestLogger(logOptions))
 	a.server.Use(middleware.CORS())
 	a.server.Use(middleware.SecureWithConfig(middleware.SecureConfig{
 		XSSProtection:         "1; mode=block",
@@ -121,15 +295,47 @@ func (a *App) initServer() error {
 
 	// Routes
 	a.server.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{
+		response := map[string]interface{}{
 			"status":  "ok",
 			"version": "1.0.0",
+			"mode":    a.mode,
+		}
+		if err := feed.CachePersistError(); err != nil {
+			response["status"] = "degraded"
+			response["cache_error"] = err.Error()
+		}
+		if status, enabled := post.GetRetryQueueStatus(); enabled {
+			response["retry_queue"] = map[string]interface{}{
+				"depth":     status.Depth,
+				"delivered": status.Delivered,
+				"dropped":   status.Dropped,
+			}
+		}
+		return c.JSON(http.StatusOK, response)
+	})
+	
+	// How feed analyses were served, revealing cache effectiveness and degradation
+	a.server.GET("/stats", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"feed_sources": feed.GetSourceStats(),
+			"feed_cache":   feed.GetCacheStats(),
 		})
 	})
 	
+	// Reject disallowed client addresses before they reach rate limiting
+	var mcpMiddleware []echo.MiddlewareFunc
+	if a.config.Access.Enabled() {
+		filter, err := handlers.NewIPFilter(a.config.Access.AllowIPs, a.config.Access.DenyIPs)
+		if err != nil {
+			return err
+		}
+		mcpMiddleware = append(mcpMiddleware, filter.Middleware())
+	}
+
 	a.server.POST("/mcp/:method", func(c echo.Context) error {
 		return handlers.HandleMCPRequest(c, a.config)
-	})
+	}, mcpMiddleware...)
+	a.server.GET("/mcp", handlers.HandleListMethods, mcpMiddleware...)
 
 	return nil
 }
@@ -180,6 +386,23 @@ func (a *App) shutdown() {
 		log.Fatalf("Server shutdown failed: %v", err)
 	}
 
+	// Save resolved identities and cached results for the next run
+	identity.StopCache()
+	feed.StopCache()
+	community.StopCache()
+
+	// Close the post sink's file, if it has one
+	if err := feed.ClosePostSink(); err != nil {
+		log.Printf("Error closing the post sink: %v", err)
+	}
+
+	// Keep rate limits for the next run
+	if path := os.Getenv("BSKY_RATE_LIMIT_FILE"); path != "" {
+		if err := handlers.SaveRateLimitState(path); err != nil {
+			log.Printf("Warning: Failed to save rate limit state: %v\n", err)
+		}
+	}
+
 	// Signal health check server to stop
 	close(a.healthyStop)
 
@@ -197,6 +420,9 @@ func (a *App) shutdown() {
 		log.Println("Health check server shutdown timed out")
 	}
 	
+	// Stop the retry queue, keeping pending posts on disk
+	post.DisableRetryQueue()
+
 	// Stop background token refreshes
 	auth.GetTokenManager(a.config).Stop()
 }
\ No newline at end of file
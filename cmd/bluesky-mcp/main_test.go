@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestCheckStartupAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"AuthenticationRequired","message":"Invalid identifier or password"}`))
+	}))
+	defer server.Close()
+
+	cfg := config.Config{
+		BskyHost:     server.URL,
+		BskyID:       "me.bsky.social",
+		BskyPassword: "wrong-password",
+	}
+
+	// Requiring authentication refuses to start, without waiting on retries
+	start := time.Now()
+	err := checkStartupAuth(cfg, config.ModeLive, "require")
+	if err == nil || !strings.Contains(err.Error(), "unable to authenticate") {
+		t.Errorf("checkStartupAuth(require) error = %v, want authentication failure", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("checkStartupAuth(require) took %s, want it to fail fast", elapsed)
+	}
+
+	// Logging only reports the failure
+	if err := checkStartupAuth(cfg, config.ModeLive, "log"); err != nil {
+		t.Errorf("checkStartupAuth(log) unexpected error: %v", err)
+	}
+
+	// Disabled checks and mock mode never contact the server
+	for _, policy := range []string{"", "off"} {
+		if err := checkStartupAuth(cfg, config.ModeLive, policy); err != nil {
+			t.Errorf("checkStartupAuth(%q) unexpected error: %v", policy, err)
+		}
+	}
+	if err := checkStartupAuth(cfg, config.ModeMock, "require"); err != nil {
+		t.Errorf("checkStartupAuth in mock mode unexpected error: %v", err)
+	}
+
+	if err := checkStartupAuth(cfg, config.ModeLive, "always"); err == nil || !strings.Contains(err.Error(), "invalid BSKY_STARTUP_CHECK") {
+		t.Errorf("checkStartupAuth(always) error = %v, want invalid value", err)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	for _, key := range []string{"BSKY_ID", "BSKY_PASSWORD", "BSKY_HOST", "BSKY_AUTH_HOST", "BSKY_MODE", "MOCK_MODE"} {
+		t.Setenv(key, "")
+	}
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	t.Setenv("BSKY_CONFIG_FILE", configFile)
+
+	check := func(content string) (int, string, string) {
+		t.Helper()
+		if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+			t.Fatalf("Writing the config file: %v", err)
+		}
+		var stdout, stderr bytes.Buffer
+		code := checkConfig(&stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, stdout, stderr := check(`{"BskyID": "alice.bsky.social", "BskyPassword": "app-password", "Mode": "live"}`)
+	if code != 0 || stderr != "" || !strings.Contains(stdout, "Configuration is valid.") {
+		t.Errorf("checkConfig() = %d with %q and %q, want a valid configuration", code, stdout, stderr)
+	}
+	if strings.Contains(stdout, "app-password") {
+		t.Errorf("checkConfig() printed the password: %s", stdout)
+	}
+
+	code, _, stderr = check(`{"Mode": "staging"}`)
+	if code == 0 || !strings.Contains(stderr, `invalid mode "staging": must be live, mock or auto`) {
+		t.Errorf("checkConfig() = %d with %q, want the invalid mode reported", code, stderr)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+)
+
+// Exit codes reported by the CLI so scripts can tell failures apart
+const (
+	ExitOK         = 0
+	ExitError      = 1 // Any failure not covered below
+	ExitAuth       = 2 // Missing or rejected credentials
+	ExitNetwork    = 3 // The Bluesky API could not be reached
+	ExitValidation = 4 // Invalid flags or input
+	ExitTimeout    = 5 // The request timed out
+)
+
+// commandError is a failed command's user-friendly message and exit code
+type commandError struct {
+	code    int
+	message string
+	err     error
+}
+
+func (e *commandError) Error() string {
+	return e.message
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
+// newCommandError wraps err with its user-friendly message for command and
+// the exit code of its category
+func newCommandError(err error, command string) error {
+	return &commandError{
+		code:    exitCodeFor(err),
+		message: formatUserFriendlyError(err, command),
+		err:     err,
+	}
+}
+
+// validationError reports invalid input detected by the CLI itself
+func validationError(message string) error {
+	return &commandError{code: ExitValidation, message: message, err: errors.New(message)}
+}
+
+// exitCode returns the process exit code for an error returned by Execute.
+// Errors cobra reports itself, such as a missing required flag, are classified
+// by their message.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	return exitCodeFor(err)
+}
+
+// exitCodeFor classifies a service error, checking categories in the same
+// order as formatUserFriendlyError
+func exitCodeFor(err error) int {
+	errMsg := err.Error()
+
+	switch {
+	case errors.Is(err, apiclient.ErrInsufficientScope):
+		return ExitAuth
+	case errors.Is(err, apiclient.ErrAccountUnavailable):
+		return ExitError
+	case errors.Is(err, feed.ErrMalformedFeed):
+		return ExitError
+	case strings.Contains(errMsg, "missing Bluesky credentials") ||
+		strings.Contains(errMsg, "authentication failed"):
+		return ExitAuth
+	case strings.Contains(errMsg, "connection refused") ||
+		strings.Contains(errMsg, "no such host") ||
+		strings.Contains(errMsg, "request failed"):
+		return ExitNetwork
+	case strings.Contains(errMsg, "timeout") ||
+		strings.Contains(errMsg, "deadline exceeded"):
+		return ExitTimeout
+	case strings.Contains(errMsg, "invalid") ||
+		strings.Contains(errMsg, "missing") ||
+		strings.Contains(errMsg, "required") ||
+		strings.Contains(errMsg, "too long") ||
+		strings.Contains(errMsg, "duplicate post"):
+		return ExitValidation
+	}
+	return ExitError
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "Missing credentials", err: errors.New("missing Bluesky credentials in configuration"), want: ExitAuth},
+		{name: "Rejected credentials", err: errors.New("authentication failed: status 401"), want: ExitAuth},
+		{name: "Connection refused", err: errors.New("dial tcp: connection refused"), want: ExitNetwork},
+		{name: "Unknown host", err: errors.New("lookup bsky.social: no such host"), want: ExitNetwork},
+		{name: "Timeout", err: errors.New("context deadline exceeded"), want: ExitTimeout},
+		{name: "Invalid hashtag", err: errors.New(`invalid hashtag "go lang"`), want: ExitValidation},
+		{name: "Missing required flag", err: errors.New(`required flag(s) "text" not set`), want: ExitValidation},
+		{name: "Duplicate post", err: errors.New("duplicate post: same text was posted recently"), want: ExitValidation},
+		{name: "Account unavailable", err: fmt.Errorf("account bob.bsky.social is suspended: %w", apiclient.ErrAccountUnavailable), want: ExitError},
+		{name: "Malformed feed", err: fmt.Errorf("%w: invalid character 'x'", feed.ErrMalformedFeed), want: ExitError},
+		{name: "Other error", err: errors.New("something unexpected happened"), want: ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeFor(tt.err); got != tt.want {
+				t.Errorf("exitCodeFor(%q) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(nil); got != ExitOK {
+		t.Errorf("exitCode(nil) = %d, want %d", got, ExitOK)
+	}
+
+	// Wrapped command errors keep their code
+	err := fmt.Errorf("wrapped: %w", newCommandError(errors.New("authentication failed"), "feed"))
+	if got := exitCode(err); got != ExitAuth {
+		t.Errorf("exitCode(wrapped auth error) = %d, want %d", got, ExitAuth)
+	}
+}
+
+// stubGetToken replaces auth.GetToken for the duration of a test
+func stubGetToken(t *testing.T, err error) {
+	original := auth.GetToken
+	t.Cleanup(func() { auth.GetToken = original })
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		return "test-token", nil
+	}
+}
+
+// executeFeedCommand runs the feed command without mock mode and returns its error
+func executeFeedCommand(t *testing.T, args ...string) error {
+	t.Helper()
+	rootCmd := &cobra.Command{Use: "bluesky-mcp-cli"}
+	rootCmd.AddCommand(feedCmd(false))
+	_, err := testExecuteCommand(rootCmd, append([]string{"feed"}, args...)...)
+	return err
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	t.Run("Authentication failure", func(t *testing.T) {
+		stubGetToken(t, errors.New("authentication failed: status 401"))
+
+		err := executeFeedCommand(t, "--hashtag", "golang")
+		if got := exitCode(err); got != ExitAuth {
+			t.Errorf("exitCode() = %d, want %d (err: %v)", got, ExitAuth, err)
+		}
+		if err == nil || !strings.Contains(err.Error(), "Authentication failed") {
+			t.Errorf("Expected a friendly authentication message, got %v", err)
+		}
+	})
+
+	t.Run("Validation failure", func(t *testing.T) {
+		stubGetToken(t, nil)
+
+		err := executeFeedCommand(t, "--hashtag", "go lang")
+		if got := exitCode(err); got != ExitValidation {
+			t.Errorf("exitCode() = %d, want %d (err: %v)", got, ExitValidation, err)
+		}
+		if err == nil || !strings.Contains(err.Error(), "Invalid hashtag") {
+			t.Errorf("Expected a friendly hashtag message, got %v", err)
+		}
+	})
+
+	t.Run("Missing user flag", func(t *testing.T) {
+		_, err := testExecuteCommand(setupRootCommand(), "community")
+		if got := exitCode(err); got != ExitValidation {
+			t.Errorf("exitCode() = %d, want %d (err: %v)", got, ExitValidation, err)
+		}
+	})
+}
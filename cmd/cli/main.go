@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
 	"github.com/littleironwaltz/bluesky-mcp/internal/models"
 	"github.com/littleironwaltz/bluesky-mcp/internal/services/community"
 	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
 	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 	"github.com/spf13/cobra"
 )
@@ -20,16 +26,27 @@ import (
 const Version = "0.1.0"
 
 func main() {
-	// Check if we're running without credentials or with MOCK_MODE env var - use mock mode
-	mockMode := false
-	if os.Getenv("BSKY_ID") == "" && os.Getenv("BSKY_PASSWORD") == "" {
-		mockMode = true
-	}
-	
-	// Also check for explicit MOCK_MODE environment variable
-	if os.Getenv("MOCK_MODE") == "1" || os.Getenv("MOCK_MODE") == "true" {
-		mockMode = true
-	}
+	// Use mock mode when requested, or in auto mode when no credentials are configured
+	cfg := config.LoadConfig()
+	mode, modeErr := config.ResolveMode(cfg)
+	mockMode := mode == config.ModeMock
+
+	// Build the service caches from the configured sizes, lifetimes and persistence
+	feed.SetCacheOptions(cfg.Caches.Feed)
+	community.SetCacheOptions(cfg.Caches.Community)
+
+	// Configure duplicate post detection for submissions
+	post.SetDuplicateCheck(post.DuplicateCheckOptionsFromEnv())
+
+	// Enforce the configured post limit, Bluesky's own by default
+	post.SetServiceLimits(post.ServiceLimitsFromEnv())
+
+	// Pace record writes to stay within Bluesky's write limits
+	writeRate := post.WriteRateOptionsFromEnv()
+	post.SetWriteRate(writeRate.Rate, writeRate.Burst)
+
+	// Bound how many users --users reads at once, and for how long each
+	community.SetFanOutOptions(community.FanOutOptionsFromEnv())
 
 	// Create the root command
 	rootCmd := &cobra.Command{
@@ -37,20 +54,42 @@ func main() {
 		Short: "Bluesky MCP CLI - Access Bluesky MCP features from command line",
 		Long: `A command-line interface for the Bluesky MCP (Model Context Protocol) service.
 Provides easy access to post suggestions, feed analysis, and community management features.`,
+		SilenceErrors: true,
+		// An invalid configuration stops every command but config validate, which reports it
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if modeErr != nil {
+				cmd.SilenceUsage = true
+			}
+			return modeErr
+		},
 	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return validationError(err.Error())
+	})
 
 	// Add subcommands
 	rootCmd.AddCommand(assistCmd(mockMode))
 	rootCmd.AddCommand(submitCmd(mockMode))
+	rootCmd.AddCommand(gateCmd(mockMode))
 	rootCmd.AddCommand(feedCmd(mockMode))
 	rootCmd.AddCommand(communityCmd(mockMode))
+	rootCmd.AddCommand(whoamiCmd(mockMode))
+	rootCmd.AddCommand(statsCmd(mockMode))
+	rootCmd.AddCommand(monitorCmd())
+	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(versionCmd())
 
-	// Execute the command
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println("Error:", err)
-		os.Exit(1)
+	os.Exit(execute(rootCmd))
+}
+
+// execute runs the command and returns the process exit code. Errors are
+// printed to stderr so that stdout only carries the command's result.
+func execute(rootCmd *cobra.Command) int {
+	err := rootCmd.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
 	}
+	return exitCode(err)
 }
 
 // assistCmd generates post suggestions based on mood and topic
@@ -58,12 +97,20 @@ func assistCmd(mockMode bool) *cobra.Command {
 	var mood, topic string
 	var outputJSON bool
 	var submitDirect bool
+	var fitToLimit bool
 
 	cmd := &cobra.Command{
 		Use:   "assist",
 		Short: "Generate post suggestions",
 		Long:  "Generate post suggestions based on specified mood and topic.",
-		Run: func(cmd *cobra.Command, args []string) {
+		PreRunE: requireFlags(
+			requiredFlag{name: "mood", example: "happy"},
+			requiredFlag{name: "topic", example: "programming"},
+		),
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Use mock data if in mock mode or testing environment
 			if mockMode {
 				mockResult := map[string]interface{}{
@@ -89,7 +136,7 @@ func assistCmd(mockMode bool) *cobra.Command {
 						fmt.Println("URI:", mockResult["post_uri"])
 					}
 				}
-				return
+				return nil
 			}
 			
 			// Load configuration
@@ -97,27 +144,26 @@ func assistCmd(mockMode bool) *cobra.Command {
 
 			// Create params
 			params := map[string]interface{}{
-				"mood":   mood,
-				"topic":  topic,
-				"submit": submitDirect,
+				"mood":       mood,
+				"topic":      topic,
+				"submit":     submitDirect,
+				"fitToLimit": fitToLimit,
 			}
 
 			// Call the service function
 			result, err := post.GeneratePost(cfg, params)
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "assist"))
-				return
+				return newCommandError(err, "assist")
 			}
 
 			// Handle different result types depending on whether post was submitted
-			if submitDirect {
-				// For direct submit, result should be a map[string]interface{}
+			if submitDirect || fitToLimit {
+				// For direct submit or fitting, result should be a map[string]interface{}
 				if resultMap, ok := result.(map[string]interface{}); ok {
 					if outputJSON {
 						jsonOutput, err := json.MarshalIndent(resultMap, "", "  ")
 						if err != nil {
-							fmt.Println("Error formatting JSON:", err)
-							return
+							return fmt.Errorf("error formatting JSON: %w", err)
 						}
 						fmt.Println(string(jsonOutput))
 					} else {
@@ -128,12 +174,14 @@ func assistCmd(mockMode bool) *cobra.Command {
 							if uri, ok := resultMap["post_uri"].(string); ok {
 								fmt.Println("URI:", uri)
 							}
+						} else if queued, _ := resultMap["queued"].(bool); queued {
+							fmt.Println("\n" + queuedPostMessage(resultMap["queue_id"]))
 						} else if errMsg, ok := resultMap["error"].(string); ok {
-							fmt.Println("\nFailed to submit post:", errMsg)
+							return newCommandError(fmt.Errorf("failed to submit post: %s", errMsg), "submit")
 						}
 					}
 				} else {
-					fmt.Println("Error: Unexpected response format")
+					return fmt.Errorf("unexpected response format")
 				}
 			} else {
 				// For suggestion only, result should be a map[string]string
@@ -141,17 +189,18 @@ func assistCmd(mockMode bool) *cobra.Command {
 					if outputJSON {
 						jsonOutput, err := json.MarshalIndent(suggestion, "", "  ")
 						if err != nil {
-							fmt.Println("Error formatting JSON:", err)
-							return
+							return fmt.Errorf("error formatting JSON: %w", err)
 						}
 						fmt.Println(string(jsonOutput))
 					} else {
 						fmt.Println(suggestion["suggestion"])
 					}
 				} else {
-					fmt.Println("Error: Unexpected response format")
+					return fmt.Errorf("unexpected response format")
 				}
 			}
+
+			return nil
 		},
 	}
 
@@ -160,6 +209,7 @@ func assistCmd(mockMode bool) *cobra.Command {
 	cmd.Flags().StringVar(&topic, "topic", "", "Topic for the post")
 	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
 	cmd.Flags().BoolVar(&submitDirect, "submit", false, "Submit the generated post directly to Bluesky")
+	cmd.Flags().BoolVar(&fitToLimit, "fit", false, "Trim the suggestion to fit the 300 grapheme post limit")
 
 	// Mark required flags
 	cmd.MarkFlagRequired("mood")
@@ -173,12 +223,47 @@ func feedCmd(mockMode bool) *cobra.Command {
 	var hashtag string
 	var limit int
 	var outputJSON bool
+	var noCache bool
+	var noReposts bool
+	var noReplies bool
+	var includeRaw bool
+	var truncate int
+	var trend bool
+	var buckets int
+	var window time.Duration
+	var contains string
+	var fromFile string
 
 	cmd := &cobra.Command{
 		Use:   "feed",
 		Short: "Analyze hashtag feed",
 		Long:  "Analyze posts with a specified hashtag and display analysis results.",
-		Run: func(cmd *cobra.Command, args []string) {
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// A saved feed is analyzed as it is, with or without a hashtag
+			if fromFile != "" {
+				return nil
+			}
+			return requireFlags(requiredFlag{name: "hashtag", example: "golang"})(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if trend {
+				return runFeedTrend(mockMode, hashtag, buckets, window, outputJSON)
+			}
+
+			// Analyze a saved response without the API, in mock mode too
+			if fromFile != "" {
+				return runFeedFromFile(fromFile, map[string]interface{}{
+					"hashtag":        hashtag,
+					"limit":          float64(limit),
+					"includeReposts": !noReposts,
+					"includeReplies": !noReplies,
+					"contains":       contains,
+				}, outputJSON, truncate)
+			}
+
 			// Use mock data if in mock mode or testing environment
 			if mockMode {
 				mockPosts := []models.Post{
@@ -200,6 +285,16 @@ func feedCmd(mockMode bool) *cobra.Command {
 					},
 				}
 				
+				if contains != "" {
+					matching := mockPosts[:0]
+					for _, post := range mockPosts {
+						if strings.Contains(strings.ToLower(post.Text), strings.ToLower(contains)) {
+							matching = append(matching, post)
+						}
+					}
+					mockPosts = matching
+				}
+
 				// Limit the number of mock posts based on the limit parameter
 				if len(mockPosts) > limit {
 					mockPosts = mockPosts[:limit]
@@ -215,9 +310,9 @@ func feedCmd(mockMode bool) *cobra.Command {
 					jsonOutput, _ := json.MarshalIndent(mockResponse, "", "  ")
 					fmt.Println(string(jsonOutput))
 				} else {
-					displayFeedResults(mockResponse)
+					displayFeedResults(mockResponse, truncationLength(truncate, feedTextPrefixLen, defaultFeedTruncate))
 				}
-				return
+				return nil
 			}
 			
 			// Load configuration
@@ -225,69 +320,201 @@ func feedCmd(mockMode bool) *cobra.Command {
 
 			// Create params
 			params := map[string]interface{}{
-				"hashtag": hashtag,
-				"limit":   float64(limit), // API expects float64
+				"hashtag":        hashtag,
+				"limit":          float64(limit), // API expects float64
+				"bypassCache":    noCache,
+				"includeReposts": !noReposts,
+				"includeReplies": !noReplies,
+				"includeRaw":     includeRaw && outputJSON, // Raw JSON is only shown in JSON output
+				"contains":       contains,
 			}
 
 			// Get auth token first to ensure we're authenticated
 			_, err := auth.GetToken(cfg)
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "feed"))
-				return
+				return newCommandError(err, "feed")
 			}
 
 			// Call the service function
 			result, err := feed.AnalyzeFeed(cfg, params)
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "feed"))
-				return
+				return newCommandError(err, "feed")
 			}
 
 			// Check if result is of the expected type
 			feedResponse, ok := result.(models.FeedResponse)
 			if !ok {
-				fmt.Println("Error: Unexpected response format")
-				return
+				return fmt.Errorf("unexpected response format")
 			}
 
 			// Output format handling
 			if outputJSON {
 				jsonOutput, err := json.MarshalIndent(feedResponse, "", "  ")
 				if err != nil {
-					fmt.Println("Error formatting JSON:", err)
-					return
+					return fmt.Errorf("error formatting JSON: %w", err)
 				}
 				fmt.Println(string(jsonOutput))
 			} else {
 				// Display in user-friendly tabular format
-				displayFeedResults(feedResponse)
+				displayFeedResults(feedResponse, truncationLength(truncate, feedTextPrefixLen, defaultFeedTruncate))
 			}
+
+			return nil
 		},
 	}
 
 	// Add flags
-	cmd.Flags().StringVar(&hashtag, "hashtag", "", "Hashtag to analyze (required)")
+	cmd.Flags().StringVar(&hashtag, "hashtag", "", "Hashtag to analyze (required unless --from-file is given)")
 	cmd.Flags().IntVar(&limit, "limit", 10, "Number of posts to analyze (max 100)")
 	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Skip cached results and fetch fresh data")
+	cmd.Flags().BoolVar(&noReposts, "no-reposts", false, "Exclude reposts from the analysis")
+	cmd.Flags().BoolVar(&noReplies, "no-replies", false, "Exclude replies from the analysis")
+	cmd.Flags().StringVar(&contains, "contains", "", "Only analyze posts whose text contains this keyword (case-insensitive)")
+	cmd.Flags().BoolVar(&includeRaw, "raw", false, "Include the raw upstream feed JSON in --json output (always fetches fresh data)")
+	cmd.Flags().IntVar(&truncate, "truncate", -1, "Maximum characters of post text to show, 0 for no truncation (default: fit the terminal width)")
+	cmd.Flags().BoolVar(&trend, "trend", false, "Show how sentiment changed over time instead of individual posts")
+	cmd.Flags().IntVar(&buckets, "buckets", feed.DefaultTrendBuckets, fmt.Sprintf("Number of time periods for --trend (max %d)", feed.MaxTrendBuckets))
+	cmd.Flags().DurationVar(&window, "window", feed.DefaultTrendWindow, "How far back --trend looks (e.g. 6h, 72h; max 168h)")
 
 	// Mark required flags
-	cmd.MarkFlagRequired("hashtag")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Analyze a saved timeline or search response from this file instead of calling the API")
+
+	// --hashtag is required unless --from-file is given, which PreRunE checks
+	cmd.MarkFlagsMutuallyExclusive("from-file", "trend")
+	cmd.MarkFlagsMutuallyExclusive("from-file", "raw")
 
 	return cmd
 }
 
+// runFeedFromFile analyzes the timeline or search response saved at path with
+// params and displays the result as the feed command does
+func runFeedFromFile(path string, params map[string]interface{}, outputJSON bool, truncate int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return validationError(fmt.Sprintf("Could not read %s: %v", path, err))
+	}
+
+	feedResponse, err := feed.AnalyzeFeedData(config.LoadConfig(), data, params)
+	if errors.Is(err, feed.ErrMalformedFeed) {
+		return validationError(fmt.Sprintf("%s is not a saved timeline or search response: %v", path, err))
+	}
+	if err != nil {
+		return newCommandError(err, "feed")
+	}
+
+	if outputJSON {
+		jsonOutput, err := json.MarshalIndent(feedResponse, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting JSON: %w", err)
+		}
+		fmt.Println(string(jsonOutput))
+		return nil
+	}
+	displayFeedResults(feedResponse, truncationLength(truncate, feedTextPrefixLen, defaultFeedTruncate))
+	return nil
+}
+
+// runFeedTrend shows the sentiment of a hashtag over time
+func runFeedTrend(mockMode bool, hashtag string, buckets int, window time.Duration, outputJSON bool) error {
+	var trend *feed.SentimentTrend
+	if mockMode {
+		trend = mockSentimentTrend(hashtag, buckets, window)
+	} else {
+		cfg := config.LoadConfig()
+		var err error
+		trend, err = feed.SentimentTimeline(cfg, hashtag, buckets, window)
+		if err != nil {
+			return newCommandError(err, "feed")
+		}
+	}
+
+	if outputJSON {
+		jsonOutput, err := json.MarshalIndent(trend, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting JSON: %w", err)
+		}
+		fmt.Println(string(jsonOutput))
+	} else {
+		displayTrendResults(trend)
+	}
+	return nil
+}
+
+// mockSentimentTrend creates sample trend data, leaving every third bucket empty
+func mockSentimentTrend(hashtag string, buckets int, window time.Duration) *feed.SentimentTrend {
+	if buckets < 1 {
+		buckets = 1
+	}
+	end := time.Now().UTC().Truncate(time.Minute)
+	start := end.Add(-window)
+	width := window / time.Duration(buckets)
+
+	trend := &feed.SentimentTrend{Hashtag: strings.TrimLeft(hashtag, "#"), Start: start, End: end}
+	for i := 0; i < buckets; i++ {
+		bucket := feed.SentimentBucket{
+			Start: start.Add(time.Duration(i) * width),
+			End:   start.Add(time.Duration(i+1) * width),
+		}
+		if i%3 != 2 {
+			score := 0.5
+			bucket.Count, bucket.Positive, bucket.Sentiment = 2, 2, "positive"
+			if i%3 == 1 {
+				score = 0
+				bucket.Positive, bucket.Neutral, bucket.Sentiment = 0, 2, "neutral"
+			}
+			bucket.AverageScore = &score
+			trend.Total += bucket.Count
+		}
+		trend.Buckets = append(trend.Buckets, bucket)
+	}
+	return trend
+}
+
 // communityCmd displays recent posts from a specified user
 func communityCmd(mockMode bool) *cobra.Command {
 	var user string
+	var users []string
 	var limit int
 	var outputJSON bool
+	var noCache bool
+	var truncate int
 
 	cmd := &cobra.Command{
 		Use:   "community",
 		Short: "Monitor user activity",
-		Long:  "Display recent posts from a specified user.",
-		Run: func(cmd *cobra.Command, args []string) {
+		Long:  "Display recent posts from a specified user, or from several users with --users.",
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if user == "" && len(users) == 0 {
+				return validationError("Please provide --user or --users, e.g. --user alice.bsky.social")
+			}
+
 			// Use mock data if in mock mode or testing environment
+			if mockMode && len(users) > 0 {
+				mockUsers := make(map[string]interface{}, len(users))
+				for _, handle := range users {
+					mockUsers[handle] = map[string]interface{}{
+						"user":        handle,
+						"recentPosts": []string{fmt.Sprintf("Hello world! This is a test post from %s", handle)},
+						"count":       1,
+					}
+				}
+				mockResult := map[string]interface{}{
+					"users": mockUsers,
+					"count": len(mockUsers),
+				}
+
+				if outputJSON {
+					jsonOutput, _ := json.MarshalIndent(mockResult, "", "  ")
+					fmt.Println(string(jsonOutput))
+				} else {
+					displayCommunityUsersResults(mockResult, truncationLength(truncate, communityTextPrefixLen, defaultCommunityTruncate))
+				}
+				return nil
+			}
 			if mockMode {
 				mockPosts := []string{
 					fmt.Sprintf("Hello world! This is a test post from %s", user),
@@ -310,9 +537,9 @@ func communityCmd(mockMode bool) *cobra.Command {
 					jsonOutput, _ := json.MarshalIndent(mockResult, "", "  ")
 					fmt.Println(string(jsonOutput))
 				} else {
-					displayCommunityResults(mockResult)
+					displayCommunityResults(mockResult, truncationLength(truncate, communityTextPrefixLen, defaultCommunityTruncate))
 				}
-				return
+				return nil
 			}
 			
 			// Load configuration
@@ -320,50 +547,195 @@ func communityCmd(mockMode bool) *cobra.Command {
 
 			// Create params
 			params := map[string]interface{}{
-				"userHandle": user,
-				"limit":      float64(limit), // API expects float64
+				"limit":       float64(limit), // API expects float64
+				"bypassCache": noCache,
+			}
+			if len(users) > 0 {
+				params["userHandles"] = users
+			} else {
+				params["userHandle"] = user
 			}
 
 			// Get auth token first to ensure we're authenticated
 			_, err := auth.GetToken(cfg)
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "community"))
-				return
+				return newCommandError(err, "community")
 			}
 
 			// Call the service function
 			result, err := community.ManageCommunity(cfg, params)
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "community"))
-				return
+				return newCommandError(err, "community")
 			}
 
 			// Output format handling
 			if outputJSON {
 				jsonOutput, err := json.MarshalIndent(result, "", "  ")
 				if err != nil {
-					fmt.Println("Error formatting JSON:", err)
-					return
+					return fmt.Errorf("error formatting JSON: %w", err)
 				}
 				fmt.Println(string(jsonOutput))
+			} else if len(users) > 0 {
+				displayCommunityUsersResults(result, truncationLength(truncate, communityTextPrefixLen, defaultCommunityTruncate))
 			} else {
 				// Display in user-friendly list format
-				displayCommunityResults(result)
+				displayCommunityResults(result, truncationLength(truncate, communityTextPrefixLen, defaultCommunityTruncate))
 			}
+
+			return nil
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringVar(&user, "user", "", "Username (format: username.bsky.social)")
+	cmd.Flags().StringSliceVar(&users, "users", nil, "Several usernames to read at once, comma-separated or repeated")
 	cmd.Flags().IntVar(&limit, "limit", 5, "Number of posts to display (max 50)")
 	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Skip cached results and fetch fresh data")
+	cmd.Flags().IntVar(&truncate, "truncate", -1, "Maximum characters of post text to show, 0 for no truncation (default: fit the terminal width)")
 
-	// Mark required flags
-	cmd.MarkFlagRequired("user")
+	// Exactly one of the user flags is needed
+	cmd.MarkFlagsMutuallyExclusive("user", "users")
 
 	return cmd
 }
 
+// whoamiCmd verifies the configured credentials and displays the authenticated identity
+func whoamiCmd(mockMode bool) *cobra.Command {
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:     "whoami",
+		Aliases: []string{"verify"},
+		Short:   "Verify credentials",
+		Long:    "Authenticate with the configured credentials and display the resolved handle, DID and token expiry.",
+		// Errors are printed by main, and a failed login is not a usage problem
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Use mock data if in mock mode or testing environment
+			if mockMode {
+				displayIdentity(auth.SessionInfo{
+					Handle:        "fake-user.bsky.social",
+					DID:           "did:plc:mock123456",
+					ExpiresAt:     time.Now().Add(1 * time.Hour).UTC(),
+					CredentialSet: auth.CredentialSetPrimary,
+				}, outputJSON)
+				return nil
+			}
+
+			// Load configuration
+			cfg := config.LoadConfig()
+
+			// Authenticate to resolve the session
+			if _, err := auth.GetToken(cfg); err != nil {
+				return newCommandError(err, "whoami")
+			}
+
+			displayIdentity(auth.GetTokenManager(cfg).GetSessionInfo(), outputJSON)
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// displayIdentity prints the authenticated session details
+func displayIdentity(info auth.SessionInfo, outputJSON bool) {
+	if outputJSON {
+		jsonOutput, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Println(string(jsonOutput))
+		return
+	}
+
+	fmt.Println("Authenticated successfully!")
+	fmt.Println("Handle:", info.Handle)
+	fmt.Println("DID:", info.DID)
+	fmt.Println("Token expires:", info.ExpiresAt.Format(time.RFC3339))
+	fmt.Println("Credentials:", info.CredentialSet)
+}
+
+// statsCmd displays the engagement of the authenticated user's recent posts
+func statsCmd(mockMode bool) *cobra.Command {
+	var limit int
+	var outputJSON bool
+	var truncate int
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show engagement for your recent posts",
+		Long:  "Display the like, repost and reply counts of your recent posts, most engaging first, with totals and averages.",
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit <= 0 || limit > community.MaxStatsPosts {
+				return validationError(fmt.Sprintf("--limit must be between 1 and %d", community.MaxStatsPosts))
+			}
+
+			// Use mock data if in mock mode or testing environment
+			if mockMode {
+				displayStatsResults(&community.PostStats{
+					Posts: []community.PostStat{
+						{URI: "at://did:plc:mock123456/app.bsky.feed.post/2", Text: "Sharing what I learned this week about Go generics", Likes: 12, Reposts: 3, Replies: 4, Engagement: 19},
+						{URI: "at://did:plc:mock123456/app.bsky.feed.post/1", Text: "Hello world! This is a test post", Likes: 2, Reposts: 0, Replies: 1, Engagement: 3},
+					},
+					Count:    2,
+					Totals:   community.EngagementTotals{Likes: 14, Reposts: 3, Replies: 5, Engagement: 22},
+					Averages: community.EngagementAverages{Likes: 7, Reposts: 1.5, Replies: 2.5, Engagement: 11},
+				}, outputJSON, truncationLength(truncate, statsTextPrefixLen, defaultStatsTruncate))
+				return nil
+			}
+
+			// Load configuration
+			cfg := config.LoadConfig()
+
+			stats, err := community.MyPostStats(cfg, limit)
+			if err != nil {
+				return newCommandError(err, "stats")
+			}
+
+			displayStatsResults(stats, outputJSON, truncationLength(truncate, statsTextPrefixLen, defaultStatsTruncate))
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().IntVar(&limit, "limit", 25, fmt.Sprintf("Number of recent posts to include (max %d)", community.MaxStatsPosts))
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+	cmd.Flags().IntVar(&truncate, "truncate", -1, "Maximum characters of post text to show, 0 for no truncation (default: fit the terminal width)")
+
+	return cmd
+}
+
+// displayStatsResults shows one line per post with its engagement counts, then the totals and averages.
+// Post text longer than maxLen characters is truncated; 0 shows it in full.
+func displayStatsResults(stats *community.PostStats, outputJSON bool, maxLen int) {
+	if outputJSON {
+		jsonOutput, _ := json.MarshalIndent(stats, "", "  ")
+		fmt.Println(string(jsonOutput))
+		return
+	}
+	if stats.Count == 0 {
+		fmt.Println("No recent posts found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Engagement for your recent posts (total: %d):\n\n", stats.Count)
+	fmt.Fprintln(w, "Likes\tReposts\tReplies\tPost")
+	for _, post := range stats.Posts {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\n", post.Likes, post.Reposts, post.Replies, truncateText(post.Text, maxLen))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%d\t%d\t%d\tTotal\n", stats.Totals.Likes, stats.Totals.Reposts, stats.Totals.Replies)
+	fmt.Fprintf(w, "%.1f\t%.1f\t%.1f\tAverage per post\n", stats.Averages.Likes, stats.Averages.Reposts, stats.Averages.Replies)
+	w.Flush()
+}
+
 // versionCmd displays the current version
 func versionCmd() *cobra.Command {
 	return &cobra.Command{
@@ -375,20 +747,97 @@ func versionCmd() *cobra.Command {
 	}
 }
 
-// displayFeedResults formats and displays feed analysis results in a user-friendly way
-func displayFeedResults(feed models.FeedResponse) {
+// configCmd groups the commands that work on the configuration
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Check the configuration",
+		// Validating the configuration must not require a valid one
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	cmd.AddCommand(configValidateCmd())
+	return cmd
+}
+
+// configValidateCmd loads and validates the configuration without contacting Bluesky
+func configValidateCmd() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration",
+		Long: `Load the configuration from the environment and the config file, as the server
+and CLI do, report every problem with it and print a summary of what was loaded,
+with the password redacted. Exits non-zero if the configuration is invalid.`,
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateConfig(configFile)
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringVar(&configFile, "config", "", "Config file to validate (default: $BSKY_CONFIG_FILE)")
+
+	return cmd
+}
+
+// validateConfig prints the summary of the configuration read with configFile,
+// returning its problems as a validation error
+func validateConfig(configFile string) error {
+	if configFile == "" {
+		configFile = os.Getenv("BSKY_CONFIG_FILE")
+	}
+	source := "the environment"
+	if configFile != "" {
+		source = configFile
+	}
+
+	cfg, err := config.Check(configFile)
+	fmt.Printf("Configuration loaded from %s:\n\n", source)
+	fmt.Print(cfg.Summary())
+
+	var checkErr *config.CheckError
+	if errors.As(err, &checkErr) {
+		message := "Invalid configuration:\n  - " + strings.Join(checkErr.Problems, "\n  - ")
+		return &commandError{code: ExitValidation, message: message, err: err}
+	}
+	if err != nil {
+		return newCommandError(err, "config validate")
+	}
+	fmt.Println("\nConfiguration is valid.")
+	return nil
+}
+
+// Post text truncation used when the terminal width is unknown, and the width
+// of the text before each post on a line
+const (
+	defaultFeedTruncate      = 60
+	defaultCommunityTruncate = 70
+	defaultStatsTruncate     = 50
+	feedTextPrefixLen        = len("Post: ")
+	communityTextPrefixLen   = len("10. ")
+	statsTextPrefixLen       = len("Likes  Reposts  Replies  ")
+)
+
+// displayFeedResults formats and displays feed analysis results in a user-friendly way.
+// Post text longer than maxLen characters is truncated; 0 shows it in full.
+func displayFeedResults(feed models.FeedResponse, maxLen int) {
 	if len(feed.Posts) == 0 {
 		fmt.Println("No posts found matching your criteria.")
 		return
 	}
 
-	// Create tabwriter for aligned output
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	
-	// Print warning if present
+	// Print warning if present, on stderr so it stays out of piped output
 	if feed.Warning != "" {
-		fmt.Fprintf(w, "Note: %s\n\n", feed.Warning)
+		fmt.Fprintf(os.Stderr, "Note: %s\n", feed.Warning)
 	}
+
+	// Create tabwriter for aligned output
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	
 	// Print header
 	fmt.Fprintf(w, "Posts with hashtag (total: %d):\n\n", feed.Count)
@@ -396,10 +845,7 @@ func displayFeedResults(feed models.FeedResponse) {
 	// Print posts
 	for _, post := range feed.Posts {
 		// Truncate text if too long
-		text := post.Text
-		if len(text) > 60 {
-			text = text[:57] + "..."
-		}
+		text := truncateText(post.Text, maxLen)
 		
 		// Get sentiment in a user-friendly way
 		sentiment := "Neutral"
@@ -423,12 +869,71 @@ func displayFeedResults(feed models.FeedResponse) {
 	w.Flush()
 }
 
-// displayCommunityResults formats and displays community results in a user-friendly way
-func displayCommunityResults(result interface{}) {
+// displayTrendResults shows one line per time period with its post count and sentiment
+func displayTrendResults(trend *feed.SentimentTrend) {
+	if trend.Warning != "" {
+		fmt.Fprintf(os.Stderr, "Note: %s\n", trend.Warning)
+	}
+	if trend.Total == 0 {
+		fmt.Println("No posts found in this time window.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Sentiment for #%s (total: %d):\n\n", trend.Hashtag, trend.Total)
+	fmt.Fprintln(w, "From\tPosts\tFeeling\tScore\t+/-/=")
+	for _, bucket := range trend.Buckets {
+		from := bucket.Start.Local().Format("Jan 02 15:04")
+		if bucket.Count == 0 {
+			fmt.Fprintf(w, "%s\t0\t-\t-\t-\n", from)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%+.2f\t%d/%d/%d\n", from, bucket.Count,
+			strings.ToUpper(bucket.Sentiment[:1])+bucket.Sentiment[1:], *bucket.AverageScore,
+			bucket.Positive, bucket.Negative, bucket.Neutral)
+	}
+	w.Flush()
+}
+
+// displayCommunityUsersResults shows the posts of each user, listing users that could not be read on stderr
+func displayCommunityUsersResults(result interface{}, maxLen int) {
+	data, ok := result.(map[string]interface{})
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Error: Unexpected response format")
+		return
+	}
+	users, _ := data["users"].(map[string]interface{})
+
+	handles := make([]string, 0, len(users))
+	for handle := range users {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+	for _, handle := range handles {
+		displayCommunityResults(users[handle], maxLen)
+		fmt.Println()
+	}
+
+	if errs, ok := data["errors"].(community.UserErrors); ok && len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, "Could not read:")
+		failed := make([]string, 0, len(errs))
+		for handle := range errs {
+			failed = append(failed, handle)
+		}
+		sort.Strings(failed)
+		for _, handle := range failed {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", handle, formatUserFriendlyError(errs[handle], "community"))
+		}
+	}
+}
+
+// displayCommunityResults formats and displays community results in a user-friendly way.
+// Posts longer than maxLen characters are truncated; 0 shows them in full.
+func displayCommunityResults(result interface{}, maxLen int) {
 	// Extract data from result
 	data, ok := result.(map[string]interface{})
 	if !ok {
-		fmt.Println("Error: Unexpected response format")
+		fmt.Fprintln(os.Stderr, "Error: Unexpected response format")
 		return
 	}
 
@@ -439,7 +944,7 @@ func displayCommunityResults(result interface{}) {
 	if !ok {
 		posts2, ok := data["recentPosts"].([]interface{})
 		if !ok {
-			fmt.Println("Error: Could not extract posts from response")
+			fmt.Fprintln(os.Stderr, "Error: Could not extract posts from response")
 			return
 		}
 		// Convert interface slice to string slice
@@ -462,24 +967,40 @@ func displayCommunityResults(result interface{}) {
 
 	for i, post := range posts {
 		// Truncate text if too long
-		if len(post) > 70 {
-			post = post[:67] + "..."
-		}
-		fmt.Printf("%d. %s\n", i+1, post)
+		fmt.Printf("%d. %s\n", i+1, truncateText(post, maxLen))
 	}
 }
 
-// formatUserFriendlyError converts technical errors into user-friendly messages
 // submitCmd submits a post directly to Bluesky
 func submitCmd(mockMode bool) *cobra.Command {
 	var text string
+	var replyTo string
+	var quote string
+	var force bool
+	var labels []string
+	var tags []string
+	var threadgate []string
+	var threadgateLists []string
+	var linkURI string
+	var linkTitle string
+	var linkDescription string
+	var linkThumb string
 	var outputJSON bool
 
 	cmd := &cobra.Command{
 		Use:   "submit",
 		Short: "Submit a post to Bluesky",
 		Long:  "Submit a post directly to your Bluesky account.",
-		Run: func(cmd *cobra.Command, args []string) {
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PreRunE:       requireFlags(requiredFlag{name: "text", example: `"Hello Bluesky!"`}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Reject empty text before authenticating, in mock mode too
+			if err := post.CheckPostText(text); err != nil {
+				return newCommandError(err, "submit")
+			}
+
 			// Use mock data if in mock mode or testing environment
 			if mockMode {
 				mockResult := map[string]interface{}{
@@ -488,6 +1009,24 @@ func submitCmd(mockMode bool) *cobra.Command {
 					"post_uri": "at://fake-user.bsky.social/post/mock123456",
 					"post_cid": "bafyreia123456789mock",
 				}
+				if replyTo != "" {
+					mockResult["reply_to"] = replyTo
+				}
+				if quote != "" {
+					mockResult["quote"] = quote
+				}
+				if len(labels) > 0 {
+					mockResult["labels"] = labels
+				}
+				if len(tags) > 0 {
+					mockResult["tags"] = tags
+				}
+				if len(threadgate) > 0 {
+					mockResult["threadgate"] = threadgate
+				}
+				if linkURI != "" {
+					mockResult["link"] = linkURI
+				}
 				
 				if outputJSON {
 					jsonOutput, _ := json.MarshalIndent(mockResult, "", "  ")
@@ -497,7 +1036,7 @@ func submitCmd(mockMode bool) *cobra.Command {
 					fmt.Println("Text:", text)
 					fmt.Println("URI:", mockResult["post_uri"])
 				}
-				return
+				return nil
 			}
 			
 			// Load configuration
@@ -506,15 +1045,56 @@ func submitCmd(mockMode bool) *cobra.Command {
 			// Get auth token first to ensure we're authenticated
 			_, err := auth.GetToken(cfg)
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "submit"))
-				return
+				return newCommandError(err, "submit")
+			}
+
+			// Resolve reply and quote targets given as bsky.app URLs, AT URIs or handle/rkey
+			opts := post.SubmitPostOptions{Force: force, Labels: labels, Tags: tags}
+			if len(threadgate) > 0 || len(threadgateLists) > 0 {
+				opts.Threadgate = &post.Threadgate{Allow: threadgate, Lists: threadgateLists}
+			}
+			if linkURI != "" {
+				opts.External = &post.ExternalCard{URI: linkURI, Title: linkTitle, Description: linkDescription}
+				if linkThumb != "" {
+					opts.External.Thumb, err = os.ReadFile(linkThumb)
+					if err != nil {
+						return validationError(fmt.Sprintf("could not read link thumbnail: %v", err))
+					}
+				}
+				// Only a URL was given, so fill in the card from the page
+				completed, fetchErr := post.CompleteExternalCard(context.Background(), *opts.External)
+				if fetchErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not fetch link preview, posting the link card without it: %v\n", fetchErr)
+				}
+				opts.External = &completed
+			}
+			if replyTo != "" {
+				opts.Reply, err = post.ResolveReplyRef(cfg, replyTo)
+				if err != nil {
+					return newCommandError(err, "submit")
+				}
+			}
+			if quote != "" {
+				opts.Quote, err = post.ResolvePostRef(cfg, quote)
+				if err != nil {
+					return newCommandError(err, "submit")
+				}
 			}
 
 			// Call the service function
-			postResult, err := post.SubmitPost(cfg, text)
+			var postResult *post.PostResult
+			if opts.Reply == nil && opts.Quote == nil && !opts.Force && len(opts.Labels) == 0 && len(opts.Tags) == 0 && opts.Threadgate == nil && opts.External == nil {
+				postResult, err = post.SubmitPost(cfg, text)
+			} else {
+				postResult, err = post.SubmitPostWithOptions(cfg, text, opts)
+			}
+			var queued *post.QueuedError
+			if errors.As(err, &queued) {
+				// The queue will send the post, so it must not be submitted again
+				return printQueuedPost(queued, text, outputJSON)
+			}
 			if err != nil {
-				fmt.Printf("Error: %s\n", formatUserFriendlyError(err, "submit"))
-				return
+				return newCommandError(err, "submit")
 			}
 
 			// Format and display the result
@@ -524,24 +1104,42 @@ func submitCmd(mockMode bool) *cobra.Command {
 				"post_uri": postResult.URI,
 				"post_cid": postResult.CID,
 			}
+			if postResult.Warning != "" {
+				result["warning"] = postResult.Warning
+			}
 
 			if outputJSON {
 				jsonOutput, err := json.MarshalIndent(result, "", "  ")
 				if err != nil {
-					fmt.Println("Error formatting JSON:", err)
-					return
+					return fmt.Errorf("error formatting JSON: %w", err)
 				}
 				fmt.Println(string(jsonOutput))
 			} else {
 				fmt.Println("Post submitted successfully!")
 				fmt.Println("Text:", text)
 				fmt.Println("URI:", postResult.URI)
+				if postResult.Warning != "" {
+					fmt.Fprintln(os.Stderr, "Warning:", postResult.Warning)
+				}
 			}
+
+			return nil
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringVar(&text, "text", "", "Text content of the post to submit")
+	cmd.Flags().StringVar(&replyTo, "reply-to", "", "Post to reply to (bsky.app URL, AT URI, or handle/rkey)")
+	cmd.Flags().StringVar(&quote, "quote", "", "Post to quote (bsky.app URL, AT URI, or handle/rkey)")
+	cmd.Flags().BoolVar(&force, "force", false, "Submit even if the same text was posted recently")
+	cmd.Flags().StringSliceVar(&labels, "label", nil, "Self-label for sensitive content (e.g., sexual, nudity, porn, graphic-media, !warn); can be repeated")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Searchable tag added to the post without appearing in its text; can be repeated")
+	cmd.Flags().StringSliceVar(&threadgate, "threadgate", nil, "Limit who can reply: nobody, mentioned, following or list; can be repeated")
+	cmd.Flags().StringSliceVar(&threadgateLists, "threadgate-list", nil, "List URI whose members may reply (with --threadgate list); can be repeated")
+	cmd.Flags().StringVar(&linkURI, "link", "", "URL to show as a link preview card")
+	cmd.Flags().StringVar(&linkTitle, "link-title", "", "Title of the link card (with --link)")
+	cmd.Flags().StringVar(&linkDescription, "link-description", "", "Description of the link card (with --link)")
+	cmd.Flags().StringVar(&linkThumb, "link-thumb", "", "Image file uploaded as the link card thumbnail (with --link, max 1MB)")
 	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
 
 	// Mark required flags
@@ -550,9 +1148,172 @@ func submitCmd(mockMode bool) *cobra.Command {
 	return cmd
 }
 
+// gateCmd changes who can reply to one of the user's existing posts
+func gateCmd(mockMode bool) *cobra.Command {
+	var postRef string
+	var threadgate []string
+	var threadgateLists []string
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "gate",
+		Short: "Change who can reply to one of your posts",
+		Long:  "Create or replace the threadgate of one of your existing posts, for example to stop unwanted replies.",
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PreRunE: requireFlags(
+			requiredFlag{name: "post", example: "https://bsky.app/profile/you.bsky.social/post/3k2a4b"},
+			requiredFlag{name: "threadgate", example: "nobody"},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gate := post.Threadgate{Allow: threadgate, Lists: threadgateLists}
+			if err := gate.Validate(); err != nil {
+				return newCommandError(err, "gate")
+			}
+
+			// Use mock data if in mock mode or testing environment
+			if mockMode {
+				displayGateResult(postRef, &repo.CreateRecordResult{
+					URI: "at://fake-user.bsky.social/app.bsky.feed.threadgate/mock123456",
+					CID: "bafyreia123456789mock",
+				}, outputJSON)
+				return nil
+			}
+
+			// Load configuration
+			cfg := config.LoadConfig()
+
+			result, err := post.UpdatePostGate(cfg, postRef, gate)
+			if err != nil {
+				return newCommandError(err, "gate")
+			}
+			displayGateResult(postRef, result, outputJSON)
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringVar(&postRef, "post", "", "Your post (bsky.app URL, AT URI, or handle/rkey)")
+	cmd.Flags().StringSliceVar(&threadgate, "threadgate", nil, "Who can reply: nobody, mentioned, following or list; can be repeated")
+	cmd.Flags().StringSliceVar(&threadgateLists, "threadgate-list", nil, "List URI whose members may reply (with --threadgate list); can be repeated")
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// displayGateResult prints the threadgate written for postRef
+func displayGateResult(postRef string, result *repo.CreateRecordResult, outputJSON bool) {
+	if outputJSON {
+		jsonOutput, _ := json.MarshalIndent(map[string]interface{}{
+			"updated":        true,
+			"post":           postRef,
+			"threadgate_uri": result.URI,
+			"threadgate_cid": result.CID,
+		}, "", "  ")
+		fmt.Println(string(jsonOutput))
+		return
+	}
+
+	fmt.Println("Reply settings updated!")
+	fmt.Println("Post:", postRef)
+	fmt.Println("Threadgate:", result.URI)
+}
+
+// requiredFlag is a flag a command cannot run without, with an example value
+// for the error message
+type requiredFlag struct {
+	name    string
+	example string
+}
+
+// requireFlags returns a PreRunE hook that reports every missing flag in one
+// friendly message, such as "Please provide --hashtag, e.g. --hashtag golang".
+// It runs before cobra's own required flag check, which would otherwise report
+// the flags by their bare names.
+func requireFlags(flags ...requiredFlag) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		var names, examples []string
+		for _, flag := range flags {
+			if f := cmd.Flags().Lookup(flag.name); f != nil && f.Changed {
+				continue
+			}
+			names = append(names, "--"+flag.name)
+			examples = append(examples, "--"+flag.name+" "+flag.example)
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		return validationError(fmt.Sprintf("Please provide %s, e.g. %s",
+			strings.Join(names, " and "), strings.Join(examples, " ")))
+	}
+}
+
+// fieldLabels names the length-limited input fields in error messages
+var fieldLabels = map[string]string{
+	config.FieldHashtag: "Hashtag",
+	config.FieldHandle:  "User handle",
+	config.FieldTopic:   "Topic",
+	config.FieldText:    "Post text",
+}
+
+// queuedPostMessage tells the user a post was queued for retry rather than sent
+func queuedPostMessage(id interface{}) string {
+	return fmt.Sprintf("The post could not be sent yet and was queued for retry (ID %v).\n"+
+		"It will be sent automatically; do not submit it again.", id)
+}
+
+// printQueuedPost reports a post that failed and was queued for retry
+func printQueuedPost(queued *post.QueuedError, text string, outputJSON bool) error {
+	if outputJSON {
+		jsonOutput, err := json.MarshalIndent(map[string]interface{}{
+			"submitted": false,
+			"queued":    true,
+			"queue_id":  queued.ID,
+			"text":      text,
+			"warning":   queued.Error(),
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting JSON: %w", err)
+		}
+		fmt.Println(string(jsonOutput))
+		return nil
+	}
+	fmt.Println(queuedPostMessage(queued.ID))
+	fmt.Fprintln(os.Stderr, "Warning:", queued.Err)
+	return nil
+}
+
+// formatUserFriendlyError converts technical errors into user-friendly messages
 func formatUserFriendlyError(err error, command string) string {
 	errMsg := err.Error()
 
+	// Restricted app passwords
+	if errors.Is(err, apiclient.ErrInsufficientScope) {
+		return "Your app password does not allow this action. Create an app password with the needed access\n" +
+			"in Bluesky's settings, or use your account password."
+	}
+
+	// A post queued for retry is still sent, so resubmitting it would post twice
+	var queued *post.QueuedError
+	if errors.As(err, &queued) {
+		return queuedPostMessage(queued.ID)
+	}
+
+	// Suspended or deactivated accounts, which are not a connection problem
+	var unavailable *community.AccountUnavailableError
+	if errors.As(err, &unavailable) {
+		return fmt.Sprintf("The account %s is %s on Bluesky, so its posts cannot be read.", unavailable.Actor, unavailable.Status)
+	}
+	if errors.Is(err, apiclient.ErrAccountUnavailable) {
+		return "The account is suspended or deactivated on Bluesky, so its posts cannot be read."
+	}
+
+	// Upstream data that could not be read, which is not an input problem
+	if errors.Is(err, feed.ErrMalformedFeed) {
+		return "Bluesky returned a response that could not be read. Please try again later."
+	}
+
 	// Authentication errors
 	if strings.Contains(errMsg, "missing Bluesky credentials") || 
 	   strings.Contains(errMsg, "authentication failed") {
@@ -573,9 +1334,18 @@ func formatUserFriendlyError(err error, command string) string {
 		return "The request timed out. Please try again later."
 	}
 
+	// Inputs over their length limit
+	var lengthErr *config.LengthError
+	if errors.As(err, &lengthErr) {
+		return fmt.Sprintf("%s is too long. Please keep it under %d characters.", fieldLabels[lengthErr.Field], lengthErr.Limit)
+	}
+
 	// Command-specific errors
 	switch command {
 	case "feed":
+		if strings.Contains(errMsg, "invalid hashtag") {
+			return "Invalid hashtag. Use a single tag of letters, digits or underscores (e.g., golang)."
+		}
 		if strings.Contains(errMsg, "feed analysis failed") {
 			return "Feed analysis failed. Please try with a different hashtag or fewer posts."
 		}
@@ -587,12 +1357,25 @@ func formatUserFriendlyError(err error, command string) string {
 			return "Invalid user handle format. Please use the format username.bsky.social or a valid DID."
 		}
 	case "assist", "submit":
-		if strings.Contains(errMsg, "topic too long") {
-			return "Topic is too long. Please keep it under 200 characters."
+		if errors.Is(err, post.ErrEmptyPostText) {
+			return "Post text cannot be empty. Please provide some text to post."
 		}
-		if strings.Contains(errMsg, "failed to create post") {
+		if strings.Contains(errMsg, "failed to create post") || strings.Contains(errMsg, "failed to create record") {
 			return "Failed to create post. Please check your account permissions and try again."
 		}
+		if strings.Contains(errMsg, "duplicate post") {
+			return "You posted the same text recently. Use --force to post it anyway."
+		}
+		if strings.Contains(errMsg, "failed to upload link card thumbnail") {
+			return "Failed to upload the link card thumbnail. Please check the image and try again."
+		}
+	case "gate":
+		if strings.Contains(errMsg, "not one of your posts") {
+			return "You can only change who can reply to your own posts."
+		}
+		if strings.Contains(errMsg, "invalid threadgate") {
+			return fmt.Sprintf("Invalid reply settings: %s", strings.TrimPrefix(errMsg, "invalid threadgate: "))
+		}
 	}
 
 	// If we don't have a specific message, return a generic one with the technical error
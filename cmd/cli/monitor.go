@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and clears the terminal between snapshots
+const clearScreen = "\033[H\033[2J"
+
+// serverHealth is the server's /health response
+type serverHealth struct {
+	Status     string `json:"status"`
+	Version    string `json:"version"`
+	Mode       string `json:"mode"`
+	CacheError string `json:"cache_error,omitempty"`
+	RetryQueue *struct {
+		Depth     int   `json:"depth"`
+		Delivered int64 `json:"delivered"`
+		Dropped   int64 `json:"dropped"`
+	} `json:"retry_queue,omitempty"`
+}
+
+// serverStats is the server's /stats response
+type serverStats struct {
+	FeedSources feed.SourceStats `json:"feed_sources"`
+	FeedCache   cache.Stats      `json:"feed_cache"`
+}
+
+// monitorSnapshot is one poll of the server, with the rates derived from the previous poll
+type monitorSnapshot struct {
+	Time              time.Time    `json:"time"`
+	Health            serverHealth `json:"health"`
+	Stats             serverStats  `json:"stats"`
+	CacheHitRate      *float64     `json:"cache_hit_rate,omitempty"`      // Share of feed cache lookups that hit, 0 to 1
+	AnalysesPerMinute *float64     `json:"analyses_per_minute,omitempty"` // Feed analyses served since the previous poll
+}
+
+// monitorCmd polls a running server's health and statistics
+func monitorCmd() *cobra.Command {
+	var server string
+	var interval time.Duration
+	var count int
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Watch a running server's health and statistics",
+		Long: "Poll the /health and /stats endpoints of a running server and show its status, feed cache hit rate\n" +
+			"and feed analysis rate until interrupted.",
+		// Errors are printed by main with an exit code for their category
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parsed, err := url.Parse(server)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return validationError("Please provide the server's address, e.g. --server http://localhost:3000")
+			}
+			if interval <= 0 {
+				return validationError("--interval must be positive, e.g. --interval 5s")
+			}
+			if count < 0 {
+				return validationError("--count cannot be negative")
+			}
+
+			// Ctrl-C ends monitoring without an error
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			client := &http.Client{Timeout: interval}
+			return runMonitor(ctx, os.Stdout, client, strings.TrimRight(server, "/"), interval, count, outputJSON)
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringVar(&server, "server", "http://localhost:3000", "Address of the running server")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between polls")
+	cmd.Flags().IntVar(&count, "count", 0, "Stop after this many polls, 0 to poll until interrupted")
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output one JSON object per poll")
+
+	return cmd
+}
+
+// runMonitor polls server every interval until ctx is done or count polls
+// were made, writing each snapshot to out. A failed poll is reported on
+// stderr and polling continues; an error is returned only if no poll succeeded.
+func runMonitor(ctx context.Context, out io.Writer, client *http.Client, server string, interval time.Duration, count int, outputJSON bool) error {
+	redraw := !outputJSON && out == os.Stdout && stdoutWidth() > 0
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev *monitorSnapshot
+	var lastErr error
+	succeeded := false
+	for polls := 1; ; polls++ {
+		snapshot, err := fetchMonitorSnapshot(ctx, client, server, prev)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			lastErr = err
+			fmt.Fprintf(os.Stderr, "%s  could not reach %s: %v\n", time.Now().Format("15:04:05"), server, err)
+		case outputJSON:
+			jsonOutput, _ := json.Marshal(snapshot)
+			fmt.Fprintln(out, string(jsonOutput))
+		default:
+			if redraw {
+				fmt.Fprint(out, clearScreen)
+			} else if prev != nil {
+				fmt.Fprintln(out)
+			}
+			renderMonitorSnapshot(out, server, snapshot)
+		}
+		if err == nil {
+			prev = snapshot
+			succeeded = true
+		}
+
+		if count > 0 && polls >= count {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	if !succeeded {
+		return &commandError{
+			code:    ExitNetwork,
+			message: fmt.Sprintf("Could not reach the server at %s. Please check that it is running.", server),
+			err:     lastErr,
+		}
+	}
+	return nil
+}
+
+// fetchMonitorSnapshot reads the server's /health and /stats, deriving the
+// analysis rate from prev when there is one
+func fetchMonitorSnapshot(ctx context.Context, client *http.Client, server string, prev *monitorSnapshot) (*monitorSnapshot, error) {
+	snapshot := &monitorSnapshot{Time: time.Now()}
+	if err := getServerJSON(ctx, client, server+"/health", &snapshot.Health); err != nil {
+		return nil, err
+	}
+	if err := getServerJSON(ctx, client, server+"/stats", &snapshot.Stats); err != nil {
+		return nil, err
+	}
+
+	cacheStats := snapshot.Stats.FeedCache
+	if lookups := cacheStats.Hits + cacheStats.Misses; lookups > 0 {
+		rate := float64(cacheStats.Hits) / float64(lookups)
+		snapshot.CacheHitRate = &rate
+	}
+	if prev != nil {
+		// A counter that went down means the server restarted, so the rate is unknown
+		served := totalAnalyses(snapshot.Stats.FeedSources) - totalAnalyses(prev.Stats.FeedSources)
+		if elapsed := snapshot.Time.Sub(prev.Time); served >= 0 && elapsed > 0 {
+			rate := float64(served) / elapsed.Minutes()
+			snapshot.AnalysesPerMinute = &rate
+		}
+	}
+	return snapshot, nil
+}
+
+// getServerJSON decodes the JSON response of a GET request to endpoint into v
+func getServerJSON(ctx context.Context, client *http.Client, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// totalAnalyses counts the feed analyses served from every source
+func totalAnalyses(sources feed.SourceStats) int64 {
+	return sources.Fresh + sources.Cached + sources.Stale + sources.Fallback
+}
+
+// renderMonitorSnapshot shows a snapshot as a compact summary
+func renderMonitorSnapshot(out io.Writer, server string, snapshot *monitorSnapshot) {
+	health := snapshot.Health
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s  %s  status %s", snapshot.Time.Format("15:04:05"), server, health.Status)
+	if health.Mode != "" {
+		fmt.Fprintf(w, " (%s)", health.Mode)
+	}
+	fmt.Fprintln(w)
+	if health.CacheError != "" {
+		fmt.Fprintf(w, "Cache error\t%s\n", health.CacheError)
+	}
+
+	cacheStats := snapshot.Stats.FeedCache
+	if snapshot.CacheHitRate != nil {
+		fmt.Fprintf(w, "Feed cache\thit rate %.1f%% (%d hits, %d misses), %d entries\n",
+			*snapshot.CacheHitRate*100, cacheStats.Hits, cacheStats.Misses, cacheStats.Size)
+	} else {
+		fmt.Fprintf(w, "Feed cache\tno lookups yet, %d entries\n", cacheStats.Size)
+	}
+
+	sources := snapshot.Stats.FeedSources
+	fmt.Fprintf(w, "Feed analyses\t%d served: %d fresh, %d cached, %d stale, %d fallback\n",
+		totalAnalyses(sources), sources.Fresh, sources.Cached, sources.Stale, sources.Fallback)
+	if snapshot.AnalysesPerMinute != nil {
+		fmt.Fprintf(w, "Rate\t%.1f analyses/min\n", *snapshot.AnalysesPerMinute)
+	}
+
+	if queue := health.RetryQueue; queue != nil {
+		fmt.Fprintf(w, "Retry queue\tdepth %d, %d delivered, %d dropped\n", queue.Depth, queue.Delivered, queue.Dropped)
+	}
+	w.Flush()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newStubStatusServer serves canned /health and /stats responses; each /stats
+// response reports 30 more cached feed analyses than the one before
+func newStubStatusServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var statsCalls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/health":
+			fmt.Fprint(w, `{"status":"degraded","version":"1.0.0","mode":"live","cache_error":"disk full",
+				"retry_queue":{"depth":2,"delivered":5,"dropped":1}}`)
+		case "/stats":
+			cached := 30 * atomic.AddInt64(&statsCalls, 1)
+			fmt.Fprintf(w, `{"feed_sources":{"fresh":10,"cached":%d,"stale":1,"fallback":0},
+				"feed_cache":{"hits":%d,"misses":10,"size":42}}`, cached, cached)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunMonitor(t *testing.T) {
+	server := newStubStatusServer(t)
+
+	var out bytes.Buffer
+	if err := runMonitor(context.Background(), &out, server.Client(), server.URL, 10*time.Millisecond, 2, false); err != nil {
+		t.Fatalf("runMonitor() unexpected error: %v", err)
+	}
+
+	rendered := out.String()
+	for _, want := range []string{
+		"status degraded (live)",
+		"Cache error    disk full",
+		"hit rate 75.0% (30 hits, 10 misses), 42 entries",
+		"41 served: 10 fresh, 30 cached, 1 stale, 0 fallback",
+		"hit rate 85.7% (60 hits, 10 misses)",
+		"analyses/min",
+		"Retry queue    depth 2, 5 delivered, 1 dropped",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("Expected the summary to contain %q, got:\n%s", want, rendered)
+		}
+	}
+	// The rate needs a previous poll
+	if strings.Count(rendered, "analyses/min") != 1 {
+		t.Errorf("Expected a rate on the second poll only, got:\n%s", rendered)
+	}
+}
+
+func TestRunMonitorJSON(t *testing.T) {
+	server := newStubStatusServer(t)
+
+	var out bytes.Buffer
+	if err := runMonitor(context.Background(), &out, server.Client(), server.URL, 10*time.Millisecond, 2, true); err != nil {
+		t.Fatalf("runMonitor() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one JSON line per poll, got %q", out.String())
+	}
+	var snapshot monitorSnapshot
+	if err := json.Unmarshal([]byte(lines[1]), &snapshot); err != nil {
+		t.Fatalf("Second poll is not JSON: %v", err)
+	}
+	if snapshot.Health.Status != "degraded" || snapshot.Stats.FeedCache.Hits != 60 ||
+		snapshot.CacheHitRate == nil || snapshot.AnalysesPerMinute == nil || *snapshot.AnalysesPerMinute <= 0 {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+}
+
+func TestRunMonitorStopsWhenCancelled(t *testing.T) {
+	server := newStubStatusServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runMonitor(ctx, &bytes.Buffer{}, server.Client(), server.URL, time.Hour, 0, false)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runMonitor() after interrupt = %v, want a clean exit", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runMonitor() did not stop when cancelled")
+	}
+}
+
+func TestRunMonitorUnreachable(t *testing.T) {
+	server := newStubStatusServer(t)
+	server.Close()
+
+	var err error
+	captureOutput(func() {
+		err = runMonitor(context.Background(), &bytes.Buffer{}, server.Client(), server.URL, time.Millisecond, 1, false)
+	})
+	if exitCode(err) != ExitNetwork || !strings.Contains(err.Error(), "Could not reach the server") {
+		t.Errorf("runMonitor() error = %v (exit %d), want a network error", err, exitCode(err))
+	}
+}
+
+func TestMonitorCommandValidation(t *testing.T) {
+	for _, args := range [][]string{
+		{"monitor", "--server", "localhost:3000"},
+		{"monitor", "--interval", "0s"},
+	} {
+		var code int
+		captureOutput(func() {
+			rootCmd := setupRootCommand()
+			rootCmd.SetArgs(args)
+			code = execute(rootCmd)
+		})
+		if code != ExitValidation {
+			t.Errorf("%v: expected exit code %d, got %d", args, ExitValidation, code)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"unicode"
+)
+
+// minAutoTruncate keeps detected truncation lengths readable on very narrow terminals
+const minAutoTruncate = 20
+
+// terminalWidth returns the width of the terminal on stdout, preferring $COLUMNS,
+// or 0 when stdout is not a terminal
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return stdoutWidth()
+}
+
+// truncationLength resolves the --truncate flag. A negative value means the
+// post text fills the rest of the terminal line after a prefix of prefixLen
+// characters, or fallback when the width is unknown.
+func truncationLength(flagValue, prefixLen, fallback int) int {
+	if flagValue >= 0 {
+		return flagValue
+	}
+	width := terminalWidth()
+	if width <= 0 {
+		return fallback
+	}
+	if width-prefixLen < minAutoTruncate {
+		return minAutoTruncate
+	}
+	return width - prefixLen
+}
+
+// truncateText shortens text to at most maxLen characters, ending in "...".
+// It cuts between runes, never inside a UTF-8 sequence, and keeps accents,
+// emoji modifiers and joined emoji with the character they belong to.
+// A maxLen of 0 disables truncation.
+func truncateText(text string, maxLen int) string {
+	runes := []rune(text)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return text
+	}
+	if maxLen <= 3 {
+		return string(runes[:clusterStart(runes, maxLen)])
+	}
+	return string(runes[:clusterStart(runes, maxLen-3)]) + "..."
+}
+
+// clusterStart moves cut back to the start of the character it falls in
+func clusterStart(runes []rune, cut int) int {
+	for cut > 0 && cut < len(runes) && (attachesToPrevious(runes[cut]) || runes[cut-1] == '\u200D') {
+		cut--
+	}
+	return cut
+}
+
+// attachesToPrevious reports whether r is drawn as part of the character before
+// it: a combining mark, variation selector, zero width joiner or skin tone
+func attachesToPrevious(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == '\u200D' ||
+		(r >= 0x1F3FB && r <= 0x1F3FF)
+}
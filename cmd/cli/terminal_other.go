@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+// stdoutWidth is unknown on this platform, so $COLUMNS or the fixed lengths are used
+func stdoutWidth() int {
+	return 0
+}
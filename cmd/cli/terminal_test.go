@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{name: "Short text", text: "Hello", maxLen: 10, want: "Hello"},
+		{name: "Exact length", text: "Hello", maxLen: 5, want: "Hello"},
+		{name: "Truncated", text: "Hello, world", maxLen: 8, want: "Hello..."},
+		{name: "Zero disables truncation", text: "Hello, world", maxLen: 0, want: "Hello, world"},
+		{name: "Too short for ellipsis", text: "Hello", maxLen: 2, want: "He"},
+		{name: "Multibyte characters", text: "こんにちは世界", maxLen: 5, want: "こん..."},
+		{name: "Two-byte characters", text: "Ça déménage très vite", maxLen: 9, want: "Ça dém..."},
+		{name: "Four-byte characters", text: "🎉🎉🎉🎉🎉🎉", maxLen: 5, want: "🎉🎉..."},
+		{name: "Multibyte without ellipsis", text: "世界", maxLen: 1, want: "世"},
+		{name: "Combining accent not split", text: "cafe\u0301 au lait", maxLen: 7, want: "caf..."},
+		{name: "Combining accent kept", text: "cafe\u0301 au lait", maxLen: 8, want: "cafe\u0301..."},
+		{name: "Skin tone kept", text: "hi 👋🏽 there", maxLen: 7, want: "hi ..."},
+		{name: "Joined emoji kept", text: "ok 👩\u200D💻 done", maxLen: 8, want: "ok ..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateText(tt.text, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateText(%q, %d) = %q, not valid UTF-8", tt.text, tt.maxLen, got)
+			}
+		})
+	}
+}
+
+func TestTruncationLength(t *testing.T) {
+	// Explicit values are used as given
+	t.Setenv("COLUMNS", "120")
+	if got := truncationLength(40, 6, 60); got != 40 {
+		t.Errorf("truncationLength(40) = %d, want 40", got)
+	}
+	if got := truncationLength(0, 6, 60); got != 0 {
+		t.Errorf("truncationLength(0) = %d, want 0", got)
+	}
+
+	// The default fills the terminal line after the prefix
+	if got := truncationLength(-1, 6, 60); got != 114 {
+		t.Errorf("truncationLength(-1) with 120 columns = %d, want 114", got)
+	}
+	t.Setenv("COLUMNS", "10")
+	if got := truncationLength(-1, 6, 60); got != minAutoTruncate {
+		t.Errorf("truncationLength(-1) with 10 columns = %d, want %d", got, minAutoTruncate)
+	}
+
+	// Tests write to a pipe, so without $COLUMNS the width is unknown
+	t.Setenv("COLUMNS", "")
+	if got := truncationLength(-1, 6, 60); got != 60 {
+		t.Errorf("truncationLength(-1) without a terminal = %d, want 60", got)
+	}
+}
+
+func TestCommunityCommandTruncate(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	long := "Another sample post from test.user talking about something interesting"
+
+	output, err := testExecuteCommand(setupRootCommand(), "community", "--user", "test.user", "--truncate", "20")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "2. Another sample po...\n") {
+		t.Errorf("Expected posts truncated to 20 characters, got: %s", output)
+	}
+
+	output, err = testExecuteCommand(setupRootCommand(), "community", "--user", "test.user", "--truncate", "0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, long) {
+		t.Errorf("Expected the full post with --truncate 0, got: %s", output)
+	}
+}
+
+func TestFeedCommandTruncate(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+
+	output, err := testExecuteCommand(setupRootCommand(), "feed", "--hashtag", "golang", "--truncate", "12")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Post: This is a...\n") {
+		t.Errorf("Expected posts truncated to 12 characters, got: %s", output)
+	}
+}
@@ -4,6 +4,7 @@ package fallbacks
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,6 +13,9 @@ import (
 	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 )
 
+// MaxFallbackFileSize is the largest fallback file, in bytes, that will be loaded
+var MaxFallbackFileSize int64 = 1 << 20
+
 var (
 	fallbacksPath = "./configs/fallbacks"
 	loaderOnce    sync.Once
@@ -24,14 +28,27 @@ func InitializeFallbacks(client *apiclient.BlueskyClient) error {
 	
 	loaderOnce.Do(func() {
 		// Load timeline fallback
-		timelineData, err := loadFallbackFile("timeline.json")
+		timelineData, err := loadFallbackFile("timeline.json", validateTimeline)
 		if err != nil {
 			initErr = fmt.Errorf("failed to load timeline fallback: %w", err)
 			return
 		}
 		
+		// Load search fallback, featured posts served for hashtag searches;
+		// deployments can leave it out of the fallbacks directory
+		searchData, err := loadFallbackFile("search.json", validateSearch)
+		if err != nil && !os.IsNotExist(err) {
+			initErr = fmt.Errorf("failed to load search fallback: %w", err)
+			return
+		}
+		
 		// Register fallback responses
 		client.RegisterFallbackResponse("app.bsky.feed.getTimeline", timelineData)
+		if searchData != nil {
+			client.RegisterFallbackResponse("app.bsky.feed.searchPosts", searchData)
+		} else {
+			log.Println("No search fallback found, searches will fail while the API is unavailable")
+		}
 		
 		// Add more fallbacks as needed
 		
@@ -42,28 +59,93 @@ func InitializeFallbacks(client *apiclient.BlueskyClient) error {
 	return initErr
 }
 
-// loadFallbackFile loads a fallback JSON file from the fallbacks directory
-func loadFallbackFile(filename string) ([]byte, error) {
+// loadFallbackFile loads a fallback JSON file from the fallbacks directory,
+// rejecting files over MaxFallbackFileSize or that validate does not accept
+func loadFallbackFile(filename string, validate func(data []byte) error) ([]byte, error) {
 	filePath, err := filepath.Abs(filepath.Join(fallbacksPath, filename))
 	if err != nil {
 		return nil, err
 	}
 	
-	// Read the file
-	data, err := os.ReadFile(filePath)
+	// Read the file, refusing to load more than the size limit
+	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxFallbackFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fallback file %s: %w", filename, err)
+	}
+	if int64(len(data)) > MaxFallbackFileSize {
+		return nil, fmt.Errorf("fallback file %s is larger than %d bytes", filename, MaxFallbackFileSize)
+	}
 	
 	// Validate JSON
 	var testJSON interface{}
 	if err := json.Unmarshal(data, &testJSON); err != nil {
 		return nil, fmt.Errorf("invalid JSON in fallback file %s: %w", filename, err)
 	}
+
+	// Validate that the file is the response it is registered for
+	if err := validate(data); err != nil {
+		return nil, fmt.Errorf("unexpected content in fallback file %s: %w", filename, err)
+	}
 	
 	return data, nil
 }
 
+// timelineResponse is the part of a getTimeline response the fallback must have
+type timelineResponse struct {
+	Feed *[]struct {
+		Post *struct {
+			URI string `json:"uri"`
+		} `json:"post"`
+	} `json:"feed"`
+}
+
+// validateTimeline checks that data is shaped like an app.bsky.feed.getTimeline response
+func validateTimeline(data []byte) error {
+	var timeline timelineResponse
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		return fmt.Errorf("not a timeline response: %w", err)
+	}
+	if timeline.Feed == nil {
+		return fmt.Errorf("not a timeline response: missing feed array")
+	}
+	for i, item := range *timeline.Feed {
+		if item.Post == nil || item.Post.URI == "" {
+			return fmt.Errorf("not a timeline response: feed item %d has no post URI", i)
+		}
+	}
+	return nil
+}
+
+// searchResponse is the part of a searchPosts response the fallback must have
+type searchResponse struct {
+	Posts *[]struct {
+		URI string `json:"uri"`
+	} `json:"posts"`
+}
+
+// validateSearch checks that data is shaped like an app.bsky.feed.searchPosts response
+func validateSearch(data []byte) error {
+	var search searchResponse
+	if err := json.Unmarshal(data, &search); err != nil {
+		return fmt.Errorf("not a search response: %w", err)
+	}
+	if search.Posts == nil {
+		return fmt.Errorf("not a search response: missing posts array")
+	}
+	for i, post := range *search.Posts {
+		if post.URI == "" {
+			return fmt.Errorf("not a search response: post %d has no URI", i)
+		}
+	}
+	return nil
+}
+
 // IsInitialized returns whether fallbacks have been successfully initialized
 func IsInitialized() bool {
 	return initialized
@@ -0,0 +1,112 @@
+package fallbacks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useFallbackDir points the loader at a temporary directory containing files
+func useFallbackDir(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	originalPath := fallbacksPath
+	fallbacksPath = dir
+	t.Cleanup(func() { fallbacksPath = originalPath })
+}
+
+func TestLoadFallbackFileTimeline(t *testing.T) {
+	timeline := `{"feed": [{"post": {"uri": "at://did:plc:fallback/feed/1", "record": {"text": "Cached"}}}]}`
+	useFallbackDir(t, map[string]string{"timeline.json": timeline})
+
+	data, err := loadFallbackFile("timeline.json", validateTimeline)
+	if err != nil {
+		t.Fatalf("loadFallbackFile() unexpected error: %v", err)
+	}
+	if string(data) != timeline {
+		t.Errorf("loadFallbackFile() = %s, want the file contents", data)
+	}
+}
+
+func TestLoadFallbackFileShippedTimeline(t *testing.T) {
+	// The timeline shipped with the repository must pass its own validation;
+	// tests run in this package's directory
+	originalPath := fallbacksPath
+	fallbacksPath = "."
+	defer func() { fallbacksPath = originalPath }()
+
+	if _, err := loadFallbackFile("timeline.json", validateTimeline); err != nil {
+		t.Errorf("Shipped timeline.json rejected: %v", err)
+	}
+}
+
+func TestLoadFallbackFileRejected(t *testing.T) {
+	originalMax := MaxFallbackFileSize
+	MaxFallbackFileSize = 64
+	defer func() { MaxFallbackFileSize = originalMax }()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "Oversized file", content: `{"feed": [` + strings.Repeat(" ", 64) + `]}`, wantErr: "larger than 64 bytes"},
+		{name: "Invalid JSON", content: `{"feed": [`, wantErr: "invalid JSON"},
+		{name: "Wrong shape", content: `{"posts": []}`, wantErr: "missing feed array"},
+		{name: "Feed of the wrong type", content: `{"feed": {"post": {}}}`, wantErr: "not a timeline response"},
+		{name: "Item without a post", content: `{"feed": [{"uri": "x"}]}`, wantErr: "feed item 0 has no post URI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFallbackDir(t, map[string]string{"timeline.json": tt.content})
+
+			_, err := loadFallbackFile("timeline.json", validateTimeline)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if !strings.Contains(err.Error(), "timeline.json") {
+				t.Errorf("Error %q should name the file", err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadFallbackFileShippedSearch(t *testing.T) {
+	originalPath := fallbacksPath
+	fallbacksPath = "."
+	defer func() { fallbacksPath = originalPath }()
+
+	if _, err := loadFallbackFile("search.json", validateSearch); err != nil {
+		t.Errorf("Shipped search.json rejected: %v", err)
+	}
+}
+
+func TestLoadFallbackFileSearchRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "Timeline shape", content: `{"feed": []}`, wantErr: "missing posts array"},
+		{name: "Post without a URI", content: `{"posts": [{"record": {"text": "x"}}]}`, wantErr: "post 0 has no URI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFallbackDir(t, map[string]string{"search.json": tt.content})
+
+			_, err := loadFallbackFile("search.json", validateSearch)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
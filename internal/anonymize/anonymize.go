@@ -0,0 +1,80 @@
+// Package anonymize replaces account handles and DIDs with stable pseudonyms,
+// so results can be shared and aggregated without naming the accounts in them
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Prefix starts every pseudonym
+const Prefix = "anon-"
+
+// pseudonymLength is the number of hex digits of the keyed hash kept
+const pseudonymLength = 16
+
+// The secret pseudonyms are derived from, random until SetKey is called
+var (
+	key   = randomKey()
+	keyMu sync.RWMutex
+)
+
+// randomKey returns a key that lives as long as the process
+func randomKey() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic("anonymize: cannot generate a key: " + err.Error())
+	}
+	return secret
+}
+
+// SetKey sets the secret pseudonyms are derived from, so that the same key
+// gives the same pseudonyms across restarts. An empty secret keeps a random
+// key, and pseudonyms then change with every restart.
+func SetKey(secret string) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	if secret == "" {
+		key = randomKey()
+		return
+	}
+	key = []byte(secret)
+}
+
+// KeyFromEnv returns the secret set in BSKY_ANONYMIZE_KEY
+func KeyFromEnv() string {
+	return os.Getenv("BSKY_ANONYMIZE_KEY")
+}
+
+// Pseudonym returns the pseudonym of an account handle or DID, such as
+// "anon-3f2a9c0d1e4b5a67". Handles are compared without case or a leading "@",
+// so every spelling of one account gets the same pseudonym. The pseudonym is a
+// keyed hash, which cannot be reversed by hashing known handles without the key.
+func Pseudonym(identity string) string {
+	identity = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(identity), "@"))
+	if identity == "" {
+		return ""
+	}
+
+	keyMu.RLock()
+	mac := hmac.New(sha256.New, key)
+	keyMu.RUnlock()
+	mac.Write([]byte(identity))
+	return Prefix + hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
+
+// Text returns text with every occurrence of each of identities replaced by
+// its pseudonym, for messages that name an account
+func Text(text string, identities ...string) string {
+	for _, identity := range identities {
+		if identity != "" {
+			text = strings.ReplaceAll(text, identity, Pseudonym(identity))
+		}
+	}
+	return text
+}
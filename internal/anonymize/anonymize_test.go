@@ -0,0 +1,48 @@
+package anonymize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPseudonym(t *testing.T) {
+	SetKey("test-key")
+	t.Cleanup(func() { SetKey("") })
+
+	alice := Pseudonym("alice.bsky.social")
+	if !strings.HasPrefix(alice, Prefix) || len(alice) != len(Prefix)+pseudonymLength {
+		t.Errorf("Pseudonym() = %q, want %s and %d hex digits", alice, Prefix, pseudonymLength)
+	}
+	if strings.Contains(alice, "alice") {
+		t.Errorf("Pseudonym() = %q names the account", alice)
+	}
+
+	// The same account always gets the same pseudonym, however it is written
+	for _, spelling := range []string{"alice.bsky.social", "@Alice.bsky.social", " ALICE.BSKY.SOCIAL "} {
+		if got := Pseudonym(spelling); got != alice {
+			t.Errorf("Pseudonym(%q) = %q, want %q", spelling, got, alice)
+		}
+	}
+
+	// Different accounts get different ones
+	if bob := Pseudonym("bob.bsky.social"); bob == alice {
+		t.Errorf("Pseudonym() gave alice and bob the same pseudonym %q", bob)
+	}
+	if Pseudonym("") != "" {
+		t.Error("Expected no pseudonym for an empty identity")
+	}
+
+	// Another key gives other pseudonyms
+	SetKey("other-key")
+	if Pseudonym("alice.bsky.social") == alice {
+		t.Error("Expected the pseudonym to depend on the key")
+	}
+}
+
+func TestText(t *testing.T) {
+	got := Text("account bob.bsky.social is suspended", "bob.bsky.social")
+	want := "account " + Pseudonym("bob.bsky.social") + " is suspended"
+	if got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +22,7 @@ type Session struct {
 	RefreshJWT string    `json:"refreshJwt"`
 	Handle     string    `json:"handle"`
 	DID        string    `json:"did"`
+	IssuedAt   time.Time // Local issue time, with ExpiresAt giving the session's lifetime
 	ExpiresAt  time.Time // Local expiration tracking (not from API)
 }
 
@@ -34,11 +37,31 @@ type TokenManager struct {
 	retryConfig    RetryConfig
 	sessionLock    sync.Mutex
 	refreshBackoff backoff.BackOff
+	credentialSet  string
+	authHost       string              // Host that issued the session, where it is refreshed
+	sessionCache   config.SessionCache // Where sessions are persisted; zero when they are not
+	sessionOwner   string              // BskyID the persisted sessions belong to
 }
 
-// RetryConfig defines retry behavior for authentication
+// SessionInfo describes the currently authenticated session
+type SessionInfo struct {
+	Handle        string    `json:"handle"`
+	DID           string    `json:"did"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CredentialSet string    `json:"credential_set"`
+}
+
+// Credential set names reported in SessionInfo
+const (
+	CredentialSetPrimary = "primary"
+	CredentialSetBackup  = "backup"
+)
+
+// RetryConfig defines retry behavior for authentication.
+// Retrying stops at whichever of MaxRetries or MaxElapsedTime is reached first;
+// a zero value disables that limit.
 type RetryConfig struct {
-	MaxRetries      int
+	MaxRetries      int // Retries after the first attempt
 	InitialInterval time.Duration
 	MaxInterval     time.Duration
 	Multiplier      float64
@@ -54,8 +77,125 @@ var DefaultRetryConfig = RetryConfig{
 	MaxElapsedTime:  2 * time.Minute,
 }
 
-// refreshThreshold is how long before expiration we should refresh
-const refreshThreshold = 5 * time.Minute
+// RefreshLead is how long before a session expires it is refreshed in the
+// background. A Fraction between 0 and 1 sets the lead to that part of the
+// session's lifetime instead of Duration, so short-lived sessions are refreshed
+// in time too. A Duration of half the lifetime or more is cut to half, so a
+// session is not refreshed as soon as it is created.
+type RefreshLead struct {
+	Duration time.Duration `json:"duration"`
+	Fraction float64       `json:"fraction"`
+}
+
+// DefaultRefreshLead refreshes sessions five minutes before they expire
+var DefaultRefreshLead = RefreshLead{Duration: 5 * time.Minute}
+
+// RefreshLeadFromEnv returns the default lead adjusted by BSKY_REFRESH_LEAD,
+// either a duration such as "2m" or a fraction of the session lifetime such
+// as "0.2". Invalid values are ignored.
+func RefreshLeadFromEnv() RefreshLead {
+	value := os.Getenv("BSKY_REFRESH_LEAD")
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return RefreshLead{Duration: duration}
+	}
+	if fraction, err := strconv.ParseFloat(value, 64); err == nil && fraction > 0 && fraction < 1 {
+		return RefreshLead{Fraction: fraction}
+	}
+	return DefaultRefreshLead
+}
+
+// lead returns how long before expiresAt a session issued at issuedAt is
+// refreshed. Without a known issue time the lifetime is unknown and Duration,
+// or the default if only Fraction is set, is used as is.
+func (l RefreshLead) lead(issuedAt, expiresAt time.Time) time.Duration {
+	duration := l.Duration
+	if duration <= 0 {
+		duration = DefaultRefreshLead.Duration
+	}
+	if issuedAt.IsZero() || !expiresAt.After(issuedAt) {
+		return duration
+	}
+
+	lifetime := expiresAt.Sub(issuedAt)
+	if l.Fraction > 0 && l.Fraction < 1 {
+		return time.Duration(float64(lifetime) * l.Fraction)
+	}
+	if duration >= lifetime/2 {
+		return lifetime / 2
+	}
+	return duration
+}
+
+// Shared refresh lead
+var (
+	refreshLead   = DefaultRefreshLead
+	refreshLeadMu sync.RWMutex
+)
+
+// SetRefreshLead changes how long before expiry sessions are refreshed
+func SetRefreshLead(lead RefreshLead) {
+	refreshLeadMu.Lock()
+	defer refreshLeadMu.Unlock()
+	refreshLead = lead
+}
+
+// getRefreshLead returns the current refresh lead
+func getRefreshLead() RefreshLead {
+	refreshLeadMu.RLock()
+	defer refreshLeadMu.RUnlock()
+	return refreshLead
+}
+
+// DefaultClockSkew is how much the local clock may differ from the Bluesky
+// server's before a token is treated as expired too late
+const DefaultClockSkew = 30 * time.Second
+
+// ClockSkewFromEnv returns the clock skew allowance set in BSKY_CLOCK_SKEW,
+// a duration such as "1m" or "0s" to allow none, or DefaultClockSkew if it is
+// unset or invalid
+func ClockSkewFromEnv() time.Duration {
+	if skew, err := time.ParseDuration(os.Getenv("BSKY_CLOCK_SKEW")); err == nil && skew >= 0 {
+		return skew
+	}
+	return DefaultClockSkew
+}
+
+// Shared clock skew allowance
+var (
+	clockSkew   = DefaultClockSkew
+	clockSkewMu sync.RWMutex
+)
+
+// SetClockSkew changes the clock skew allowance; a negative skew allows none
+func SetClockSkew(skew time.Duration) {
+	if skew < 0 {
+		skew = 0
+	}
+	clockSkewMu.Lock()
+	defer clockSkewMu.Unlock()
+	clockSkew = skew
+}
+
+// getClockSkew returns the current clock skew allowance
+func getClockSkew() time.Duration {
+	clockSkewMu.RLock()
+	defer clockSkewMu.RUnlock()
+	return clockSkew
+}
+
+// usableUntil returns when a session issued at issuedAt and expiring at
+// expiresAt stops being used: skew before it expires, so a token is not sent
+// when the server, with its clock ahead of ours, already considers it expired.
+// The skew is cut to a quarter of the session's lifetime, when that is known,
+// so short-lived sessions are still used for most of it.
+func usableUntil(issuedAt, expiresAt time.Time, skew time.Duration) time.Time {
+	if !issuedAt.IsZero() && expiresAt.After(issuedAt) {
+		if lifetime := expiresAt.Sub(issuedAt); skew > lifetime/4 {
+			skew = lifetime / 4
+		}
+	}
+	return expiresAt.Add(-skew)
+}
 
 // Global token manager instance
 var (
@@ -68,20 +208,31 @@ type BackupCredentials struct {
 	BskyID       string
 	BskyPassword string
 	BskyHost     string
+	AuthHost     string // Host to authenticate on; empty uses BskyHost, then the main config's host
 }
 
-// Global backup credentials
-var backupCredentials []BackupCredentials
+// Global backup credentials, guarded by their own lock so they can be
+// registered while sessions are being created
+var (
+	backupCredentials   []BackupCredentials
+	backupCredentialsMu sync.RWMutex
+)
 
-// RegisterBackupCredentials registers alternative authentication credentials
+// RegisterBackupCredentials registers alternative authentication credentials.
+// It is safe to call while sessions are being created.
 func RegisterBackupCredentials(credentials BackupCredentials) {
-	if manager != nil {
-		manager.mutex.Lock()
-		defer manager.mutex.Unlock()
-	}
+	backupCredentialsMu.Lock()
+	defer backupCredentialsMu.Unlock()
 	backupCredentials = append(backupCredentials, credentials)
 }
 
+// getBackupCredentials returns a copy of the registered backup credentials
+func getBackupCredentials() []BackupCredentials {
+	backupCredentialsMu.RLock()
+	defer backupCredentialsMu.RUnlock()
+	return append([]BackupCredentials(nil), backupCredentials...)
+}
+
 // GetTokenManager returns the shared token manager instance
 func GetTokenManager(cfg config.Config) *TokenManager {
 	once.Do(func() {
@@ -101,6 +252,12 @@ func GetTokenManager(cfg config.Config) *TokenManager {
 			retryConfig:    DefaultRetryConfig,
 			refreshBackoff: bOff,
 		}
+
+		// Re-authenticate once and replay requests rejected with an expired token
+		tm := manager
+		tm.client.SetReauthFunc(func() (string, error) {
+			return tm.Reauthenticate(cfg)
+		})
 	})
 	return manager
 }
@@ -125,6 +282,9 @@ func (tm *TokenManager) GetToken(cfg config.Config) (string, error) {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
+	// After a restart, continue with the session persisted on disk
+	tm.useSessionCacheUnlocked(cfg)
+
 	// Double-check token validity after acquiring write lock
 	token, valid = tm.getValidTokenUnlocked()
 	if valid {
@@ -144,6 +304,47 @@ func (tm *TokenManager) GetToken(cfg config.Config) (string, error) {
 	return tm.createSessionWithRetries(cfg)
 }
 
+// DefaultProbeTimeout bounds the startup authentication check
+const DefaultProbeTimeout = 30 * time.Second
+
+// Probe authenticates once, falling back to backup credentials like GetToken,
+// and reports the resulting session. It gives up once timeout elapses.
+func (tm *TokenManager) Probe(cfg config.Config, timeout time.Duration) (SessionInfo, error) {
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := tm.GetToken(cfg)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return SessionInfo{}, err
+		}
+		return tm.GetSessionInfo(), nil
+	case <-time.After(timeout):
+		return SessionInfo{}, fmt.Errorf("authentication timeout after %s", timeout)
+	}
+}
+
+// Reauthenticate discards the current access token and obtains a new one,
+// refreshing the session when possible and creating a new one otherwise
+func (tm *TokenManager) Reauthenticate(cfg config.Config) (string, error) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.useSessionCacheUnlocked(cfg)
+	tm.session.AccessJWT = ""
+
+	if tm.session.RefreshJWT != "" {
+		if err := tm.refreshSessionWithRetries(cfg); err == nil {
+			return tm.session.AccessJWT, nil
+		}
+	}
+
+	return tm.createSessionWithRetries(cfg)
+}
+
 // GetClient returns the token manager's client instance
 func (tm *TokenManager) GetClient() *apiclient.BlueskyClient {
 	return tm.client
@@ -156,21 +357,37 @@ func (tm *TokenManager) GetDID() string {
 	return tm.session.DID
 }
 
+// GetSessionInfo returns details about the current session, including which
+// credential set was used to create it
+func (tm *TokenManager) GetSessionInfo() SessionInfo {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+	return SessionInfo{
+		Handle:        tm.session.Handle,
+		DID:           tm.session.DID,
+		ExpiresAt:     tm.session.ExpiresAt,
+		CredentialSet: tm.credentialSet,
+	}
+}
+
 // getValidTokenUnlocked checks if we have a valid token (must be called with lock held)
 func (tm *TokenManager) getValidTokenUnlocked() (string, bool) {
 	if tm.session.AccessJWT == "" {
 		return "", false
 	}
 
+	// Tokens in the clock skew allowance before expiry are treated as expired
 	now := time.Now()
+	expiresAt := usableUntil(tm.session.IssuedAt, tm.session.ExpiresAt, getClockSkew())
+	lead := getRefreshLead().lead(tm.session.IssuedAt, expiresAt)
 	// If token is valid but close to expiration, schedule refresh
-	if now.Add(refreshThreshold).After(tm.session.ExpiresAt) && now.Before(tm.session.ExpiresAt) && !tm.refreshing {
+	if now.Add(lead).After(expiresAt) && now.Before(expiresAt) && !tm.refreshing {
 		// Don't wait for refresh, return current token and refresh in background
 		go tm.refreshInBackground()
 	}
 
 	// Check if token is still valid
-	if now.Before(tm.session.ExpiresAt) {
+	if now.Before(expiresAt) {
 		if isValidJWT(tm.session.AccessJWT) {
 			return tm.session.AccessJWT, true
 		}
@@ -189,7 +406,10 @@ func (tm *TokenManager) refreshInBackground() {
 	
 	tm.refreshing = true
 	refreshToken := tm.session.RefreshJWT
-	host := tm.client.BaseURL 
+	host := tm.client.BaseURL
+	if tm.authHost != "" {
+		host = tm.authHost
+	}
 	tm.mutex.Unlock()
 
 	defer func() {
@@ -237,10 +457,12 @@ func (tm *TokenManager) refreshInBackground() {
 		}
 
 		// Update the session
-		session.ExpiresAt = time.Now().Add(1 * time.Hour)
+		session.IssuedAt = time.Now()
+		session.ExpiresAt = session.IssuedAt.Add(1 * time.Hour)
 		
 		tm.mutex.Lock()
 		tm.session = session
+		tm.persistSessionUnlocked()
 		tm.mutex.Unlock()
 		
 		return nil
@@ -260,13 +482,22 @@ func (tm *TokenManager) refreshSessionWithRetries(cfg config.Config) error {
 
 // retryOperation executes an operation with exponential backoff retry logic
 func (tm *TokenManager) retryOperation(operation func() error) error {
-	bOff := backoff.NewExponentialBackOff()
-	bOff.InitialInterval = tm.retryConfig.InitialInterval
-	bOff.MaxInterval = tm.retryConfig.MaxInterval
-	bOff.Multiplier = tm.retryConfig.Multiplier
-	bOff.MaxElapsedTime = tm.retryConfig.MaxElapsedTime
-	
-	return backoff.Retry(func() error {
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = tm.retryConfig.InitialInterval
+	expBackoff.MaxInterval = tm.retryConfig.MaxInterval
+	expBackoff.Multiplier = tm.retryConfig.Multiplier
+	expBackoff.MaxElapsedTime = tm.retryConfig.MaxElapsedTime
+
+	// Cap the number of retries as well as the elapsed time
+	var bOff backoff.BackOff = expBackoff
+	if tm.retryConfig.MaxRetries > 0 {
+		bOff = backoff.WithMaxRetries(expBackoff, uint64(tm.retryConfig.MaxRetries))
+	}
+
+	tracer := apiclient.NewRetryTracer("authentication")
+	attempts := 0
+	err := backoff.RetryNotify(func() error {
+		attempts++
 		err := operation()
 		if err != nil && isRetryableError(err) {
 			return err // Retry on retryable errors
@@ -275,7 +506,14 @@ func (tm *TokenManager) retryOperation(operation func() error) error {
 			return backoff.Permanent(err) // Don't retry on non-retryable errors
 		}
 		return nil // Success
-	}, bOff)
+	}, bOff, tracer.Notify)
+	err = tracer.Finish(err)
+
+	// Report when the retry limits were exhausted
+	if err != nil && isRetryableError(err) {
+		return fmt.Errorf("%w (gave up after %d attempts)", err, attempts)
+	}
+	return err
 }
 
 // createSessionWithRetries creates a new session with retry logic
@@ -293,22 +531,27 @@ func (tm *TokenManager) createSessionWithRetries(cfg config.Config) (string, err
 	
 	// If main credentials succeeded, return the token
 	if err == nil {
+		tm.credentialSet = CredentialSetPrimary
+		tm.persistSessionUnlocked()
 		return token, nil
 	}
 	
-	// If main credentials failed, try backup credentials
-	if len(backupCredentials) > 0 {
-		for _, backupCfg := range backupCredentials {
+	// If main credentials failed, try backup credentials, keeping each failure
+	// so the final error shows which credentials failed and why
+	if backups := getBackupCredentials(); len(backups) > 0 {
+		failures := []error{fmt.Errorf("%s credentials (%s): %w", CredentialSetPrimary, cfg.BskyID, err)}
+		for i, backupCfg := range backups {
 			// Create temporary config from backup credentials
 			tempCfg := config.Config{
 				BskyID:       backupCfg.BskyID,
 				BskyPassword: backupCfg.BskyPassword,
 				BskyHost:     backupCfg.BskyHost,
+				AuthHost:     backupCfg.AuthHost,
 			}
 			
-			// If host is empty, use the main host
-			if tempCfg.BskyHost == "" {
-				tempCfg.BskyHost = cfg.BskyHost
+			// If host is empty, authenticate where the main credentials do
+			if tempCfg.BskyHost == "" && tempCfg.AuthHost == "" {
+				tempCfg.AuthHost = cfg.SessionHost()
 			}
 			
 			// Try with backup credentials
@@ -320,9 +563,13 @@ func (tm *TokenManager) createSessionWithRetries(cfg config.Config) (string, err
 			
 			// If successful with backup, return the token
 			if backupErr == nil {
+				tm.credentialSet = fmt.Sprintf("%s #%d", CredentialSetBackup, i+1)
+				tm.persistSessionUnlocked()
 				return token, nil
 			}
+			failures = append(failures, fmt.Errorf("%s credentials #%d (%s): %w", CredentialSetBackup, i+1, backupCfg.BskyID, backupErr))
 		}
+		err = errors.Join(failures...)
 	}
 	
 	// All attempts failed
@@ -342,8 +589,9 @@ func (tm *TokenManager) createSessionUnlocked(cfg config.Config) (string, error)
 		"password":   cfg.BskyPassword,
 	}
 
-	// Make API request
-	responseBody, err := tm.client.Post("com.atproto.server.createSession", requestBody)
+	// Make API request on the account's auth host
+	host := cfg.SessionHost()
+	responseBody, err := tm.authClient(host).Post("com.atproto.server.createSession", requestBody)
 	if err != nil {
 		return "", fmt.Errorf("authentication failed: %w", err)
 	}
@@ -355,10 +603,12 @@ func (tm *TokenManager) createSessionUnlocked(cfg config.Config) (string, error)
 	}
 
 	// Set expiration (tokens typically last 2 hours, but we'll use 1 hour to be safe)
-	session.ExpiresAt = time.Now().Add(1 * time.Hour)
+	session.IssuedAt = time.Now()
+	session.ExpiresAt = session.IssuedAt.Add(1 * time.Hour)
 	
 	// Update session
 	tm.session = session
+	tm.authHost = host
 	
 	// Update client auth token
 	tm.client.SetAuthToken(session.AccessJWT)
@@ -366,6 +616,15 @@ func (tm *TokenManager) createSessionUnlocked(cfg config.Config) (string, error)
 	return session.AccessJWT, nil
 }
 
+// authClient returns a client for host, reusing the shared client when host is
+// its own or unset so the default setup makes no extra connections
+func (tm *TokenManager) authClient(host string) *apiclient.BlueskyClient {
+	if host == "" || host == strings.TrimRight(tm.client.BaseURL, "/") {
+		return tm.client
+	}
+	return apiclient.NewClient(host)
+}
+
 // refreshSessionUnlocked refreshes an existing session (must be called with write lock held)
 func (tm *TokenManager) refreshSessionUnlocked(cfg config.Config) error {
 	// Validate refresh token
@@ -377,8 +636,8 @@ func (tm *TokenManager) refreshSessionUnlocked(cfg config.Config) error {
 		"refreshJwt": tm.session.RefreshJWT,
 	}
 
-	// Make API request
-	responseBody, err := tm.client.Post("com.atproto.server.refreshSession", requestBody)
+	// Make API request where the session was created
+	responseBody, err := tm.authClient(tm.authHost).Post("com.atproto.server.refreshSession", requestBody)
 	if err != nil {
 		return fmt.Errorf("token refresh failed: %w", err)
 	}
@@ -390,13 +649,15 @@ func (tm *TokenManager) refreshSessionUnlocked(cfg config.Config) error {
 	}
 
 	// Set expiration
-	session.ExpiresAt = time.Now().Add(1 * time.Hour)
+	session.IssuedAt = time.Now()
+	session.ExpiresAt = session.IssuedAt.Add(1 * time.Hour)
 	
 	// Update session
 	tm.session = session
 	
 	// Update client auth token
 	tm.client.SetAuthToken(session.AccessJWT)
+	tm.persistSessionUnlocked()
 	
 	return nil
 }
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// cachedSession is the session as kept on disk
+type cachedSession struct {
+	Identifier    string    `json:"identifier"` // BskyID the session was created for
+	AccessJWT     string    `json:"accessJwt"`
+	RefreshJWT    string    `json:"refreshJwt"`
+	Handle        string    `json:"handle"`
+	DID           string    `json:"did"`
+	IssuedAt      time.Time `json:"issuedAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	AuthHost      string    `json:"authHost"`
+	CredentialSet string    `json:"credentialSet"`
+}
+
+// errSessionCacheCorrupt is returned for cache files that cannot be decrypted,
+// such as ones written with another key
+var errSessionCacheCorrupt = errors.New("session cache cannot be decrypted")
+
+// sessionCipher returns an AES-256-GCM cipher keyed with the SHA-256 of secret
+func sessionCipher(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// saveCachedSession encrypts session and writes it to the cache file,
+// replacing the previous one in a single rename
+func saveCachedSession(cache config.SessionCache, session cachedSession) error {
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	aead, err := sessionCipher(cache.Key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+
+	if err := os.MkdirAll(filepath.Dir(cache.File), 0700); err != nil {
+		return err
+	}
+	tmp := cache.File + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cache.File)
+}
+
+// loadCachedSession reads and decrypts the cache file
+func loadCachedSession(cache config.SessionCache) (cachedSession, error) {
+	var session cachedSession
+	sealed, err := os.ReadFile(cache.File)
+	if err != nil {
+		return session, err
+	}
+	aead, err := sessionCipher(cache.Key)
+	if err != nil {
+		return session, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return session, errSessionCacheCorrupt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return session, errSessionCacheCorrupt
+	}
+	if err := json.Unmarshal(plaintext, &session); err != nil {
+		return session, fmt.Errorf("error parsing cached session: %w", err)
+	}
+	return session, nil
+}
+
+// useSessionCacheUnlocked starts persisting cfg's sessions, and on first use
+// restores the session kept on disk if it belongs to cfg's account. A
+// restored session that is expired or about to expire is refreshed by the
+// caller like any other. Must be called with the write lock held.
+func (tm *TokenManager) useSessionCacheUnlocked(cfg config.Config) {
+	if !cfg.SessionCache.Enabled() || tm.sessionCache == cfg.SessionCache {
+		return
+	}
+	tm.sessionCache = cfg.SessionCache
+	tm.sessionOwner = cfg.BskyID
+
+	// A session already held is newer than the one on disk
+	if tm.session.AccessJWT != "" || tm.session.RefreshJWT != "" {
+		return
+	}
+
+	cached, err := loadCachedSession(cfg.SessionCache)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Warning: could not restore the cached session, logging in again: %v", err)
+		}
+		return
+	}
+	if cached.Identifier != cfg.BskyID || cached.RefreshJWT == "" {
+		return
+	}
+
+	tm.session = Session{
+		AccessJWT:  cached.AccessJWT,
+		RefreshJWT: cached.RefreshJWT,
+		Handle:     cached.Handle,
+		DID:        cached.DID,
+		IssuedAt:   cached.IssuedAt,
+		ExpiresAt:  cached.ExpiresAt,
+	}
+	tm.authHost = cached.AuthHost
+	tm.credentialSet = cached.CredentialSet
+	tm.client.SetAuthToken(cached.AccessJWT)
+}
+
+// persistSessionUnlocked writes the current session to the session cache, if
+// one is in use. Failures are logged, as the session itself is still valid.
+// Must be called with the write lock held.
+func (tm *TokenManager) persistSessionUnlocked() {
+	if !tm.sessionCache.Enabled() {
+		return
+	}
+	err := saveCachedSession(tm.sessionCache, cachedSession{
+		Identifier:    tm.sessionOwner,
+		AccessJWT:     tm.session.AccessJWT,
+		RefreshJWT:    tm.session.RefreshJWT,
+		Handle:        tm.session.Handle,
+		DID:           tm.session.DID,
+		IssuedAt:      tm.session.IssuedAt,
+		ExpiresAt:     tm.session.ExpiresAt,
+		AuthHost:      tm.authHost,
+		CredentialSet: tm.credentialSet,
+	})
+	if err != nil {
+		log.Printf("Warning: could not cache the session: %v", err)
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
 // Item represents a cached item with expiration
@@ -20,15 +24,17 @@ type Item struct {
 
 // Stats tracks cache statistics
 type Stats struct {
-	Hits           int64 `json:"hits"`
-	Misses         int64 `json:"misses"`
-	Size           int   `json:"size"`
-	Evictions      int64 `json:"evictions"`
-	PersistHits    int64 `json:"persist_hits"`
-	PersistMisses  int64 `json:"persist_misses"`
-	PersistWrites  int64 `json:"persist_writes"`
-	PersistErrors  int64 `json:"persist_errors"`
-	StaleServed    int64 `json:"stale_served"`
+	Hits             int64  `json:"hits"`
+	Misses           int64  `json:"misses"`
+	Size             int    `json:"size"`
+	Evictions        int64  `json:"evictions"`
+	PersistHits      int64  `json:"persist_hits"`
+	PersistMisses    int64  `json:"persist_misses"`
+	PersistWrites    int64  `json:"persist_writes"`
+	PersistErrors    int64  `json:"persist_errors"`
+	PersistTrimmed   int64  `json:"persist_trimmed"` // Entries left out of the file to stay under MaxFileSize
+	StaleServed      int64  `json:"stale_served"`
+	LastPersistError string `json:"last_persist_error,omitempty"`
 }
 
 // PersistOptions defines how cache persistence works
@@ -38,16 +44,115 @@ type PersistOptions struct {
 	Filename      string        `json:"filename"`
 	SaveInterval  time.Duration `json:"save_interval"`
 	LoadOnStartup bool          `json:"load_on_startup"`
+	DirMode       os.FileMode   `json:"dir_mode"`      // Defaults to DefaultDirMode when zero
+	MaxFileSize   int64         `json:"max_file_size"` // Bytes; 0 means unlimited
+
+	// ValueDecoder turns persisted values back into the type they were cached
+	// as; nil loads them as generic JSON values, such as map[string]interface{}
+	ValueDecoder ValueDecoder `json:"-"`
+}
+
+// ValueDecoder decodes values read from the persistence file
+type ValueDecoder interface {
+	DecodeValue(data json.RawMessage) (interface{}, error)
+}
+
+// DecodeAs returns a ValueDecoder for caches whose values are all of type T,
+// so that values persisted by one run are loaded by the next as T
+func DecodeAs[T any]() ValueDecoder {
+	return typedDecoder[T]{}
+}
+
+// typedDecoder decodes values as T
+type typedDecoder[T any] struct{}
+
+func (typedDecoder[T]) DecodeValue(data json.RawMessage) (interface{}, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// jsonDecoder is the ValueDecoder used when none is set
+type jsonDecoder struct{}
+
+func (jsonDecoder) DecodeValue(data json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
 }
 
+// DefaultDirMode is the permission used for the persistence directory when none is set
+const DefaultDirMode os.FileMode = 0755
+
+// Shortest background intervals accepted; shorter positive values are raised to
+// these so the timers cannot spin
+const (
+	MinCleanupInterval = 100 * time.Millisecond
+	MinSaveInterval    = 100 * time.Millisecond
+)
+
 // CacheOptions contains configuration options for the cache
 type CacheOptions struct {
-	MaxItems         int           `json:"max_items"`
-	DefaultTTL       time.Duration `json:"default_ttl"`
-	CleanupInterval  time.Duration `json:"cleanup_interval"`
-	AllowStaleOnFail bool          `json:"allow_stale_on_fail"`
-	StaleTimeout     time.Duration `json:"stale_timeout"`
+	MaxItems         int            `json:"max_items"`
+	DefaultTTL       time.Duration  `json:"default_ttl"`
+	CleanupInterval  time.Duration  `json:"cleanup_interval"`
+	AllowStaleOnFail bool           `json:"allow_stale_on_fail"`
+	StaleTimeout     time.Duration  `json:"stale_timeout"`
 	PersistOptions   PersistOptions `json:"persist_options"`
+	Logger           Logger         `json:"-"` // Receives the cache's own messages; nil uses the standard logger (stderr)
+}
+
+// WithSettings returns the options with the non-zero fields of configured
+// settings applied. A Directory of config.CacheDirectoryOff disables
+// persistence; any other Directory enables it there.
+func (o CacheOptions) WithSettings(settings config.CacheSettings) CacheOptions {
+	if settings.MaxItems > 0 {
+		o.MaxItems = settings.MaxItems
+	}
+	if settings.TTL > 0 {
+		o.DefaultTTL = time.Duration(settings.TTL)
+	}
+	if settings.StaleTimeout > 0 {
+		o.StaleTimeout = time.Duration(settings.StaleTimeout)
+	}
+	switch settings.Directory {
+	case "":
+	case config.CacheDirectoryOff:
+		o.PersistOptions.Enabled = false
+	default:
+		o.PersistOptions.Enabled = true
+		o.PersistOptions.Directory = settings.Directory
+		if o.PersistOptions.Filename == "" {
+			o.PersistOptions.Filename = DefaultCacheOptions.PersistOptions.Filename
+		}
+	}
+	return o
+}
+
+// Logger receives the messages a cache reports about itself, such as
+// persistence setup errors. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// NopLogger discards every message, for callers that read errors from
+// GetStats and PersistError instead
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...interface{}) {}
+
+// logger returns the logger set in options, or the standard logger
+func (o CacheOptions) logger() Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return log.Default()
 }
 
 // DefaultCacheOptions contains reasonable defaults
@@ -63,6 +168,7 @@ var DefaultCacheOptions = CacheOptions{
 		Filename:      "cache_data.json",
 		SaveInterval:  10 * time.Minute,
 		LoadOnStartup: true,
+		DirMode:       DefaultDirMode,
 	},
 }
 
@@ -76,6 +182,7 @@ type Cache struct {
 	options       CacheOptions
 	persistMu     sync.Mutex
 	stopPersist   chan bool
+	persistErr    error           // Last persistence error, guarded by statsMu
 	fallbackItems map[string]Item // Used for stale-while-revalidate
 }
 
@@ -87,8 +194,36 @@ func New() *Cache {
 	return NewWithOptions(DefaultCacheOptions)
 }
 
-// NewWithOptions creates a new cache with specified options
+// NewWithOptions creates a new cache with specified options.
+// Persistence setup errors are logged and recorded in the stats, but the cache
+// remains usable in memory; use NewWithOptionsChecked to fail instead.
 func NewWithOptions(options CacheOptions) *Cache {
+	cache, err := newCache(options, false)
+	if err != nil {
+		// Log error but continue
+		options.logger().Printf("Error setting up cache persistence: %v", err)
+	}
+	return cache
+}
+
+// NewWithOptionsChecked creates a new cache and returns an error if persistence
+// is enabled but the directory cannot be created, the existing data cannot be
+// loaded, or the first write to disk fails
+func NewWithOptionsChecked(options CacheOptions) (*Cache, error) {
+	cache, err := newCache(options, true)
+	if err != nil {
+		cache.stopTimers()
+		return nil, err
+	}
+	return cache, nil
+}
+
+// newCache creates a cache and starts its background routines, returning the
+// first persistence setup error. When writeCheck is set, the cache is written
+// to disk once to verify the directory is writable.
+func newCache(options CacheOptions, writeCheck bool) (*Cache, error) {
+	options = validateIntervals(options)
+
 	cache := &Cache{
 		items:         make(map[string]Item),
 		fallbackItems: make(map[string]Item),
@@ -101,18 +236,30 @@ func NewWithOptions(options CacheOptions) *Cache {
 	go cache.startCleanupTimer()
 
 	// Start persistence if enabled
+	var setupErr error
 	if options.PersistOptions.Enabled {
+		dirMode := options.PersistOptions.DirMode
+		if dirMode == 0 {
+			dirMode = DefaultDirMode
+		}
+
 		// Create directory if it doesn't exist
-		if err := os.MkdirAll(options.PersistOptions.Directory, 0755); err != nil {
-			// Log error but continue
-			fmt.Printf("Error creating cache directory: %v\n", err)
+		if err := os.MkdirAll(options.PersistOptions.Directory, dirMode); err != nil {
+			setupErr = fmt.Errorf("error creating cache directory: %w", err)
+			cache.recordPersistError(setupErr)
 		}
 
 		// Load cache from disk on startup if enabled
-		if options.PersistOptions.LoadOnStartup {
+		if setupErr == nil && options.PersistOptions.LoadOnStartup {
 			if err := cache.loadFromDisk(); err != nil {
-				// Log error but continue
-				fmt.Printf("Error loading cache from disk: %v\n", err)
+				setupErr = fmt.Errorf("error loading cache from disk: %w", err)
+			}
+		}
+
+		// Verify the directory is writable
+		if setupErr == nil && writeCheck {
+			if err := cache.persistToDisk(); err != nil {
+				setupErr = fmt.Errorf("error writing cache to disk: %w", err)
 			}
 		}
 
@@ -120,7 +267,34 @@ func NewWithOptions(options CacheOptions) *Cache {
 		go cache.startPersistTimer()
 	}
 
-	return cache
+	return cache, setupErr
+}
+
+// validateIntervals replaces background intervals that would panic the timers or
+// make them spin: zero or negative values fall back to the defaults and values
+// under the minimums are raised to them. Each replacement is logged.
+func validateIntervals(options CacheOptions) CacheOptions {
+	logger := options.logger()
+	options.CleanupInterval = checkInterval(logger, "cleanup interval", options.CleanupInterval,
+		DefaultCacheOptions.CleanupInterval, MinCleanupInterval)
+	if options.PersistOptions.Enabled {
+		options.PersistOptions.SaveInterval = checkInterval(logger, "save interval", options.PersistOptions.SaveInterval,
+			DefaultCacheOptions.PersistOptions.SaveInterval, MinSaveInterval)
+	}
+	return options
+}
+
+// checkInterval returns interval, or its replacement if it is unusable
+func checkInterval(logger Logger, name string, interval, fallback, minimum time.Duration) time.Duration {
+	switch {
+	case interval <= 0:
+		logger.Printf("Warning: invalid cache %s %v, using %v", name, interval, fallback)
+		return fallback
+	case interval < minimum:
+		logger.Printf("Warning: cache %s %v is below the minimum, using %v", name, interval, minimum)
+		return minimum
+	}
+	return interval
 }
 
 // Set adds an item to the cache with expiration
@@ -240,6 +414,19 @@ func (c *Cache) GetWithLoader(key string, duration time.Duration, loader LoadFun
 	return value, nil
 }
 
+// Refresh calls the loader without consulting the cache and stores the fresh value,
+// so subsequent reads are served from the refreshed entry
+func (c *Cache) Refresh(key string, duration time.Duration, loader LoadFunc) (interface{}, error) {
+	value, err := loader()
+	if err != nil {
+		return nil, err
+	}
+
+	// Store in cache
+	c.Set(key, value, duration)
+	return value, nil
+}
+
 // Delete removes an item from the cache
 func (c *Cache) Delete(key string) {
 	c.mu.Lock()
@@ -266,10 +453,27 @@ func (c *Cache) GetStats() Stats {
 	stats := c.stats
 	stats.Size = len(c.items)
 	c.mu.RUnlock()
+
+	if c.persistErr != nil {
+		stats.LastPersistError = c.persistErr.Error()
+	}
 	
 	return stats
 }
 
+// Options returns the options the cache was created with, after validation
+func (c *Cache) Options() CacheOptions {
+	return c.options
+}
+
+// PersistError returns the most recent persistence error, or nil if the last
+// write to disk succeeded or persistence is disabled
+func (c *Cache) PersistError() error {
+	c.statsMu.RLock()
+	defer c.statsMu.RUnlock()
+	return c.persistErr
+}
+
 // incrementHits increases the hit counter
 func (c *Cache) incrementHits() {
 	c.statsMu.Lock()
@@ -300,10 +504,17 @@ func (c *Cache) incrementStaleServed() {
 
 // Stop halts the background cleanup goroutine
 func (c *Cache) Stop() {
-	close(c.stopClean)
 	if c.options.PersistOptions.Enabled {
 		// Save one last time
 		c.persistToDisk()
+	}
+	c.stopTimers()
+}
+
+// stopTimers halts the background cleanup and persistence goroutines
+func (c *Cache) stopTimers() {
+	close(c.stopClean)
+	if c.options.PersistOptions.Enabled {
 		close(c.stopPersist)
 	}
 }
@@ -344,24 +555,55 @@ func (c *Cache) cleanup() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for k, v := range c.items {
-		if now > v.Expiration {
-			delete(c.items, k)
-		}
-	}
+	removeExpired(c.items, now)
 
 	// Also cleanup fallback items
-	for k, v := range c.fallbackItems {
+	removeExpired(c.fallbackItems, now)
+}
+
+// PruneExpired removes expired live items now rather than at the next cleanup,
+// returning how many were removed. Their stale copies are kept.
+func (c *Cache) PruneExpired() int {
+	now := time.Now().UnixNano()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return removeExpired(c.items, now)
+}
+
+// PruneStale removes stale copies past StaleTimeout now rather than at the next
+// cleanup, returning how many were removed
+func (c *Cache) PruneStale() int {
+	now := time.Now().UnixNano()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return removeExpired(c.fallbackItems, now)
+}
+
+// Len returns the number of live items and of stale copies kept for
+// stale-while-revalidate, including expired ones not yet pruned
+func (c *Cache) Len() (live, stale int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items), len(c.fallbackItems)
+}
+
+// removeExpired deletes the items that expired before now and returns how many
+// there were; the caller must hold c.mu
+func removeExpired(items map[string]Item, now int64) int {
+	removed := 0
+	for k, v := range items {
 		if now > v.Expiration {
-			delete(c.fallbackItems, k)
+			delete(items, k)
+			removed++
 		}
 	}
+	return removed
 }
 
 // persistToDisk saves the cache to disk
-func (c *Cache) persistToDisk() {
+func (c *Cache) persistToDisk() error {
 	if !c.options.PersistOptions.Enabled {
-		return
+		return nil
 	}
 
 	c.persistMu.Lock()
@@ -375,23 +617,102 @@ func (c *Cache) persistToDisk() {
 	}
 	c.mu.RUnlock()
 
+	data, err := c.encodeSnapshot(snapshot)
+	if err != nil {
+		c.recordPersistError(err)
+		return err
+	}
+
 	// Create the file
 	filePath := filepath.Join(c.options.PersistOptions.Directory, c.options.PersistOptions.Filename)
 	file, err := os.Create(filePath)
 	if err != nil {
-		c.incrementPersistErrors()
-		return
+		c.recordPersistError(err)
+		return err
 	}
 	defer file.Close()
 
 	// Write to the file
-	encoder := json.NewEncoder(file)
-	if err := encoder.Encode(snapshot); err != nil {
-		c.incrementPersistErrors()
-		return
+	if _, err := file.Write(data); err != nil {
+		c.recordPersistError(err)
+		return err
 	}
 
 	c.incrementPersistWrites()
+	return nil
+}
+
+// encodeSnapshot encodes the snapshot as JSON. When MaxFileSize is set and the
+// encoded cache would exceed it, the least recently used entries are dropped
+// from the snapshot until it fits. The in-memory cache is left untouched.
+func (c *Cache) encodeSnapshot(snapshot map[string]Item) ([]byte, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, '\n')
+
+	maxSize := c.options.PersistOptions.MaxFileSize
+	if maxSize <= 0 || int64(len(data)) <= maxSize {
+		return data, nil
+	}
+
+	// Measure each entry so the drop count can be worked out in one pass
+	keys := make([]string, 0, len(snapshot))
+	sizes := make(map[string]int64, len(snapshot))
+	for k, v := range snapshot {
+		entry, err := json.Marshal(map[string]Item{k: v})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		sizes[k] = int64(len(entry)) - 1 // Without one brace, plus a comma
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return snapshot[keys[i]].LastAccess < snapshot[keys[j]].LastAccess
+	})
+
+	size := int64(len(data))
+	trimmed := int64(0)
+	for _, k := range keys {
+		if size <= maxSize {
+			break
+		}
+		size -= sizes[k]
+		delete(snapshot, k)
+		trimmed++
+	}
+
+	data, err = json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, '\n')
+
+	// The estimate is exact for map encoding, but never write past the cap
+	for _, k := range keys[trimmed:] {
+		if int64(len(data)) <= maxSize {
+			break
+		}
+		delete(snapshot, k)
+		trimmed++
+		if data, err = json.Marshal(snapshot); err != nil {
+			return nil, err
+		}
+		data = append(data, '\n')
+	}
+
+	c.statsMu.Lock()
+	c.stats.PersistTrimmed += trimmed
+	c.statsMu.Unlock()
+	return data, nil
+}
+
+// persistedItem is an Item as read from the persistence file, its value still encoded
+type persistedItem struct {
+	Value      json.RawMessage `json:"value"`
+	Expiration int64           `json:"expiration"`
+	LastAccess int64           `json:"last_access,omitempty"`
 }
 
 // loadFromDisk loads the cache from disk
@@ -406,17 +727,17 @@ func (c *Cache) loadFromDisk() error {
 			// File doesn't exist, not an error
 			return nil
 		}
-		c.incrementPersistErrors()
+		c.recordPersistError(err)
 		return err
 	}
 	defer file.Close()
 
-	// Read from the file
-	var snapshot map[string]Item
+	// Read from the file, leaving values encoded until their type is known
+	var snapshot map[string]persistedItem
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&snapshot); err != nil {
 		if err != io.EOF {
-			c.incrementPersistErrors()
+			c.recordPersistError(err)
 			return err
 		}
 		// Empty file, not an error
@@ -430,9 +751,35 @@ func (c *Cache) loadFromDisk() error {
 	now := time.Now().UnixNano()
 	loadCount := 0
 
-	for k, v := range snapshot {
+	// Load the most recently used entries first so MaxItems keeps the hottest ones
+	keys := make([]string, 0, len(snapshot))
+	for k := range snapshot {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return snapshot[keys[i]].LastAccess > snapshot[keys[j]].LastAccess
+	})
+
+	valueDecoder := c.options.PersistOptions.ValueDecoder
+	if valueDecoder == nil {
+		valueDecoder = jsonDecoder{}
+	}
+
+	for _, k := range keys {
+		persisted := snapshot[k]
+		if c.options.MaxItems > 0 && len(c.items) >= c.options.MaxItems {
+			break
+		}
 		// Only load non-expired items
-		if now <= v.Expiration {
+		if now <= persisted.Expiration {
+			// An entry that no longer decodes, such as one of another type,
+			// is left out rather than failing the whole load
+			value, err := valueDecoder.DecodeValue(persisted.Value)
+			if err != nil {
+				c.incrementPersistMisses()
+				continue
+			}
+			v := Item{Value: value, Expiration: persisted.Expiration, LastAccess: persisted.LastAccess}
 			c.items[k] = v
 			// Also create fallback items with extended TTL
 			if c.options.AllowStaleOnFail {
@@ -464,16 +811,18 @@ func (c *Cache) incrementPersistMisses() {
 	c.statsMu.Unlock()
 }
 
-// incrementPersistWrites increases the persist writes counter
+// incrementPersistWrites increases the persist writes counter and clears the error state
 func (c *Cache) incrementPersistWrites() {
 	c.statsMu.Lock()
 	c.stats.PersistWrites++
+	c.persistErr = nil
 	c.statsMu.Unlock()
 }
 
-// incrementPersistErrors increases the persist errors counter
-func (c *Cache) incrementPersistErrors() {
+// recordPersistError increases the persist errors counter and stores the error
+func (c *Cache) recordPersistError(err error) {
 	c.statsMu.Lock()
 	c.stats.PersistErrors++
+	c.persistErr = err
 	c.statsMu.Unlock()
 }
\ No newline at end of file
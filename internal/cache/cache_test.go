@@ -2,10 +2,14 @@ package cache
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
 func TestNewCache(t *testing.T) {
@@ -54,6 +58,56 @@ func TestNewWithOptions(t *testing.T) {
 	cache.Stop() // Clean up
 }
 
+func TestNewWithOptionsInvalidIntervals(t *testing.T) {
+	tests := []struct {
+		name        string
+		cleanup     time.Duration
+		save        time.Duration
+		wantCleanup time.Duration
+		wantSave    time.Duration
+	}{
+		{name: "Zero intervals use defaults", cleanup: 0, save: 0,
+			wantCleanup: DefaultCacheOptions.CleanupInterval, wantSave: DefaultCacheOptions.PersistOptions.SaveInterval},
+		{name: "Negative intervals use defaults", cleanup: -time.Second, save: -time.Second,
+			wantCleanup: DefaultCacheOptions.CleanupInterval, wantSave: DefaultCacheOptions.PersistOptions.SaveInterval},
+		{name: "Tiny intervals are raised to the minimums", cleanup: time.Nanosecond, save: time.Microsecond,
+			wantCleanup: MinCleanupInterval, wantSave: MinSaveInterval},
+		{name: "Valid intervals are kept", cleanup: time.Minute, save: time.Hour,
+			wantCleanup: time.Minute, wantSave: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Options built by hand, leaving out fields such as the intervals, must not panic
+			options := CacheOptions{
+				MaxItems:        10,
+				CleanupInterval: tt.cleanup,
+				PersistOptions: PersistOptions{
+					Enabled:      true,
+					Directory:    t.TempDir(),
+					Filename:     "cache.json",
+					SaveInterval: tt.save,
+				},
+			}
+
+			cache := NewWithOptions(options)
+			defer cache.Stop()
+
+			if cache.options.CleanupInterval != tt.wantCleanup {
+				t.Errorf("CleanupInterval = %v, want %v", cache.options.CleanupInterval, tt.wantCleanup)
+			}
+			if cache.options.PersistOptions.SaveInterval != tt.wantSave {
+				t.Errorf("SaveInterval = %v, want %v", cache.options.PersistOptions.SaveInterval, tt.wantSave)
+			}
+
+			cache.Set("key", "value", time.Minute)
+			if _, found := cache.Get("key"); !found {
+				t.Error("Expected the cache to work with corrected intervals")
+			}
+		})
+	}
+}
+
 func TestSetAndGet(t *testing.T) {
 	cache := New()
 	defer cache.Stop()
@@ -195,6 +249,48 @@ func TestGetWithLoaderFallback(t *testing.T) {
 	}
 }
 
+func TestRefresh(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	cache.Set("key", "cached_value", 1*time.Hour)
+
+	// Refresh must call the loader even though the key is cached
+	loaderCalls := 0
+	loader := func() (interface{}, error) {
+		loaderCalls++
+		return "fresh_value", nil
+	}
+
+	value, err := cache.Refresh("key", 1*time.Hour, loader)
+	if err != nil {
+		t.Errorf("Refresh returned error: %v", err)
+	}
+	if loaderCalls != 1 {
+		t.Errorf("Expected loader to be called once, got %d", loaderCalls)
+	}
+	if value != "fresh_value" {
+		t.Errorf("Expected fresh_value, got %v", value)
+	}
+
+	// The fresh value should now be served from the cache
+	cachedValue, found := cache.Get("key")
+	if !found || cachedValue != "fresh_value" {
+		t.Errorf("Expected cached fresh_value after refresh, got %v (found=%v)", cachedValue, found)
+	}
+
+	// A failing loader leaves the existing entry untouched
+	_, err = cache.Refresh("key", 1*time.Hour, func() (interface{}, error) {
+		return nil, fmt.Errorf("load error")
+	})
+	if err == nil {
+		t.Error("Expected error from failing loader, got nil")
+	}
+	if cachedValue, _ := cache.Get("key"); cachedValue != "fresh_value" {
+		t.Errorf("Expected fresh_value to remain cached, got %v", cachedValue)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	cache := New()
 	defer cache.Stop()
@@ -389,6 +485,66 @@ func TestPersistence(t *testing.T) {
 	}
 }
 
+func TestPersistenceValueDecoder(t *testing.T) {
+	type entry struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+
+	options := DefaultCacheOptions
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = t.TempDir()
+	options.PersistOptions.Filename = "typed_cache.json"
+
+	cache := NewWithOptions(options)
+	cache.Set("typed", entry{Name: "golang", Count: 3, Tags: []string{"go"}}, time.Hour)
+	cache.Stop()
+
+	// Without a decoder the value reloads as generic JSON
+	generic := NewWithOptions(options)
+	value, _ := generic.Get("typed")
+	generic.Stop()
+	if _, ok := value.(map[string]interface{}); !ok {
+		t.Errorf("Reloaded value without a decoder = %T, want map[string]interface{}", value)
+	}
+
+	// With one it reloads as the type it was cached as
+	options.PersistOptions.ValueDecoder = DecodeAs[entry]()
+	typed := NewWithOptions(options)
+	defer typed.Stop()
+	value, found := typed.Get("typed")
+	got, ok := value.(entry)
+	if !found || !ok || got.Name != "golang" || got.Count != 3 || len(got.Tags) != 1 {
+		t.Errorf("Reloaded value = %#v, want the cached entry", value)
+	}
+}
+
+func TestPersistenceSkipsUndecodableValues(t *testing.T) {
+	options := DefaultCacheOptions
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = t.TempDir()
+	options.PersistOptions.Filename = "mixed_cache.json"
+
+	cache := NewWithOptions(options)
+	cache.Set("number", 42, time.Hour)
+	cache.Set("text", "not a number", time.Hour)
+	cache.Stop()
+
+	options.PersistOptions.ValueDecoder = DecodeAs[int]()
+	reloaded := NewWithOptions(options)
+	defer reloaded.Stop()
+	if value, found := reloaded.Get("number"); !found || value != 42 {
+		t.Errorf("Get(number) = %v, %v, want 42", value, found)
+	}
+	if _, found := reloaded.Get("text"); found {
+		t.Error("Expected the value that does not decode to be left out")
+	}
+	if stats := reloaded.GetStats(); stats.PersistMisses != 1 {
+		t.Errorf("PersistMisses = %d, want 1", stats.PersistMisses)
+	}
+}
+
 func TestCleanup(t *testing.T) {
 	// Create cache with short cleanup interval
 	options := DefaultCacheOptions
@@ -417,4 +573,296 @@ func TestCleanup(t *testing.T) {
 	if !foundLong {
 		t.Error("Expected long-lived item to still be in cache")
 	}
-}
\ No newline at end of file
+}
+func TestPersistenceDirectoryErrors(t *testing.T) {
+	// A path below a regular file can never be created, even when running as root
+	tmpDir := t.TempDir()
+	blocker := filepath.Join(tmpDir, "not-a-directory")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create blocking file: %v", err)
+	}
+
+	options := DefaultCacheOptions
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = filepath.Join(blocker, "cache")
+	options.PersistOptions.SaveInterval = time.Hour
+
+	// The checked constructor fails
+	cache, err := NewWithOptionsChecked(options)
+	if err == nil {
+		cache.Stop()
+		t.Fatal("Expected error creating cache in a non-writable directory")
+	}
+	if !strings.Contains(err.Error(), "cache directory") {
+		t.Errorf("Expected directory error, got: %v", err)
+	}
+
+	// The unchecked constructor keeps working in memory and exposes the error
+	cache = NewWithOptions(options)
+	defer cache.Stop()
+
+	cache.Set("key", "value", time.Minute)
+	if value, found := cache.Get("key"); !found || value != "value" {
+		t.Error("Expected cache to keep working in memory")
+	}
+	if cache.PersistError() == nil {
+		t.Error("Expected PersistError() to report the directory error")
+	}
+	stats := cache.GetStats()
+	if stats.PersistErrors == 0 || stats.LastPersistError == "" {
+		t.Errorf("Expected persistence error in stats, got: %+v", stats)
+	}
+}
+
+func TestPersistenceDirMode(t *testing.T) {
+	options := DefaultCacheOptions
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = filepath.Join(t.TempDir(), "private")
+	options.PersistOptions.SaveInterval = time.Hour
+	options.PersistOptions.DirMode = 0700
+
+	cache, err := NewWithOptionsChecked(options)
+	if err != nil {
+		t.Fatalf("NewWithOptionsChecked() unexpected error: %v", err)
+	}
+	defer cache.Stop()
+
+	info, err := os.Stat(options.PersistOptions.Directory)
+	if err != nil {
+		t.Fatalf("Expected cache directory to exist: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("Directory mode = %o, want 700", perm)
+	}
+	if cache.PersistError() != nil {
+		t.Errorf("PersistError() = %v, want nil", cache.PersistError())
+	}
+}
+
+func TestPersistenceMaxFileSize(t *testing.T) {
+	options := DefaultCacheOptions
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = t.TempDir()
+	options.PersistOptions.Filename = "bounded_cache.json"
+	options.PersistOptions.SaveInterval = time.Hour
+	options.PersistOptions.MaxFileSize = 2048
+
+	cache := NewWithOptions(options)
+	defer cache.Stop()
+
+	// Each entry is roughly 150 bytes, so 50 of them cannot fit in 2KB
+	value := strings.Repeat("x", 100)
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("key%02d", i), value, time.Hour)
+	}
+
+	// Make key00 the least recently used and key49 the most recently used
+	cache.mu.Lock()
+	for k, item := range cache.items {
+		var i int
+		fmt.Sscanf(k, "key%02d", &i)
+		item.LastAccess = int64(i + 1)
+		cache.items[k] = item
+	}
+	cache.mu.Unlock()
+
+	if err := cache.persistToDisk(); err != nil {
+		t.Fatalf("persistToDisk() unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(options.PersistOptions.Directory, "bounded_cache.json"))
+	if err != nil {
+		t.Fatalf("Expected cache file to exist: %v", err)
+	}
+	if info.Size() > options.PersistOptions.MaxFileSize {
+		t.Errorf("Cache file size = %d, want at most %d", info.Size(), options.PersistOptions.MaxFileSize)
+	}
+
+	stats := cache.GetStats()
+	if stats.PersistTrimmed == 0 {
+		t.Error("Expected entries to be trimmed from the cache file")
+	}
+	if stats.Size != 50 {
+		t.Errorf("In-memory size = %d, want 50 (trimming only affects the file)", stats.Size)
+	}
+
+	// A fresh cache loads only the most recently used entries
+	loaded := NewWithOptions(options)
+	defer loaded.Stop()
+
+	if _, found := loaded.Get("key49"); !found {
+		t.Error("Expected the most recently used entry to be persisted")
+	}
+	if _, found := loaded.Get("key00"); found {
+		t.Error("Expected the least recently used entry to be trimmed")
+	}
+	if got := loaded.GetStats().Size; int64(got) != 50-stats.PersistTrimmed {
+		t.Errorf("Loaded %d entries, want %d", got, 50-stats.PersistTrimmed)
+	}
+}
+
+func TestLoadFromDiskRespectsMaxItems(t *testing.T) {
+	options := DefaultCacheOptions
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = t.TempDir()
+	options.PersistOptions.Filename = "cache.json"
+	options.PersistOptions.SaveInterval = time.Hour
+
+	cache := NewWithOptions(options)
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i, time.Hour)
+	}
+	cache.mu.Lock()
+	for k, item := range cache.items {
+		var i int
+		fmt.Sscanf(k, "key%d", &i)
+		item.LastAccess = int64(i + 1)
+		cache.items[k] = item
+	}
+	cache.mu.Unlock()
+	cache.Stop()
+
+	options.MaxItems = 2
+	loaded := NewWithOptions(options)
+	defer loaded.Stop()
+
+	if size := loaded.GetStats().Size; size != 2 {
+		t.Fatalf("Loaded %d entries, want MaxItems (2)", size)
+	}
+	for _, key := range []string{"key3", "key4"} {
+		if _, found := loaded.Get(key); !found {
+			t.Errorf("Expected recently used %s to be loaded", key)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	options := DefaultCacheOptions
+	options.AllowStaleOnFail = true
+	options.StaleTimeout = 20 * time.Millisecond
+
+	cache := NewWithOptions(options)
+	defer cache.Stop()
+
+	cache.Set("short1", "value1", time.Millisecond)
+	cache.Set("short2", "value2", time.Millisecond)
+	cache.Set("long", "value3", time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	// Expired live items go, their stale copies stay until StaleTimeout
+	if removed := cache.PruneExpired(); removed != 2 {
+		t.Errorf("PruneExpired() = %d, want 2", removed)
+	}
+	if live, stale := cache.Len(); live != 1 || stale != 3 {
+		t.Errorf("Len() = %d, %d, want 1, 3", live, stale)
+	}
+	if removed := cache.PruneStale(); removed != 0 {
+		t.Errorf("PruneStale() before StaleTimeout = %d, want 0", removed)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if removed := cache.PruneStale(); removed != 3 {
+		t.Errorf("PruneStale() = %d, want 3", removed)
+	}
+	if removed := cache.PruneExpired(); removed != 0 {
+		t.Errorf("PruneExpired() again = %d, want 0", removed)
+	}
+	if live, stale := cache.Len(); live != 1 || stale != 0 {
+		t.Errorf("Len() = %d, %d, want 1, 0", live, stale)
+	}
+	if value, found := cache.Get("long"); !found || value != "value3" {
+		t.Errorf("Get(long) = %v, %v, want the unexpired value", value, found)
+	}
+}
+
+// recordingLogger keeps the messages logged to it
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestLoggerKeepsStdoutClean(t *testing.T) {
+	dir := t.TempDir()
+	options := DefaultCacheOptions
+	options.CleanupInterval = -1 // Logs a warning
+	options.PersistOptions.Enabled = true
+	options.PersistOptions.Directory = dir
+
+	// newFailingCache creates a cache whose load fails on a corrupt file
+	newFailingCache := func(logger Logger) *Cache {
+		if err := os.WriteFile(filepath.Join(dir, "cache_data.json"), []byte("{not json"), 0644); err != nil {
+			t.Fatalf("Failed to write cache file: %v", err)
+		}
+		options.Logger = logger
+		return NewWithOptions(options)
+	}
+
+	// Capture anything written to stdout while the caches are created
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	originalStdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = originalStdout }()
+
+	cache := newFailingCache(NopLogger)
+	if cache.PersistError() == nil {
+		t.Error("Expected the load error to be recorded")
+	}
+	cache.Stop()
+
+	recorder := &recordingLogger{}
+	newFailingCache(recorder).Stop()
+
+	writer.Close()
+	os.Stdout = originalStdout
+	written, _ := io.ReadAll(reader)
+	if len(written) > 0 {
+		t.Errorf("Cache wrote to stdout: %q", written)
+	}
+
+	// The same messages reach an injected logger
+	if len(recorder.messages) != 2 ||
+		!strings.Contains(recorder.messages[0], "invalid cache cleanup interval") ||
+		!strings.Contains(recorder.messages[1], "error loading cache from disk") {
+		t.Errorf("Logged messages = %q", recorder.messages)
+	}
+}
+
+func TestOptionsWithSettings(t *testing.T) {
+	base := DefaultCacheOptions
+	base.PersistOptions.Enabled = true
+
+	// Zero settings keep every option
+	if got := base.WithSettings(config.CacheSettings{}); got != base {
+		t.Errorf("WithSettings(zero) = %+v, want the options unchanged", got)
+	}
+
+	got := base.WithSettings(config.CacheSettings{
+		MaxItems:     50,
+		TTL:          config.Duration(time.Minute),
+		StaleTimeout: config.Duration(2 * time.Hour),
+		Directory:    "/var/cache/test",
+	})
+	if got.MaxItems != 50 || got.DefaultTTL != time.Minute || got.StaleTimeout != 2*time.Hour {
+		t.Errorf("WithSettings() = %+v", got)
+	}
+	if !got.PersistOptions.Enabled || got.PersistOptions.Directory != "/var/cache/test" ||
+		got.PersistOptions.Filename != base.PersistOptions.Filename {
+		t.Errorf("PersistOptions = %+v, want persistence to /var/cache/test", got.PersistOptions)
+	}
+
+	if got := base.WithSettings(config.CacheSettings{Directory: config.CacheDirectoryOff}); got.PersistOptions.Enabled {
+		t.Error("Expected \"off\" to disable persistence")
+	}
+
+	// Persistence enabled on options without a file name gets the default name
+	got = CacheOptions{}.WithSettings(config.CacheSettings{Directory: "/tmp/x"})
+	if got.PersistOptions.Filename != DefaultCacheOptions.PersistOptions.Filename {
+		t.Errorf("Filename = %q, want the default", got.PersistOptions.Filename)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// IPFilter decides which client IPs may call the server.
+// A deny match always rejects; otherwise an empty allow list admits every source.
+type IPFilter struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// NewIPFilter creates a filter from IP addresses or CIDR ranges
+func NewIPFilter(allow, deny []string) (*IPFilter, error) {
+	allowNets, err := parseIPNets(allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allow list: %w", err)
+	}
+	denyNets, err := parseIPNets(deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny list: %w", err)
+	}
+	return &IPFilter{allow: allowNets, deny: denyNets}, nil
+}
+
+// Allowed reports whether requests from ip are accepted.
+// An unparseable IP is only accepted when there is no allow list to match it against.
+func (f *IPFilter) Allowed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return len(f.allow) == 0
+	}
+	if containsIP(f.deny, parsed) {
+		return false
+	}
+	return len(f.allow) == 0 || containsIP(f.allow, parsed)
+}
+
+// Middleware rejects requests from disallowed sources with 403 before they reach
+// the handler, and so before they count against the rate limit. The source is
+// c.RealIP(), the same address the rate limiter uses.
+func (f *IPFilter) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !f.Allowed(c.RealIP()) {
+				return respondWithError(c, http.StatusForbidden, models.ErrForbidden,
+					"Access denied for this client address", 0)
+			}
+			return next(c)
+		}
+	}
+}
+
+// NewIPExtractor chooses how c.RealIP() finds the client address. With trusted
+// proxies, X-Forwarded-For is read up to the first hop not in those ranges; with
+// none but an access list configured, only the connection address is used so
+// clients cannot spoof headers past the filter. Otherwise nil keeps Echo's default.
+func NewIPExtractor(access config.Access) (echo.IPExtractor, error) {
+	if len(access.TrustedProxies) > 0 {
+		proxies, err := parseIPNets(access.TrustedProxies)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+		}
+		options := []echo.TrustOption{
+			echo.TrustLoopback(false),
+			echo.TrustLinkLocal(false),
+			echo.TrustPrivateNet(false),
+		}
+		for _, proxy := range proxies {
+			options = append(options, echo.TrustIPRange(proxy))
+		}
+		return echo.ExtractIPFromXFFHeader(options...), nil
+	}
+	if access.Enabled() {
+		return echo.ExtractIPDirect(), nil
+	}
+	return nil, nil
+}
+
+// parseIPNets parses IP addresses and CIDR ranges; a bare address matches only itself
+func parseIPNets(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid CIDR range", entry)
+			}
+			nets = append(nets, ipNet)
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("%q is not a valid IP address", entry)
+		}
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return nets, nil
+}
+
+// containsIP reports whether any of nets contains ip
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
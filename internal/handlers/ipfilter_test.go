@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestIPFilterAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		ip    string
+		want  bool
+	}{
+		{name: "No lists allow all", ip: "203.0.113.7", want: true},
+		{name: "Allowed IP", allow: []string{"203.0.113.7"}, ip: "203.0.113.7", want: true},
+		{name: "IP not in allow list", allow: []string{"203.0.113.7"}, ip: "203.0.113.8", want: false},
+		{name: "Denied IP", deny: []string{"198.51.100.1"}, ip: "198.51.100.1", want: false},
+		{name: "IP not in deny list", deny: []string{"198.51.100.1"}, ip: "198.51.100.2", want: true},
+		{name: "CIDR range match", allow: []string{"10.0.0.0/8"}, ip: "10.20.30.40", want: true},
+		{name: "CIDR range miss", allow: []string{"10.0.0.0/8"}, ip: "11.0.0.1", want: false},
+		{name: "Deny takes precedence", allow: []string{"10.0.0.0/8"}, deny: []string{"10.1.0.0/16"}, ip: "10.1.2.3", want: false},
+		{name: "IPv6 range", allow: []string{"2001:db8::/32"}, ip: "2001:db8::1", want: true},
+		{name: "Unparseable IP with allow list", allow: []string{"10.0.0.0/8"}, ip: "unknown", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewIPFilter(tt.allow, tt.deny)
+			if err != nil {
+				t.Fatalf("NewIPFilter() unexpected error: %v", err)
+			}
+			if got := filter.Allowed(tt.ip); got != tt.want {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIPFilterInvalidEntry(t *testing.T) {
+	if _, err := NewIPFilter([]string{"10.0.0.0/33"}, nil); err == nil || !strings.Contains(err.Error(), "invalid allow list") {
+		t.Errorf("Expected invalid allow list error, got %v", err)
+	}
+	if _, err := NewIPFilter(nil, []string{"not-an-ip"}); err == nil || !strings.Contains(err.Error(), "invalid deny list") {
+		t.Errorf("Expected invalid deny list error, got %v", err)
+	}
+}
+
+// serveFiltered sends a request from remoteAddr through the filter middleware
+func serveFiltered(t *testing.T, e *echo.Echo, filter *IPFilter, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	t.Helper()
+	e.POST("/mcp/:method", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}, filter.Middleware())
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp/feed-analysis", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIPFilterMiddleware(t *testing.T) {
+	filter, err := NewIPFilter([]string{"192.0.2.0/24"}, []string{"192.0.2.66"})
+	if err != nil {
+		t.Fatalf("NewIPFilter() unexpected error: %v", err)
+	}
+
+	if rec := serveFiltered(t, echo.New(), filter, "192.0.2.10:5000", ""); rec.Code != http.StatusOK {
+		t.Errorf("Allowed IP got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec := serveFiltered(t, echo.New(), filter, "192.0.2.66:5000", "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Denied IP got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if !strings.Contains(rec.Body.String(), `"forbidden"`) {
+		t.Errorf("Expected forbidden error code, got %s", rec.Body.String())
+	}
+}
+
+func TestIPFilterTrustedProxies(t *testing.T) {
+	filter, err := NewIPFilter([]string{"192.0.2.0/24"}, nil)
+	if err != nil {
+		t.Fatalf("NewIPFilter() unexpected error: %v", err)
+	}
+
+	newServer := func(access config.Access) *echo.Echo {
+		extractor, err := NewIPExtractor(access)
+		if err != nil {
+			t.Fatalf("NewIPExtractor() unexpected error: %v", err)
+		}
+		e := echo.New()
+		e.IPExtractor = extractor
+		return e
+	}
+
+	// The forwarded client address is used only when the request comes through a trusted proxy
+	trusted := config.Access{AllowIPs: []string{"192.0.2.0/24"}, TrustedProxies: []string{"10.0.0.1"}}
+	if rec := serveFiltered(t, newServer(trusted), filter, "10.0.0.1:443", "192.0.2.10"); rec.Code != http.StatusOK {
+		t.Errorf("Forwarded allowed IP got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serveFiltered(t, newServer(trusted), filter, "10.0.0.2:443", "192.0.2.10"); rec.Code != http.StatusForbidden {
+		t.Errorf("Untrusted proxy got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	// Without trusted proxies a spoofed header does not get past the filter
+	direct := config.Access{AllowIPs: []string{"192.0.2.0/24"}}
+	if rec := serveFiltered(t, newServer(direct), filter, "10.0.0.1:443", "192.0.2.10"); rec.Code != http.StatusForbidden {
+		t.Errorf("Spoofed header got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,97 +15,39 @@ import (
 	"github.com/littleironwaltz/bluesky-mcp/internal/services/community"
 	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
 	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 	"github.com/labstack/echo/v4"
 )
 
-// ValidMethods defines the allowed MCP methods
-var ValidMethods = map[string]bool{
-	"feed-analysis":    true,
-	"post-assist":      true,
-	"post-submit":      true,
-	"community-manage": true,
+// isWriteRequest reports whether a request modifies the account, either through
+// a write method or, like post-assist with submit, a method asked to write
+func isWriteRequest(method string, params map[string]interface{}) bool {
+	if IsWriteMethod(method) {
+		return true
+	}
+	submit, _ := params["submit"].(bool)
+	return submit
 }
 
-// RateLimiter provides a simple rate limiting mechanism
-type RateLimiter struct {
-	mu            sync.Mutex
-	requests      map[string][]time.Time // Map of IP to request timestamps
-	windowSize    time.Duration          // Time window to track
-	maxRequests   int                    // Max requests per window
-	cleanupPeriod time.Duration          // How often to clean up old entries
-	lastCleanup   time.Time              // Last time cleanup was performed
-}
+// readOnly is set when the server runs without credentials
+var (
+	readOnlyMu sync.RWMutex
+	readOnly   bool
+)
 
-// Global rate limiter instance
-var rateLimiter = &RateLimiter{
-	requests:      make(map[string][]time.Time),
-	windowSize:    time.Minute,
-	maxRequests:   60, // 60 requests per minute
-	cleanupPeriod: 5 * time.Minute,
-	lastCleanup:   time.Now(),
+// SetReadOnly enables or disables rejecting write methods
+func SetReadOnly(enabled bool) {
+	readOnlyMu.Lock()
+	defer readOnlyMu.Unlock()
+	readOnly = enabled
 }
 
-// Allow checks if a request from the given IP should be allowed
-func (rl *RateLimiter) Allow(ip string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-	
-	now := time.Now()
-	
-	// Clean up old entries periodically
-	if now.Sub(rl.lastCleanup) > rl.cleanupPeriod {
-		rl.cleanup(now)
-		rl.lastCleanup = now
-	}
-	
-	// Get the list of request times for this IP
-	times, exists := rl.requests[ip]
-	if !exists {
-		times = []time.Time{}
-	}
-	
-	// Remove timestamps outside the window
-	cutoff := now.Add(-rl.windowSize)
-	validTimes := []time.Time{}
-	
-	for _, t := range times {
-		if t.After(cutoff) {
-			validTimes = append(validTimes, t)
-		}
-	}
-	
-	// Check if under the limit
-	if len(validTimes) >= rl.maxRequests {
-		return false
-	}
-	
-	// Add this request
-	validTimes = append(validTimes, now)
-	rl.requests[ip] = validTimes
-	
-	return true
-}
-
-// cleanup removes old entries from the rate limiter
-func (rl *RateLimiter) cleanup(now time.Time) {
-	cutoff := now.Add(-rl.windowSize)
-	
-	for ip, times := range rl.requests {
-		validTimes := []time.Time{}
-		
-		for _, t := range times {
-			if t.After(cutoff) {
-				validTimes = append(validTimes, t)
-			}
-		}
-		
-		if len(validTimes) == 0 {
-			delete(rl.requests, ip)
-		} else {
-			rl.requests[ip] = validTimes
-		}
-	}
+// IsReadOnly reports whether write methods are being rejected
+func IsReadOnly() bool {
+	readOnlyMu.RLock()
+	defer readOnlyMu.RUnlock()
+	return readOnly
 }
 
 // HandleMCPRequest processes MCP (Model Context Protocol) requests
@@ -116,13 +61,24 @@ func HandleMCPRequest(c echo.Context, cfg config.Config) error {
 	}
 	
 	method := c.Param("method")
+
+	// Reject an unknown response format rather than guessing
+	if err := validFormatParam(c); err != nil {
+		return respondWithError(c, http.StatusBadRequest, models.ErrInvalidRequest, err.Error(), 0)
+	}
 	
 	// Validate method
-	if !ValidMethods[method] {
+	if !IsValidMethod(method) {
 		return respondWithError(c, http.StatusBadRequest, models.ErrInvalidRequest, 
 			fmt.Sprintf("Invalid method: %s", method), 0)
 	}
 
+	// Refuse writes when running without credentials
+	if IsWriteMethod(method) && IsReadOnly() {
+		return respondWithError(c, http.StatusServiceUnavailable, models.ErrServiceUnavailable,
+			fmt.Sprintf("Method %s is unavailable in read-only mode", method), 0)
+	}
+
 	// Parse request
 	var req models.JSONRPCRequest
 	if err := c.Bind(&req); err != nil {
@@ -136,72 +92,125 @@ func HandleMCPRequest(c echo.Context, cfg config.Config) error {
 			"Unsupported JSON-RPC version", req.ID)
 	}
 
-	// Process the MCP method request
-	result, err := processMCPMethod(method, req.Params, cfg)
+	// A method that is not a write method may still be asked to write
+	if isWriteRequest(method, req.Params) && IsReadOnly() {
+		return respondWithError(c, http.StatusServiceUnavailable, models.ErrServiceUnavailable,
+			fmt.Sprintf("Method %s with submit is unavailable in read-only mode", method), req.ID)
+	}
+
+	// An analysis version asked for in the Accept header counts as a parameter
+	req.Params = withAcceptedAnalysisVersion(c, method, req.Params)
+
+	// Process the MCP method request, serving repeated requests from the result cache if enabled
+	result, err := processCachedMethod(method, req.Params, func() (interface{}, error) {
+		return processMCPMethod(method, req.Params, cfg)
+	})
 	if err != nil {
-		log.Printf("Error processing '%s' request: %v", method, err)
-		return handleMethodError(c, err, req.ID)
+		// A post queued for retry will still be sent, so it is accepted rather
+		// than failed; reporting a failure would invite a duplicate submission
+		var queued *post.QueuedError
+		if !errors.As(err, &queued) {
+			log.Printf("Error processing '%s' request: %v", method, err)
+			return handleMethodError(c, err, req.ID)
+		}
+		result = queuedResult(queued)
 	}
+	status := resultStatus(result)
 	
-	// Success response
-	return c.JSON(http.StatusOK, models.JSONRPCResponse{
-		JSONRPC: "2.0",
-		Result:  result,
-		ID:      req.ID,
+	// Plain responses carry the bare result, with any degradation in headers
+	if negotiateFormat(c) == formatPlain {
+		setWarningHeaders(c, resultWarnings(result))
+		return c.JSON(status, result)
+	}
+
+	// Success response, surfacing any degradation reported in the result
+	return c.JSON(status, models.JSONRPCResponse{
+		JSONRPC:  "2.0",
+		Result:   result,
+		Warnings: resultWarnings(result),
+		ID:       req.ID,
 	})
 }
 
-// processMCPMethod handles the execution of a specific MCP method with timeout
+// resultWarnings collects the warnings a service reported in its result, such as
+// stale feed data served from the cache, for the response envelope
+func resultWarnings(result interface{}) []string {
+	var warning string
+	switch r := result.(type) {
+	case models.FeedResponse:
+		warning = r.Warning
+	case *feed.SentimentTrend:
+		warning = r.Warning
+	case map[string]interface{}:
+		warning, _ = r["warning"].(string)
+	}
+	if warning == "" {
+		return nil
+	}
+	return []string{warning}
+}
+
+// queuedResult describes a post that failed and was queued for retry
+func queuedResult(queued *post.QueuedError) map[string]interface{} {
+	return map[string]interface{}{
+		"submitted": false,
+		"queued":    true,
+		"queue_id":  queued.ID,
+		"warning":   queued.Error(),
+	}
+}
+
+// resultStatus is 202 Accepted for a post queued for retry, which is yet to be
+// sent, and 200 OK otherwise
+func resultStatus(result interface{}) int {
+	if r, ok := result.(map[string]interface{}); ok {
+		if queued, _ := r["queued"].(bool); queued {
+			return http.StatusAccepted
+		}
+	}
+	return http.StatusOK
+}
+
+// analyzeFeed runs a feed analysis, can be replaced for testing
+var analyzeFeed = feed.AnalyzeFeed
+
+// sentimentTimeline computes a hashtag's sentiment trend, can be replaced for testing
+var sentimentTimeline = feed.SentimentTimeline
+
+// suggestHashtags finds hashtags used with a topic, can be replaced for testing
+var suggestHashtags = post.SuggestHashtags
+
+// getQuotes finds the posts quoting a post, can be replaced for testing
+var getQuotes = feed.GetQuotes
+
+// submitPost submits a post for post-submit, can be replaced for testing
+var submitPost = post.SubmitPostContext
+
+// linkCardFetchTimeout bounds fetching link card metadata within a post-submit request
+const linkCardFetchTimeout = 4 * time.Second
+
+// processMCPMethod runs a registered MCP method, bounded by its timeout
 func processMCPMethod(method string, params map[string]interface{}, cfg config.Config) (interface{}, error) {
+	registered, ok := lookupMethod(method)
+	if !ok {
+		return nil, fmt.Errorf("invalid method: %s", method)
+	}
+
+	// A request without params has a nil map, which services may write defaults into
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	// The context tells the method when the request has timed out
+	ctx, cancel := context.WithTimeout(context.Background(), registered.timeout)
+	defer cancel()
+
 	resultCh := make(chan interface{}, 1)
 	errCh := make(chan error, 1)
 	
-	// Set appropriate timeout based on method
-	var timeout time.Duration
-	switch method {
-	case "feed-analysis":
-		timeout = 15 * time.Second
-	case "post-assist":
-		timeout = 5 * time.Second
-	case "post-submit":
-		timeout = 10 * time.Second
-	case "community-manage":
-		timeout = 10 * time.Second
-	default:
-		timeout = 10 * time.Second
-	}
-	
 	// Process in a goroutine
 	go func() {
-		var result interface{}
-		var err error
-		
-		switch method {
-		case "feed-analysis":
-			result, err = feed.AnalyzeFeed(cfg, params)
-		case "post-assist":
-			result, err = post.GeneratePost(cfg, params)
-		case "post-submit":
-			// For direct post submission
-			text, ok := params["text"].(string)
-			if !ok || text == "" {
-				err = fmt.Errorf("invalid parameter: text is required")
-				break
-			}
-			postResult, postErr := post.SubmitPost(cfg, text)
-			if postErr != nil {
-				err = postErr
-				break
-			}
-			result = map[string]interface{}{
-				"submitted": true,
-				"post_uri": postResult.URI,
-				"post_cid": postResult.CID,
-			}
-		case "community-manage":
-			result, err = community.ManageCommunity(cfg, params)
-		}
-		
+		result, err := registered.handler(ctx, cfg, params)
 		if err != nil {
 			errCh <- err
 			return
@@ -215,14 +224,282 @@ func processMCPMethod(method string, params map[string]interface{}, cfg config.C
 		return result, nil
 	case err := <-errCh:
 		return nil, err
-	case <-time.After(timeout):
+	case <-ctx.Done():
 		return nil, fmt.Errorf("timeout processing '%s' request", method)
 	}
 }
 
+// registerBuiltinMethods registers the methods the server provides itself
+func registerBuiltinMethods() {
+	RegisterMethod("feed-analysis", func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		return analyzeFeed(cfg, params)
+	}, 15*time.Second)
+	RegisterMethod("post-assist", post.GeneratePost, 5*time.Second)
+	RegisterMethodContext("post-submit", submitPostMethod, 10*time.Second, WriteMethod())
+	RegisterMethodContext("post-gate", postGateMethod, 10*time.Second, WriteMethod())
+	RegisterMethod("community-manage", community.ManageCommunity, 10*time.Second)
+	RegisterMethodContext("community-list", community.ManageListContext, 15*time.Second, WriteMethod())
+	RegisterMethod("text-analyze", textAnalyzeMethod, 5*time.Second)
+	RegisterMethod("feed-trend", feedTrendMethod, 25*time.Second)
+	RegisterMethod("post-hashtags", postHashtagsMethod, 10*time.Second)
+	RegisterMethod("feed-quotes", feedQuotesMethod, 15*time.Second)
+}
+
+// submitPostMethod submits a post directly, with optional labels, tags, reply
+// restrictions and link card
+func submitPostMethod(ctx context.Context, cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	text, ok := params["text"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid parameter: text is required")
+	}
+	if err := post.CheckPostText(text); err != nil {
+		return nil, err
+	}
+	force, _ := params["force"].(bool)
+	labels, err := stringSliceParam(params, "labels")
+	if err != nil {
+		return nil, err
+	}
+	tags, err := stringSliceParam(params, "tags")
+	if err != nil {
+		return nil, err
+	}
+	threadgate, err := threadgateParam(params)
+	if err != nil {
+		return nil, err
+	}
+	external, err := externalParam(params)
+	if err != nil {
+		return nil, err
+	}
+	if external != nil {
+		// Fill in a URI-only card from the page, leaving time for the post itself
+		fetchCtx, cancel := context.WithTimeout(ctx, linkCardFetchTimeout)
+		completed, fetchErr := post.CompleteExternalCard(fetchCtx, *external)
+		cancel()
+		if fetchErr != nil {
+			log.Printf("Warning: could not fetch link card metadata for %s: %v", external.URI, fetchErr)
+		}
+		external = &completed
+	}
+
+	postResult, err := submitPost(ctx, cfg, text, post.SubmitPostOptions{
+		Force:      force,
+		Labels:     labels,
+		Tags:       tags,
+		Threadgate: threadgate,
+		External:   external,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	submitted := map[string]interface{}{
+		"submitted": true,
+		"post_uri":  postResult.URI,
+		"post_cid":  postResult.CID,
+	}
+	if postResult.Warning != "" {
+		submitted["warning"] = postResult.Warning
+	}
+	return submitted, nil
+}
+
+// postGateMethod changes who can reply to one of the user's existing posts
+func postGateMethod(ctx context.Context, cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	uri, _ := params["uri"].(string)
+	if strings.TrimSpace(uri) == "" {
+		return nil, fmt.Errorf("invalid parameter: uri is required")
+	}
+	threadgate, err := threadgateParam(params)
+	if err != nil {
+		return nil, err
+	}
+	if threadgate == nil {
+		return nil, fmt.Errorf("invalid parameter: threadgate is required")
+	}
+
+	gate, err := post.UpdatePostGateContext(ctx, cfg, uri, *threadgate)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"updated":        true,
+		"threadgate_uri": gate.URI,
+		"threadgate_cid": gate.CID,
+	}, nil
+}
+
+// textAnalyzeMethod analyzes text without any Bluesky API calls
+func textAnalyzeMethod(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	text, ok := params["text"].(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("invalid parameter: text is required")
+	}
+	if len(text) > feed.MaxAnalyzeTextLength {
+		return nil, fmt.Errorf("invalid parameter: text exceeds %d bytes", feed.MaxAnalyzeTextLength)
+	}
+	version, err := feed.AnalysisVersionParam(params)
+	if err != nil {
+		return nil, err
+	}
+	return feed.AnalyzeText(text).AsVersion(version), nil
+}
+
+// feedTrendMethod computes a hashtag's sentiment over a window split into buckets
+func feedTrendMethod(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	hashtag, _ := params["hashtag"].(string)
+	buckets := feed.DefaultTrendBuckets
+	if value, ok := params["buckets"].(float64); ok {
+		buckets = int(value)
+	}
+	window := feed.DefaultTrendWindow
+	if value, ok := params["window"].(string); ok && value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter: window must be a duration such as \"24h\"")
+		}
+		window = parsed
+	}
+	return sentimentTimeline(cfg, hashtag, buckets, window)
+}
+
+// postHashtagsMethod suggests hashtags used alongside a topic
+func postHashtagsMethod(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	topic, _ := params["topic"].(string)
+	count := post.DefaultHashtagSuggestions
+	if value, ok := params["count"].(float64); ok {
+		count = int(value)
+	}
+	hashtags, err := suggestHashtags(cfg, topic, count)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"topic":    topic,
+		"hashtags": hashtags,
+	}, nil
+}
+
+// feedQuotesMethod returns a page of the posts quoting a post, analyzed like a feed
+func feedQuotesMethod(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	uri, _ := params["uri"].(string)
+	cid, _ := params["cid"].(string)
+	limit, _ := params["limit"].(float64)
+	cursor, _ := params["cursor"].(string)
+	return getQuotes(cfg, uri, cid, int(limit), cursor)
+}
+
+// stringSliceParam extracts an optional array of strings from the request params
+func stringSliceParam(params map[string]interface{}, name string) ([]string, error) {
+	raw, ok := params[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid parameter: %s must be an array of strings", name)
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter: %s must be an array of strings", name)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// threadgateParam builds the optional reply restrictions from the threadgate and threadgate_lists params
+func threadgateParam(params map[string]interface{}) (*post.Threadgate, error) {
+	allow, err := stringSliceParam(params, "threadgate")
+	if err != nil {
+		return nil, err
+	}
+	lists, err := stringSliceParam(params, "threadgate_lists")
+	if err != nil {
+		return nil, err
+	}
+	if allow == nil && lists == nil {
+		return nil, nil
+	}
+	return &post.Threadgate{Allow: allow, Lists: lists}, nil
+}
+
+// externalParam builds the optional link card from the external_uri, external_title,
+// external_description and base64-encoded external_thumb params
+func externalParam(params map[string]interface{}) (*post.ExternalCard, error) {
+	names := []string{"external_uri", "external_title", "external_description", "external_thumb"}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		raw, ok := params[name]
+		if !ok {
+			continue
+		}
+		value, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter: %s must be a string", name)
+		}
+		values[name] = value
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	if values["external_uri"] == "" {
+		return nil, fmt.Errorf("invalid parameter: external_uri is required for a link card")
+	}
+	card := &post.ExternalCard{
+		URI:         values["external_uri"],
+		Title:       values["external_title"],
+		Description: values["external_description"],
+	}
+	if thumb := values["external_thumb"]; thumb != "" {
+		data, err := base64.StdEncoding.DecodeString(thumb)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter: external_thumb must be base64-encoded image data")
+		}
+		card.Thumb = data
+	}
+	return card, nil
+}
+
 // handleMethodError categorizes errors and returns an appropriate response
 func handleMethodError(c echo.Context, err error, requestID int) error {
 	errString := err.Error()
+
+	// Length limit errors name the field and its limit
+	var lengthErr *config.LengthError
+	if errors.As(err, &lengthErr) {
+		return respondWithError(c, http.StatusBadRequest, models.ErrInvalidParams,
+			fmt.Sprintf("Invalid parameters: %s exceeds %d characters", lengthErr.Field, lengthErr.Limit), requestID)
+	}
+
+	// A restricted app password is not a failed sign-in, so it is reported apart
+	if errors.Is(err, apiclient.ErrInsufficientScope) {
+		return respondWithError(c, http.StatusForbidden, models.ErrForbidden,
+			"The app password in use lacks permission for this action", requestID)
+	}
+
+	// A suspended or deactivated account is named, rather than reported as an upstream failure
+	var unavailable *community.AccountUnavailableError
+	if errors.As(err, &unavailable) {
+		return respondWithError(c, http.StatusNotFound, models.ErrNotFound,
+			fmt.Sprintf("The account %s is %s", unavailable.Actor, unavailable.Status), requestID)
+	}
+	if errors.Is(err, apiclient.ErrAccountUnavailable) {
+		return respondWithError(c, http.StatusNotFound, models.ErrNotFound,
+			"The account is suspended or deactivated", requestID)
+	}
+
+	// Unreadable upstream data is not the caller's mistake, whatever its parse error says
+	if errors.Is(err, feed.ErrMalformedFeed) {
+		return respondWithError(c, http.StatusBadGateway, models.ErrAPIError,
+			"Upstream API returned a malformed feed", requestID)
+	}
 	
 	// Check for known error types
 	switch {
@@ -234,6 +511,10 @@ func handleMethodError(c echo.Context, err error, requestID int) error {
 		return respondWithError(c, http.StatusUnauthorized, models.ErrAuthenticationError, 
 			"Authentication failed", requestID)
 			
+	case strings.Contains(errString, "duplicate post"):
+		return respondWithError(c, http.StatusConflict, models.ErrDuplicatePost, 
+			"Duplicate post", requestID)
+			
 	case strings.Contains(errString, "not found") || strings.Contains(errString, "404"):
 		return respondWithError(c, http.StatusNotFound, models.ErrNotFound, 
 			"Resource not found", requestID)
@@ -244,7 +525,8 @@ func handleMethodError(c echo.Context, err error, requestID int) error {
 			"Invalid parameters", requestID)
 			
 	case strings.Contains(errString, "server") || strings.Contains(errString, "API error") ||
-		 strings.Contains(errString, "status 5") || strings.Contains(errString, "failed to create post"):
+		 strings.Contains(errString, "status 5") || strings.Contains(errString, "failed to create post") ||
+		 strings.Contains(errString, "failed to create record"):
 		return respondWithError(c, http.StatusBadGateway, models.ErrAPIError, 
 			"Upstream API error", requestID)
 	
@@ -261,6 +543,12 @@ func respondWithError(c echo.Context, httpStatus int, errorCode, message string,
 		log.Printf("Error response: %s - %s", errorCode, message)
 	}
 	
+	// Plain responses describe the error as RFC 7807 problem details
+	if negotiateFormat(c) == formatPlain {
+		c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+		return c.JSON(httpStatus, models.NewProblemDetails(httpStatus, errorCode, message))
+	}
+
 	// For 5xx errors, use detailed error format with timestamp
 	if httpStatus >= 500 {
 		timestamp := time.Now().Format(time.RFC3339)
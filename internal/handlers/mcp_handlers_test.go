@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,6 +11,10 @@ import (
 	"time"
 
 	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/community"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/feed"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 	"github.com/labstack/echo/v4"
 )
@@ -93,6 +99,13 @@ func TestHandleMCPRequestValidationErrors(t *testing.T) {
 			wantStatusCode: http.StatusBadRequest,
 			wantErrorCode:  models.ErrInvalidRequest,
 		},
+		{
+			name:           "Text analyze method with missing text",
+			method:         "text-analyze",
+			requestBody:    `{"jsonrpc": "2.0", "method": "text-analyze", "params": {"text": "  "}, "id": 1}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrorCode:  models.ErrInvalidParams,
+		},
 		{
 			name:           "Post submit method with missing text",
 			method:         "post-submit",
@@ -100,6 +113,34 @@ func TestHandleMCPRequestValidationErrors(t *testing.T) {
 			wantStatusCode: http.StatusBadRequest,
 			wantErrorCode:  models.ErrInvalidParams,
 		},
+		{
+			name:           "Post submit method with empty text",
+			method:         "post-submit",
+			requestBody:    `{"jsonrpc": "2.0", "method": "post-submit", "params": {"text": ""}, "id": 1}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrorCode:  models.ErrInvalidParams,
+		},
+		{
+			name:           "Post gate method with missing uri",
+			method:         "post-gate",
+			requestBody:    `{"jsonrpc": "2.0", "method": "post-gate", "params": {"threadgate": ["nobody"]}, "id": 1}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrorCode:  models.ErrInvalidParams,
+		},
+		{
+			name:           "Post gate method with missing threadgate",
+			method:         "post-gate",
+			requestBody:    `{"jsonrpc": "2.0", "method": "post-gate", "params": {"uri": "at://did:plc:me/app.bsky.feed.post/3k"}, "id": 1}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrorCode:  models.ErrInvalidParams,
+		},
+		{
+			name:           "Post submit method with whitespace-only text",
+			method:         "post-submit",
+			requestBody:    `{"jsonrpc": "2.0", "method": "post-submit", "params": {"text": " \n\t "}, "id": 1}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrorCode:  models.ErrInvalidParams,
+		},
 	}
 
 	for _, tt := range tests {
@@ -148,6 +189,346 @@ func TestHandleMCPRequestValidationErrors(t *testing.T) {
 	}
 }
 
+func TestHandleMCPRequestTextAnalyze(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "text-analyze", "params": {"text": "Feeling happy and good"}, "id": 7}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("text-analyze")
+
+	// No credentials are needed
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleMCPRequest() status code = %v, want %v: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var response struct {
+		Result models.Post `json:"result"`
+		ID     int         `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Result.Analysis["sentiment"] != "positive" || response.Result.Metrics["words"] != 4 {
+		t.Errorf("Result = %+v, want positive sentiment and 4 words", response.Result)
+	}
+}
+
+func TestHandleMCPRequestStaleFeedWarnings(t *testing.T) {
+	originalAnalyzeFeed := analyzeFeed
+	defer func() { analyzeFeed = originalAnalyzeFeed }()
+	analyzeFeed = func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		return models.FeedResponse{
+			Posts:   []models.Post{{ID: "post1", Text: "Cached #golang post"}},
+			Count:   1,
+			Warning: "Data may be stale due to API errors",
+			Source:  "cache_stale",
+		}, nil
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"hashtag": "golang"}, "id": 3}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("feed-analysis")
+
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleMCPRequest() status code = %v, want %v: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var response struct {
+		Result   models.FeedResponse `json:"result"`
+		Warnings []string            `json:"warnings"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Warnings) != 1 || response.Warnings[0] != "Data may be stale due to API errors" {
+		t.Errorf("Warnings = %v, want the stale data warning", response.Warnings)
+	}
+	if response.Result.Source != "cache_stale" {
+		t.Errorf("Result source = %q, want cache_stale", response.Result.Source)
+	}
+}
+
+func TestHandleMCPRequestQueuedPost(t *testing.T) {
+	originalSubmitPost := submitPost
+	defer func() { submitPost = originalSubmitPost }()
+	submitPost = func(ctx context.Context, cfg config.Config, text string, opts post.SubmitPostOptions) (*post.PostResult, error) {
+		return nil, &post.QueuedError{ID: "42", Err: fmt.Errorf("failed to create post: %w", apiclient.ErrCircuitOpen)}
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "post-submit", "params": {"text": "hello"}, "id": 4}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("post-submit")
+
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	// The queue will send the post, so it is accepted rather than reported as failed
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("HandleMCPRequest() status code = %v, want %v: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+
+	var response struct {
+		Result   map[string]interface{} `json:"result"`
+		Warnings []string               `json:"warnings"`
+		Error    *models.ErrorInfo      `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error != nil {
+		t.Errorf("Error = %+v, want none for a queued post", response.Error)
+	}
+	if response.Result["queued"] != true || response.Result["queue_id"] != "42" || response.Result["submitted"] != false {
+		t.Errorf("Result = %v, want a queued post with its queue ID", response.Result)
+	}
+	if len(response.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want the submission failure", response.Warnings)
+	}
+}
+
+func TestHandleMCPRequestWithoutParams(t *testing.T) {
+	originalAnalyzeFeed := analyzeFeed
+	defer func() { analyzeFeed = originalAnalyzeFeed }()
+	var gotParams map[string]interface{}
+	analyzeFeed = func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		// Services fill in defaults, which panics on a nil map
+		params["hashtag"] = ""
+		gotParams = params
+		return models.FeedResponse{Posts: []models.Post{}, Source: "api_fresh"}, nil
+	}
+
+	for _, body := range []string{
+		`{"jsonrpc": "2.0", "method": "feed-analysis", "id": 4}`,
+		`{"jsonrpc": "2.0", "method": "feed-analysis", "params": null, "id": 4}`,
+	} {
+		gotParams = nil
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/mcp/:method")
+		c.SetParamNames("method")
+		c.SetParamValues("feed-analysis")
+
+		if err := HandleMCPRequest(c, config.Config{}); err != nil {
+			t.Fatalf("HandleMCPRequest(%s) returned error: %v", body, err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("HandleMCPRequest(%s) status code = %v, want %v: %s", body, rec.Code, http.StatusOK, rec.Body.String())
+		}
+		if gotParams == nil {
+			t.Errorf("HandleMCPRequest(%s) passed no params map to the service", body)
+		}
+	}
+}
+
+func TestProcessMCPMethodFeedTrend(t *testing.T) {
+	originalTimeline := sentimentTimeline
+	defer func() { sentimentTimeline = originalTimeline }()
+
+	var gotHashtag string
+	var gotBuckets int
+	var gotWindow time.Duration
+	sentimentTimeline = func(cfg config.Config, hashtag string, buckets int, window time.Duration) (*feed.SentimentTrend, error) {
+		gotHashtag, gotBuckets, gotWindow = hashtag, buckets, window
+		return &feed.SentimentTrend{Hashtag: hashtag, Warning: "Only the latest 1000 posts were analyzed"}, nil
+	}
+
+	result, err := processMCPMethod("feed-trend", map[string]interface{}{
+		"hashtag": "golang",
+		"buckets": float64(6),
+		"window":  "3h",
+	}, config.Config{})
+	if err != nil {
+		t.Fatalf("processMCPMethod() unexpected error: %v", err)
+	}
+	if gotHashtag != "golang" || gotBuckets != 6 || gotWindow != 3*time.Hour {
+		t.Errorf("SentimentTimeline called with %q, %d, %v; want golang, 6, 3h", gotHashtag, gotBuckets, gotWindow)
+	}
+	if warnings := resultWarnings(result); len(warnings) != 1 {
+		t.Errorf("resultWarnings() = %v, want the trend warning", warnings)
+	}
+
+	// Defaults apply when buckets and window are omitted
+	if _, err := processMCPMethod("feed-trend", map[string]interface{}{"hashtag": "golang"}, config.Config{}); err != nil {
+		t.Fatalf("processMCPMethod() unexpected error: %v", err)
+	}
+	if gotBuckets != feed.DefaultTrendBuckets || gotWindow != feed.DefaultTrendWindow {
+		t.Errorf("Defaults = %d, %v; want %d, %v", gotBuckets, gotWindow, feed.DefaultTrendBuckets, feed.DefaultTrendWindow)
+	}
+
+	_, err = processMCPMethod("feed-trend", map[string]interface{}{"hashtag": "golang", "window": "a day"}, config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "invalid parameter: window") {
+		t.Errorf("Expected invalid window error, got %v", err)
+	}
+}
+
+func TestProcessMCPMethodPostHashtags(t *testing.T) {
+	originalSuggest := suggestHashtags
+	defer func() { suggestHashtags = originalSuggest }()
+
+	var gotTopic string
+	var gotCount int
+	suggestHashtags = func(cfg config.Config, topic string, n int) ([]string, error) {
+		gotTopic, gotCount = topic, n
+		return []string{"gophers", "programming"}, nil
+	}
+
+	result, err := processMCPMethod("post-hashtags", map[string]interface{}{"topic": "golang", "count": float64(2)}, config.Config{})
+	if err != nil {
+		t.Fatalf("processMCPMethod() unexpected error: %v", err)
+	}
+	if gotTopic != "golang" || gotCount != 2 {
+		t.Errorf("SuggestHashtags called with %q, %d; want golang, 2", gotTopic, gotCount)
+	}
+	if hashtags := result.(map[string]interface{})["hashtags"].([]string); len(hashtags) != 2 || hashtags[0] != "gophers" {
+		t.Errorf("Unexpected result: %v", result)
+	}
+
+	// The count defaults when omitted
+	if _, err := processMCPMethod("post-hashtags", map[string]interface{}{"topic": "golang"}, config.Config{}); err != nil {
+		t.Fatalf("processMCPMethod() unexpected error: %v", err)
+	}
+	if gotCount != post.DefaultHashtagSuggestions {
+		t.Errorf("Default count = %d, want %d", gotCount, post.DefaultHashtagSuggestions)
+	}
+}
+
+func TestProcessMCPMethodFeedQuotes(t *testing.T) {
+	originalQuotes := getQuotes
+	defer func() { getQuotes = originalQuotes }()
+
+	var gotURI, gotCID, gotCursor string
+	var gotLimit int
+	getQuotes = func(cfg config.Config, uri, cid string, limit int, cursor string) (*feed.QuotesResult, error) {
+		gotURI, gotCID, gotLimit, gotCursor = uri, cid, limit, cursor
+		return &feed.QuotesResult{URI: uri, Posts: []models.Post{{ID: "1"}}, Cursor: "next"}, nil
+	}
+
+	result, err := processMCPMethod("feed-quotes", map[string]interface{}{
+		"uri":    "at://did:plc:alice/app.bsky.feed.post/3kabc",
+		"cid":    "bafyquoted",
+		"limit":  float64(20),
+		"cursor": "page2",
+	}, config.Config{})
+	if err != nil {
+		t.Fatalf("processMCPMethod() unexpected error: %v", err)
+	}
+	if gotURI != "at://did:plc:alice/app.bsky.feed.post/3kabc" || gotCID != "bafyquoted" || gotLimit != 20 || gotCursor != "page2" {
+		t.Errorf("GetQuotes called with %q, %q, %d, %q", gotURI, gotCID, gotLimit, gotCursor)
+	}
+	if quotes := result.(*feed.QuotesResult); len(quotes.Posts) != 1 || quotes.Cursor != "next" {
+		t.Errorf("Unexpected result: %+v", quotes)
+	}
+}
+
+func TestResultWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		want   int
+	}{
+		{name: "Fresh feed", result: models.FeedResponse{Source: "api_fresh"}, want: 0},
+		{name: "Stale feed", result: models.FeedResponse{Warning: "Data may be stale", Source: "cache_stale"}, want: 1},
+		{name: "Post with warning", result: map[string]interface{}{"submitted": true, "warning": "threadgate was not applied"}, want: 1},
+		{name: "Post without warning", result: map[string]interface{}{"submitted": true}, want: 0},
+		{name: "Other result", result: models.Post{Text: "hello"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultWarnings(tt.result); len(got) != tt.want {
+				t.Errorf("resultWarnings() = %v, want %d warnings", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMCPRequestReadOnly(t *testing.T) {
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Write method",
+			method:   "post-submit",
+			body:     `{"jsonrpc": "2.0", "method": "post-submit", "params": {"text": "hello"}, "id": 1}`,
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "Suggestion that would be submitted",
+			method:   "post-assist",
+			body:     `{"jsonrpc": "2.0", "method": "post-assist", "params": {"topic": "Go", "submit": true}, "id": 2}`,
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "Suggestion only",
+			method:   "post-assist",
+			body:     `{"jsonrpc": "2.0", "method": "post-assist", "params": {"topic": "Go"}, "id": 3}`,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/mcp/:method")
+			c.SetParamNames("method")
+			c.SetParamValues(tt.method)
+
+			if err := HandleMCPRequest(c, config.Config{}); err != nil {
+				t.Fatalf("HandleMCPRequest() returned error: %v", err)
+			}
+			if rec.Code != tt.wantCode {
+				t.Fatalf("HandleMCPRequest() status code = %v, want %v: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode == http.StatusOK {
+				return
+			}
+
+			var response models.JSONRPCResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Error == nil || response.Error.Code != models.ErrServiceUnavailable {
+				t.Errorf("Error = %+v, want code %s", response.Error, models.ErrServiceUnavailable)
+			}
+		})
+	}
+}
+
 func TestHandleMethodError(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -167,6 +548,12 @@ func TestHandleMethodError(t *testing.T) {
 			wantStatusCode: http.StatusUnauthorized,
 			wantErrorCode:  models.ErrAuthenticationError,
 		},
+		{
+			name:           "Duplicate post error",
+			errString:      "duplicate post: same text was posted at 2025-01-01T00:00:00Z",
+			wantStatusCode: http.StatusConflict,
+			wantErrorCode:  models.ErrDuplicatePost,
+		},
 		{
 			name:           "Not found error",
 			errString:      "resource not found",
@@ -191,6 +578,12 @@ func TestHandleMethodError(t *testing.T) {
 			wantStatusCode: http.StatusBadGateway,
 			wantErrorCode:  models.ErrAPIError,
 		},
+		{
+			name:           "Failed to create record error",
+			errString:      "failed to create record: request failed",
+			wantStatusCode: http.StatusBadGateway,
+			wantErrorCode:  models.ErrAPIError,
+		},
 		{
 			name:           "Generic error",
 			errString:      "something went wrong",
@@ -243,4 +636,153 @@ func TestHandleMethodError(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestHandleMethodErrorInsufficientScope(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	scopeErr := &apiclient.APIError{StatusCode: http.StatusBadRequest, Response: map[string]interface{}{"message": "Bad token scope"}}
+	if err := handleMethodError(c, fmt.Errorf("failed to create record: %w", scopeErr), 1); err != nil {
+		t.Fatalf("handleMethodError() returned error: %v", err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("handleMethodError() status code = %v, want %v", rec.Code, http.StatusForbidden)
+	}
+
+	var response models.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != models.ErrForbidden || !strings.Contains(response.Error.Message, "app password") {
+		t.Errorf("Error = %+v, want a forbidden error naming the app password", response.Error)
+	}
+}
+
+func TestHandleMethodErrorAccountUnavailable(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	unavailable := &community.AccountUnavailableError{Actor: "bob.bsky.social", Status: "suspended"}
+	if err := handleMethodError(c, unavailable, 1); err != nil {
+		t.Fatalf("handleMethodError() returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("handleMethodError() status code = %v, want %v", rec.Code, http.StatusNotFound)
+	}
+
+	var response models.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Message != "The account bob.bsky.social is suspended" {
+		t.Errorf("Error = %+v, want the account and its status named", response.Error)
+	}
+}
+
+func TestHandleMethodErrorMalformedFeed(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// The parse error's "invalid character" must not make it a parameter error
+	err := fmt.Errorf("feed analysis failed: %w", feed.FetchError{
+		Message: "Invalid JSON response from API",
+		Cause:   fmt.Errorf("%w: invalid character 'x' looking for beginning of value", feed.ErrMalformedFeed),
+	})
+	if err := handleMethodError(c, err, 1); err != nil {
+		t.Fatalf("handleMethodError() returned error: %v", err)
+	}
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("handleMethodError() status code = %v, want %v", rec.Code, http.StatusBadGateway)
+	}
+
+	var response models.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != models.ErrAPIError {
+		t.Errorf("Error = %+v, want an upstream API error", response.Error)
+	}
+}
+
+func TestHandleMethodErrorLengthLimit(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := fmt.Errorf("wrapped: %w", &config.LengthError{Field: config.FieldTopic, Limit: 200})
+	if err := handleMethodError(c, err, 1); err != nil {
+		t.Fatalf("handleMethodError() returned error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("handleMethodError() status code = %v, want %v", rec.Code, http.StatusBadRequest)
+	}
+
+	var response models.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != models.ErrInvalidParams {
+		t.Fatalf("Error = %+v, want %s", response.Error, models.ErrInvalidParams)
+	}
+	if !strings.Contains(response.Error.Message, "topic exceeds 200 characters") {
+		t.Errorf("Error message = %q, want the field and limit", response.Error.Message)
+	}
+}
+
+func TestStringSliceParam(t *testing.T) {
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"labels": ["nudity", "!warn"], "bad": ["ok", 1], "text": "hi"}`), &params); err != nil {
+		t.Fatalf("Failed to unmarshal params: %v", err)
+	}
+
+	labels, err := stringSliceParam(params, "labels")
+	if err != nil || len(labels) != 2 || labels[0] != "nudity" || labels[1] != "!warn" {
+		t.Errorf("stringSliceParam(labels) = %v, %v", labels, err)
+	}
+
+	if values, err := stringSliceParam(params, "missing"); err != nil || values != nil {
+		t.Errorf("stringSliceParam(missing) = %v, %v; want nil, nil", values, err)
+	}
+
+	for _, name := range []string{"bad", "text"} {
+		if _, err := stringSliceParam(params, name); err == nil || !strings.Contains(err.Error(), "invalid parameter") {
+			t.Errorf("stringSliceParam(%s) error = %v, want invalid parameter", name, err)
+		}
+	}
+}
+
+func TestExternalParam(t *testing.T) {
+	if card, err := externalParam(map[string]interface{}{"text": "hi"}); err != nil || card != nil {
+		t.Errorf("externalParam() without link params = %v, %v; want nil, nil", card, err)
+	}
+
+	card, err := externalParam(map[string]interface{}{
+		"external_uri":   "https://go.dev",
+		"external_title": "Go",
+		"external_thumb": "iVBORw0KGgo=",
+	})
+	if err != nil {
+		t.Fatalf("externalParam() unexpected error: %v", err)
+	}
+	if card.URI != "https://go.dev" || card.Title != "Go" || string(card.Thumb) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("externalParam() = %+v", card)
+	}
+
+	invalid := []map[string]interface{}{
+		{"external_title": "Go"},
+		{"external_uri": 1},
+		{"external_uri": "https://go.dev", "external_thumb": "not base64!"},
+	}
+	for _, params := range invalid {
+		if _, err := externalParam(params); err == nil || !strings.Contains(err.Error(), "invalid parameter") {
+			t.Errorf("externalParam(%v) error = %v, want invalid parameter", params, err)
+		}
+	}
+}
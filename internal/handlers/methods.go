@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// MethodFunc runs an MCP method with the request's params and returns its result
+type MethodFunc func(cfg config.Config, params map[string]interface{}) (interface{}, error)
+
+// MethodContextFunc is a MethodFunc that is also given a context, which is
+// done once the method's timeout elapses
+type MethodContextFunc func(ctx context.Context, cfg config.Config, params map[string]interface{}) (interface{}, error)
+
+// DefaultMethodTimeout bounds methods registered without a timeout of their own
+const DefaultMethodTimeout = 10 * time.Second
+
+// registeredMethod is a method handler, how long a request to it may take and
+// whether it modifies the account
+type registeredMethod struct {
+	handler MethodContextFunc
+	timeout time.Duration
+	write   bool
+}
+
+// methods holds every MCP method served at /mcp/:method
+var (
+	methodsMu sync.RWMutex
+	methods   = map[string]registeredMethod{}
+)
+
+// MethodOption configures a method when it is registered
+type MethodOption func(*methodOptions)
+
+// methodOptions are the settings MethodOptions change
+type methodOptions struct {
+	write bool
+}
+
+// WriteMethod marks a method as modifying the account, so it is refused in
+// read-only mode and its results are never cached
+func WriteMethod() MethodOption {
+	return func(options *methodOptions) {
+		options.write = true
+	}
+}
+
+func init() {
+	registerBuiltinMethods()
+}
+
+// RegisterMethod serves handler as the MCP method name, replacing any method
+// already registered with that name, so embedders can add or override methods.
+// A timeout of 0 or less uses DefaultMethodTimeout. A method that modifies the
+// account must be registered with WriteMethod, as replacing a method also
+// replaces whether it counts as a write.
+func RegisterMethod(name string, handler MethodFunc, timeout time.Duration, options ...MethodOption) {
+	RegisterMethodContext(name, func(ctx context.Context, cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		return handler(cfg, params)
+	}, timeout, options...)
+}
+
+// RegisterMethodContext is RegisterMethod for a handler that is given the
+// request's context, so it can stop waiting or writing once the request times out
+func RegisterMethodContext(name string, handler MethodContextFunc, timeout time.Duration, options ...MethodOption) {
+	if timeout <= 0 {
+		timeout = DefaultMethodTimeout
+	}
+	var settings methodOptions
+	for _, option := range options {
+		option(&settings)
+	}
+
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+	methods[name] = registeredMethod{handler: handler, timeout: timeout, write: settings.write}
+}
+
+// lookupMethod returns the method registered under name
+func lookupMethod(name string) (registeredMethod, bool) {
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+	method, ok := methods[name]
+	return method, ok
+}
+
+// IsWriteMethod reports whether name is registered as a write method
+func IsWriteMethod(name string) bool {
+	method, ok := lookupMethod(name)
+	return ok && method.write
+}
+
+// IsValidMethod reports whether name is a registered MCP method
+func IsValidMethod(name string) bool {
+	_, ok := lookupMethod(name)
+	return ok
+}
+
+// Methods returns the names of the registered MCP methods in sorted order
+func Methods() []string {
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleListMethods lists the registered MCP methods so clients can discover them
+func HandleListMethods(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{"methods": Methods()})
+}
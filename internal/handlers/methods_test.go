@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// registerTestMethod registers a method for the length of a test
+func registerTestMethod(t *testing.T, name string, handler MethodFunc, timeout time.Duration, options ...MethodOption) {
+	t.Helper()
+	RegisterMethod(name, handler, timeout, options...)
+	t.Cleanup(func() { unregisterTestMethod(name) })
+}
+
+// unregisterTestMethod removes a method registered by a test
+func unregisterTestMethod(name string) {
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+	delete(methods, name)
+}
+
+func TestRegisterMethodDispatches(t *testing.T) {
+	registerTestMethod(t, "echo-greeting", func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		name, _ := params["name"].(string)
+		return map[string]string{"greeting": "hello " + name}, nil
+	}, time.Second)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "echo-greeting", "params": {"name": "sky"}, "id": 9}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("echo-greeting")
+
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Result map[string]string `json:"result"`
+		ID     int               `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Result["greeting"] != "hello sky" || response.ID != 9 {
+		t.Errorf("Response = %+v, want the custom method's result", response)
+	}
+}
+
+func TestRegisterMethodTimeout(t *testing.T) {
+	registerTestMethod(t, "slow-method", func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		time.Sleep(200 * time.Millisecond)
+		return "done", nil
+	}, 20*time.Millisecond)
+
+	_, err := processMCPMethod("slow-method", map[string]interface{}{}, config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("processMCPMethod() error = %v, want a timeout", err)
+	}
+}
+
+func TestRegisterMethodContextCancelledOnTimeout(t *testing.T) {
+	done := make(chan error, 1)
+	RegisterMethodContext("slow-context-method", func(ctx context.Context, cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		<-ctx.Done()
+		done <- ctx.Err()
+		return nil, ctx.Err()
+	}, 20*time.Millisecond)
+	t.Cleanup(func() { unregisterTestMethod("slow-context-method") })
+
+	_, err := processMCPMethod("slow-context-method", map[string]interface{}{}, config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("processMCPMethod() error = %v, want a timeout", err)
+	}
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Method context error = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Method context was not cancelled when the request timed out")
+	}
+}
+
+func TestRegisterMethodValidationAndDiscovery(t *testing.T) {
+	if IsValidMethod("custom-lookup") {
+		t.Fatal("custom-lookup is valid before it is registered")
+	}
+	registerTestMethod(t, "custom-lookup", func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	}, 0)
+
+	if !IsValidMethod("custom-lookup") {
+		t.Error("A registered method is not valid")
+	}
+	if IsWriteMethod("custom-lookup") {
+		t.Error("A method registered without WriteMethod is a write method")
+	}
+	if method, _ := lookupMethod("custom-lookup"); method.timeout != DefaultMethodTimeout {
+		t.Errorf("Timeout = %s, want the default", method.timeout)
+	}
+	// Registered methods can have results cached like the built-in ones
+	if _, err := ParseResultCacheTTLs("custom-lookup=30s"); err != nil {
+		t.Errorf("ParseResultCacheTTLs() unexpected error: %v", err)
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	rec := httptest.NewRecorder()
+	if err := HandleListMethods(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("HandleListMethods() returned error: %v", err)
+	}
+	var listed struct {
+		Methods []string `json:"methods"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Failed to unmarshal methods: %v", err)
+	}
+	found := map[string]bool{}
+	for _, name := range listed.Methods {
+		found[name] = true
+	}
+	for _, name := range []string{"custom-lookup", "feed-analysis", "post-submit", "text-analyze"} {
+		if !found[name] {
+			t.Errorf("Methods %v missing %s", listed.Methods, name)
+		}
+	}
+}
+
+func TestHandleMCPRequestUnregisteredMethod(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "not-registered", "params": {}, "id": 1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("not-registered")
+
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	var response models.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest || response.Error == nil || response.Error.Code != models.ErrInvalidRequest {
+		t.Errorf("Response = %d %s, want an invalid method error", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRegisterMethodWrite(t *testing.T) {
+	calls := 0
+	registerTestMethod(t, "custom-follow", func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		calls++
+		return map[string]bool{"followed": true}, nil
+	}, time.Second, WriteMethod())
+	enableResultCache(t, map[string]time.Duration{"custom-follow": time.Minute})
+
+	if !IsWriteMethod("custom-follow") {
+		t.Fatal("A method registered with WriteMethod is not a write method")
+	}
+
+	// Writes always run, so their results are never cached
+	body := `{"jsonrpc": "2.0", "method": "custom-follow", "params": {"actor": "did:plc:alice"}, "id": 1}`
+	sendMCPRequest(t, "custom-follow", body)
+	sendMCPRequest(t, "custom-follow", body)
+	if calls != 2 {
+		t.Errorf("Got %d calls, want every request to run", calls)
+	}
+
+	// and are refused in read-only mode
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("custom-follow")
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d in read-only mode, want 503", rec.Code)
+	}
+	if calls != 2 {
+		t.Errorf("Got %d calls, want the read-only request refused", calls)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON is the media type of RFC 7807 problem details
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// responseFormat is how a request's result or error is written
+type responseFormat string
+
+const (
+	// formatJSONRPC wraps results and errors in a JSON-RPC envelope, the default
+	formatJSONRPC responseFormat = "jsonrpc"
+	// formatPlain writes the bare result, or problem details on error, with
+	// the HTTP status alone telling them apart
+	formatPlain responseFormat = "plain"
+)
+
+// negotiateFormat picks the response format from the format query parameter,
+// or else from an Accept header listing application/problem+json. Unknown
+// format values fall back to JSON-RPC; validFormatParam reports them.
+func negotiateFormat(c echo.Context) responseFormat {
+	switch responseFormat(c.QueryParam("format")) {
+	case formatPlain:
+		return formatPlain
+	case formatJSONRPC:
+		return formatJSONRPC
+	}
+
+	for _, accepted := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, _ := strings.Cut(accepted, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), MIMEApplicationProblemJSON) {
+			return formatPlain
+		}
+	}
+	return formatJSONRPC
+}
+
+// analysisVersionMethods are the methods whose results follow the analysis version
+var analysisVersionMethods = map[string]bool{
+	"feed-analysis": true,
+	"text-analyze":  true,
+}
+
+// acceptedAnalysisVersion returns the analysis-version parameter of the first
+// Accept media range carrying one, as in "application/json; analysis-version=1".
+// A value that is not a number is returned as is for the method to reject.
+func acceptedAnalysisVersion(c echo.Context) (interface{}, bool) {
+	for _, accepted := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		_, mediaParams, _ := strings.Cut(accepted, ";")
+		for _, param := range strings.Split(mediaParams, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "analysis-version") {
+				continue
+			}
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if version, err := strconv.Atoi(value); err == nil {
+				return float64(version), true
+			}
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// withAcceptedAnalysisVersion sets the analysisVersion parameter of an analysis
+// method from the Accept header, unless the parameters already name one
+func withAcceptedAnalysisVersion(c echo.Context, method string, params map[string]interface{}) map[string]interface{} {
+	if !analysisVersionMethods[method] {
+		return params
+	}
+	if _, set := params["analysisVersion"]; set {
+		return params
+	}
+	version, ok := acceptedAnalysisVersion(c)
+	if !ok {
+		return params
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	params["analysisVersion"] = version
+	return params
+}
+
+// validFormatParam checks the format query parameter, which may be left out
+func validFormatParam(c echo.Context) error {
+	switch format := responseFormat(c.QueryParam("format")); format {
+	case "", formatJSONRPC, formatPlain:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q: want jsonrpc or plain", format)
+	}
+}
+
+// setWarningHeaders reports a plain result's warnings in Warning headers, since
+// there is no envelope to carry them
+func setWarningHeaders(c echo.Context, warnings []string) {
+	for _, warning := range warnings {
+		c.Response().Header().Add("Warning", "199 - "+strconv.Quote(warning))
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// serveNegotiated sends a text-analyze request with the given query and Accept header
+func serveNegotiated(t *testing.T, query, accept, text string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	body := `{"jsonrpc": "2.0", "method": "text-analyze", "params": {"text": ` + jsonString(text) + `}, "id": 5}`
+	req := httptest.NewRequest(http.MethodPost, "/mcp/text-analyze"+query, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if accept != "" {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues("text-analyze")
+
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	return rec
+}
+
+func jsonString(s string) string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
+
+func TestHandleMCPRequestPlainFormat(t *testing.T) {
+	modes := map[string]struct{ query, accept string }{
+		"query param":   {query: "?format=plain"},
+		"accept header": {accept: "application/problem+json, application/json;q=0.9"},
+	}
+
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			// Success is the bare result
+			rec := serveNegotiated(t, mode.query, mode.accept, "Feeling happy and good")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+			var result models.Post
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result.Analysis["sentiment"] != "positive" {
+				t.Errorf("Result = %+v, want the bare analysis", result)
+			}
+			if strings.Contains(rec.Body.String(), `"jsonrpc"`) {
+				t.Errorf("Plain response has a JSON-RPC envelope: %s", rec.Body.String())
+			}
+
+			// Errors are problem details
+			rec = serveNegotiated(t, mode.query, mode.accept, "   ")
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+			if contentType := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(contentType, MIMEApplicationProblemJSON) {
+				t.Errorf("Content-Type = %q, want %s", contentType, MIMEApplicationProblemJSON)
+			}
+			var problem models.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("Failed to unmarshal problem: %v", err)
+			}
+			if problem.Status != http.StatusBadRequest || problem.Title != "Bad Request" || problem.Code != models.ErrInvalidParams {
+				t.Errorf("Problem = %+v, want a 400 invalid_params problem", problem)
+			}
+		})
+	}
+}
+
+func TestHandleMCPRequestJSONRPCFormat(t *testing.T) {
+	modes := map[string]struct{ query, accept string }{
+		"default":        {accept: "application/json"},
+		"explicit query": {query: "?format=jsonrpc", accept: "application/problem+json"},
+	}
+
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			rec := serveNegotiated(t, mode.query, mode.accept, "Feeling happy and good")
+			var response models.JSONRPCResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if rec.Code != http.StatusOK || response.JSONRPC != "2.0" || response.ID != 5 || response.Result == nil {
+				t.Errorf("Response = %d %s, want a JSON-RPC result", rec.Code, rec.Body.String())
+			}
+
+			rec = serveNegotiated(t, mode.query, mode.accept, "   ")
+			response = models.JSONRPCResponse{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if rec.Code != http.StatusBadRequest || response.Error == nil || response.Error.Code != models.ErrInvalidParams || response.ID != 5 {
+				t.Errorf("Response = %d %s, want a JSON-RPC error", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleMCPRequestInvalidFormat(t *testing.T) {
+	rec := serveNegotiated(t, "?format=xml", "", "Feeling happy")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `invalid format \"xml\"`) {
+		t.Errorf("Response = %d %s, want an invalid format error", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleMCPRequestAnalysisVersion(t *testing.T) {
+	// The plain format leaves the bare result to check for fields
+	tests := []struct {
+		name           string
+		accept         string
+		wantConfidence bool
+	}{
+		{name: "latest by default", accept: MIMEApplicationProblemJSON, wantConfidence: true},
+		{name: "version 1", accept: MIMEApplicationProblemJSON + "; analysis-version=1", wantConfidence: false},
+		{name: "version 2", accept: MIMEApplicationProblemJSON + `; analysis-version="2"`, wantConfidence: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveNegotiated(t, "", tt.accept, "Feeling happy and good")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+			var result models.Post
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result.Analysis["sentiment"] != "positive" {
+				t.Errorf("Result = %+v, want positive sentiment", result)
+			}
+			if _, ok := result.Analysis["confidence"]; ok != tt.wantConfidence {
+				t.Errorf("Result has confidence = %v, want %v", ok, tt.wantConfidence)
+			}
+		})
+	}
+
+	rec := serveNegotiated(t, "", "application/json; analysis-version=v9", "Feeling happy")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), models.ErrInvalidParams) {
+		t.Errorf("Response = %d %s, want an invalid analysisVersion error", rec.Code, rec.Body.String())
+	}
+}
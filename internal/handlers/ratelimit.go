@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// RateLimiter provides a simple rate limiting mechanism
+type RateLimiter struct {
+	mu            sync.Mutex
+	requests      map[string][]time.Time // Map of IP to request timestamps
+	windowSize    time.Duration          // Time window to track
+	maxRequests   int                    // Max requests per window
+	cleanupPeriod time.Duration          // How often to clean up old entries
+	lastCleanup   time.Time              // Last time cleanup was performed
+	store         RateLimitStore         // Shared request counts; nil keeps them in requests
+}
+
+// RateLimitStore keeps request counts outside a single RateLimiter so that
+// several limiters enforce a shared limit. Only MemoryRateLimitStore, shared
+// within one process, is provided; the server does not set a store, so
+// replicas limit independently.
+type RateLimitStore interface {
+	// Take records a request for key at now unless key already made limit
+	// requests within window, and reports whether the request is allowed
+	Take(key string, now time.Time, window time.Duration, limit int) (bool, error)
+}
+
+// Global rate limiter instance
+var rateLimiter = NewRateLimiter(time.Minute, 60) // 60 requests per minute
+
+// NewRateLimiter creates a rate limiter allowing maxRequests per window for each IP
+func NewRateLimiter(window time.Duration, maxRequests int) *RateLimiter {
+	return &RateLimiter{
+		requests:      make(map[string][]time.Time),
+		windowSize:    window,
+		maxRequests:   maxRequests,
+		cleanupPeriod: 5 * time.Minute,
+		lastCleanup:   time.Now(),
+	}
+}
+
+// SetStore makes the limiter count requests in store, shared with other
+// limiters; nil goes back to counting in memory
+func (rl *RateLimiter) SetStore(store RateLimitStore) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.store = store
+}
+
+// Allow checks if a request from the given IP should be allowed
+func (rl *RateLimiter) Allow(ip string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	
+	now := time.Now()
+
+	if rl.store != nil {
+		allowed, err := rl.store.Take(ip, now, rl.windowSize, rl.maxRequests)
+		if err != nil {
+			// An unavailable store should not take the service down with it
+			log.Printf("Warning: rate limit store error, allowing request: %v", err)
+			return true
+		}
+		return allowed
+	}
+	
+	// Clean up old entries periodically
+	if now.Sub(rl.lastCleanup) > rl.cleanupPeriod {
+		rl.cleanup(now)
+		rl.lastCleanup = now
+	}
+	
+	return takeRequest(rl.requests, ip, now, rl.windowSize, rl.maxRequests)
+}
+
+// takeRequest records a request for key in requests unless it is over the
+// limit within the window, dropping timestamps outside the window
+func takeRequest(requests map[string][]time.Time, key string, now time.Time, window time.Duration, limit int) bool {
+	// Remove timestamps outside the window
+	cutoff := now.Add(-window)
+	validTimes := []time.Time{}
+	
+	for _, t := range requests[key] {
+		if t.After(cutoff) {
+			validTimes = append(validTimes, t)
+		}
+	}
+	
+	// Check if under the limit
+	if len(validTimes) >= limit {
+		requests[key] = validTimes
+		return false
+	}
+	
+	// Add this request
+	requests[key] = append(validTimes, now)
+	return true
+}
+
+// cleanup removes old entries from the rate limiter
+func (rl *RateLimiter) cleanup(now time.Time) {
+	cutoff := now.Add(-rl.windowSize)
+	
+	for ip, times := range rl.requests {
+		validTimes := []time.Time{}
+		
+		for _, t := range times {
+			if t.After(cutoff) {
+				validTimes = append(validTimes, t)
+			}
+		}
+		
+		if len(validTimes) == 0 {
+			delete(rl.requests, ip)
+		} else {
+			rl.requests[ip] = validTimes
+		}
+	}
+}
+
+// Save writes the in-memory request timestamps still within the window to
+// path, so that Load can restore them after a restart
+func (rl *RateLimiter) Save(path string) error {
+	rl.mu.Lock()
+	rl.cleanup(time.Now())
+	snapshot := make(map[string][]time.Time, len(rl.requests))
+	for ip, times := range rl.requests {
+		snapshot[ip] = append([]time.Time(nil), times...)
+	}
+	rl.mu.Unlock()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(snapshot)
+}
+
+// Load restores request timestamps saved by Save, ignoring those that have
+// left the window. A missing file is not an error.
+func (rl *RateLimiter) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var snapshot map[string][]time.Time
+	if err := json.NewDecoder(file).Decode(&snapshot); err != nil {
+		return err
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	for ip, times := range snapshot {
+		rl.requests[ip] = append(rl.requests[ip], times...)
+	}
+	rl.cleanup(time.Now())
+	return nil
+}
+
+// LoadRateLimitState restores the server's rate limiter state from path
+func LoadRateLimitState(path string) error {
+	return rateLimiter.Load(path)
+}
+
+// SaveRateLimitState writes the server's rate limiter state to path
+func SaveRateLimitState(path string) error {
+	return rateLimiter.Save(path)
+}
+
+// SetRateLimitStore makes the server's rate limiter share its counts through store
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimiter.SetStore(store)
+}
+
+// MemoryRateLimitStore is a RateLimitStore shared by limiters in the same process
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	requests  map[string][]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-process store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{requests: make(map[string][]time.Time)}
+}
+
+// Take implements RateLimitStore
+func (s *MemoryRateLimitStore) Take(key string, now time.Time, window time.Duration, limit int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Forget keys with no requests left in the window
+	if now.Sub(s.lastSweep) > window {
+		cutoff := now.Add(-window)
+		for k, times := range s.requests {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(s.requests, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return takeRequest(s.requests, key, now, window, limit), nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+
+	rl := NewRateLimiter(time.Minute, 3)
+	for i := 0; i < 3; i++ {
+		if !rl.Allow("10.0.0.1") {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+	rl.Allow("10.0.0.2")
+	if err := rl.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	// A new limiter, as after a restart, keeps throttling the same client
+	restored := NewRateLimiter(time.Minute, 3)
+	if err := restored.Load(path); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if restored.Allow("10.0.0.1") {
+		t.Error("Expected the throttled client to stay throttled after reloading")
+	}
+	if !restored.Allow("10.0.0.2") || !restored.Allow("10.0.0.2") {
+		t.Error("Expected the other client to keep its remaining quota")
+	}
+	if restored.Allow("10.0.0.2") {
+		t.Error("Expected the other client's saved request to count towards its limit")
+	}
+}
+
+func TestRateLimiterLoadDropsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+
+	rl := NewRateLimiter(50*time.Millisecond, 1)
+	rl.Allow("10.0.0.1")
+	if err := rl.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	restored := NewRateLimiter(50*time.Millisecond, 1)
+	if err := restored.Load(path); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !restored.Allow("10.0.0.1") {
+		t.Error("Expected requests outside the window to be dropped on load")
+	}
+}
+
+func TestRateLimiterLoadMissingOrInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+
+	rl := NewRateLimiter(time.Minute, 1)
+	if err := rl.Load(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("Load() of a missing file should not fail, got %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := rl.Load(invalid); err == nil {
+		t.Error("Load() of an invalid file should fail")
+	}
+}
+
+func TestRateLimiterSharedStore(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+
+	// Two limiters, as on two replicas, share one limit through the store
+	first := NewRateLimiter(time.Minute, 3)
+	first.SetStore(store)
+	second := NewRateLimiter(time.Minute, 3)
+	second.SetStore(store)
+
+	if !first.Allow("10.0.0.1") || !second.Allow("10.0.0.1") || !first.Allow("10.0.0.1") {
+		t.Fatal("Expected the first three requests across both limiters to be allowed")
+	}
+	if second.Allow("10.0.0.1") {
+		t.Error("Expected the fourth request to be denied by the shared limit")
+	}
+	if first.Allow("10.0.0.1") {
+		t.Error("Expected the other limiter to deny the client too")
+	}
+	if !second.Allow("10.0.0.2") {
+		t.Error("Expected a different client to be allowed")
+	}
+}
+
+// failingStore is a RateLimitStore that is unavailable
+type failingStore struct{}
+
+func (failingStore) Take(key string, now time.Time, window time.Duration, limit int) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func TestRateLimiterStoreFailureAllows(t *testing.T) {
+	rl := NewRateLimiter(time.Minute, 1)
+	rl.SetStore(failingStore{})
+
+	for i := 0; i < 3; i++ {
+		if !rl.Allow("10.0.0.1") {
+			t.Errorf("Expected request %d to be allowed while the store is unavailable", i+1)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLogMode controls whether request bodies appear in the request log
+type BodyLogMode string
+
+const (
+	// BodyLogOff never logs bodies
+	BodyLogOff BodyLogMode = "off"
+	// BodyLogRedacted logs the body's shape with every string value except the
+	// JSON-RPC version and method replaced, so post text and handles are left out
+	BodyLogRedacted BodyLogMode = "redacted"
+	// BodyLogFull logs bodies as sent, except for credential fields
+	BodyLogFull BodyLogMode = "full"
+)
+
+// MaxLoggedBodyBytes is the most of a request body read for the log; longer
+// bodies are only described by their size
+const MaxLoggedBodyBytes = 4096
+
+// redactedValue replaces values left out of the log
+const redactedValue = "[redacted]"
+
+// requestLogFields are the fields a log line can contain, in the style of Echo's logger
+var requestLogFields = map[string]func(c echo.Context, start time.Time) string{
+	"time": func(c echo.Context, start time.Time) string {
+		return start.Format(time.RFC3339)
+	},
+	"remote_ip": func(c echo.Context, start time.Time) string {
+		return c.RealIP()
+	},
+	"method": func(c echo.Context, start time.Time) string {
+		return c.Request().Method
+	},
+	"uri": func(c echo.Context, start time.Time) string {
+		return c.Request().RequestURI
+	},
+	"status": func(c echo.Context, start time.Time) string {
+		return strconv.Itoa(c.Response().Status)
+	},
+	"latency": func(c echo.Context, start time.Time) string {
+		return time.Since(start).String()
+	},
+	"request_id": func(c echo.Context, start time.Time) string {
+		return c.Response().Header().Get(echo.HeaderXRequestID)
+	},
+	"bytes_in": func(c echo.Context, start time.Time) string {
+		return strconv.FormatInt(c.Request().ContentLength, 10)
+	},
+	"bytes_out": func(c echo.Context, start time.Time) string {
+		return strconv.FormatInt(c.Response().Size, 10)
+	},
+}
+
+// RequestLogOptions defines what the request log records
+type RequestLogOptions struct {
+	Fields []string    // Fields of each line, in order
+	Body   BodyLogMode // Whether and how request bodies are appended
+	Output io.Writer   // Where lines are written; nil writes to stdout
+}
+
+// DefaultRequestLogOptions logs the same line as before bodies could be logged
+var DefaultRequestLogOptions = RequestLogOptions{
+	Fields: []string{"time", "remote_ip", "method", "uri", "status", "latency"},
+	Body:   BodyLogOff,
+}
+
+// RequestLogOptionsFromEnv returns the default options adjusted by the
+// BSKY_LOG_FIELDS (comma-separated field names) and BSKY_LOG_BODIES ("off",
+// "redacted" or "full") environment variables
+func RequestLogOptionsFromEnv() (RequestLogOptions, error) {
+	options := DefaultRequestLogOptions
+
+	if value := os.Getenv("BSKY_LOG_FIELDS"); value != "" {
+		fields, err := ParseRequestLogFields(value)
+		if err != nil {
+			return options, err
+		}
+		options.Fields = fields
+	}
+
+	switch mode := BodyLogMode(os.Getenv("BSKY_LOG_BODIES")); mode {
+	case "":
+	case BodyLogOff, BodyLogRedacted, BodyLogFull:
+		options.Body = mode
+	default:
+		return options, fmt.Errorf("invalid BSKY_LOG_BODIES %q: want off, redacted or full", mode)
+	}
+
+	return options, nil
+}
+
+// ParseRequestLogFields parses a comma-separated list of log field names
+func ParseRequestLogFields(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := requestLogFields[field]; !ok {
+			return nil, fmt.Errorf("invalid log field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid log fields %q: no fields given", value)
+	}
+	return fields, nil
+}
+
+// NewRequestLogger returns middleware writing one line per request with the
+// configured fields. Bodies are only read when body logging is on, and never
+// for authentication paths.
+func NewRequestLogger(options RequestLogOptions) echo.MiddlewareFunc {
+	if len(options.Fields) == 0 {
+		options.Fields = DefaultRequestLogOptions.Fields
+	}
+	output := options.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	var outputMu sync.Mutex
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			body := ""
+			if options.Body == BodyLogRedacted || options.Body == BodyLogFull {
+				if !isAuthPath(c.Request().URL.Path) {
+					body = readLoggedBody(c, options.Body)
+				}
+			}
+
+			err := next(c)
+			if err != nil {
+				// Let Echo write the error response so its status is logged
+				c.Error(err)
+			}
+
+			values := make([]string, 0, len(options.Fields)+1)
+			for _, field := range options.Fields {
+				values = append(values, requestLogFields[field](c, start))
+			}
+			if body != "" {
+				values = append(values, "body="+body)
+			}
+
+			outputMu.Lock()
+			fmt.Fprintln(output, strings.Join(values, " "))
+			outputMu.Unlock()
+			return err
+		}
+	}
+}
+
+// isAuthPath reports whether a path looks like it carries credentials, such as
+// a session or login endpoint, whatever the body log mode
+func isAuthPath(path string) bool {
+	path = strings.ToLower(path)
+	for _, marker := range []string{"auth", "session", "login", "token", "password"} {
+		if strings.Contains(path, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// readLoggedBody reads up to MaxLoggedBodyBytes of the request body for the log
+// and puts it back for the handler
+func readLoggedBody(c echo.Context, mode BodyLogMode) string {
+	req := c.Request()
+	if req.Body == nil {
+		return ""
+	}
+	head, err := io.ReadAll(io.LimitReader(req.Body, MaxLoggedBodyBytes+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+	if err != nil || len(head) == 0 {
+		return ""
+	}
+	if len(head) > MaxLoggedBodyBytes {
+		return fmt.Sprintf("[body over %d bytes]", MaxLoggedBodyBytes)
+	}
+	return formatLoggedBody(head, mode)
+}
+
+// formatLoggedBody renders a JSON body for the log. Bodies that are not JSON are
+// only described, since their fields cannot be redacted.
+func formatLoggedBody(body []byte, mode BodyLogMode) string {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return fmt.Sprintf("[non-JSON body, %d bytes]", len(body))
+	}
+
+	if object, ok := decoded.(map[string]interface{}); ok {
+		for key, value := range object {
+			if mode == BodyLogRedacted && key != "jsonrpc" && key != "method" {
+				object[key] = redactStrings(value)
+			} else {
+				object[key] = redactCredentials(key, value)
+			}
+		}
+	} else if mode == BodyLogRedacted {
+		decoded = redactStrings(decoded)
+	} else {
+		decoded = redactCredentials("", decoded)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Sprintf("[unloggable body, %d bytes]", len(body))
+	}
+	return string(encoded)
+}
+
+// redactStrings replaces every string within value, keeping numbers, booleans and structure
+func redactStrings(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redactedValue
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = redactStrings(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactStrings(item)
+		}
+	}
+	return value
+}
+
+// redactCredentials replaces the values of credential-like keys within value
+func redactCredentials(key string, value interface{}) interface{} {
+	if isCredentialKey(key) {
+		return redactedValue
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for itemKey, item := range v {
+			v[itemKey] = redactCredentials(itemKey, item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactCredentials("", item)
+		}
+	}
+	return value
+}
+
+// isCredentialKey reports whether a JSON key names a secret, such as password,
+// accessJwt or authToken
+func isCredentialKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range []string{"password", "secret", "token", "jwt", "authorization"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// serveLogged sends body to path through the request logger and returns the log
+// output, checking that the handler still receives the whole body
+func serveLogged(t *testing.T, options RequestLogOptions, path, body string) string {
+	t.Helper()
+	var logged bytes.Buffer
+	options.Output = &logged
+
+	e := echo.New()
+	e.Use(NewRequestLogger(options))
+	e.POST("/*", func(c echo.Context) error {
+		received, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			t.Fatalf("reading body: %v", err)
+		}
+		if string(received) != body {
+			t.Errorf("Handler received %q, want %q", received, body)
+		}
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	return logged.String()
+}
+
+func TestRequestLoggerOmitsPostText(t *testing.T) {
+	body := `{"jsonrpc": "2.0", "method": "post-submit", "params": {"text": "My private draft", "force": true}, "id": 1}`
+
+	for _, mode := range []BodyLogMode{BodyLogOff, BodyLogRedacted} {
+		t.Run(string(mode), func(t *testing.T) {
+			options := DefaultRequestLogOptions
+			options.Body = mode
+			logged := serveLogged(t, options, "/mcp/post-submit", body)
+
+			if strings.Contains(logged, "My private draft") {
+				t.Errorf("Post text was logged: %s", logged)
+			}
+			if !strings.Contains(logged, "POST /mcp/post-submit 200") {
+				t.Errorf("Expected the request line, got %q", logged)
+			}
+		})
+	}
+
+	// Redacted bodies keep their shape and non-text values
+	options := DefaultRequestLogOptions
+	options.Body = BodyLogRedacted
+	logged := serveLogged(t, options, "/mcp/post-submit", body)
+	if !strings.Contains(logged, `"method":"post-submit"`) || !strings.Contains(logged, `"text":"[redacted]"`) || !strings.Contains(logged, `"force":true`) {
+		t.Errorf("Expected a redacted body, got %q", logged)
+	}
+}
+
+func TestRequestLoggerFullBodyRedactsCredentials(t *testing.T) {
+	options := DefaultRequestLogOptions
+	options.Body = BodyLogFull
+
+	logged := serveLogged(t, options, "/mcp/post-submit",
+		`{"method": "post-submit", "params": {"text": "Hello", "password": "hunter2", "auth": {"accessJwt": "eyJ.abc.def"}}}`)
+	if !strings.Contains(logged, `"text":"Hello"`) {
+		t.Errorf("Expected the post text in a full body log, got %q", logged)
+	}
+	if strings.Contains(logged, "hunter2") || strings.Contains(logged, "eyJ.abc.def") {
+		t.Errorf("Credentials were logged: %s", logged)
+	}
+
+	// Bodies that cannot be parsed, and so not redacted, are only described
+	logged = serveLogged(t, options, "/mcp/post-submit", "identifier=me&password=hunter2")
+	if strings.Contains(logged, "hunter2") || !strings.Contains(logged, "non-JSON body") {
+		t.Errorf("Expected a non-JSON body description, got %q", logged)
+	}
+}
+
+func TestRequestLoggerNeverLogsAuthBodies(t *testing.T) {
+	options := DefaultRequestLogOptions
+	options.Body = BodyLogFull
+
+	for _, path := range []string{"/xrpc/com.atproto.server.createSession", "/auth/login", "/oauth/token"} {
+		logged := serveLogged(t, options, path, `{"identifier": "me.bsky.social", "note": "plain"}`)
+		if strings.Contains(logged, "body=") || strings.Contains(logged, "me.bsky.social") {
+			t.Errorf("Body logged for %s: %s", path, logged)
+		}
+	}
+}
+
+func TestRequestLoggerFields(t *testing.T) {
+	options := RequestLogOptions{Fields: []string{"method", "status", "uri"}}
+	logged := serveLogged(t, options, "/mcp/feed-analysis", `{}`)
+	if logged != "POST 200 /mcp/feed-analysis\n" {
+		t.Errorf("Log line = %q, want only the configured fields", logged)
+	}
+}
+
+func TestRequestLogOptionsFromEnv(t *testing.T) {
+	defer os.Unsetenv("BSKY_LOG_FIELDS")
+	defer os.Unsetenv("BSKY_LOG_BODIES")
+
+	os.Setenv("BSKY_LOG_FIELDS", "time, request_id,status")
+	os.Setenv("BSKY_LOG_BODIES", "redacted")
+	options, err := RequestLogOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("RequestLogOptionsFromEnv() unexpected error: %v", err)
+	}
+	if strings.Join(options.Fields, ",") != "time,request_id,status" || options.Body != BodyLogRedacted {
+		t.Errorf("RequestLogOptionsFromEnv() = %+v", options)
+	}
+
+	os.Setenv("BSKY_LOG_FIELDS", "time,body")
+	if _, err := RequestLogOptionsFromEnv(); err == nil || !strings.Contains(err.Error(), `invalid log field "body"`) {
+		t.Errorf("Expected an invalid field error, got %v", err)
+	}
+
+	os.Unsetenv("BSKY_LOG_FIELDS")
+	os.Setenv("BSKY_LOG_BODIES", "everything")
+	if _, err := RequestLogOptionsFromEnv(); err == nil {
+		t.Error("Expected an invalid body mode error")
+	}
+}
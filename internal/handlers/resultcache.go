@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+)
+
+// resultCache holds method results at the protocol boundary, separate from the
+// feed and community caches inside the services
+var resultCache = cache.NewWithOptions(cache.CacheOptions{
+	MaxItems:        1000,
+	DefaultTTL:      time.Minute,
+	CleanupInterval: 5 * time.Minute,
+})
+
+// resultCacheTTLs is how long each method's results are kept; methods without a TTL are not cached
+var (
+	resultCacheTTLsMu sync.RWMutex
+	resultCacheTTLs   = map[string]time.Duration{}
+)
+
+// SetResultCacheTTLs sets how long identical requests to each method are served
+// from the result cache. A TTL of 0 or less, or a method left out, disables
+// caching for that method; write methods are never cached.
+func SetResultCacheTTLs(ttls map[string]time.Duration) {
+	copied := make(map[string]time.Duration, len(ttls))
+	for method, ttl := range ttls {
+		if ttl > 0 {
+			copied[method] = ttl
+		}
+	}
+
+	resultCacheTTLsMu.Lock()
+	defer resultCacheTTLsMu.Unlock()
+	resultCacheTTLs = copied
+	resultCache.Clear()
+}
+
+// ParseResultCacheTTLs parses per-method TTLs written as "method=ttl" pairs
+// separated by commas, such as "feed-analysis=30s,text-analyze=5m"
+func ParseResultCacheTTLs(value string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		method, rawTTL, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid result cache entry %q: want method=ttl", entry)
+		}
+		method = strings.TrimSpace(method)
+		if !IsValidMethod(method) {
+			return nil, fmt.Errorf("invalid result cache entry %q: unknown method %s", entry, method)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(rawTTL))
+		if err != nil {
+			return nil, fmt.Errorf("invalid result cache entry %q: %w", entry, err)
+		}
+		ttls[method] = ttl
+	}
+	return ttls, nil
+}
+
+// resultCacheTTL returns how long a request's result may be cached, or 0 if it must not be
+func resultCacheTTL(method string, params map[string]interface{}) time.Duration {
+	// Writes, including post-assist with submit, always run
+	if isWriteRequest(method, params) {
+		return 0
+	}
+
+	resultCacheTTLsMu.RLock()
+	defer resultCacheTTLsMu.RUnlock()
+	return resultCacheTTLs[method]
+}
+
+// resultCacheKey hashes the method and its params; encoding/json sorts map keys,
+// so identical params give the same key whatever order they were sent in.
+// bypassCache is left out so a refreshed result replaces the cached one.
+func resultCacheKey(method string, params map[string]interface{}) (string, error) {
+	keyParams := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if name != "bypassCache" {
+			keyParams[name] = value
+		}
+	}
+	encoded, err := json.Marshal(keyParams)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(append([]byte(method+"\x00"), encoded...))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// processCachedMethod serves a cacheable request from the result cache, or runs
+// the method and caches a successful result without warnings. bypassCache skips the lookup but
+// still stores the fresh result.
+func processCachedMethod(method string, params map[string]interface{}, run func() (interface{}, error)) (interface{}, error) {
+	ttl := resultCacheTTL(method, params)
+	if ttl <= 0 {
+		return run()
+	}
+	key, err := resultCacheKey(method, params)
+	if err != nil {
+		return run()
+	}
+
+	if bypass, _ := params["bypassCache"].(bool); !bypass {
+		if cached, found := resultCache.Get(key); found {
+			return cached, nil
+		}
+	}
+
+	result, err := run()
+	if err != nil {
+		return nil, err
+	}
+	// Degraded results, such as stale feeds, are not kept beyond this request
+	if len(resultWarnings(result)) == 0 {
+		resultCache.Set(key, result, ttl)
+	}
+	return result, nil
+}
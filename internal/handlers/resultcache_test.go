@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// enableResultCache caches results for ttls and gives the test its own rate limit
+func enableResultCache(t *testing.T, ttls map[string]time.Duration) {
+	t.Helper()
+	originalLimiter := rateLimiter
+	rateLimiter = NewRateLimiter(time.Minute, 1000)
+	SetResultCacheTTLs(ttls)
+	t.Cleanup(func() {
+		rateLimiter = originalLimiter
+		SetResultCacheTTLs(nil)
+	})
+}
+
+// sendMCPRequest posts body to method and returns the response recorder
+func sendMCPRequest(t *testing.T, method, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/mcp/:method")
+	c.SetParamNames("method")
+	c.SetParamValues(method)
+
+	if err := HandleMCPRequest(c, config.Config{}); err != nil {
+		t.Fatalf("HandleMCPRequest() returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleMCPRequest() status code = %v: %s", rec.Code, rec.Body.String())
+	}
+	return rec
+}
+
+func TestResultCacheServesRepeatedRequests(t *testing.T) {
+	enableResultCache(t, map[string]time.Duration{"feed-analysis": time.Minute})
+
+	originalAnalyzeFeed := analyzeFeed
+	defer func() { analyzeFeed = originalAnalyzeFeed }()
+	calls := 0
+	analyzeFeed = func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		calls++
+		return models.FeedResponse{Count: calls, Source: "api_fresh"}, nil
+	}
+
+	first := sendMCPRequest(t, "feed-analysis", `{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"hashtag": "golang", "limit": 5}, "id": 1}`)
+	// Same params in a different order, with a different request ID
+	second := sendMCPRequest(t, "feed-analysis", `{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"limit": 5, "hashtag": "golang"}, "id": 2}`)
+
+	if calls != 1 {
+		t.Errorf("Expected the second identical request to be served from cache, got %d calls", calls)
+	}
+	if !strings.Contains(second.Body.String(), `"count":1`) || !strings.Contains(second.Body.String(), `"id":2`) {
+		t.Errorf("Cached response = %s, want the first result with the new ID", second.Body.String())
+	}
+	if first.Body.String() == second.Body.String() {
+		t.Error("Expected the responses to differ in their IDs")
+	}
+
+	// Different params and bypassCache both run the method
+	sendMCPRequest(t, "feed-analysis", `{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"hashtag": "rust", "limit": 5}, "id": 3}`)
+	sendMCPRequest(t, "feed-analysis", `{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"hashtag": "golang", "limit": 5, "bypassCache": true}, "id": 4}`)
+	if calls != 3 {
+		t.Errorf("Expected 3 calls after a new query and a bypass, got %d", calls)
+	}
+
+	// The bypassed request refreshed the cached result
+	refreshed := sendMCPRequest(t, "feed-analysis", `{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"hashtag": "golang", "limit": 5}, "id": 5}`)
+	if calls != 3 || !strings.Contains(refreshed.Body.String(), `"count":3`) {
+		t.Errorf("Expected the refreshed result from cache, got %s after %d calls", refreshed.Body.String(), calls)
+	}
+}
+
+func TestResultCacheSkipsUncachedMethodsAndWarnings(t *testing.T) {
+	enableResultCache(t, map[string]time.Duration{"feed-analysis": time.Minute, "text-analyze": 0})
+
+	originalAnalyzeFeed := analyzeFeed
+	defer func() { analyzeFeed = originalAnalyzeFeed }()
+	calls := 0
+	analyzeFeed = func(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+		calls++
+		return models.FeedResponse{Warning: "Data may be stale due to API errors", Source: "cache_stale"}, nil
+	}
+
+	body := `{"jsonrpc": "2.0", "method": "feed-analysis", "params": {"hashtag": "golang"}, "id": 1}`
+	sendMCPRequest(t, "feed-analysis", body)
+	sendMCPRequest(t, "feed-analysis", body)
+	if calls != 2 {
+		t.Errorf("Expected stale results not to be cached, got %d calls", calls)
+	}
+
+	if ttl := resultCacheTTL("text-analyze", nil); ttl != 0 {
+		t.Errorf("text-analyze TTL = %v, want 0 to disable caching", ttl)
+	}
+}
+
+func TestResultCacheNeverCachesWrites(t *testing.T) {
+	enableResultCache(t, map[string]time.Duration{"post-submit": time.Minute, "post-assist": time.Minute})
+
+	originalSubmitPost, originalPostSubmitPost := submitPost, post.SubmitPost
+	defer func() { submitPost, post.SubmitPost = originalSubmitPost, originalPostSubmitPost }()
+	submits := 0
+	submitPost = func(ctx context.Context, cfg config.Config, text string, opts post.SubmitPostOptions) (*post.PostResult, error) {
+		submits++
+		return &post.PostResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafy"}, nil
+	}
+	post.SubmitPost = func(cfg config.Config, text string) (*post.PostResult, error) {
+		submits++
+		return &post.PostResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafy"}, nil
+	}
+
+	submit := `{"jsonrpc": "2.0", "method": "post-submit", "params": {"text": "Hello"}, "id": 1}`
+	sendMCPRequest(t, "post-submit", submit)
+	sendMCPRequest(t, "post-submit", submit)
+	if submits != 2 {
+		t.Errorf("Expected every post-submit to run, got %d submissions", submits)
+	}
+
+	// post-assist is cached, but not when it submits the suggestion
+	assist := `{"jsonrpc": "2.0", "method": "post-assist", "params": {"topic": "Go", "submit": true}, "id": 2}`
+	sendMCPRequest(t, "post-assist", assist)
+	sendMCPRequest(t, "post-assist", assist)
+	if submits != 4 {
+		t.Errorf("Expected every submitting post-assist to run, got %d submissions", submits)
+	}
+}
+
+func TestParseResultCacheTTLs(t *testing.T) {
+	ttls, err := ParseResultCacheTTLs("feed-analysis=30s, text-analyze = 5m,")
+	if err != nil {
+		t.Fatalf("ParseResultCacheTTLs() unexpected error: %v", err)
+	}
+	if ttls["feed-analysis"] != 30*time.Second || ttls["text-analyze"] != 5*time.Minute {
+		t.Errorf("ParseResultCacheTTLs() = %v", ttls)
+	}
+
+	for _, value := range []string{"feed-analysis", "actor-search=1m", "feed-analysis=soon"} {
+		if _, err := ParseResultCacheTTLs(value); err == nil {
+			t.Errorf("ParseResultCacheTTLs(%q) expected an error", value)
+		}
+	}
+}
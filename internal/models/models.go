@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"net/http"
+)
+
 // Common API error response codes
 const (
 	ErrInvalidRequest      = "invalid_request"
@@ -11,6 +16,8 @@ const (
 	ErrServiceUnavailable  = "service_unavailable"
 	ErrTimeout             = "timeout"
 	ErrRateLimited         = "rate_limited"
+	ErrForbidden           = "forbidden"
+	ErrDuplicatePost       = "duplicate_post"
 )
 
 // JSONRPCRequest represents a JSON-RPC request
@@ -23,10 +30,11 @@ type JSONRPCRequest struct {
 
 // JSONRPCResponse represents a JSON-RPC response
 type JSONRPCResponse struct {
-	JSONRPC string      `json:"jsonrpc"`
-	Result  interface{} `json:"result,omitempty"`
-	Error   *ErrorInfo  `json:"error,omitempty"`
-	ID      int         `json:"id"`
+	JSONRPC  string      `json:"jsonrpc"`
+	Result   interface{} `json:"result,omitempty"`
+	Error    *ErrorInfo  `json:"error,omitempty"`
+	Warnings []string    `json:"warnings,omitempty"` // Extension field, set when the result is degraded (e.g. stale cache data)
+	ID       int         `json:"id"`
 }
 
 // ErrorInfo provides detailed error information
@@ -61,20 +69,108 @@ func NewDetailedErrorResponse(id int, code string, message string, details strin
 	}
 }
 
+// ProblemDetails is an RFC 7807 error body, sent instead of a JSON-RPC error
+// to clients that ask for plain responses
+type ProblemDetails struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"` // Extension member carrying the JSON-RPC error code
+}
+
+// NewProblemDetails creates a problem for an HTTP status, titled with its status text
+func NewProblemDetails(httpStatus int, code string, detail string) ProblemDetails {
+	return ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(httpStatus),
+		Status: httpStatus,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
 // Post represents a social media post with analysis
 type Post struct {
 	ID        string            `json:"id,omitempty"`
+	URI       string            `json:"uri,omitempty"`
+	CID       string            `json:"cid,omitempty"` // Needed to like or repost the post
 	Text      string            `json:"text"`
 	CreatedAt string            `json:"created_at,omitempty"`
 	Author    string            `json:"author,omitempty"`
+	AuthorDID string            `json:"author_did,omitempty"`
 	Metrics   map[string]int    `json:"metrics,omitempty"`
 	Analysis  map[string]string `json:"analysis,omitempty"`
+
+	AuthorProfile *AuthorProfile `json:"author_profile,omitempty"` // Only when requested with includeAuthorProfile
+}
+
+// AuthorProfile holds the public profile of a post's author
+type AuthorProfile struct {
+	DID            string `json:"did"`
+	Handle         string `json:"handle"`
+	DisplayName    string `json:"display_name,omitempty"`
+	FollowersCount int    `json:"followers_count"`
+	FollowsCount   int    `json:"follows_count"`
+	PostsCount     int    `json:"posts_count"`
 }
 
 // FeedResponse represents a standardized feed analysis response
 type FeedResponse struct {
-	Posts   []Post `json:"posts"`
-	Count   int    `json:"count"`
-	Warning string `json:"warning,omitempty"`
-	Source  string `json:"source,omitempty"` // Indicates if data is from cache, api, etc.
+	Posts   []Post          `json:"posts"`
+	Count   int             `json:"count"`
+	Warning string          `json:"warning,omitempty"`
+	Source  string          `json:"source,omitempty"` // Indicates if data is from cache, api, etc.
+	Raw     json.RawMessage `json:"raw,omitempty"`    // Upstream feed JSON, only when requested with includeRaw
+}
+
+// Analysis result versions a client can ask for. Version 1 is the original
+// shape of posts, for clients written before the analysis was enriched.
+const (
+	AnalysisVersion1      = 1
+	AnalysisVersion2      = 2
+	LatestAnalysisVersion = AnalysisVersion2
+)
+
+// analysisKeysV1 are the analysis entries of a version 1 post
+var analysisKeysV1 = []string{"sentiment"}
+
+// AsVersion returns the post with only the fields of the given analysis
+// version. Version 1 leaves out the post's URI and CID, the author's DID and
+// profile, and every analysis entry but sentiment. The post is not modified.
+func (p Post) AsVersion(version int) Post {
+	if version != AnalysisVersion1 {
+		return p
+	}
+	v1 := Post{
+		ID:        p.ID,
+		Text:      p.Text,
+		CreatedAt: p.CreatedAt,
+		Author:    p.Author,
+		Metrics:   p.Metrics,
+	}
+	for _, key := range analysisKeysV1 {
+		if value, ok := p.Analysis[key]; ok {
+			if v1.Analysis == nil {
+				v1.Analysis = make(map[string]string, len(analysisKeysV1))
+			}
+			v1.Analysis[key] = value
+		}
+	}
+	return v1
+}
+
+// AsVersion returns the response with only the fields of the given analysis
+// version, applied to each post. Version 1 also leaves out the raw feed JSON.
+func (r FeedResponse) AsVersion(version int) FeedResponse {
+	if version != AnalysisVersion1 {
+		return r
+	}
+	r.Raw = nil
+	posts := make([]Post, len(r.Posts))
+	for i, post := range r.Posts {
+		posts[i] = post.AsVersion(version)
+	}
+	r.Posts = posts
+	return r
 }
\ No newline at end of file
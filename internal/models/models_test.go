@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 )
@@ -212,4 +213,40 @@ func TestFeedResponse(t *testing.T) {
 	if response.Posts[1].ID != "post2" {
 		t.Errorf("FeedResponse.Posts[1].ID = %v, want %v", response.Posts[1].ID, "post2")
 	}
-}
\ No newline at end of file
+}
+func TestFeedResponseAsVersion(t *testing.T) {
+	response := FeedResponse{
+		Posts: []Post{{
+			ID:            "post1",
+			URI:           "at://did:plc:alice/app.bsky.feed.post/post1",
+			CID:           "bafy1",
+			Text:          "Great news",
+			Author:        "alice.bsky.social",
+			AuthorDID:     "did:plc:alice",
+			Metrics:       map[string]int{"words": 2},
+			Analysis:      map[string]string{"sentiment": "positive", "confidence": "0.80", "repost": "true"},
+			AuthorProfile: &AuthorProfile{DID: "did:plc:alice", Handle: "alice.bsky.social"},
+		}},
+		Count: 1,
+		Raw:   json.RawMessage(`{"feed":[]}`),
+	}
+
+	// Version 1 keeps the original fields only
+	v1, err := json.Marshal(response.AsVersion(AnalysisVersion1))
+	if err != nil {
+		t.Fatalf("Failed to marshal version 1: %v", err)
+	}
+	want := `{"posts":[{"id":"post1","text":"Great news","author":"alice.bsky.social","metrics":{"words":2},"analysis":{"sentiment":"positive"}}],"count":1}`
+	if string(v1) != want {
+		t.Errorf("Version 1 = %s, want %s", v1, want)
+	}
+
+	// Version 2 is the response unchanged, which version 1 left as it was
+	v2 := response.AsVersion(AnalysisVersion2)
+	if !reflect.DeepEqual(v2, response) {
+		t.Errorf("Version 2 = %+v, want %+v", v2, response)
+	}
+	if response.Posts[0].URI == "" || response.Posts[0].Analysis["confidence"] != "0.80" || response.Raw == nil {
+		t.Errorf("AsVersion() modified the response: %+v", response)
+	}
+}
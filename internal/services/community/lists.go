@@ -0,0 +1,297 @@
+package community
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// List collections
+const (
+	CollectionList     = "app.bsky.graph.list"
+	CollectionListItem = "app.bsky.graph.listitem"
+)
+
+// List purposes
+const (
+	PurposeCurate = "app.bsky.graph.defs#curatelist"
+	PurposeMod    = "app.bsky.graph.defs#modlist"
+)
+
+// listPurposes maps the accepted purpose names to their lexicon values
+var listPurposes = map[string]string{
+	"curate":      PurposeCurate,
+	"mod":         PurposeMod,
+	PurposeCurate: PurposeCurate,
+	PurposeMod:    PurposeMod,
+}
+
+// List field limits
+const (
+	maxListNameLength        = 64
+	maxListDescriptionLength = 300
+)
+
+// Record operations, can be replaced for testing
+var (
+	createRecord = repo.CreateRecordContext
+	deleteRecord = repo.DeleteRecordContext
+	listRecords  = repo.ListRecords
+)
+
+// getListPage fetches a page of a list and its members, can be replaced for testing
+var getListPage = func(cfg config.Config, query url.Values) ([]byte, error) {
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("authentication error")
+	}
+
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+
+	return client.Get("app.bsky.graph.getList", query)
+}
+
+// ListView describes a list and a page of its members
+type ListView struct {
+	URI         string     `json:"uri"`
+	CID         string     `json:"cid"`
+	Name        string     `json:"name"`
+	Purpose     string     `json:"purpose"`
+	Description string     `json:"description,omitempty"`
+	Items       []ListItem `json:"items"`
+	Cursor      string     `json:"cursor,omitempty"`
+}
+
+// ListItem is a member of a list
+type ListItem struct {
+	URI    string `json:"uri"`
+	DID    string `json:"did"`
+	Handle string `json:"handle"`
+}
+
+// CreateList creates a user list. Purpose is "curate" or "mod" (or the full lexicon value).
+func CreateList(cfg config.Config, name, purpose, description string) (*repo.CreateRecordResult, error) {
+	return createList(context.Background(), cfg, name, purpose, description)
+}
+
+// createList creates a user list, giving up on the write when ctx is done
+func createList(ctx context.Context, cfg config.Config, name, purpose, description string) (*repo.CreateRecordResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxListNameLength {
+		return nil, fmt.Errorf("invalid list name: must be 1 to %d characters", maxListNameLength)
+	}
+	if utf8.RuneCountInString(description) > maxListDescriptionLength {
+		return nil, fmt.Errorf("invalid list description: must be at most %d characters", maxListDescriptionLength)
+	}
+
+	purposeValue, ok := listPurposes[purpose]
+	if !ok {
+		return nil, fmt.Errorf("invalid list purpose %q: must be curate or mod", purpose)
+	}
+
+	record := map[string]interface{}{
+		"$type":     CollectionList,
+		"name":      name,
+		"purpose":   purposeValue,
+		"createdAt": time.Now().UTC().Format(time.RFC3339),
+	}
+	if description != "" {
+		record["description"] = description
+	}
+
+	return createPacedRecord(ctx, cfg, CollectionList, record)
+}
+
+// AddToList adds an account to a list
+func AddToList(cfg config.Config, listURI, actorDID string) (*repo.CreateRecordResult, error) {
+	return addToList(context.Background(), cfg, listURI, actorDID)
+}
+
+// addToList adds an account to a list, giving up on the write when ctx is done
+func addToList(ctx context.Context, cfg config.Config, listURI, actorDID string) (*repo.CreateRecordResult, error) {
+	if err := validateListMember(listURI, actorDID); err != nil {
+		return nil, err
+	}
+
+	record := map[string]interface{}{
+		"$type":     CollectionListItem,
+		"subject":   actorDID,
+		"list":      listURI,
+		"createdAt": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	return createPacedRecord(ctx, cfg, CollectionListItem, record)
+}
+
+// createPacedRecord creates a record once the shared write pacer allows it, so
+// list changes count toward the same write budget as posts
+func createPacedRecord(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+	if err := post.WaitForWrite(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create record: %w", err)
+	}
+	return createRecord(ctx, cfg, collection, record)
+}
+
+// RemoveFromList removes an account from a list by deleting its listitem record
+func RemoveFromList(cfg config.Config, listURI, actorDID string) error {
+	return removeFromList(context.Background(), cfg, listURI, actorDID)
+}
+
+// removeFromList removes an account from a list, giving up on the write when ctx is done
+func removeFromList(ctx context.Context, cfg config.Config, listURI, actorDID string) error {
+	if err := validateListMember(listURI, actorDID); err != nil {
+		return err
+	}
+
+	// Find the listitem record for this member, paging through the collection
+	cursor := ""
+	for {
+		page, err := listRecords(cfg, CollectionListItem, repo.MaxListLimit, cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range page.Records {
+			if record.Value["list"] == listURI && record.Value["subject"] == actorDID {
+				if err := post.WaitForWrite(ctx); err != nil {
+					return fmt.Errorf("failed to delete record: %w", err)
+				}
+				return deleteRecord(ctx, cfg, CollectionListItem, repo.RecordKey(record.URI))
+			}
+		}
+
+		if page.Cursor == "" {
+			return fmt.Errorf("list member not found: %s is not in %s", actorDID, listURI)
+		}
+		cursor = page.Cursor
+	}
+}
+
+// GetList returns a list and a page of its members; pass the returned cursor to fetch more
+func GetList(cfg config.Config, listURI string, limit int, cursor string) (*ListView, error) {
+	if err := validateListURI(listURI); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > repo.MaxListLimit {
+		limit = repo.DefaultListLimit
+	}
+
+	query := url.Values{}
+	query.Set("list", listURI)
+	query.Set("limit", fmt.Sprintf("%d", limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	responseBody, err := getListPage(cfg, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	var response struct {
+		Cursor string `json:"cursor"`
+		List   struct {
+			URI         string `json:"uri"`
+			CID         string `json:"cid"`
+			Name        string `json:"name"`
+			Purpose     string `json:"purpose"`
+			Description string `json:"description"`
+		} `json:"list"`
+		Items []struct {
+			URI     string `json:"uri"`
+			Subject struct {
+				DID    string `json:"did"`
+				Handle string `json:"handle"`
+			} `json:"subject"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return nil, fmt.Errorf("error parsing list response: %w", err)
+	}
+
+	view := &ListView{
+		URI:         response.List.URI,
+		CID:         response.List.CID,
+		Name:        response.List.Name,
+		Purpose:     response.List.Purpose,
+		Description: response.List.Description,
+		Items:       make([]ListItem, 0, len(response.Items)),
+		Cursor:      response.Cursor,
+	}
+	for _, item := range response.Items {
+		view.Items = append(view.Items, ListItem{
+			URI:    item.URI,
+			DID:    item.Subject.DID,
+			Handle: item.Subject.Handle,
+		})
+	}
+
+	return view, nil
+}
+
+// ManageList performs a list action requested through the community-list MCP method
+func ManageList(cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	return ManageListContext(context.Background(), cfg, params)
+}
+
+// ManageListContext is ManageList, giving up on a list change when ctx is done
+func ManageListContext(ctx context.Context, cfg config.Config, params map[string]interface{}) (interface{}, error) {
+	action, _ := params["action"].(string)
+	listURI, _ := params["list"].(string)
+	actor, _ := params["actor"].(string)
+
+	switch action {
+	case "create":
+		name, _ := params["name"].(string)
+		purpose, _ := params["purpose"].(string)
+		description, _ := params["description"].(string)
+		return createList(ctx, cfg, name, purpose, description)
+
+	case "add":
+		return addToList(ctx, cfg, listURI, actor)
+
+	case "remove":
+		if err := removeFromList(ctx, cfg, listURI, actor); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"removed": true, "list": listURI, "actor": actor}, nil
+
+	case "get":
+		limit, _ := params["limit"].(float64)
+		cursor, _ := params["cursor"].(string)
+		return GetList(cfg, listURI, int(limit), cursor)
+
+	default:
+		return nil, fmt.Errorf("invalid parameter: action must be one of create, add, remove, get")
+	}
+}
+
+// validateListMember checks the list URI and member DID
+func validateListMember(listURI, actorDID string) error {
+	if err := validateListURI(listURI); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(actorDID, "did:") {
+		return fmt.Errorf("invalid actor: %q is not a DID", actorDID)
+	}
+	return nil
+}
+
+// validateListURI checks that uri refers to a list record
+func validateListURI(uri string) error {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if !strings.HasPrefix(uri, "at://") || len(parts) != 3 || parts[1] != CollectionList || parts[2] == "" {
+		return fmt.Errorf("invalid list URI: %q (expected at://<did>/%s/<rkey>)", uri, CollectionList)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package community
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/post"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+const testListURI = "at://did:plc:me/app.bsky.graph.list/3klist"
+
+// recordedCreate captures a record passed to createRecord
+type recordedCreate struct {
+	collection string
+	record     map[string]interface{}
+}
+
+// disableWritePacing lets a test write without waiting for the shared write pacer
+func disableWritePacing(t *testing.T) {
+	post.SetWriteRate(0, post.DefaultWriteBurst)
+	t.Cleanup(func() { post.SetWriteRate(post.DefaultWriteRate, post.DefaultWriteBurst) })
+}
+
+// stubCreateRecord captures created records for the duration of a test
+func stubCreateRecord(t *testing.T) *[]recordedCreate {
+	disableWritePacing(t)
+	original := createRecord
+	t.Cleanup(func() { createRecord = original })
+
+	var created []recordedCreate
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		created = append(created, recordedCreate{collection: collection, record: record})
+		return &repo.CreateRecordResult{
+			URI: fmt.Sprintf("at://did:plc:me/%s/%d", collection, len(created)),
+			CID: "bafyrei",
+		}, nil
+	}
+	return &created
+}
+
+func TestCreateList(t *testing.T) {
+	created := stubCreateRecord(t)
+
+	result, err := CreateList(config.Config{}, "Go developers", "curate", "People writing Go")
+	if err != nil {
+		t.Fatalf("CreateList() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result.URI, "at://did:plc:me/app.bsky.graph.list/") {
+		t.Errorf("CreateList() URI = %q", result.URI)
+	}
+
+	if len(*created) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(*created))
+	}
+	got := (*created)[0]
+	if got.collection != CollectionList {
+		t.Errorf("Collection = %q, want %q", got.collection, CollectionList)
+	}
+	if got.record["$type"] != CollectionList || got.record["name"] != "Go developers" ||
+		got.record["purpose"] != PurposeCurate || got.record["description"] != "People writing Go" {
+		t.Errorf("Unexpected list record: %v", got.record)
+	}
+	if _, ok := got.record["createdAt"].(string); !ok {
+		t.Error("List record should have createdAt")
+	}
+}
+
+func TestCreateListValidation(t *testing.T) {
+	created := stubCreateRecord(t)
+
+	tests := []struct {
+		name    string
+		list    string
+		purpose string
+		wantErr string
+	}{
+		{name: "Unknown purpose", list: "Friends", purpose: "reference", wantErr: "purpose"},
+		{name: "Empty name", list: "  ", purpose: "mod", wantErr: "name"},
+		{name: "Long name", list: strings.Repeat("x", 65), purpose: "mod", wantErr: "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateList(config.Config{}, tt.list, tt.purpose, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CreateList() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The full lexicon value is accepted too
+	if _, err := CreateList(config.Config{}, "Blocked", PurposeMod, ""); err != nil {
+		t.Errorf("CreateList() with %s unexpected error: %v", PurposeMod, err)
+	}
+	if len(*created) != 1 {
+		t.Errorf("Only the valid list should be created, got %d records", len(*created))
+	}
+}
+
+func TestAddToList(t *testing.T) {
+	created := stubCreateRecord(t)
+
+	if _, err := AddToList(config.Config{}, testListURI, "did:plc:alice"); err != nil {
+		t.Fatalf("AddToList() unexpected error: %v", err)
+	}
+
+	got := (*created)[0]
+	if got.collection != CollectionListItem {
+		t.Errorf("Collection = %q, want %q", got.collection, CollectionListItem)
+	}
+	if got.record["$type"] != CollectionListItem || got.record["subject"] != "did:plc:alice" || got.record["list"] != testListURI {
+		t.Errorf("Unexpected listitem record: %v", got.record)
+	}
+
+	// Members must be DIDs and lists must be list records
+	if _, err := AddToList(config.Config{}, testListURI, "alice.bsky.social"); err == nil {
+		t.Error("Expected error for non-DID member")
+	}
+	if _, err := AddToList(config.Config{}, "at://did:plc:me/app.bsky.feed.post/1", "did:plc:alice"); err == nil {
+		t.Error("Expected error for non-list URI")
+	}
+}
+
+func TestRemoveFromList(t *testing.T) {
+	disableWritePacing(t)
+	originalList, originalDelete := listRecords, deleteRecord
+	defer func() { listRecords, deleteRecord = originalList, originalDelete }()
+
+	pages := map[string]*repo.ListRecordsResult{
+		"": {
+			Records: []repo.Record{
+				{URI: "at://did:plc:me/app.bsky.graph.listitem/a", Value: map[string]interface{}{"list": testListURI, "subject": "did:plc:bob"}},
+			},
+			Cursor: "next",
+		},
+		"next": {
+			Records: []repo.Record{
+				{URI: "at://did:plc:me/app.bsky.graph.listitem/b", Value: map[string]interface{}{"list": testListURI, "subject": "did:plc:alice"}},
+			},
+		},
+	}
+	listRecords = func(cfg config.Config, collection string, limit int, cursor string) (*repo.ListRecordsResult, error) {
+		return pages[cursor], nil
+	}
+
+	var deleted []string
+	deleteRecord = func(ctx context.Context, cfg config.Config, collection, rkey string) error {
+		deleted = append(deleted, collection+"/"+rkey)
+		return nil
+	}
+
+	if err := RemoveFromList(config.Config{}, testListURI, "did:plc:alice"); err != nil {
+		t.Fatalf("RemoveFromList() unexpected error: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != CollectionListItem+"/b" {
+		t.Errorf("Deleted = %v, want the listitem on the second page", deleted)
+	}
+
+	if err := RemoveFromList(config.Config{}, testListURI, "did:plc:carol"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("RemoveFromList() for non-member error = %v, want not found", err)
+	}
+}
+
+func TestManageListWaitsForWritePacer(t *testing.T) {
+	created := stubCreateRecord(t)
+
+	// A slow pacer with its only token already spent
+	post.SetWriteRate(0.001, 1)
+	if err := post.WaitForWrite(context.Background()); err != nil {
+		t.Fatalf("WaitForWrite() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := ManageListContext(ctx, config.Config{}, map[string]interface{}{
+		"action": "add",
+		"list":   testListURI,
+		"actor":  "did:plc:alice",
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ManageListContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if len(*created) != 0 {
+		t.Errorf("Created %d records, want none once the request gave up", len(*created))
+	}
+}
+
+func TestGetList(t *testing.T) {
+	original := getListPage
+	defer func() { getListPage = original }()
+
+	var gotQuery url.Values
+	getListPage = func(cfg config.Config, query url.Values) ([]byte, error) {
+		gotQuery = query
+		return []byte(`{
+			"cursor": "more",
+			"list": {"uri": "` + testListURI + `", "cid": "bafyrei", "name": "Go developers", "purpose": "app.bsky.graph.defs#curatelist"},
+			"items": [{"uri": "at://did:plc:me/app.bsky.graph.listitem/b", "subject": {"did": "did:plc:alice", "handle": "alice.bsky.social"}}]
+		}`), nil
+	}
+
+	view, err := GetList(config.Config{}, testListURI, 10, "")
+	if err != nil {
+		t.Fatalf("GetList() unexpected error: %v", err)
+	}
+	if gotQuery.Get("list") != testListURI || gotQuery.Get("limit") != "10" {
+		t.Errorf("Unexpected query: %v", gotQuery)
+	}
+	if view.Name != "Go developers" || view.Purpose != PurposeCurate || view.Cursor != "more" {
+		t.Errorf("Unexpected list view: %+v", view)
+	}
+	if len(view.Items) != 1 || view.Items[0].Handle != "alice.bsky.social" {
+		t.Errorf("Unexpected list items: %+v", view.Items)
+	}
+}
+
+func TestManageListInvalidAction(t *testing.T) {
+	_, err := ManageList(config.Config{}, map[string]interface{}{"action": "rename"})
+	if err == nil || !strings.Contains(err.Error(), "invalid parameter") {
+		t.Errorf("ManageList() error = %v, want invalid parameter", err)
+	}
+}
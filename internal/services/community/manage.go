@@ -1,50 +1,391 @@
 package community
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/littleironwaltz/bluesky-mcp/internal/anonymize"
 	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
 	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
-// Cache for user feed results
+// DefaultCacheOptions are the user feed cache's options before the configured
+// config.CacheSettings are applied; results are kept in memory for 3 minutes
+var DefaultCacheOptions = cache.CacheOptions{
+	MaxItems:         cache.DefaultCacheOptions.MaxItems,
+	DefaultTTL:       3 * time.Minute,
+	CleanupInterval:  cache.DefaultCacheOptions.CleanupInterval,
+	AllowStaleOnFail: cache.DefaultCacheOptions.AllowStaleOnFail,
+	StaleTimeout:     cache.DefaultCacheOptions.StaleTimeout,
+	PersistOptions: cache.PersistOptions{
+		Directory:     "./cache/community",
+		Filename:      "community_cache.json",
+		SaveInterval:  10 * time.Minute,
+		LoadOnStartup: true,
+		DirMode:       cache.DefaultDirMode,
+	},
+}
+
+// Cache for user feed results, built from DefaultCacheOptions until SetCacheOptions is called at startup
+var (
+	userFeedCacheMu sync.RWMutex
+	userFeedCache   = cache.NewWithOptions(DefaultCacheOptions)
+)
+
+// SetCacheOptions replaces the user feed cache with one built from
+// DefaultCacheOptions and settings. The current cache is stopped first.
+func SetCacheOptions(settings config.CacheSettings) {
+	userFeedCacheMu.Lock()
+	defer userFeedCacheMu.Unlock()
+	userFeedCache.Stop()
+	userFeedCache = cache.NewWithOptions(DefaultCacheOptions.WithSettings(settings))
+}
+
+// StopCache saves the user feed cache, if persisted, and stops its timers
+func StopCache() {
+	getUserFeedCache().Stop()
+}
+
+// getUserFeedCache returns the current user feed cache
+func getUserFeedCache() *cache.Cache {
+	userFeedCacheMu.RLock()
+	defer userFeedCacheMu.RUnlock()
+	return userFeedCache
+}
+
+// DefaultRequestTimeout bounds a single community API request; it is shorter
+// than the handler's method timeout so the service reports the timeout itself
+const DefaultRequestTimeout = 8 * time.Second
+
+// requestTimeout is the current per-request timeout
+var (
+	requestTimeoutMu sync.RWMutex
+	requestTimeout   = DefaultRequestTimeout
+)
+
+// SetRequestTimeout changes how long community API requests may take; zero or less restores the default
+func SetRequestTimeout(timeout time.Duration) {
+	requestTimeoutMu.Lock()
+	defer requestTimeoutMu.Unlock()
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	requestTimeout = timeout
+}
+
+// getRequestTimeout returns the current per-request timeout
+func getRequestTimeout() time.Duration {
+	requestTimeoutMu.RLock()
+	defer requestTimeoutMu.RUnlock()
+	return requestTimeout
+}
+
+// getAuthorFeed fetches a user's feed, can be replaced for testing
+var getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+	// Get the shared authentication token manager's client
+	client := auth.GetTokenManager(cfg).GetClient()
+
+	// Make sure the client has the auth token set
+	client.SetAuthToken(token)
+
+	return client.GetContext(ctx, "app.bsky.feed.getAuthorFeed", query)
+}
+
+// getAuthorFeedWithTimeout fetches a user's feed, cancelling the request when ctx is done
+func getAuthorFeedWithTimeout(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+	data, err := getAuthorFeed(ctx, cfg, token, query)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("timeout fetching author feed: %w", ctx.Err())
+	}
+	return data, err
+}
+
+// AccountUnavailableError reports that an account's feed cannot be read
+// because the account is suspended or deactivated. It matches
+// apiclient.ErrAccountUnavailable.
+type AccountUnavailableError struct {
+	Actor  string // Handle or DID of the account
+	Status string // "suspended", "deactivated", or "suspended or deactivated" when unknown
+}
+
+func (e *AccountUnavailableError) Error() string {
+	return fmt.Sprintf("account %s is %s: %v", e.Actor, e.Status, apiclient.ErrAccountUnavailable)
+}
+
+// Is makes the error match apiclient.ErrAccountUnavailable
+func (e *AccountUnavailableError) Is(target error) bool {
+	return target == apiclient.ErrAccountUnavailable
+}
+
+// accountUnavailableError reports that actor's feed cannot be read, taking the
+// account's status from the API's refusal
+func accountUnavailableError(actor string, err error) error {
+	status := "suspended or deactivated"
+	var apiErr *apiclient.APIError
+	if errors.As(err, &apiErr) && apiErr.AccountStatus() != "" {
+		status = apiErr.AccountStatus()
+	}
+	return &AccountUnavailableError{Actor: actor, Status: status}
+}
+
+// UserErrors maps each user of a multi-user read that failed to its error. It
+// is encoded in JSON as the error messages.
+type UserErrors map[string]error
+
+// MarshalJSON encodes the errors as their messages
+func (e UserErrors) MarshalJSON() ([]byte, error) {
+	messages := make(map[string]string, len(e))
+	for user, err := range e {
+		messages[user] = err.Error()
+	}
+	return json.Marshal(messages)
+}
+
+// MaxUserHandles is the most users that can be read in one call
+const MaxUserHandles = 25
+
+// FanOutOptions control reading several users in one call. Each user gets its
+// own timeout so a slow or unreachable account is reported as timed out while
+// the others are returned; the whole call is still bounded by the request timeout.
+type FanOutOptions struct {
+	Concurrency int           `json:"concurrency"`  // Author feeds fetched at once
+	UserTimeout time.Duration `json:"user_timeout"` // How long one user's feed may take
+}
+
+// DefaultFanOutOptions leave room within DefaultRequestTimeout for a second round of users
+var DefaultFanOutOptions = FanOutOptions{
+	Concurrency: 4,
+	UserTimeout: 4 * time.Second,
+}
+
+// FanOutOptionsFromEnv returns the default options adjusted by the
+// BSKY_COMMUNITY_CONCURRENCY and BSKY_COMMUNITY_USER_TIMEOUT (e.g. "3s") environment variables
+func FanOutOptionsFromEnv() FanOutOptions {
+	options := DefaultFanOutOptions
+	if concurrency, err := strconv.Atoi(os.Getenv("BSKY_COMMUNITY_CONCURRENCY")); err == nil && concurrency > 0 {
+		options.Concurrency = concurrency
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("BSKY_COMMUNITY_USER_TIMEOUT")); err == nil && timeout > 0 {
+		options.UserTimeout = timeout
+	}
+	return options
+}
+
+// Shared fan-out settings
 var (
-	userFeedCache = cache.New()
+	fanOut   = DefaultFanOutOptions
+	fanOutMu sync.RWMutex
 )
 
+// SetFanOutOptions configures multi-user reads; zero or negative fields use the defaults
+func SetFanOutOptions(options FanOutOptions) {
+	if options.Concurrency <= 0 {
+		options.Concurrency = DefaultFanOutOptions.Concurrency
+	}
+	if options.UserTimeout <= 0 {
+		options.UserTimeout = DefaultFanOutOptions.UserTimeout
+	}
+
+	fanOutMu.Lock()
+	defer fanOutMu.Unlock()
+	fanOut = options
+}
+
+// getFanOutOptions returns the current fan-out settings
+func getFanOutOptions() FanOutOptions {
+	fanOutMu.RLock()
+	defer fanOutMu.RUnlock()
+	return fanOut
+}
+
+// ManageCommunity returns recent posts from userHandle, or from each of userHandles.
+// With anonymize set, the handles in the result are replaced by pseudonyms.
 func ManageCommunity(cfg config.Config, params map[string]interface{}) (interface{}, error) {
-	// Proper type assertions with validation
-	userHandle, ok := params["userHandle"].(string)
-	if !ok || userHandle == "" {
-		return nil, fmt.Errorf("missing or invalid user handle")
+	result, err := manageCommunity(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	if anonymized, _ := params["anonymize"].(bool); anonymized {
+		result = anonymizeResult(result)
 	}
+	return result, nil
+}
 
+// manageCommunity reads the posts ManageCommunity returns
+func manageCommunity(cfg config.Config, params map[string]interface{}) (interface{}, error) {
 	limit, ok := params["limit"].(float64)
 	if !ok || limit <= 0 || limit > 50 {
 		// Default with reasonable upper bound
 		limit = 5
 	}
 
+	bypassCache, _ := params["bypassCache"].(bool)
+
+	// Only return posts newer than since when polling; the previous result's latest can be passed back
+	var since time.Time
+	if sinceParam, ok := params["since"].(string); ok && sinceParam != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter: since must be an RFC 3339 timestamp")
+		}
+		since = parsed
+	}
+
+	if rawHandles, ok := params["userHandles"]; ok {
+		if _, single := params["userHandle"]; single {
+			return nil, fmt.Errorf("invalid parameter: use either userHandle or userHandles")
+		}
+		userHandles, err := userHandlesParam(rawHandles)
+		if err != nil {
+			return nil, err
+		}
+		return fetchUsersPosts(cfg, userHandles, limit, since, bypassCache)
+	}
+
+	// Proper type assertions with validation
+	userHandle, ok := params["userHandle"].(string)
+	if !ok || userHandle == "" {
+		return nil, fmt.Errorf("missing or invalid user handle")
+	}
+
+	// Make API request with a timeout of its own
+	ctx, cancel := context.WithTimeout(context.Background(), getRequestTimeout())
+	defer cancel()
+
+	return fetchUserPosts(ctx, cfg, userHandle, limit, since, bypassCache)
+}
+
+// userHandlesParam extracts the list of handles, dropping repeats
+func userHandlesParam(raw interface{}) ([]string, error) {
+	var values []string
+	switch v := raw.(type) {
+	case []string:
+		values = v
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid parameter: userHandles must be an array of strings")
+			}
+			values = append(values, s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid parameter: userHandles must be an array of strings")
+	}
+
+	seen := make(map[string]bool, len(values))
+	handles := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		handles = append(handles, value)
+	}
+
+	if len(handles) == 0 {
+		return nil, fmt.Errorf("missing or invalid user handle")
+	}
+	if len(handles) > MaxUserHandles {
+		return nil, fmt.Errorf("invalid parameter: at most %d userHandles are allowed", MaxUserHandles)
+	}
+	return handles, nil
+}
+
+// fetchUsersPosts reads several users' recent posts with at most the configured
+// number at once, giving each user its own timeout within the request timeout.
+// Results and errors are keyed by handle so one failing or slow user does not
+// fail the others; an error is returned only when every user fails.
+func fetchUsersPosts(cfg config.Config, userHandles []string, limit float64, since time.Time, bypassCache bool) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), getRequestTimeout())
+	defer cancel()
+
+	options := getFanOutOptions()
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, options.Concurrency)
+		results = make(map[string]interface{}, len(userHandles))
+		errs    = make(UserErrors)
+	)
+
+	for _, userHandle := range userHandles {
+		wg.Add(1)
+		go func(userHandle string) {
+			defer wg.Done()
+
+			var result interface{}
+			var err error
+			select {
+			case sem <- struct{}{}:
+				userCtx, userCancel := context.WithTimeout(ctx, options.UserTimeout)
+				result, err = fetchUserPosts(userCtx, cfg, userHandle, limit, since, bypassCache)
+				userCancel()
+				<-sem
+			case <-ctx.Done():
+				err = fmt.Errorf("timeout fetching author feed: %w", ctx.Err())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[userHandle] = err
+				return
+			}
+			results[userHandle] = result
+		}(userHandle)
+	}
+	wg.Wait()
+
+	// Report the first failure in request order when nothing succeeded
+	if len(results) == 0 {
+		return nil, errs[userHandles[0]]
+	}
+
+	result := map[string]interface{}{
+		"users": results,
+		"count": len(results),
+	}
+	if len(errs) > 0 {
+		result["errors"] = errs
+	}
+	return result, nil
+}
+
+// fetchUserPosts validates userHandle and returns its recent posts, giving up when ctx is done
+func fetchUserPosts(ctx context.Context, cfg config.Config, userHandle string, limit float64, since time.Time, bypassCache bool) (interface{}, error) {
 	// Validate and sanitize userHandle to prevent injection
 	userHandle = strings.TrimSpace(userHandle)
+	if err := cfg.Limits.CheckLength(config.FieldHandle, userHandle); err != nil {
+		return nil, err
+	}
 	if !strings.HasPrefix(userHandle, "did:") && !strings.Contains(userHandle, ".") {
 		return nil, fmt.Errorf("invalid user handle format")
 	}
 
+	polling := !since.IsZero()
 
 	// Generate cache key based on params
 	cacheKey := generateCacheKey(userHandle, limit)
 
-	// Check cache first
-	if cachedResult, found := userFeedCache.Get(cacheKey); found {
-		return cachedResult, nil
+	// Check cache first unless a fresh fetch was requested; polling always fetches
+	if !bypassCache && !polling {
+		if cachedResult, found := getUserFeedCache().Get(cacheKey); found {
+			return cachedResult, nil
+		}
 	}
 
 	// Get auth token from Bluesky API
@@ -53,20 +394,19 @@ func ManageCommunity(cfg config.Config, params map[string]interface{}) (interfac
 		return nil, fmt.Errorf("authentication error")
 	}
 
-	// Get the shared authentication token manager's client
-	client := auth.GetTokenManager(cfg).GetClient()
-	
-	// Make sure the client has the auth token set
-	client.SetAuthToken(token)
-
 	// Prepare parameters
 	query := url.Values{}
 	query.Set("actor", userHandle)
 	query.Set("limit", fmt.Sprintf("%d", int(limit)))
 
-	// Make API request
-	responseBody, err := client.Get("app.bsky.feed.getAuthorFeed", query)
+	responseBody, err := getAuthorFeedWithTimeout(ctx, cfg, token, query)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if errors.Is(err, apiclient.ErrAccountUnavailable) {
+			return nil, accountUnavailableError(userHandle, err)
+		}
 		return nil, fmt.Errorf("API request error")
 	}
 
@@ -87,11 +427,20 @@ func ManageCommunity(cfg config.Config, params map[string]interface{}) (interfac
 
 	// Pre-allocate slice with capacity equal to limit for better performance
 	recentPosts := make([]string, 0, int(limit))
-	weekAgo := time.Now().Add(-7 * 24 * time.Hour)
+	cutoff := time.Now().Add(-7 * 24 * time.Hour)
+	if since.After(cutoff) {
+		cutoff = since
+	}
 
+	// latest marks the newest post returned, or stays at since when nothing is new
+	latest := since
 	for _, item := range feed.Feed {
-		if item.Post.Record.CreatedAt.After(weekAgo) {
+		createdAt := item.Post.Record.CreatedAt
+		if createdAt.After(cutoff) {
 			recentPosts = append(recentPosts, item.Post.Record.Text)
+			if createdAt.After(latest) {
+				latest = createdAt
+			}
 		}
 		if len(recentPosts) >= int(limit) {
 			break
@@ -104,13 +453,57 @@ func ManageCommunity(cfg config.Config, params map[string]interface{}) (interfac
 		"recentPosts": recentPosts,
 		"count":       len(recentPosts),
 	}
+	if !latest.IsZero() {
+		result["latest"] = latest.UTC().Format(time.RFC3339Nano)
+	}
 
-	// Cache the result for 3 minutes
-	userFeedCache.Set(cacheKey, result, 3*time.Minute)
+	// Cache the result for the configured TTL; polling results depend on since and are not cached
+	if !polling {
+		getUserFeedCache().Set(cacheKey, result, 0)
+	}
 
 	return result, nil
 }
 
+// anonymizeResult returns a copy of a single- or multi-user result with each
+// handle replaced by its pseudonym, including the handles named in per-user
+// errors. Results are anonymized after caching, so the cached ones keep the handles.
+func anonymizeResult(result interface{}) interface{} {
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	anonymized := make(map[string]interface{}, len(resultMap))
+	for k, v := range resultMap {
+		anonymized[k] = v
+	}
+	if user, ok := resultMap["user"].(string); ok {
+		anonymized["user"] = anonymize.Pseudonym(user)
+	}
+	if users, ok := resultMap["users"].(map[string]interface{}); ok {
+		anonymizedUsers := make(map[string]interface{}, len(users))
+		for userHandle, userResult := range users {
+			anonymizedUsers[anonymize.Pseudonym(userHandle)] = anonymizeResult(userResult)
+		}
+		anonymized["users"] = anonymizedUsers
+	}
+	if errs, ok := resultMap["errors"].(UserErrors); ok {
+		anonymizedErrors := make(UserErrors, len(errs))
+		for userHandle, err := range errs {
+			pseudonym := anonymize.Pseudonym(userHandle)
+			var unavailable *AccountUnavailableError
+			if errors.As(err, &unavailable) {
+				anonymizedErrors[pseudonym] = &AccountUnavailableError{Actor: pseudonym, Status: unavailable.Status}
+				continue
+			}
+			anonymizedErrors[pseudonym] = errors.New(anonymize.Text(err.Error(), userHandle))
+		}
+		anonymized["errors"] = anonymizedErrors
+	}
+	return anonymized
+}
+
 // generateCacheKey creates a unique key for caching based on parameters
 func generateCacheKey(userHandle string, limit float64) string {
 	hash := sha256.New()
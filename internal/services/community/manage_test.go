@@ -1,8 +1,19 @@
 package community
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/littleironwaltz/bluesky-mcp/internal/anonymize"
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
@@ -168,4 +179,526 @@ func TestManageCommunity(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestManageCommunityBypassCache(t *testing.T) {
+	cacheKey := generateCacheKey("cached.bsky.social", 5)
+	defer userFeedCache.Delete(cacheKey)
+	cached := map[string]interface{}{
+		"user":        "cached.bsky.social",
+		"recentPosts": []string{"cached post"},
+		"count":       1,
+	}
+	userFeedCache.Set(cacheKey, cached, time.Minute)
+
+	// Without bypass the cached result is returned
+	result, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandle": "cached.bsky.social",
+		"limit":      float64(5),
+	})
+	if err != nil {
+		t.Fatalf("ManageCommunity() unexpected error: %v", err)
+	}
+	if resultMap, ok := result.(map[string]interface{}); !ok || resultMap["count"] != 1 {
+		t.Errorf("ManageCommunity() = %v, want cached result", result)
+	}
+
+	// With bypass the cache is skipped and a fetch is attempted, which fails
+	// here because no credentials are configured
+	_, err = ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandle":  "cached.bsky.social",
+		"limit":       float64(5),
+		"bypassCache": true,
+	})
+	if err == nil || err.Error() != "authentication error" {
+		t.Errorf("ManageCommunity() error = %v, want 'authentication error'", err)
+	}
+}
+
+func TestManageCommunityTimeout(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+		SetRequestTimeout(DefaultRequestTimeout)
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		<-ctx.Done()
+		return nil, fmt.Errorf("request failed: %w", ctx.Err())
+	}
+	SetRequestTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandle":  "slow.bsky.social",
+		"limit":       float64(5),
+		"bypassCache": true,
+	})
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("ManageCommunity() error = %v, want timeout error", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("ManageCommunity() took %v, want it to give up after the request timeout", elapsed)
+	}
+}
+
+func TestManageCommunityAccountUnavailable(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		switch query.Get("actor") {
+		case "gone.bsky.social":
+			return nil, &apiclient.APIError{StatusCode: 400, Response: map[string]interface{}{
+				"error": "AccountTakedown", "message": "Account has been suspended"}}
+		case "away.bsky.social":
+			return nil, &apiclient.APIError{StatusCode: 400, Response: map[string]interface{}{
+				"error": "AccountDeactivated", "message": "Account is deactivated"}}
+		}
+		return nil, &apiclient.APIError{StatusCode: 502}
+	}
+
+	tests := []struct {
+		userHandle string
+		want       string
+	}{
+		{userHandle: "gone.bsky.social", want: "account gone.bsky.social is suspended: account unavailable"},
+		{userHandle: "away.bsky.social", want: "account away.bsky.social is deactivated: account unavailable"},
+	}
+	for _, tt := range tests {
+		_, err := ManageCommunity(config.Config{}, map[string]interface{}{
+			"userHandle":  tt.userHandle,
+			"bypassCache": true,
+		})
+		if !errors.Is(err, apiclient.ErrAccountUnavailable) || err.Error() != tt.want {
+			t.Errorf("ManageCommunity(%s) error = %v, want %q", tt.userHandle, err, tt.want)
+		}
+	}
+
+	// Other API failures stay generic
+	_, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandle":  "down.bsky.social",
+		"bypassCache": true,
+	})
+	if err == nil || errors.Is(err, apiclient.ErrAccountUnavailable) || err.Error() != "API request error" {
+		t.Errorf("ManageCommunity() error = %v, want API request error", err)
+	}
+}
+
+func TestManageCommunitySince(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	older := now.Add(-2 * time.Hour)
+	newer := now.Add(-1 * time.Hour)
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		return []byte(fmt.Sprintf(`{"feed":[
+			{"post":{"record":{"text":"newer post","createdAt":%q}}},
+			{"post":{"record":{"text":"older post","createdAt":%q}}}
+		]}`, newer.Format(time.RFC3339), older.Format(time.RFC3339))), nil
+	}
+
+	poll := func(since string) map[string]interface{} {
+		t.Helper()
+		params := map[string]interface{}{
+			"userHandle": "poll.bsky.social",
+			"limit":      float64(10),
+		}
+		if since != "" {
+			params["since"] = since
+		}
+		result, err := ManageCommunity(config.Config{}, params)
+		if err != nil {
+			t.Fatalf("ManageCommunity() unexpected error: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+	defer userFeedCache.Delete(generateCacheKey("poll.bsky.social", 10))
+
+	// The first call returns everything and the newest timestamp
+	first := poll("")
+	if first["count"] != 2 {
+		t.Fatalf("First poll count = %v, want 2", first["count"])
+	}
+	latest, _ := first["latest"].(string)
+	if latest != newer.Format(time.RFC3339Nano) {
+		t.Fatalf("First poll latest = %q, want %q", latest, newer.Format(time.RFC3339Nano))
+	}
+
+	// Passing the older post's timestamp returns only the newer post
+	second := poll(older.Format(time.RFC3339))
+	posts := second["recentPosts"].([]string)
+	if len(posts) != 1 || posts[0] != "newer post" {
+		t.Errorf("Poll since older post = %v, want only the newer post", posts)
+	}
+	if second["latest"] != latest {
+		t.Errorf("Poll since older post latest = %v, want %q", second["latest"], latest)
+	}
+
+	// Passing the previous latest returns nothing new and an unchanged marker
+	third := poll(latest)
+	if third["count"] != 0 {
+		t.Errorf("Poll since latest count = %v, want 0", third["count"])
+	}
+	if third["latest"] != latest {
+		t.Errorf("Poll since latest marker = %v, want unchanged %q", third["latest"], latest)
+	}
+}
+
+func TestManageCommunityInvalidSince(t *testing.T) {
+	_, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandle": "user.bsky.social",
+		"since":      "yesterday",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid parameter") {
+		t.Errorf("ManageCommunity() error = %v, want invalid parameter error", err)
+	}
+}
+
+func TestManageCommunityMultipleUsers(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+
+	createdAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	var mu sync.Mutex
+	var actors []string
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		actor := query.Get("actor")
+		mu.Lock()
+		actors = append(actors, actor)
+		mu.Unlock()
+		if actor == "down.bsky.social" {
+			return nil, fmt.Errorf("API error: status 502")
+		}
+		return []byte(fmt.Sprintf(`{"feed":[{"post":{"record":{"text":"hello from %s","createdAt":%q}}}]}`, actor, createdAt)), nil
+	}
+
+	result, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandles": []interface{}{"alice.bsky.social", "not a handle", "down.bsky.social", " bob.bsky.social", "alice.bsky.social"},
+		"limit":       float64(5),
+		"bypassCache": true,
+	})
+	if err != nil {
+		t.Fatalf("ManageCommunity() unexpected error: %v", err)
+	}
+	resultMap := result.(map[string]interface{})
+
+	users := resultMap["users"].(map[string]interface{})
+	if len(users) != 2 || resultMap["count"] != 2 {
+		t.Fatalf("Expected results for 2 users, got %v", users)
+	}
+	for _, handle := range []string{"alice.bsky.social", "bob.bsky.social"} {
+		user, ok := users[handle].(map[string]interface{})
+		if !ok {
+			t.Errorf("Missing result for %s", handle)
+			continue
+		}
+		posts := user["recentPosts"].([]string)
+		if len(posts) != 1 || posts[0] != "hello from "+handle {
+			t.Errorf("Posts for %s = %v", handle, posts)
+		}
+	}
+
+	errs := resultMap["errors"].(UserErrors)
+	if err := errs["not a handle"]; err == nil || err.Error() != "invalid user handle format" {
+		t.Errorf("Error for invalid handle = %v, want invalid user handle format", err)
+	}
+	if err := errs["down.bsky.social"]; err == nil || err.Error() != "API request error" {
+		t.Errorf("Error for failing user = %v, want API request error", err)
+	}
+
+	// The repeated handle is fetched once and the invalid one never reaches the API
+	if len(actors) != 3 {
+		t.Errorf("Expected 3 feed requests, got %v", actors)
+	}
+}
+
+func TestManageCommunityAnonymize(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+	createdAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		if query.Get("actor") == "gone.bsky.social" {
+			return nil, &apiclient.APIError{StatusCode: 400, Response: map[string]interface{}{"error": "AccountTakedown"}}
+		}
+		return []byte(fmt.Sprintf(`{"feed":[{"post":{"record":{"text":"hello","createdAt":%q}}}]}`, createdAt)), nil
+	}
+
+	read := func(params map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		params["anonymize"] = true
+		result, err := ManageCommunity(config.Config{}, params)
+		if err != nil {
+			t.Fatalf("ManageCommunity() unexpected error: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	// The same user gets the same pseudonym in single- and multi-user results
+	single := read(map[string]interface{}{"userHandle": "alice.bsky.social"})
+	alice := anonymize.Pseudonym("alice.bsky.social")
+	if single["user"] != alice {
+		t.Errorf("user = %v, want %s", single["user"], alice)
+	}
+
+	multi := read(map[string]interface{}{
+		"userHandles": []interface{}{"alice.bsky.social", "bob.bsky.social", "gone.bsky.social"},
+	})
+	users := multi["users"].(map[string]interface{})
+	bob := anonymize.Pseudonym("bob.bsky.social")
+	if len(users) != 2 || users[alice] == nil || users[bob] == nil || alice == bob {
+		t.Fatalf("users = %v, want distinct pseudonyms %s and %s", users, alice, bob)
+	}
+	if user := users[alice].(map[string]interface{})["user"]; user != alice {
+		t.Errorf("users[%s].user = %v", alice, user)
+	}
+
+	// Errors neither key on nor mention the handle
+	gone := anonymize.Pseudonym("gone.bsky.social")
+	errs := multi["errors"].(UserErrors)
+	var unavailable *AccountUnavailableError
+	if !errors.As(errs[gone], &unavailable) || unavailable.Actor != gone || unavailable.Status != "suspended" {
+		t.Errorf("errors = %v, want the suspended account under %s", errs, gone)
+	}
+
+	// The cached result keeps the handle for requests without anonymize
+	result, err := ManageCommunity(config.Config{}, map[string]interface{}{"userHandle": "alice.bsky.social"})
+	if err != nil {
+		t.Fatalf("ManageCommunity() unexpected error: %v", err)
+	}
+	if user := result.(map[string]interface{})["user"]; user != "alice.bsky.social" {
+		t.Errorf("user without anonymize = %v, want alice.bsky.social", user)
+	}
+}
+
+func TestManageCommunityMultipleUsersSlowUser(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+		SetFanOutOptions(DefaultFanOutOptions)
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+	createdAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		actor := query.Get("actor")
+		if actor == "hung.bsky.social" {
+			<-ctx.Done()
+			return nil, fmt.Errorf("request failed: %w", ctx.Err())
+		}
+		return []byte(fmt.Sprintf(`{"feed":[{"post":{"record":{"text":"hello from %s","createdAt":%q}}}]}`, actor, createdAt)), nil
+	}
+	SetFanOutOptions(FanOutOptions{Concurrency: 2, UserTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	result, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandles": []string{"hung.bsky.social", "alice.bsky.social", "bob.bsky.social", "carol.bsky.social"},
+		"bypassCache": true,
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("ManageCommunity() unexpected error: %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("ManageCommunity() took %v, want the hung user to time out on its own", elapsed)
+	}
+
+	resultMap := result.(map[string]interface{})
+	users := resultMap["users"].(map[string]interface{})
+	for _, handle := range []string{"alice.bsky.social", "bob.bsky.social", "carol.bsky.social"} {
+		if _, ok := users[handle]; !ok {
+			t.Errorf("Missing result for %s", handle)
+		}
+	}
+	errs := resultMap["errors"].(UserErrors)
+	if len(errs) != 1 || errs["hung.bsky.social"] == nil || !strings.Contains(errs["hung.bsky.social"].Error(), "timeout") {
+		t.Errorf("Errors = %v, want only a timeout for the hung user", errs)
+	}
+}
+
+func TestManageCommunityMultipleUsersConcurrency(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+		SetFanOutOptions(DefaultFanOutOptions)
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return []byte(`{"feed":[]}`), nil
+	}
+	SetFanOutOptions(FanOutOptions{Concurrency: 2})
+
+	handles := make([]string, 6)
+	for i := range handles {
+		handles[i] = fmt.Sprintf("user%d.bsky.social", i)
+	}
+	if _, err := ManageCommunity(config.Config{}, map[string]interface{}{"userHandles": handles, "bypassCache": true}); err != nil {
+		t.Fatalf("ManageCommunity() unexpected error: %v", err)
+	}
+	if maxActive > 2 {
+		t.Errorf("Up to %d feeds were fetched at once, want at most 2", maxActive)
+	}
+}
+
+func TestFanOutOptionsFromEnv(t *testing.T) {
+	t.Setenv("BSKY_COMMUNITY_CONCURRENCY", "8")
+	t.Setenv("BSKY_COMMUNITY_USER_TIMEOUT", "not-a-duration")
+
+	options := FanOutOptionsFromEnv()
+	if options.Concurrency != 8 {
+		t.Errorf("Concurrency = %d, want 8", options.Concurrency)
+	}
+	if options.UserTimeout != DefaultFanOutOptions.UserTimeout {
+		t.Errorf("UserTimeout = %v, want the default for an invalid value", options.UserTimeout)
+	}
+}
+
+func TestManageCommunityMultipleUsersAllFail(t *testing.T) {
+	_, err := ManageCommunity(config.Config{}, map[string]interface{}{
+		"userHandles": []string{"bad handle", "also bad"},
+	})
+	if err == nil || err.Error() != "invalid user handle format" {
+		t.Errorf("ManageCommunity() error = %v, want invalid user handle format", err)
+	}
+}
+
+func TestManageCommunityHandleLengthLimit(t *testing.T) {
+	cfg := config.Config{Limits: config.Limits{HandleLength: 20}}
+
+	_, err := ManageCommunity(cfg, map[string]interface{}{
+		"userHandle": "a-very-long-handle.bsky.social",
+	})
+	var lengthErr *config.LengthError
+	if !errors.As(err, &lengthErr) || lengthErr.Field != "userHandle" || lengthErr.Limit != 20 {
+		t.Errorf("ManageCommunity() error = %v, want a userHandle length error with limit 20", err)
+	}
+}
+
+func TestManageCommunityUserHandlesParam(t *testing.T) {
+	tooMany := make([]string, MaxUserHandles+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("user%d.bsky.social", i)
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{name: "Empty list", params: map[string]interface{}{"userHandles": []interface{}{}}, errMsg: "missing or invalid user handle"},
+		{name: "Not a list", params: map[string]interface{}{"userHandles": "alice.bsky.social"}, errMsg: "invalid parameter"},
+		{name: "Non-string entry", params: map[string]interface{}{"userHandles": []interface{}{"alice.bsky.social", 1}}, errMsg: "invalid parameter"},
+		{name: "Both forms", params: map[string]interface{}{"userHandle": "a.bsky.social", "userHandles": []string{"b.bsky.social"}}, errMsg: "either userHandle or userHandles"},
+		{name: "Too many handles", params: map[string]interface{}{"userHandles": tooMany}, errMsg: "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ManageCommunity(config.Config{}, tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ManageCommunity() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestSetCacheOptions(t *testing.T) {
+	t.Cleanup(func() { SetCacheOptions(config.CacheSettings{}) })
+
+	dir := t.TempDir()
+	SetCacheOptions(config.CacheSettings{MaxItems: 20, TTL: config.Duration(time.Minute), Directory: dir})
+	options := getUserFeedCache().Options()
+	if options.MaxItems != 20 || options.DefaultTTL != time.Minute {
+		t.Errorf("Options = %+v, want the configured values", options)
+	}
+	if !options.PersistOptions.Enabled || options.PersistOptions.Directory != dir {
+		t.Errorf("PersistOptions = %+v, want persistence to %s", options.PersistOptions, dir)
+	}
+
+	// Results are cached for the configured TTL
+	SetCacheOptions(config.CacheSettings{TTL: config.Duration(20 * time.Millisecond)})
+	getUserFeedCache().Set("key", "value", 0)
+	if _, found := getUserFeedCache().Get("key"); !found {
+		t.Fatal("Expected the result to be cached")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, found := getUserFeedCache().Get("key"); found {
+		t.Error("Expected the result to expire after the configured TTL")
+	}
+}
+
+func TestUserErrorsMarshalJSON(t *testing.T) {
+	errs := UserErrors{
+		"gone.bsky.social": &AccountUnavailableError{Actor: "gone.bsky.social", Status: "suspended"},
+		"down.bsky.social": errors.New("API request error"),
+	}
+	data, err := json.Marshal(errs)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	want := `{"down.bsky.social":"API request error","gone.bsky.social":"account gone.bsky.social is suspended: account unavailable"}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,172 @@
+package community
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// MaxStatsPosts is the most recent posts MyPostStats reads, the author feed's page size
+const MaxStatsPosts = 100
+
+// PostStat is one of the user's posts with its engagement counts
+type PostStat struct {
+	URI        string    `json:"uri"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Likes      int       `json:"likes"`
+	Reposts    int       `json:"reposts"`
+	Replies    int       `json:"replies"`
+	Engagement int       `json:"engagement"` // Likes, reposts and replies together
+}
+
+// EngagementTotals sums the engagement counts of several posts
+type EngagementTotals struct {
+	Likes      int `json:"likes"`
+	Reposts    int `json:"reposts"`
+	Replies    int `json:"replies"`
+	Engagement int `json:"engagement"`
+}
+
+// EngagementAverages are the engagement counts per post
+type EngagementAverages struct {
+	Likes      float64 `json:"likes"`
+	Reposts    float64 `json:"reposts"`
+	Replies    float64 `json:"replies"`
+	Engagement float64 `json:"engagement"`
+}
+
+// PostStats summarizes the engagement of the user's recent posts
+type PostStats struct {
+	Posts    []PostStat         `json:"posts"` // Highest engagement first
+	Count    int                `json:"count"`
+	Totals   EngagementTotals   `json:"totals"`
+	Averages EngagementAverages `json:"averages"`
+}
+
+// sessionDID returns the authenticated user's DID, can be replaced for testing
+var sessionDID = func(cfg config.Config) string {
+	return auth.GetTokenManager(cfg).GetDID()
+}
+
+// MyPostStats reads the authenticated user's most recent limit posts and
+// returns their like, repost and reply counts, highest engagement first, with
+// totals and averages. Replies and reposts are left out; posts with equal
+// engagement stay newest first.
+func MyPostStats(cfg config.Config, limit int) (*PostStats, error) {
+	if limit <= 0 || limit > MaxStatsPosts {
+		return nil, fmt.Errorf("invalid parameter: limit must be between 1 and %d", MaxStatsPosts)
+	}
+
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("authentication error")
+	}
+	did := sessionDID(cfg)
+	if did == "" {
+		return nil, fmt.Errorf("authentication error")
+	}
+
+	query := url.Values{}
+	query.Set("actor", did)
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("filter", "posts_no_replies")
+
+	ctx, cancel := context.WithTimeout(context.Background(), getRequestTimeout())
+	defer cancel()
+
+	responseBody, err := getAuthorFeedWithTimeout(ctx, cfg, token, query)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if errors.Is(err, apiclient.ErrAccountUnavailable) {
+			return nil, accountUnavailableError(did, err)
+		}
+		return nil, fmt.Errorf("API request error")
+	}
+
+	posts, err := parsePostStats(responseBody, did)
+	if err != nil {
+		return nil, err
+	}
+	return summarizePostStats(posts), nil
+}
+
+// parsePostStats extracts the engagement counts of the posts did wrote from
+// an author feed response, skipping reposts of other users' posts
+func parsePostStats(data []byte, did string) ([]PostStat, error) {
+	var feed struct {
+		Feed []struct {
+			Post struct {
+				URI    string `json:"uri"`
+				Author struct {
+					DID string `json:"did"`
+				} `json:"author"`
+				Record struct {
+					Text      string    `json:"text"`
+					CreatedAt time.Time `json:"createdAt"`
+				} `json:"record"`
+				LikeCount   int `json:"likeCount"`
+				RepostCount int `json:"repostCount"`
+				ReplyCount  int `json:"replyCount"`
+			} `json:"post"`
+			Reason json.RawMessage `json:"reason"`
+		} `json:"feed"`
+	}
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("response parsing error")
+	}
+
+	posts := make([]PostStat, 0, len(feed.Feed))
+	for _, item := range feed.Feed {
+		// A reason marks a repost, which is not the user's own content
+		if len(item.Reason) > 0 || item.Post.Author.DID != did {
+			continue
+		}
+		post := item.Post
+		posts = append(posts, PostStat{
+			URI:        post.URI,
+			Text:       post.Record.Text,
+			CreatedAt:  post.Record.CreatedAt,
+			Likes:      post.LikeCount,
+			Reposts:    post.RepostCount,
+			Replies:    post.ReplyCount,
+			Engagement: post.LikeCount + post.RepostCount + post.ReplyCount,
+		})
+	}
+	return posts, nil
+}
+
+// summarizePostStats sorts posts by engagement and adds their totals and averages
+func summarizePostStats(posts []PostStat) *PostStats {
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Engagement > posts[j].Engagement
+	})
+
+	stats := &PostStats{Posts: posts, Count: len(posts)}
+	for _, post := range posts {
+		stats.Totals.Likes += post.Likes
+		stats.Totals.Reposts += post.Reposts
+		stats.Totals.Replies += post.Replies
+		stats.Totals.Engagement += post.Engagement
+	}
+	if count := float64(len(posts)); count > 0 {
+		stats.Averages = EngagementAverages{
+			Likes:      float64(stats.Totals.Likes) / count,
+			Reposts:    float64(stats.Totals.Reposts) / count,
+			Replies:    float64(stats.Totals.Replies) / count,
+			Engagement: float64(stats.Totals.Engagement) / count,
+		}
+	}
+	return stats
+}
@@ -0,0 +1,101 @@
+package community
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// statsFeedFixture is an author feed with three of the user's posts and a repost
+const statsFeedFixture = `{"feed":[
+	{"post":{"uri":"at://did:plc:me/app.bsky.feed.post/3","author":{"did":"did:plc:me"},
+		"record":{"text":"quiet post","createdAt":"2026-10-14T12:00:00Z"},
+		"likeCount":1,"repostCount":0,"replyCount":0}},
+	{"post":{"uri":"at://did:plc:other/app.bsky.feed.post/9","author":{"did":"did:plc:other"},
+		"record":{"text":"someone else's hit","createdAt":"2026-10-14T11:00:00Z"},
+		"likeCount":500,"repostCount":90,"replyCount":40},
+		"reason":{"$type":"app.bsky.feed.defs#reasonRepost"}},
+	{"post":{"uri":"at://did:plc:me/app.bsky.feed.post/2","author":{"did":"did:plc:me"},
+		"record":{"text":"popular post","createdAt":"2026-10-13T12:00:00Z"},
+		"likeCount":10,"repostCount":4,"replyCount":2}},
+	{"post":{"uri":"at://did:plc:me/app.bsky.feed.post/1","author":{"did":"did:plc:me"},
+		"record":{"text":"older quiet post","createdAt":"2026-10-12T12:00:00Z"},
+		"likeCount":0,"repostCount":1,"replyCount":0}}
+]}`
+
+func TestMyPostStats(t *testing.T) {
+	originalGetToken := auth.GetToken
+	originalGetAuthorFeed := getAuthorFeed
+	originalSessionDID := sessionDID
+	defer func() {
+		auth.GetToken = originalGetToken
+		getAuthorFeed = originalGetAuthorFeed
+		sessionDID = originalSessionDID
+	}()
+
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		return "test-token", nil
+	}
+	sessionDID = func(cfg config.Config) string {
+		return "did:plc:me"
+	}
+	var gotQuery url.Values
+	getAuthorFeed = func(ctx context.Context, cfg config.Config, token string, query url.Values) ([]byte, error) {
+		gotQuery = query
+		return []byte(statsFeedFixture), nil
+	}
+
+	stats, err := MyPostStats(config.Config{}, 10)
+	if err != nil {
+		t.Fatalf("MyPostStats() unexpected error: %v", err)
+	}
+
+	if gotQuery.Get("actor") != "did:plc:me" || gotQuery.Get("filter") != "posts_no_replies" || gotQuery.Get("limit") != "10" {
+		t.Errorf("Query = %v, want the user's own posts without replies", gotQuery)
+	}
+
+	// The repost is left out, and equal engagement keeps the newest first
+	wantURIs := []string{
+		"at://did:plc:me/app.bsky.feed.post/2",
+		"at://did:plc:me/app.bsky.feed.post/3",
+		"at://did:plc:me/app.bsky.feed.post/1",
+	}
+	if stats.Count != len(wantURIs) || len(stats.Posts) != len(wantURIs) {
+		t.Fatalf("Got %d posts (count %d), want %d", len(stats.Posts), stats.Count, len(wantURIs))
+	}
+	for i, uri := range wantURIs {
+		if stats.Posts[i].URI != uri {
+			t.Errorf("Posts[%d] = %s, want %s", i, stats.Posts[i].URI, uri)
+		}
+	}
+	if top := stats.Posts[0]; top.Likes != 10 || top.Reposts != 4 || top.Replies != 2 || top.Engagement != 16 {
+		t.Errorf("Top post = %+v, want 10 likes, 4 reposts, 2 replies", top)
+	}
+
+	wantTotals := EngagementTotals{Likes: 11, Reposts: 5, Replies: 2, Engagement: 18}
+	if stats.Totals != wantTotals {
+		t.Errorf("Totals = %+v, want %+v", stats.Totals, wantTotals)
+	}
+	wantAverages := EngagementAverages{Likes: 11.0 / 3, Reposts: 5.0 / 3, Replies: 2.0 / 3, Engagement: 6}
+	if stats.Averages != wantAverages {
+		t.Errorf("Averages = %+v, want %+v", stats.Averages, wantAverages)
+	}
+}
+
+func TestMyPostStatsInvalidLimit(t *testing.T) {
+	for _, limit := range []int{0, MaxStatsPosts + 1} {
+		if _, err := MyPostStats(config.Config{}, limit); err == nil {
+			t.Errorf("MyPostStats(%d) expected an error", limit)
+		}
+	}
+}
+
+func TestSummarizePostStatsEmpty(t *testing.T) {
+	stats := summarizePostStats(nil)
+	if stats.Count != 0 || stats.Averages != (EngagementAverages{}) {
+		t.Errorf("summarizePostStats(nil) = %+v, want zero counts and averages", stats)
+	}
+}
@@ -1,16 +1,22 @@
 package feed
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html"
+	"io"
+	"log"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
 	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
@@ -26,24 +32,59 @@ type BlueskyAPIClient interface {
 	SetAuthToken(token string)
 }
 
-// Cache for feed operations
+// DefaultCacheOptions are the feed cache's options before the configured
+// config.CacheSettings are applied
+var DefaultCacheOptions = cache.CacheOptions{
+	MaxItems:         2000,
+	DefaultTTL:       2 * time.Minute,
+	CleanupInterval:  5 * time.Minute,
+	AllowStaleOnFail: true,
+	StaleTimeout:     1 * time.Hour,
+	PersistOptions: cache.PersistOptions{
+		Enabled:       true,
+		Directory:     "./cache/feed",
+		Filename:      "feed_cache.json",
+		SaveInterval:  10 * time.Minute,
+		LoadOnStartup: true,
+		DirMode:       cache.DefaultDirMode,
+		MaxFileSize:   10 << 20, // Least recently used feeds are left out past 10MB
+		ValueDecoder:  cache.DecodeAs[models.FeedResponse](),
+	},
+}
+
+// Cache for feed operations, in memory only until SetCacheOptions is called at startup
 var (
-	feedCache = cache.NewWithOptions(cache.CacheOptions{
-		MaxItems:         2000,
-		DefaultTTL:       5 * time.Minute,
-		CleanupInterval:  5 * time.Minute,
-		AllowStaleOnFail: true,
-		StaleTimeout:     1 * time.Hour,
-		PersistOptions: cache.PersistOptions{
-			Enabled:       true,
-			Directory:     "./cache/feed",
-			Filename:      "feed_cache.json",
-			SaveInterval:  10 * time.Minute,
-			LoadOnStartup: true,
-		},
-	})
+	feedCacheMu sync.RWMutex
+	feedCache   = cache.NewWithOptions(DefaultCacheOptions.WithSettings(config.CacheSettings{Directory: config.CacheDirectoryOff}))
 )
 
+// SetCacheOptions replaces the feed cache with one built from DefaultCacheOptions
+// and settings, loading feeds persisted by a previous run. The current cache is
+// stopped first.
+func SetCacheOptions(settings config.CacheSettings) {
+	feedCacheMu.Lock()
+	defer feedCacheMu.Unlock()
+	feedCache.Stop()
+	feedCache = cache.NewWithOptions(DefaultCacheOptions.WithSettings(settings))
+}
+
+// StopCache saves the feed cache, if persisted, and stops its timers
+func StopCache() {
+	getFeedCache().Stop()
+}
+
+// getFeedCache returns the current feed cache
+func getFeedCache() *cache.Cache {
+	feedCacheMu.RLock()
+	defer feedCacheMu.RUnlock()
+	return feedCache
+}
+
+// CachePersistError returns the last error persisting the feed cache to disk, if any
+func CachePersistError() error {
+	return getFeedCache().PersistError()
+}
+
 // FetchError represents an error during feed fetching
 type FetchError struct {
 	Message   string
@@ -58,54 +99,215 @@ func (e FetchError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the cause, so errors.Is and errors.As see through a FetchError
+func (e FetchError) Unwrap() error {
+	return e.Cause
+}
+
+// ErrMalformedFeed matches, with errors.Is, a feed response that could not be
+// parsed. A valid response without posts is an empty feed, not an error.
+var ErrMalformedFeed = errors.New("malformed feed response")
+
+// malformedFeedError reports feed data that parseFeedItems could not read
+func malformedFeedError(err error) error {
+	return FetchError{
+		Message:   "Invalid JSON response from API",
+		Cause:     fmt.Errorf("%w: %v", ErrMalformedFeed, err),
+		Retryable: true,
+	}
+}
+
 // Note: We're now using the shared client from auth.GetTokenManager().GetClient()
 // This ensures we have a consistent authentication state across all services
 
 // AnalyzeFeed processes and analyzes a user's feed
 func AnalyzeFeed(cfg config.Config, params map[string]interface{}) (interface{}, error) {
 	// Validate and extract parameters
-	params, err := validateParams(params)
+	params, err := validateParams(params, cfg.Limits)
 	if err != nil {
 		return nil, err
 	}
 
 	hashtag := params["hashtag"].(string)
-	limit := int(params["limit"].(float64))
+	limit, ceilingWarning := analyzedLimit(params, cfg.Limits)
+	filter := paramsFilter(params)
 
-	// Generate cache key
-	cacheKey := generateCacheKey(hashtag, limit)
+	bypassCache, _ := params["bypassCache"].(bool)
 
-	// Try to get from cache with the loader function
-	result, err := feedCache.GetWithLoader(cacheKey, 2*time.Minute, func() (interface{}, error) {
-		// This function is called if the item isn't in the cache
-		return fetchAndProcessFeed(cfg, hashtag, limit)
-	})
+	includeRaw, _ := params["includeRaw"].(bool)
+	result, err := loadFeed(cfg, hashtag, limit, filter, includeRaw, bypassCache)
+	if err != nil {
+		return nil, err
+	}
+	if ceilingWarning != "" {
+		result = withWarning(result, ceilingWarning)
+	}
 
+	// Author profiles and sentence sentiment are added, and authors anonymized,
+	// after the feed is read, so cached feeds are shared between requests with
+	// and without them
+	if includeProfiles, _ := params["includeAuthorProfile"].(bool); includeProfiles {
+		result = withAuthorProfiles(cfg, result)
+	}
+	if perSentence, _ := params["sentenceSentiment"].(bool); perSentence {
+		result = withSentenceSentiment(result)
+	}
+	if anonymized, _ := params["anonymize"].(bool); anonymized {
+		result = withAnonymizedAuthors(result)
+	}
+	if feedResp, ok := result.(models.FeedResponse); ok {
+		version, _ := AnalysisVersionParam(params)
+		result = feedResp.AsVersion(version)
+	}
+	return result, nil
+}
+
+// AnalyzeFeedData analyzes a saved timeline or search response, such as one
+// captured with includeRaw, taking the same parameters as AnalyzeFeed. Nothing
+// is fetched or cached, so the same data always gives the same analysis.
+func AnalyzeFeedData(cfg config.Config, feedData []byte, params map[string]interface{}) (models.FeedResponse, error) {
+	params, err := validateParams(params, cfg.Limits)
 	if err != nil {
-		// Even with the error, we might have gotten a stale result
-		if result != nil {
-			// We have a stale result (from fallback cache)
-			// Return the stale result with a warning
-			if feedResp, ok := result.(models.FeedResponse); ok {
-				feedResp.Warning = "Data may be stale due to API errors"
-				feedResp.Source = "cache_stale"
-				return feedResp, nil
-			}
+		return models.FeedResponse{}, err
+	}
+
+	hashtag := params["hashtag"].(string)
+	limit, ceilingWarning := analyzedLimit(params, cfg.Limits)
+
+	result, err := buildFeedResponse(feedData, hashtag, limit, paramsFilter(params), false)
+	if err != nil {
+		return models.FeedResponse{}, err
+	}
+	if result.Source != "fallback" {
+		result.Source = "file"
+	}
+	if ceilingWarning != "" {
+		result = withWarning(result, ceilingWarning).(models.FeedResponse)
+	}
+	if anonymized, _ := params["anonymize"].(bool); anonymized {
+		result = withAnonymizedAuthors(result).(models.FeedResponse)
+	}
+	version, _ := AnalysisVersionParam(params)
+	return result.AsVersion(version), nil
+}
+
+// analyzedLimit returns the validated limit, lowered to the server-wide
+// ceiling on analyzed posts with a warning if it is over
+func analyzedLimit(params map[string]interface{}, limits config.Limits) (int, string) {
+	limit := int(params["limit"].(float64))
+	if maxPosts := limits.MaxAnalyzedPosts(); limit > maxPosts {
+		return maxPosts, fmt.Sprintf("Only %d posts were analyzed, the server's limit per request", maxPosts)
+	}
+	return limit, ""
+}
+
+// paramsFilter builds the item filter from validated parameters. Reposts and
+// replies are included unless explicitly excluded.
+func paramsFilter(params map[string]interface{}) itemFilter {
+	filter := defaultItemFilter
+	if include, ok := params["includeReposts"].(bool); ok {
+		filter.IncludeReposts = include
+	}
+	if include, ok := params["includeReplies"].(bool); ok {
+		filter.IncludeReplies = include
+	}
+	filter.Contains, _ = params["contains"].(string)
+	if minWords, ok := params["minWords"].(float64); ok {
+		filter.MinWords = int(minWords)
+	}
+	if analyzers, ok := params["analyzers"]; ok {
+		filter.Skipped, _ = skippedAnalyzers(analyzers)
+	}
+	return filter
+}
+
+// loadFeed returns the analyzed feed from the cache, fetching it when missing
+// or when bypassCache is set
+func loadFeed(cfg config.Config, hashtag string, limit int, filter itemFilter, includeRaw, bypassCache bool) (interface{}, error) {
+	// Raw upstream JSON is only attached on request, and such responses are
+	// always fetched fresh and never cached so the payload matches the analysis
+	if includeRaw {
+		result, err := fetchFeedResponse(cfg, hashtag, limit, filter, true)
+		if err != nil {
+			return nil, fmt.Errorf("feed analysis failed: %w", err)
 		}
+		recordSource(fetchedSource(result))
+		emitPosts(result)
+		return result, nil
+	}
+
+	// Generate cache key
+	cacheKey := generateCacheKey(hashtag, limit, filter)
+
+	// This function is called if the item isn't in the cache. It records whether
+	// it ran and how it failed, since a stale value stands in for a failed load
+	// without an error.
+	loaded := false
+	var loadErr error
+	loader := func() (interface{}, error) {
+		loaded = true
+		result, err := fetchFeedResponse(cfg, hashtag, limit, filter, false)
+		loadErr = err
+		if err == nil {
+			emitPosts(result)
+		}
+		return result, err
+	}
+
+	// Try to get from cache with the loader function, unless a fresh fetch was requested
+	var result interface{}
+	var err error
+	if bypassCache {
+		result, err = getFeedCache().Refresh(cacheKey, 0, loader)
+	} else {
+		result, err = getFeedCache().GetWithLoader(cacheKey, 0, loader)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("feed analysis failed: %w", err)
 	}
 
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok {
+		return result, nil
+	}
+
+	switch {
+	case loadErr != nil:
+		// The fetch failed and the fallback cache served the last good result
+		feedResp.Warning = "Data may be stale due to API errors"
+		feedResp.Source = "cache_stale"
+		recordSource(SourceStale)
+	case loaded:
+		recordSource(fetchedSource(feedResp))
+	default:
+		recordSource(SourceCached)
+	}
+
 	// Add source if missing
-	if feedResp, ok := result.(models.FeedResponse); ok && feedResp.Source == "" {
+	if feedResp.Source == "" {
 		feedResp.Source = "api_fresh"
-		return feedResp, nil
 	}
+	return feedResp, nil
+}
 
-	return result, nil
+// withWarning adds warning to a feed response, after any warning it already has
+func withWarning(result interface{}, warning string) interface{} {
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok {
+		return result
+	}
+	if feedResp.Warning != "" {
+		warning = feedResp.Warning + "; " + warning
+	}
+	feedResp.Warning = warning
+	return feedResp
 }
 
+// fetchFeedResponse fetches and analyzes a feed, can be replaced for testing
+var fetchFeedResponse = fetchAndProcessFeed
+
 // fetchAndProcessFeed fetches and processes the feed data
-func fetchAndProcessFeed(cfg config.Config, hashtag string, limit int) (interface{}, error) {
+func fetchAndProcessFeed(cfg config.Config, hashtag string, limit int, filter itemFilter, includeRaw bool) (interface{}, error) {
 	// Get auth token
 	token, err := auth.GetToken(cfg)
 	if err != nil {
@@ -133,8 +335,18 @@ func fetchAndProcessFeed(cfg config.Config, hashtag string, limit int) (interfac
 		return nil, err
 	}
 
+	return buildFeedResponse(feedData, hashtag, limit, filter, includeRaw)
+}
+
+// buildFeedResponse analyzes the feed data, attaching the upstream JSON when
+// includeRaw is set. Data that cannot be parsed is an error rather than an
+// empty feed.
+func buildFeedResponse(feedData []byte, hashtag string, limit int, filter itemFilter, includeRaw bool) (models.FeedResponse, error) {
 	// Process posts with parallelism for sentiment analysis
-	posts := processPostsParallel(feedData, hashtag, limit)
+	posts, err := processPostsParallel(feedData, hashtag, limit, filter)
+	if err != nil {
+		return models.FeedResponse{}, err
+	}
 
 	// Create response
 	result := models.FeedResponse{
@@ -142,13 +354,25 @@ func fetchAndProcessFeed(cfg config.Config, hashtag string, limit int) (interfac
 		Count:  len(posts),
 		Source: "api_fresh",
 	}
+	if includeRaw {
+		result.Raw = json.RawMessage(feedData)
+	}
+
+	// Data from the fallback system is marked so it is not taken for the real feed
+	var checkJSON map[string]interface{}
+	if err := json.Unmarshal(feedData, &checkJSON); err == nil && isFallbackResponse(checkJSON) {
+		result.Source = "fallback"
+	}
 
 	return result, nil
 }
 
 // validateParams validates and normalizes the request parameters
-func validateParams(params map[string]interface{}) (map[string]interface{}, error) {
-	// Set defaults for missing params
+func validateParams(params map[string]interface{}, limits config.Limits) (map[string]interface{}, error) {
+	// Set defaults for missing params, in a new map if none were given
+	if params == nil {
+		params = map[string]interface{}{}
+	}
 	if _, ok := params["hashtag"]; !ok {
 		params["hashtag"] = ""
 	}
@@ -162,10 +386,43 @@ func validateParams(params map[string]interface{}) (map[string]interface{}, erro
 	if !ok {
 		params["hashtag"] = ""
 	} else {
-		// Sanitize hashtag input
-		hashtag = strings.TrimSpace(hashtag)
-		hashtag = html.EscapeString(hashtag)
-		params["hashtag"] = hashtag
+		normalized, err := normalizeHashtag(hashtag)
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.CheckLength(config.FieldHashtag, normalized); err != nil {
+			return nil, err
+		}
+		params["hashtag"] = normalized
+	}
+
+	// Validate the keyword, matched case-insensitively against post text
+	if contains, ok := params["contains"].(string); ok {
+		contains = strings.ToLower(strings.TrimSpace(contains))
+		if utf8.RuneCountInString(contains) > MaxContainsLength {
+			return nil, fmt.Errorf("invalid parameter: contains exceeds %d characters", MaxContainsLength)
+		}
+		params["contains"] = contains
+	}
+
+	// Validate the minimum word count, counted as in the post metrics
+	if raw, ok := params["minWords"]; ok {
+		minWords, ok := raw.(float64)
+		if !ok || minWords < 0 || minWords > MaxMinWords || minWords != float64(int(minWords)) {
+			return nil, fmt.Errorf("invalid parameter: minWords must be a whole number between 0 and %d", MaxMinWords)
+		}
+	}
+
+	// Validate the analyzers to run on each post
+	if raw, ok := params["analyzers"]; ok {
+		if _, err := skippedAnalyzers(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the result format version the client asked for
+	if _, err := AnalysisVersionParam(params); err != nil {
+		return nil, err
 	}
 
 	// Validate limit
@@ -177,6 +434,44 @@ func validateParams(params map[string]interface{}) (map[string]interface{}, erro
 	return params, nil
 }
 
+// AnalysisVersionParam returns the analysis result version asked for with the
+// analysisVersion parameter, models.LatestAnalysisVersion if it is left out
+func AnalysisVersionParam(params map[string]interface{}) (int, error) {
+	raw, ok := params["analysisVersion"]
+	if !ok {
+		return models.LatestAnalysisVersion, nil
+	}
+	version, ok := raw.(float64)
+	if !ok || (version != models.AnalysisVersion1 && version != models.AnalysisVersion2) {
+		return 0, fmt.Errorf("invalid parameter: analysisVersion must be %d or %d",
+			models.AnalysisVersion1, models.AnalysisVersion2)
+	}
+	return int(version), nil
+}
+
+// normalizeHashtag trims the hashtag, strips any leading '#' and lowercases it,
+// since tag search is case-insensitive. Tags may contain letters, digits and
+// underscores; anything else, including inner whitespace, is rejected rather
+// than sent as a malformed search query. An empty tag selects the timeline.
+func normalizeHashtag(hashtag string) (string, error) {
+	hashtag = strings.TrimSpace(hashtag)
+	hashtag = strings.TrimLeft(hashtag, "#")
+	if hashtag == "" {
+		return "", nil
+	}
+
+	for _, r := range hashtag {
+		switch {
+		case unicode.IsSpace(r):
+			return "", fmt.Errorf("invalid hashtag %q: must be a single tag without spaces", hashtag)
+		case r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r):
+			return "", fmt.Errorf("invalid hashtag %q: unsupported character %q", hashtag, r)
+		}
+	}
+
+	return strings.ToLower(hashtag), nil
+}
+
 // fetchFeedWithTimeout retrieves feed data from the API with a timeout
 func fetchFeedWithTimeout(ctx context.Context, client BlueskyAPIClient, hashtag string, limit int) ([]byte, error) {
 	// Create a channel for the result
@@ -328,60 +623,171 @@ func isRetryableError(err error) bool {
 		strings.Contains(errStr, "status 504")
 }
 
+// MaxContainsLength is the longest keyword accepted by the contains parameter
+const MaxContainsLength = 100
+
+// MaxMinWords is the largest word count accepted by the minWords parameter
+const MaxMinWords = 100
+
+// itemFilter selects which kinds of feed items are analyzed
+type itemFilter struct {
+	IncludeReposts bool
+	IncludeReplies bool
+	Contains       string      // Lowercase keyword the post text must contain; empty keeps every post
+	MinWords       int         // Fewest words, as counted by countWords, a post must have; 0 keeps every post
+	Skipped        analyzerSet // Analyzers not run on each post; empty runs them all
+}
+
+// defaultItemFilter analyzes every item in the feed
+var defaultItemFilter = itemFilter{IncludeReposts: true, IncludeReplies: true}
+
+// reasonRepost is the feed item reason type for reposts
+const reasonRepost = "app.bsky.feed.defs#reasonRepost"
+
+// isRepost reports whether the item appears in the feed because someone reposted it
+func (item FeedItem) isRepost() bool {
+	return item.Reason != nil && item.Reason.Type == reasonRepost
+}
+
+// isReply reports whether the item's post is a reply to another post
+func (item FeedItem) isReply() bool {
+	return len(item.Post.Record.Reply) > 0 && string(item.Post.Record.Reply) != "null"
+}
+
+// applyItemFilter drops reposts and replies that were not requested
+func applyItemFilter(items []FeedItem, filter itemFilter) []FeedItem {
+	if filter.IncludeReposts && filter.IncludeReplies {
+		return items
+	}
+
+	result := make([]FeedItem, 0, len(items))
+	for _, item := range items {
+		if !filter.IncludeReposts && item.isRepost() {
+			continue
+		}
+		if !filter.IncludeReplies && item.isReply() {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
 // FeedResponse represents the full feed data structure
 type FeedResponse struct {
 	Feed []FeedItem `json:"feed"`
 }
 
-// processPostsParallel processes the feed posts with parallel sentiment analysis
-func processPostsParallel(feedData []byte, hashtag string, limit int) []models.Post {
-	// Try to unmarshal as timeline response first
-	var feed FeedResponse
-	if err := json.Unmarshal(feedData, &feed); err != nil || feed.Feed == nil {
-		// If that fails, try as search response format
-		var searchResp struct {
-			Posts []struct {
-				URI    string `json:"uri"`
-				Record struct {
-					Text      string `json:"text"`
-					CreatedAt string `json:"createdAt"`
-				} `json:"record"`
-				Author struct {
-					Handle string `json:"handle"`
-				} `json:"author"`
-			} `json:"posts"`
+// processPostsParallel processes the feed posts with parallel sentiment
+// analysis, returning an error wrapping ErrMalformedFeed if feedData cannot be parsed
+func processPostsParallel(feedData []byte, hashtag string, limit int, filter itemFilter) ([]models.Post, error) {
+	items, err := parseFeedItems(feedData)
+	if err != nil {
+		return nil, malformedFeedError(err)
+	}
+	return processItems(applyItemFilter(items, filter), hashtag, limit, filter.Contains, filter.MinWords, filter.Skipped), nil
+}
+
+// parseFeedItems reads the items of a timeline response, or of a search
+// response converted to feed items
+func parseFeedItems(feedData []byte) ([]FeedItem, error) {
+	items, _, err := decodeFeedPage(bytes.NewReader(feedData))
+	return items, err
+}
+
+// parseFeedPage reads the items of a timeline or search response along with
+// its cursor, which is empty on the last page
+func parseFeedPage(feedData []byte) ([]FeedItem, string, error) {
+	return decodeFeedPage(bytes.NewReader(feedData))
+}
+
+// decodeFeedPage reads a timeline or search response and its cursor in a
+// single pass. The shape is told by the top-level key, "feed" or "posts", so
+// the items are decoded once, as they are read, and other fields are skipped.
+// A timeline takes precedence if a response has both.
+func decodeFeedPage(r io.Reader) ([]FeedItem, string, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, "", err
+	}
+
+	var feed, searchItems []FeedItem
+	var cursor string
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, "", err
 		}
-		
-		if err := json.Unmarshal(feedData, &searchResp); err != nil {
-			return []models.Post{}
+		switch token {
+		case "feed":
+			err = dec.Decode(&feed)
+		case "posts":
+			// Search results are post views, the post of a feed item
+			searchItems, err = decodeSearchPosts(dec)
+		case "cursor":
+			err = dec.Decode(&cursor)
+		default:
+			var skipped json.RawMessage
+			err = dec.Decode(&skipped)
 		}
-		
-		// Convert search response to feed items
-		feedItems := make([]FeedItem, 0, len(searchResp.Posts))
-		for _, post := range searchResp.Posts {
-			item := FeedItem{}
-			item.Post.URI = post.URI
-			item.Post.Record.Text = post.Record.Text
-			item.Post.Record.CreatedAt = post.Record.CreatedAt
-			item.Post.Author.Handle = post.Author.Handle
-			feedItems = append(feedItems, item)
+		if err != nil {
+			return nil, "", err
 		}
-		
-		// Process the converted search results
-		return processItems(feedItems, hashtag, limit)
 	}
-	
-	// For timeline responses, process as before
-	return processItems(feed.Feed, hashtag, limit)
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, "", err
+	}
+
+	if feed != nil {
+		return feed, cursor, nil
+	}
+	if searchItems == nil {
+		searchItems = []FeedItem{}
+	}
+	return searchItems, cursor, nil
+}
+
+// decodeSearchPosts reads the posts array of a search response, one post at
+// a time, into feed items
+func decodeSearchPosts(dec *json.Decoder) ([]FeedItem, error) {
+	token, err := dec.Token()
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("posts must be an array, got %v", token)
+	}
+
+	var items []FeedItem
+	for dec.More() {
+		var item FeedItem
+		if err := dec.Decode(&item.Post); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token, which must be the delimiter want
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %q in JSON, got %v", want, token)
+	}
+	return nil
 }
 
 // processItems processes feed items with parallel sentiment analysis
-func processItems(items []FeedItem, hashtag string, limit int) []models.Post {
+func processItems(items []FeedItem, hashtag string, limit int, contains string, minWords int, skipped analyzerSet) []models.Post {
 	var (
 		posts    = make([]models.Post, 0, limit)
 		mu       sync.Mutex
 		wg       sync.WaitGroup
-		filtered = filterPosts(items, hashtag, limit)
+		filtered = filterPosts(items, hashtag, limit, contains, minWords)
 	)
 
 	// Process posts in parallel
@@ -389,20 +795,17 @@ func processItems(items []FeedItem, hashtag string, limit int) []models.Post {
 	for _, item := range filtered {
 		go func(item FeedItem) {
 			defer wg.Done()
+
+			// Upstream data is untrusted, so a post that makes the analysis
+			// panic is logged and left out instead of crashing the server
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Warning: Skipping post %s, analysis panicked: %v", item.Post.URI, r)
+				}
+			}()
 			
 			// Create post with analysis
-			post := models.Post{
-				ID:        getPostID(item.Post.URI),
-				Text:      item.Post.Record.Text,
-				CreatedAt: item.Post.Record.CreatedAt,
-				Author:    item.Post.Author.Handle,
-				Analysis: map[string]string{
-					"sentiment": analyzeSentiment(item.Post.Record.Text),
-				},
-			}
-			
-			// Add metrics if available
-			post.Metrics = calculateMetrics(item.Post.Record.Text)
+			post := analyzePost(item, skipped)
 			
 			// Add to results thread-safely
 			mu.Lock()
@@ -417,23 +820,79 @@ func processItems(items []FeedItem, hashtag string, limit int) []models.Post {
 	return posts
 }
 
+// analyzePost analyzes each feed item in processItems, can be replaced for testing
+var analyzePost = analyzeItem
+
+// analyzeItem converts a feed item to a post with its analysis, leaving out
+// the skipped analyzers
+func analyzeItem(item FeedItem, skipped analyzerSet) models.Post {
+	post := analyzeText(item.Post.Record.Text, skipped)
+	post.ID = getPostID(item.Post.URI)
+	post.URI = item.Post.URI
+	post.CID = item.Post.CID
+	post.CreatedAt = item.Post.Record.CreatedAt
+	post.Author = item.Post.Author.Handle
+	post.AuthorDID = item.Post.Author.DID
+	post.Analysis["repost"] = strconv.FormatBool(item.isRepost())
+	post.Analysis["reply"] = strconv.FormatBool(item.isReply())
+	if item.isRepost() && item.Reason.By.Handle != "" {
+		post.Analysis["reposted_by"] = item.Reason.By.Handle
+	}
+	return post
+}
+
 // FeedItem represents a single post item in the feed
 type FeedItem struct {
 	Post struct {
 		URI string `json:"uri"`
+		CID string `json:"cid"`
 		Record struct {
-			Text      string `json:"text"`
-			CreatedAt string `json:"createdAt"`
+			Text      string          `json:"text"`
+			CreatedAt string          `json:"createdAt"`
+			Reply     json.RawMessage `json:"reply"` // Present when the post is a reply
 		} `json:"record"`
 		Author struct {
+			DID    string `json:"did"`
 			Handle string `json:"handle"`
 		} `json:"author"`
 	} `json:"post"`
+	Reason *FeedItemReason `json:"reason,omitempty"`
+}
+
+// FeedItemReason explains why an item appears in a timeline, such as a repost
+type FeedItemReason struct {
+	Type string `json:"$type"`
+	By   struct {
+		Handle string `json:"handle"`
+	} `json:"by"`
 }
 
-// filterPosts filters posts based on criteria
-func filterPosts(feed []FeedItem, hashtag string, limit int) []FeedItem {
+// filterPosts filters posts based on criteria. A contains keyword, which must
+// already be lowercase, drops posts whose text does not include it, and minWords
+// drops posts with fewer words, before the limit is applied; both filter what
+// was fetched and never change the endpoint.
+func filterPosts(feed []FeedItem, hashtag string, limit int, contains string, minWords int) []FeedItem {
 	var result = make([]FeedItem, 0, limit)
+
+	if minWords > 0 {
+		long := make([]FeedItem, 0, len(feed))
+		for _, item := range feed {
+			if countWords(item.Post.Record.Text) >= minWords {
+				long = append(long, item)
+			}
+		}
+		feed = long
+	}
+
+	if contains != "" {
+		matching := make([]FeedItem, 0, len(feed))
+		for _, item := range feed {
+			if strings.Contains(strings.ToLower(item.Post.Record.Text), contains) {
+				matching = append(matching, item)
+			}
+		}
+		feed = matching
+	}
 	
 	// When using the search endpoint, we don't need to filter by hashtag again
 	// because the API has already filtered for us
@@ -458,16 +917,42 @@ func filterPosts(feed []FeedItem, hashtag string, limit int) []FeedItem {
 	return result
 }
 
+// MaxAnalyzeTextLength is the longest text accepted by the text-analyze method
+const MaxAnalyzeTextLength = 10000
+
+// AnalyzeText runs the post analysis on text that was not fetched from Bluesky,
+// such as a draft. Only the text, analysis and metrics of the result are set.
+func AnalyzeText(text string) models.Post {
+	return analyzeText(text, 0)
+}
+
+// analyzeText runs every analyzer but the skipped ones on text
+func analyzeText(text string, skipped analyzerSet) models.Post {
+	post := models.Post{Text: text, Analysis: map[string]string{}}
+	if !skipped.has(sentimentAnalyzer) {
+		sentiment := getSentimentLexicon().Analyze(text)
+		post.Analysis["sentiment"] = sentiment.Label
+		post.Analysis["confidence"] = strconv.FormatFloat(sentiment.Confidence, 'f', 2, 64)
+	}
+	if !skipped.has(metricsAnalyzer) {
+		post.Metrics = calculateMetrics(text)
+	}
+	return post
+}
+
 // calculateMetrics calculates additional metrics for a post
 func calculateMetrics(text string) map[string]int {
-	words := strings.Fields(text)
-	
 	return map[string]int{
 		"length": len(text),
-		"words":  len(words),
+		"words":  countWords(text),
 	}
 }
 
+// countWords counts the whitespace-separated words of text
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
 // getPostID extracts a shorter post ID from the URI
 func getPostID(uri string) string {
 	parts := strings.Split(uri, "/")
@@ -477,40 +962,29 @@ func getPostID(uri string) string {
 	return ""
 }
 
+// cacheSchemaVersion is part of every feed cache key. Bump it whenever the shape
+// of cached FeedResponse values changes so entries from older releases, including
+// persisted ones, are no longer served.
+const cacheSchemaVersion = 1
+
 // generateCacheKey creates a unique key for caching
-func generateCacheKey(hashtag string, limit int) string {
-	key := fmt.Sprintf("feed:%s:%d", hashtag, limit)
-	hash := sha256.Sum256([]byte(key))
-	return hex.EncodeToString(hash[:])
+func generateCacheKey(hashtag string, limit int, filter itemFilter) string {
+	return versionedCacheKey(cacheSchemaVersion, hashtag, limit, filter)
 }
 
-// analyzeSentiment performs basic sentiment analysis
-func analyzeSentiment(text string) string {
-	text = strings.ToLower(text)
-	
-	// Simple word-based sentiment analysis
-	positiveWords := []string{"good", "great", "happy", "excited", "love", "awesome"}
-	negativeWords := []string{"bad", "sad", "angry", "hate", "terrible", "awful"}
-	
-	var positiveCount, negativeCount int
-	
-	for _, word := range positiveWords {
-		if strings.Contains(text, word) {
-			positiveCount++
-		}
+// versionedCacheKey creates the cache key for a specific schema version
+func versionedCacheKey(version int, hashtag string, limit int, filter itemFilter) string {
+	key := fmt.Sprintf("feed:v%d:%s:%d:%t:%t", version, hashtag, limit, filter.IncludeReposts, filter.IncludeReplies)
+	if filter.Contains != "" {
+		// Appended only when set so keys without a keyword are unchanged
+		key += fmt.Sprintf(":%q", filter.Contains)
 	}
-	
-	for _, word := range negativeWords {
-		if strings.Contains(text, word) {
-			negativeCount++
-		}
+	if filter.MinWords > 0 {
+		key += fmt.Sprintf(":words>=%d", filter.MinWords)
 	}
-	
-	if positiveCount > negativeCount {
-		return "positive"
-	} else if negativeCount > positiveCount {
-		return "negative"
+	if filter.Skipped != 0 {
+		key += fmt.Sprintf(":skip=%d", filter.Skipped)
 	}
-	
-	return "neutral"
-}
\ No newline at end of file
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -2,11 +2,23 @@ package feed
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
 func TestValidateParams(t *testing.T) {
@@ -25,6 +37,15 @@ func TestValidateParams(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "No params",
+			params: nil,
+			want: map[string]interface{}{
+				"hashtag": "",
+				"limit":   float64(10),
+			},
+			wantErr: false,
+		},
 		{
 			name: "Valid params",
 			params: map[string]interface{}{
@@ -61,6 +82,56 @@ func TestValidateParams(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Leading hash stripped",
+			params: map[string]interface{}{
+				"hashtag": " #GoLang ",
+				"limit":   float64(20),
+			},
+			want: map[string]interface{}{
+				"hashtag": "golang",
+				"limit":   float64(20),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Hashtag with spaces",
+			params: map[string]interface{}{
+				"hashtag": "go lang",
+				"limit":   float64(20),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Hashtag too long",
+			params: map[string]interface{}{
+				"hashtag": strings.Repeat("a", config.DefaultLimits.HashtagLength+1),
+				"limit":   float64(20),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Analysis version 1",
+			params: map[string]interface{}{
+				"hashtag":         "test",
+				"limit":           float64(20),
+				"analysisVersion": float64(1),
+			},
+			want: map[string]interface{}{
+				"hashtag":         "test",
+				"limit":           float64(20),
+				"analysisVersion": float64(1),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unknown analysis version",
+			params: map[string]interface{}{
+				"hashtag":         "test",
+				"analysisVersion": float64(3),
+			},
+			wantErr: true,
+		},
 		{
 			name: "Invalid hashtag type",
 			params: map[string]interface{}{
@@ -77,7 +148,7 @@ func TestValidateParams(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := validateParams(tt.params)
+			got, err := validateParams(tt.params, config.Limits{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateParams() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -89,6 +160,64 @@ func TestValidateParams(t *testing.T) {
 	}
 }
 
+func TestValidateParamsHashtagLimit(t *testing.T) {
+	limits := config.Limits{HashtagLength: 5}
+
+	if _, err := validateParams(map[string]interface{}{"hashtag": "#golang"}, limits); err == nil {
+		t.Fatal("validateParams() expected an error for a hashtag over the configured limit")
+	} else {
+		var lengthErr *config.LengthError
+		if !errors.As(err, &lengthErr) || lengthErr.Field != "hashtag" || lengthErr.Limit != 5 {
+			t.Errorf("validateParams() error = %v, want a hashtag length error with limit 5", err)
+		}
+	}
+
+	// The leading '#' does not count towards the limit
+	if _, err := validateParams(map[string]interface{}{"hashtag": "#rusty"}, limits); err != nil {
+		t.Errorf("validateParams() unexpected error: %v", err)
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		name    string
+		hashtag string
+		want    string
+		wantErr string
+	}{
+		{name: "Plain tag", hashtag: "golang", want: "golang"},
+		{name: "Leading hash", hashtag: "#golang", want: "golang"},
+		{name: "Repeated hashes", hashtag: "##golang", want: "golang"},
+		{name: "Mixed case", hashtag: "GoLang", want: "golang"},
+		{name: "Underscore and digits", hashtag: "go_1_22", want: "go_1_22"},
+		{name: "Non-Latin letters", hashtag: "日本語", want: "日本語"},
+		{name: "Empty", hashtag: "", want: ""},
+		{name: "Whitespace only", hashtag: "   ", want: ""},
+		{name: "Hash only", hashtag: " # ", want: ""},
+		{name: "Inner space", hashtag: "go lang", wantErr: "without spaces"},
+		{name: "Query syntax", hashtag: "golang OR rust", wantErr: "without spaces"},
+		{name: "Special characters", hashtag: "go&lang", wantErr: "unsupported character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeHashtag(tt.hashtag)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("normalizeHashtag(%q) error = %v, want error containing %q", tt.hashtag, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeHashtag(%q) unexpected error: %v", tt.hashtag, err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeHashtag(%q) = %q, want %q", tt.hashtag, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAnalyzeSentiment(t *testing.T) {
 	tests := []struct {
 		name string
@@ -136,6 +265,26 @@ func TestAnalyzeSentiment(t *testing.T) {
 	}
 }
 
+func TestAnalyzeText(t *testing.T) {
+	post := AnalyzeText("What a great and happy day")
+
+	if post.Text != "What a great and happy day" {
+		t.Errorf("Text = %q, want the input text", post.Text)
+	}
+	if post.Analysis["sentiment"] != "positive" {
+		t.Errorf("Sentiment = %q, want positive", post.Analysis["sentiment"])
+	}
+	if post.Analysis["confidence"] == "" {
+		t.Errorf("Expected confidence to be populated")
+	}
+	if post.Metrics["length"] != 26 || post.Metrics["words"] != 6 {
+		t.Errorf("Metrics = %v, want length 26 and words 6", post.Metrics)
+	}
+	if post.ID != "" || post.URI != "" || post.Author != "" {
+		t.Errorf("Fields describing a fetched post should be empty, got %+v", post)
+	}
+}
+
 func TestCalculateMetrics(t *testing.T) {
 	tests := []struct {
 		name string
@@ -207,23 +356,28 @@ func TestFilterPosts(t *testing.T) {
 		{
 			Post: struct {
 				URI    string "json:\"uri\""
+				CID    string "json:\"cid\""
 				Record struct {
-					Text      string "json:\"text\""
-					CreatedAt string "json:\"createdAt\""
+					Text      string          "json:\"text\""
+					CreatedAt string          "json:\"createdAt\""
+					Reply     json.RawMessage "json:\"reply\""
 				} "json:\"record\""
 				Author struct {
+					DID    string "json:\"did\""
 					Handle string "json:\"handle\""
 				} "json:\"author\""
 			}{
 				URI: "at://user.bsky.social/post/1",
 				Record: struct {
-					Text      string "json:\"text\""
-					CreatedAt string "json:\"createdAt\""
+					Text      string          "json:\"text\""
+					CreatedAt string          "json:\"createdAt\""
+					Reply     json.RawMessage "json:\"reply\""
 				}{
 					Text:      "Post with #golang tag",
 					CreatedAt: "2023-01-01T00:00:00Z",
 				},
 				Author: struct {
+					DID    string "json:\"did\""
 					Handle string "json:\"handle\""
 				}{
 					Handle: "user1.bsky.social",
@@ -233,23 +387,28 @@ func TestFilterPosts(t *testing.T) {
 		{
 			Post: struct {
 				URI    string "json:\"uri\""
+				CID    string "json:\"cid\""
 				Record struct {
-					Text      string "json:\"text\""
-					CreatedAt string "json:\"createdAt\""
+					Text      string          "json:\"text\""
+					CreatedAt string          "json:\"createdAt\""
+					Reply     json.RawMessage "json:\"reply\""
 				} "json:\"record\""
 				Author struct {
+					DID    string "json:\"did\""
 					Handle string "json:\"handle\""
 				} "json:\"author\""
 			}{
 				URI: "at://user.bsky.social/post/2",
 				Record: struct {
-					Text      string "json:\"text\""
-					CreatedAt string "json:\"createdAt\""
+					Text      string          "json:\"text\""
+					CreatedAt string          "json:\"createdAt\""
+					Reply     json.RawMessage "json:\"reply\""
 				}{
 					Text:      "Post with #javascript tag",
 					CreatedAt: "2023-01-02T00:00:00Z",
 				},
 				Author: struct {
+					DID    string "json:\"did\""
 					Handle string "json:\"handle\""
 				}{
 					Handle: "user2.bsky.social",
@@ -259,23 +418,28 @@ func TestFilterPosts(t *testing.T) {
 		{
 			Post: struct {
 				URI    string "json:\"uri\""
+				CID    string "json:\"cid\""
 				Record struct {
-					Text      string "json:\"text\""
-					CreatedAt string "json:\"createdAt\""
+					Text      string          "json:\"text\""
+					CreatedAt string          "json:\"createdAt\""
+					Reply     json.RawMessage "json:\"reply\""
 				} "json:\"record\""
 				Author struct {
+					DID    string "json:\"did\""
 					Handle string "json:\"handle\""
 				} "json:\"author\""
 			}{
 				URI: "at://user.bsky.social/post/3",
 				Record: struct {
-					Text      string "json:\"text\""
-					CreatedAt string "json:\"createdAt\""
+					Text      string          "json:\"text\""
+					CreatedAt string          "json:\"createdAt\""
+					Reply     json.RawMessage "json:\"reply\""
 				}{
 					Text:      "Another post with #golang",
 					CreatedAt: "2023-01-03T00:00:00Z",
 				},
 				Author: struct {
+					DID    string "json:\"did\""
 					Handle string "json:\"handle\""
 				}{
 					Handle: "user3.bsky.social",
@@ -330,7 +494,7 @@ func TestFilterPosts(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := filterPosts(tt.feed, tt.hashtag, tt.limit)
+			got := filterPosts(tt.feed, tt.hashtag, tt.limit, "", 0)
 			if len(got) != tt.want {
 				t.Errorf("filterPosts() returned %v posts, want %v", len(got), tt.want)
 			}
@@ -349,40 +513,58 @@ func TestGenerateCacheKey(t *testing.T) {
 			name:    "With hashtag",
 			hashtag: "golang",
 			limit:   10,
-			want:    generateCacheKey("golang", 10),
+			want:    generateCacheKey("golang", 10, defaultItemFilter),
 		},
 		{
 			name:    "Without hashtag",
 			hashtag: "",
 			limit:   10,
-			want:    generateCacheKey("", 10),
+			want:    generateCacheKey("", 10, defaultItemFilter),
 		},
 		{
 			name:    "Different limits",
 			hashtag: "golang",
 			limit:   20,
-			want:    generateCacheKey("golang", 20),
+			want:    generateCacheKey("golang", 20, defaultItemFilter),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := generateCacheKey(tt.hashtag, tt.limit)
+			got := generateCacheKey(tt.hashtag, tt.limit, defaultItemFilter)
 			if got != tt.want {
 				t.Errorf("generateCacheKey() = %v, want %v", got, tt.want)
 			}
 
 			// Keys for different inputs should be different
 			if tt.name != "Without hashtag" {
-				differentKey := generateCacheKey("different", tt.limit)
+				differentKey := generateCacheKey("different", tt.limit, defaultItemFilter)
 				if got == differentKey {
 					t.Errorf("generateCacheKey() generated same key for different inputs")
 				}
 			}
+
+			// Keys for different item filters should be different
+			originalsOnly := generateCacheKey(tt.hashtag, tt.limit, itemFilter{})
+			if got == originalsOnly {
+				t.Errorf("generateCacheKey() generated same key for different item filters")
+			}
 		})
 	}
 }
 
+func TestCacheKeySchemaVersion(t *testing.T) {
+	current := generateCacheKey("golang", 10, defaultItemFilter)
+	if current != versionedCacheKey(cacheSchemaVersion, "golang", 10, defaultItemFilter) {
+		t.Errorf("generateCacheKey() should use the current schema version")
+	}
+
+	bumped := versionedCacheKey(cacheSchemaVersion+1, "golang", 10, defaultItemFilter)
+	if bumped == current {
+		t.Errorf("Bumping the schema version should produce a different key for identical inputs")
+	}
+}
+
 // Mock for testing AnalyzeFeed without real API calls
 type mockClient struct {
 	mockResponse     []byte
@@ -428,6 +610,7 @@ func TestProcessPostsParallel(t *testing.T) {
 			{
 				"post": {
 					"uri": "at://user.bsky.social/post/1",
+					"cid": "bafyreicid1",
 					"record": {
 						"text": "Post with positive sentiment happy good",
 						"createdAt": "2023-01-01T00:00:00Z"
@@ -440,6 +623,7 @@ func TestProcessPostsParallel(t *testing.T) {
 			{
 				"post": {
 					"uri": "at://user.bsky.social/post/2",
+					"cid": "bafyreicid2",
 					"record": {
 						"text": "Post with negative sentiment sad bad terrible",
 						"createdAt": "2023-01-02T00:00:00Z"
@@ -452,6 +636,7 @@ func TestProcessPostsParallel(t *testing.T) {
 			{
 				"post": {
 					"uri": "at://user.bsky.social/post/3",
+					"cid": "bafyreicid3",
 					"record": {
 						"text": "Post with neutral sentiment",
 						"createdAt": "2023-01-03T00:00:00Z"
@@ -469,6 +654,7 @@ func TestProcessPostsParallel(t *testing.T) {
 		"posts": [
 			{
 				"uri": "at://user.bsky.social/post/1",
+				"cid": "bafyreicid1",
 				"record": {
 					"text": "Post with positive sentiment happy good",
 					"createdAt": "2023-01-01T00:00:00Z"
@@ -479,6 +665,7 @@ func TestProcessPostsParallel(t *testing.T) {
 			},
 			{
 				"uri": "at://user.bsky.social/post/2",
+				"cid": "bafyreicid2",
 				"record": {
 					"text": "Post with negative sentiment sad bad terrible",
 					"createdAt": "2023-01-02T00:00:00Z"
@@ -529,7 +716,10 @@ func TestProcessPostsParallel(t *testing.T) {
 	
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results := processPostsParallel(tt.jsonData, tt.hashtag, tt.limit)
+			results, err := processPostsParallel(tt.jsonData, tt.hashtag, tt.limit, defaultItemFilter)
+			if err != nil {
+				t.Fatalf("processPostsParallel() unexpected error: %v", err)
+			}
 			
 			// Verify count
 			if len(results) != tt.wantCount {
@@ -576,6 +766,278 @@ func TestProcessPostsParallel(t *testing.T) {
 	}
 }
 
+func TestProcessPostsParallelCapturesCID(t *testing.T) {
+	timelineJSON := []byte(`{"feed": [{"post": {
+		"uri": "at://did:plc:abc/app.bsky.feed.post/1",
+		"cid": "bafyreitimeline",
+		"record": {"text": "hello", "createdAt": "2023-01-01T00:00:00Z"},
+		"author": {"handle": "user1.bsky.social"}
+	}}]}`)
+	searchJSON := []byte(`{"posts": [{
+		"uri": "at://did:plc:abc/app.bsky.feed.post/2",
+		"cid": "bafyreisearch",
+		"record": {"text": "hello", "createdAt": "2023-01-01T00:00:00Z"},
+		"author": {"handle": "user1.bsky.social"}
+	}]}`)
+
+	tests := []struct {
+		name     string
+		jsonData []byte
+		hashtag  string
+		wantURI  string
+		wantCID  string
+	}{
+		{"Timeline format", timelineJSON, "", "at://did:plc:abc/app.bsky.feed.post/1", "bafyreitimeline"},
+		{"Search format", searchJSON, "golang", "at://did:plc:abc/app.bsky.feed.post/2", "bafyreisearch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := processPostsParallel(tt.jsonData, tt.hashtag, 10, defaultItemFilter)
+			if err != nil {
+				t.Fatalf("processPostsParallel() unexpected error: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %d", len(results))
+			}
+			if results[0].URI != tt.wantURI {
+				t.Errorf("URI = %q, want %q", results[0].URI, tt.wantURI)
+			}
+			if results[0].CID != tt.wantCID {
+				t.Errorf("CID = %q, want %q", results[0].CID, tt.wantCID)
+			}
+		})
+	}
+}
+
+func TestProcessPostsParallelRepostsAndReplies(t *testing.T) {
+	timelineJSON := []byte(`{"feed": [
+		{
+			"post": {
+				"uri": "at://did:plc:abc/app.bsky.feed.post/original",
+				"record": {"text": "An original post", "createdAt": "2023-01-01T00:00:00Z"},
+				"author": {"handle": "user1.bsky.social"}
+			}
+		},
+		{
+			"post": {
+				"uri": "at://did:plc:abc/app.bsky.feed.post/reply",
+				"record": {
+					"text": "A reply",
+					"createdAt": "2023-01-02T00:00:00Z",
+					"reply": {
+						"root": {"uri": "at://did:plc:xyz/app.bsky.feed.post/root", "cid": "bafyreiroot"},
+						"parent": {"uri": "at://did:plc:xyz/app.bsky.feed.post/root", "cid": "bafyreiroot"}
+					}
+				},
+				"author": {"handle": "user2.bsky.social"}
+			}
+		},
+		{
+			"post": {
+				"uri": "at://did:plc:abc/app.bsky.feed.post/reposted",
+				"record": {"text": "A reposted post", "createdAt": "2023-01-03T00:00:00Z"},
+				"author": {"handle": "user3.bsky.social"}
+			},
+			"reason": {
+				"$type": "app.bsky.feed.defs#reasonRepost",
+				"by": {"handle": "friend.bsky.social"}
+			}
+		}
+	]}`)
+
+	tests := []struct {
+		name    string
+		filter  itemFilter
+		wantIDs []string
+	}{
+		{name: "Everything", filter: defaultItemFilter, wantIDs: []string{"original", "reply", "reposted"}},
+		{name: "Without reposts", filter: itemFilter{IncludeReplies: true}, wantIDs: []string{"original", "reply"}},
+		{name: "Without replies", filter: itemFilter{IncludeReposts: true}, wantIDs: []string{"original", "reposted"}},
+		{name: "Original posts only", filter: itemFilter{}, wantIDs: []string{"original"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := processPostsParallel(timelineJSON, "", 10, tt.filter)
+			if err != nil {
+				t.Fatalf("processPostsParallel() unexpected error: %v", err)
+			}
+
+			byID := make(map[string]models.Post, len(results))
+			for _, result := range results {
+				byID[result.ID] = result
+			}
+			if len(byID) != len(tt.wantIDs) {
+				t.Fatalf("Expected posts %v, got %d posts", tt.wantIDs, len(results))
+			}
+			for _, id := range tt.wantIDs {
+				if _, ok := byID[id]; !ok {
+					t.Errorf("Expected post %s in results", id)
+				}
+			}
+		})
+	}
+
+	results, err := processPostsParallel(timelineJSON, "", 10, defaultItemFilter)
+	if err != nil {
+		t.Fatalf("processPostsParallel() unexpected error: %v", err)
+	}
+	want := map[string]map[string]string{
+		"original": {"repost": "false", "reply": "false"},
+		"reply":    {"repost": "false", "reply": "true"},
+		"reposted": {"repost": "true", "reply": "false", "reposted_by": "friend.bsky.social"},
+	}
+	for _, result := range results {
+		for key, value := range want[result.ID] {
+			if result.Analysis[key] != value {
+				t.Errorf("Post %s analysis[%s] = %q, want %q", result.ID, key, result.Analysis[key], value)
+			}
+		}
+		if _, ok := result.Analysis["reposted_by"]; ok && result.ID != "reposted" {
+			t.Errorf("Post %s should not have reposted_by", result.ID)
+		}
+	}
+}
+
+func TestProcessPostsParallelContains(t *testing.T) {
+	timelineJSON := []byte(`{"feed": [
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/1", "record": {"text": "Learning Golang today"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/2", "record": {"text": "Coffee first"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/3", "record": {"text": "golang generics are neat"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/4", "record": {"text": "More GOLANG tips"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/5", "record": {"text": "Rust or go?"}}}
+	]}`)
+
+	tests := []struct {
+		name     string
+		contains string
+		limit    int
+		wantIDs  []string
+	}{
+		{name: "Case-insensitive match", contains: "golang", limit: 10, wantIDs: []string{"1", "3", "4"}},
+		{name: "Limit applies to matching posts", contains: "golang", limit: 2, wantIDs: []string{"1", "3"}},
+		{name: "No matches", contains: "python", limit: 10, wantIDs: nil},
+		{name: "No keyword keeps every post", contains: "", limit: 3, wantIDs: []string{"1", "2", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := defaultItemFilter
+			filter.Contains = tt.contains
+			results, err := processPostsParallel(timelineJSON, "", tt.limit, filter)
+			if err != nil {
+				t.Fatalf("processPostsParallel() unexpected error: %v", err)
+			}
+
+			got := make(map[string]bool, len(results))
+			for _, result := range results {
+				got[result.ID] = true
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Expected posts %v, got %d posts", tt.wantIDs, len(results))
+			}
+			for _, id := range tt.wantIDs {
+				if !got[id] {
+					t.Errorf("Expected post %s in results", id)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateParamsContains(t *testing.T) {
+	params, err := validateParams(map[string]interface{}{"contains": "  GoLang "}, config.Limits{})
+	if err != nil {
+		t.Fatalf("validateParams() unexpected error: %v", err)
+	}
+	if params["contains"] != "golang" {
+		t.Errorf("contains = %q, want the trimmed, lowercased keyword", params["contains"])
+	}
+
+	_, err = validateParams(map[string]interface{}{"contains": strings.Repeat("a", MaxContainsLength+1)}, config.Limits{})
+	if err == nil || !strings.Contains(err.Error(), "invalid parameter: contains") {
+		t.Errorf("Expected a contains length error, got %v", err)
+	}
+
+	// The keyword is part of the cache key, so filtered and unfiltered results are kept apart
+	filtered := defaultItemFilter
+	filtered.Contains = "golang"
+	if generateCacheKey("", 10, filtered) == generateCacheKey("", 10, defaultItemFilter) {
+		t.Error("generateCacheKey() generated the same key with and without a keyword")
+	}
+}
+
+func TestProcessPostsParallelMinWords(t *testing.T) {
+	timelineJSON := []byte(`{"feed": [
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/1", "record": {"text": "nice!"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/2", "record": {"text": "Learning Golang today with friends"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/3", "record": {"text": "lol"}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/4", "record": {"text": "  so   much  fun  "}}},
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/5", "record": {"text": "Generics make Go code shorter"}}}
+	]}`)
+
+	tests := []struct {
+		name     string
+		minWords int
+		limit    int
+		wantIDs  []string
+	}{
+		{name: "Short posts excluded", minWords: 3, limit: 10, wantIDs: []string{"2", "4", "5"}},
+		{name: "Limit applies to long posts", minWords: 4, limit: 1, wantIDs: []string{"2"}},
+		{name: "Threshold above every post", minWords: 10, limit: 10, wantIDs: nil},
+		{name: "Zero keeps every post", minWords: 0, limit: 10, wantIDs: []string{"1", "2", "3", "4", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := defaultItemFilter
+			filter.MinWords = tt.minWords
+			results, err := processPostsParallel(timelineJSON, "", tt.limit, filter)
+			if err != nil {
+				t.Fatalf("processPostsParallel() unexpected error: %v", err)
+			}
+
+			got := make(map[string]bool, len(results))
+			for _, result := range results {
+				got[result.ID] = true
+				// The filter counts words the way the metrics do
+				if result.Metrics["words"] < tt.minWords {
+					t.Errorf("Post %s has %d words, under %d", result.ID, result.Metrics["words"], tt.minWords)
+				}
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Expected posts %v, got %d posts", tt.wantIDs, len(results))
+			}
+			for _, id := range tt.wantIDs {
+				if !got[id] {
+					t.Errorf("Expected post %s in results", id)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateParamsMinWords(t *testing.T) {
+	if _, err := validateParams(map[string]interface{}{"minWords": float64(3)}, config.Limits{}); err != nil {
+		t.Fatalf("validateParams() unexpected error: %v", err)
+	}
+
+	for _, value := range []interface{}{float64(-1), float64(MaxMinWords + 1), 2.5, "3"} {
+		_, err := validateParams(map[string]interface{}{"minWords": value}, config.Limits{})
+		if err == nil || !strings.Contains(err.Error(), "invalid parameter: minWords") {
+			t.Errorf("minWords %v: expected an invalid parameter error, got %v", value, err)
+		}
+	}
+
+	// The threshold is part of the cache key
+	filtered := defaultItemFilter
+	filtered.MinWords = 3
+	if generateCacheKey("", 10, filtered) == generateCacheKey("", 10, defaultItemFilter) {
+		t.Error("generateCacheKey() generated the same key with and without minWords")
+	}
+}
+
 func TestIsFallbackResponse(t *testing.T) {
 	tests := []struct {
 		name string
@@ -776,6 +1238,49 @@ func TestFetchFeed(t *testing.T) {
 	}
 }
 
+func TestFetchFeedSearchFallbackWhenCircuitOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Open the circuit with one failed call before any fallback is registered
+	client := apiclient.NewClient(server.URL)
+	client.SetRetryConfig(apiclient.RetryConfig{
+		MaxRetries:      1,
+		InitialInterval: time.Microsecond,
+		MaxInterval:     time.Microsecond,
+		Multiplier:      1,
+	})
+	client.SetCircuitBreakerConfig(apiclient.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		ResetTimeout:     time.Minute,
+	})
+	if _, err := fetchFeed(client, "golang", 10); err == nil {
+		t.Fatal("Expected the failing search to return an error")
+	}
+	if _, err := fetchFeed(client, "golang", 10); err == nil || !errors.Is(err.(FetchError).Cause, apiclient.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen without a search fallback, got %v", err)
+	}
+
+	searchFallback, err := os.ReadFile("../../../configs/fallbacks/search.json")
+	if err != nil {
+		t.Fatalf("Failed to read the shipped search fallback: %v", err)
+	}
+	client.RegisterFallbackResponse("app.bsky.feed.searchPosts", searchFallback)
+
+	data, err := fetchFeed(client, "golang", 10)
+	if err != nil {
+		t.Fatalf("fetchFeed() with the circuit open unexpected error: %v", err)
+	}
+	if string(data) != string(searchFallback) {
+		t.Errorf("fetchFeed() = %s, want the registered search fallback", data)
+	}
+	if resp, err := buildFeedResponse(data, "golang", 10, defaultItemFilter, false); err != nil || resp.Source != "fallback" {
+		t.Errorf("Source = %q (err %v), want fallback", resp.Source, err)
+	}
+}
+
 func TestFetchFeedWithTimeout(t *testing.T) {
 	// This test just verifies the function doesn't crash since the real timeout
 	// is difficult to test reliably in unit tests without mocking everything
@@ -796,4 +1301,410 @@ func TestFetchFeedWithTimeout(t *testing.T) {
 	if err != nil {
 		t.Errorf("fetchFeedWithTimeout() unexpected error: %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestAnalyzeFeedBypassCache(t *testing.T) {
+	// Count loader invocations through the auth step it performs first
+	originalGetToken := auth.GetToken
+	loaderCalls := 0
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		loaderCalls++
+		return "", errors.New("authentication failed")
+	}
+	defer func() {
+		auth.GetToken = originalGetToken
+	}()
+
+	cacheKey := generateCacheKey("bypasstest", 10, defaultItemFilter)
+	defer feedCache.Delete(cacheKey)
+	feedCache.Set(cacheKey, models.FeedResponse{Count: 1, Source: "api_fresh"}, time.Minute)
+
+	// A normal request is served from the cache without calling the loader
+	result, err := AnalyzeFeed(config.Config{}, map[string]interface{}{
+		"hashtag": "bypasstest",
+		"limit":   float64(10),
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if resp, ok := result.(models.FeedResponse); !ok || resp.Count != 1 {
+		t.Errorf("AnalyzeFeed() = %v, want cached response", result)
+	}
+	if loaderCalls != 0 {
+		t.Errorf("Expected no loader calls for a cached request, got %d", loaderCalls)
+	}
+
+	// Bypassing the cache must call the loader even though a cached value exists
+	_, err = AnalyzeFeed(config.Config{}, map[string]interface{}{
+		"hashtag":     "bypasstest",
+		"limit":       float64(10),
+		"bypassCache": true,
+	})
+	if err == nil {
+		t.Error("AnalyzeFeed() expected loader error when bypassing cache, got nil")
+	}
+	if loaderCalls != 1 {
+		t.Errorf("Expected 1 loader call when bypassing cache, got %d", loaderCalls)
+	}
+}
+
+func TestAnalyzeFeedEmptyVersusMalformed(t *testing.T) {
+	var feedData []byte
+	original := fetchFeedResponse
+	fetchFeedResponse = func(cfg config.Config, hashtag string, limit int, filter itemFilter, includeRaw bool) (interface{}, error) {
+		return buildFeedResponse(feedData, hashtag, limit, filter, includeRaw)
+	}
+	defer func() { fetchFeedResponse = original }()
+	defer feedCache.Delete(generateCacheKey("emptytest", 10, defaultItemFilter))
+
+	tests := []struct {
+		name          string
+		data          string
+		wantMalformed bool
+	}{
+		{name: "Empty timeline", data: `{"feed":[]}`},
+		{name: "No search results", data: `{"posts":[],"hitsTotal":0}`},
+		{name: "Truncated response", data: `{"feed":[{"post":`, wantMalformed: true},
+		{name: "Not JSON", data: `<html>Bad Gateway</html>`, wantMalformed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedData = []byte(tt.data)
+			result, err := AnalyzeFeed(config.Config{}, map[string]interface{}{
+				"hashtag":     "emptytest",
+				"limit":       float64(10),
+				"bypassCache": true,
+			})
+
+			if tt.wantMalformed {
+				if !errors.Is(err, ErrMalformedFeed) {
+					t.Errorf("AnalyzeFeed() error = %v, want ErrMalformedFeed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+			}
+			if resp, ok := result.(models.FeedResponse); !ok || resp.Count != 0 || resp.Posts == nil {
+				t.Errorf("AnalyzeFeed() = %+v, want an empty feed", result)
+			}
+		})
+	}
+}
+
+func TestAnalyzeFeedData(t *testing.T) {
+	// Any fetch would fail, so a result shows nothing was fetched
+	originalGetToken := auth.GetToken
+	defer func() { auth.GetToken = originalGetToken }()
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		t.Error("AnalyzeFeedData() authenticated")
+		return "", errors.New("authentication failed")
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		params  map[string]interface{}
+		wantIDs []string
+	}{
+		{
+			name: "Timeline",
+			data: `{"cursor":"next","feed":[
+				{"post":{"uri":"at://did:plc:a/app.bsky.feed.post/1","record":{"text":"I love this great release"}}},
+				{"post":{"uri":"at://did:plc:b/app.bsky.feed.post/2","record":{"text":"Terrible and sad news"}},
+					"reason":{"$type":"app.bsky.feed.defs#reasonRepost","by":{"handle":"c.test"}}}
+			]}`,
+			params:  map[string]interface{}{"includeReposts": false},
+			wantIDs: []string{"1"},
+		},
+		{
+			name: "Search results",
+			data: `{"hitsTotal":3,"posts":[
+				{"uri":"at://did:plc:a/app.bsky.feed.post/3","record":{"text":"Learning #golang today"}},
+				{"uri":"at://did:plc:b/app.bsky.feed.post/4","record":{"text":"More #golang tips"}},
+				{"uri":"at://did:plc:c/app.bsky.feed.post/5","record":{"text":"#golang is great"}}
+			]}`,
+			params:  map[string]interface{}{"hashtag": "golang", "limit": float64(2)},
+			wantIDs: []string{"3", "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := AnalyzeFeedData(config.Config{}, []byte(tt.data), tt.params)
+			if err != nil {
+				t.Fatalf("AnalyzeFeedData() unexpected error: %v", err)
+			}
+			if resp.Source != "file" || resp.Count != len(tt.wantIDs) {
+				t.Fatalf("AnalyzeFeedData() = %+v, want %d posts from file", resp, len(tt.wantIDs))
+			}
+			// Posts are analyzed in parallel, so their order is not fixed
+			ids := make([]string, 0, len(resp.Posts))
+			for _, post := range resp.Posts {
+				if post.Analysis["sentiment"] == "" {
+					t.Errorf("Post %s has no sentiment analysis", post.ID)
+				}
+				ids = append(ids, post.ID)
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Post IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	if _, err := AnalyzeFeedData(config.Config{}, []byte(`{"feed":[`), nil); !errors.Is(err, ErrMalformedFeed) {
+		t.Errorf("AnalyzeFeedData() with truncated data error = %v, want ErrMalformedFeed", err)
+	}
+}
+
+func TestAnalyzeFeedPostCeiling(t *testing.T) {
+	cfg := config.Config{Limits: config.Limits{AnalyzedPosts: 5}}
+
+	// The limit is lowered to the ceiling, so the feed is cached under it
+	cacheKey := generateCacheKey("ceilingtest", 5, defaultItemFilter)
+	defer feedCache.Delete(cacheKey)
+	feedCache.Set(cacheKey, models.FeedResponse{Count: 5, Source: "api_fresh"}, time.Minute)
+
+	result, err := AnalyzeFeed(cfg, map[string]interface{}{
+		"hashtag": "ceilingtest",
+		"limit":   float64(50),
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	resp := result.(models.FeedResponse)
+	if resp.Count != 5 || !strings.Contains(resp.Warning, "Only 5 posts were analyzed") {
+		t.Errorf("AnalyzeFeed() = %+v, want the ceiling's feed with a warning", resp)
+	}
+}
+
+func TestBuildFeedResponseIncludeRaw(t *testing.T) {
+	feedData := []byte(`{"feed":[{"post":{"uri":"at://did:plc:abc/app.bsky.feed.post/1","cid":"bafy1","record":{"text":"Hello #golang","createdAt":"2025-01-01T00:00:00Z","langs":["en"]},"author":{"handle":"user.bsky.social"},"likeCount":3}}]}`)
+
+	withRaw, err := buildFeedResponse(feedData, "golang", 10, defaultItemFilter, true)
+	if err != nil {
+		t.Fatalf("buildFeedResponse() unexpected error: %v", err)
+	}
+	if withRaw.Count != 1 {
+		t.Fatalf("Count = %d, want 1", withRaw.Count)
+	}
+	if string(withRaw.Raw) != string(feedData) {
+		t.Errorf("Raw = %s, want the upstream payload", withRaw.Raw)
+	}
+
+	encoded, err := json.Marshal(withRaw)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+	}
+	raw, ok := decoded["raw"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected raw object in response, got %v", decoded["raw"])
+	}
+	if _, ok := raw["feed"]; !ok {
+		t.Errorf("Raw payload missing upstream fields: %v", raw)
+	}
+
+	withoutRaw, err := buildFeedResponse(feedData, "golang", 10, defaultItemFilter, false)
+	if err != nil {
+		t.Fatalf("buildFeedResponse() unexpected error: %v", err)
+	}
+	if withoutRaw.Raw != nil {
+		t.Errorf("Raw = %s, want nil when not requested", withoutRaw.Raw)
+	}
+	encoded, err = json.Marshal(withoutRaw)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	if strings.Contains(string(encoded), `"raw"`) {
+		t.Errorf("Response should omit raw when not requested: %s", encoded)
+	}
+}
+
+func TestAnalyzeFeedIncludeRawSkipsCache(t *testing.T) {
+	// Count fetches through the auth step they perform first
+	originalGetToken := auth.GetToken
+	fetches := 0
+	auth.GetToken = func(cfg config.Config) (string, error) {
+		fetches++
+		return "", errors.New("authentication failed")
+	}
+	defer func() {
+		auth.GetToken = originalGetToken
+	}()
+
+	cacheKey := generateCacheKey("rawtest", 10, defaultItemFilter)
+	defer feedCache.Delete(cacheKey)
+	feedCache.Set(cacheKey, models.FeedResponse{Count: 1, Source: "api_fresh"}, time.Minute)
+
+	// A cached response has no raw payload, so includeRaw must fetch fresh data
+	_, err := AnalyzeFeed(config.Config{}, map[string]interface{}{
+		"hashtag":    "rawtest",
+		"limit":      float64(10),
+		"includeRaw": true,
+	})
+	if err == nil {
+		t.Error("AnalyzeFeed() expected fetch error with includeRaw, got nil")
+	}
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch with includeRaw, got %d", fetches)
+	}
+
+	// The cached entry is left untouched
+	cached, found := feedCache.Get(cacheKey)
+	if resp, ok := cached.(models.FeedResponse); !found || !ok || resp.Count != 1 {
+		t.Errorf("Cached entry = %v, want the original response", cached)
+	}
+}
+
+func TestProcessItemsRecoversFromPanics(t *testing.T) {
+	original := analyzePost
+	defer func() { analyzePost = original }()
+	analyzePost = func(item FeedItem, skipped analyzerSet) models.Post {
+		if strings.Contains(item.Post.Record.Text, "malformed") {
+			panic("analyzer bug")
+		}
+		return analyzeItem(item, skipped)
+	}
+
+	items, err := parseFeedItems([]byte(`{"feed":[
+		{"post":{"uri":"at://did:plc:a/app.bsky.feed.post/1","record":{"text":"A good post"},"author":{"handle":"a.bsky.social"}}},
+		{"post":{"uri":"at://did:plc:b/app.bsky.feed.post/2","record":{"text":"A malformed post"},"author":{"handle":"b.bsky.social"}}},
+		{"post":{"uri":"at://did:plc:c/app.bsky.feed.post/3","record":{"text":"Another good post"},"author":{"handle":"c.bsky.social"}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("parseFeedItems() unexpected error: %v", err)
+	}
+
+	posts := processItems(items, "", 10, "", 0, 0)
+	if len(posts) != 2 {
+		t.Fatalf("processItems() returned %d posts, want the 2 that did not panic", len(posts))
+	}
+	for _, post := range posts {
+		if post.ID == "2" {
+			t.Errorf("The panicking post was returned: %+v", post)
+		}
+	}
+}
+
+func TestParseFeedItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantURIs   []string
+		wantCursor string
+		wantErr    bool
+	}{
+		{
+			name:     "Timeline with unknown fields",
+			data:     `{"cursor":"abc","extra":{"nested":[1,2]},"feed":[{"post":{"uri":"at://a/app.bsky.feed.post/1","labels":[],"record":{"text":"hi","langs":["en"]}},"feedContext":"x"}]}`,
+			wantURIs:   []string{"at://a/app.bsky.feed.post/1"},
+			wantCursor: "abc",
+		},
+		{
+			name:     "Search results",
+			data:     `{"hitsTotal":2,"posts":[{"uri":"at://a/app.bsky.feed.post/1","cid":"c1","author":{"did":"did:plc:a","handle":"a.test"},"record":{"text":"one"}},{"uri":"at://b/app.bsky.feed.post/2","record":{"text":"two"}}],"cursor":"2"}`,
+			wantURIs:   []string{"at://a/app.bsky.feed.post/1", "at://b/app.bsky.feed.post/2"},
+			wantCursor: "2",
+		},
+		{
+			name:     "Timeline takes precedence",
+			data:     `{"posts":[{"uri":"at://s/app.bsky.feed.post/1"}],"feed":[{"post":{"uri":"at://t/app.bsky.feed.post/1"}}]}`,
+			wantURIs: []string{"at://t/app.bsky.feed.post/1"},
+		},
+		{name: "Null feed and no posts", data: `{"feed":null}`, wantURIs: []string{}},
+		{name: "Malformed", data: `{"feed":[{"post":`, wantErr: true},
+		{name: "Not an object", data: `[]`, wantErr: true},
+		{name: "Posts not an array", data: `{"posts":{}}`, wantErr: true},
+		{name: "Cursor not a string", data: `{"feed":[],"cursor":5}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, cursor, err := parseFeedPage([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFeedPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			uris := make([]string, 0, len(items))
+			for _, item := range items {
+				uris = append(uris, item.Post.URI)
+			}
+			if !reflect.DeepEqual(uris, tt.wantURIs) {
+				t.Errorf("URIs = %v, want %v", uris, tt.wantURIs)
+			}
+			if cursor != tt.wantCursor {
+				t.Errorf("Cursor = %q, want %q", cursor, tt.wantCursor)
+			}
+		})
+	}
+
+	// Search posts keep their details
+	items, _ := parseFeedItems([]byte(tests[1].data))
+	if got := items[0].Post; got.CID != "c1" || got.Author.Handle != "a.test" || got.Record.Text != "one" {
+		t.Errorf("First search post = %+v", got)
+	}
+}
+
+// parseFeedItemsTwice is the former parser, which unmarshals a search
+// response twice, kept to compare against parseFeedItems
+func parseFeedItemsTwice(feedData []byte) ([]FeedItem, error) {
+	var feed FeedResponse
+	if err := json.Unmarshal(feedData, &feed); err == nil && feed.Feed != nil {
+		return feed.Feed, nil
+	}
+	var searchResp struct {
+		Posts []struct {
+			URI    string `json:"uri"`
+			CID    string `json:"cid"`
+			Record struct {
+				Text      string          `json:"text"`
+				CreatedAt string          `json:"createdAt"`
+				Reply     json.RawMessage `json:"reply"`
+			} `json:"record"`
+			Author struct {
+				DID    string `json:"did"`
+				Handle string `json:"handle"`
+			} `json:"author"`
+		} `json:"posts"`
+	}
+	if err := json.Unmarshal(feedData, &searchResp); err != nil {
+		return nil, err
+	}
+	items := make([]FeedItem, len(searchResp.Posts))
+	for i, post := range searchResp.Posts {
+		items[i].Post = post
+	}
+	return items, nil
+}
+
+func BenchmarkParseFeedItems(b *testing.B) {
+	var posts []string
+	for i := 0; i < 1000; i++ {
+		posts = append(posts, fmt.Sprintf(`{"uri":"at://did:plc:abc/app.bsky.feed.post/%d","cid":"bafy%d","author":{"did":"did:plc:abc","handle":"abc.bsky.social","displayName":"ABC","avatar":"https://cdn.example/avatar.jpg"},"record":{"$type":"app.bsky.feed.post","text":"Post %d about #golang and how great it is","createdAt":"2024-01-01T00:00:00Z","langs":["en"]},"replyCount":1,"repostCount":2,"likeCount":3,"indexedAt":"2024-01-01T00:00:01Z","labels":[]}`, i, i, i))
+	}
+	search := []byte(`{"hitsTotal":1000,"cursor":"1000","posts":[` + strings.Join(posts, ",") + `]}`)
+
+	for _, bench := range []struct {
+		name  string
+		parse func([]byte) ([]FeedItem, error)
+	}{
+		{name: "DoubleUnmarshal", parse: parseFeedItemsTwice},
+		{name: "ShapeDetecting", parse: parseFeedItems},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if items, err := bench.parse(search); err != nil || len(items) != 1000 {
+					b.Fatalf("parse() = %d items, %v", len(items), err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Analyzers selectable with the analyzers parameter of feed-analysis
+const (
+	AnalyzerSentiment = "sentiment" // The sentiment and confidence analysis keys
+	AnalyzerMetrics   = "metrics"   // Length and word count
+)
+
+// analyzerSet holds one bit per analyzer
+type analyzerSet uint8
+
+const (
+	sentimentAnalyzer analyzerSet = 1 << iota
+	metricsAnalyzer
+)
+
+// analyzerBits maps each analyzer name to its bit
+var analyzerBits = map[string]analyzerSet{
+	AnalyzerSentiment: sentimentAnalyzer,
+	AnalyzerMetrics:   metricsAnalyzer,
+}
+
+// has reports whether analyzer is in the set
+func (s analyzerSet) has(analyzer analyzerSet) bool {
+	return s&analyzer != 0
+}
+
+// skippedAnalyzers reads the analyzers parameter, a list of analyzer names,
+// and returns the analyzers it leaves out. An empty list skips them all.
+func skippedAnalyzers(raw interface{}) (analyzerSet, error) {
+	var names []string
+	switch value := raw.(type) {
+	case []string:
+		names = value
+	case []interface{}:
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				return 0, analyzersError()
+			}
+			names = append(names, name)
+		}
+	default:
+		return 0, analyzersError()
+	}
+
+	var all, enabled analyzerSet
+	for _, bit := range analyzerBits {
+		all |= bit
+	}
+	for _, name := range names {
+		bit, ok := analyzerBits[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, analyzersError()
+		}
+		enabled |= bit
+	}
+	return all &^ enabled, nil
+}
+
+// analyzersError describes the accepted analyzers parameter
+func analyzersError() error {
+	names := make([]string, 0, len(analyzerBits))
+	for name := range analyzerBits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("invalid parameter: analyzers must be an array of analyzer names (%s)", strings.Join(names, ", "))
+}
@@ -0,0 +1,126 @@
+package feed
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestSkippedAnalyzers(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     interface{}
+		want    analyzerSet
+		wantErr bool
+	}{
+		{name: "All", raw: []interface{}{"sentiment", "metrics"}, want: 0},
+		{name: "Metrics only", raw: []interface{}{"metrics"}, want: sentimentAnalyzer},
+		{name: "Names are case-insensitive", raw: []string{" Sentiment "}, want: metricsAnalyzer},
+		{name: "None", raw: []interface{}{}, want: sentimentAnalyzer | metricsAnalyzer},
+		{name: "Unknown analyzer", raw: []interface{}{"language"}, wantErr: true},
+		{name: "Not a list", raw: "metrics", wantErr: true},
+		{name: "Not a name", raw: []interface{}{float64(1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := skippedAnalyzers(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("skippedAnalyzers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "invalid parameter: analyzers") || !strings.Contains(err.Error(), "metrics, sentiment") {
+					t.Errorf("Error = %q, want it to list the analyzers", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("skippedAnalyzers() = %b, want %b", got, tt.want)
+			}
+		})
+	}
+
+	// validateParams rejects the same values
+	if _, err := validateParams(map[string]interface{}{"analyzers": []interface{}{"language"}}, config.Limits{}); err == nil {
+		t.Error("validateParams() accepted an unknown analyzer")
+	}
+}
+
+func TestProcessPostsParallelAnalyzers(t *testing.T) {
+	timelineJSON := []byte(`{"feed": [
+		{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/1", "record": {"text": "I love this great day"}}}
+	]}`)
+
+	tests := []struct {
+		name          string
+		skipped       analyzerSet
+		wantSentiment bool
+		wantMetrics   bool
+	}{
+		{name: "All", skipped: 0, wantSentiment: true, wantMetrics: true},
+		{name: "Sentiment disabled", skipped: sentimentAnalyzer, wantMetrics: true},
+		{name: "Metrics disabled", skipped: metricsAnalyzer, wantSentiment: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := defaultItemFilter
+			filter.Skipped = tt.skipped
+			posts, err := processPostsParallel(timelineJSON, "", 10, filter)
+			if err != nil {
+				t.Fatalf("processPostsParallel() unexpected error: %v", err)
+			}
+			if len(posts) != 1 {
+				t.Fatalf("Got %d posts, want 1", len(posts))
+			}
+			post := posts[0]
+
+			_, hasSentiment := post.Analysis["sentiment"]
+			_, hasConfidence := post.Analysis["confidence"]
+			if hasSentiment != tt.wantSentiment || hasConfidence != tt.wantSentiment {
+				t.Errorf("Analysis = %v, want sentiment keys %v", post.Analysis, tt.wantSentiment)
+			}
+			if (post.Metrics != nil) != tt.wantMetrics {
+				t.Errorf("Metrics = %v, want metrics %v", post.Metrics, tt.wantMetrics)
+			}
+			// Item details are kept whichever analyzers run
+			if post.Analysis["repost"] != "false" || post.ID != "1" {
+				t.Errorf("Post = %+v, want its item details", post)
+			}
+		})
+	}
+
+	// The analyzers are part of the cache key
+	metricsOnly := defaultItemFilter
+	metricsOnly.Skipped = sentimentAnalyzer
+	if generateCacheKey("", 10, metricsOnly) == generateCacheKey("", 10, defaultItemFilter) {
+		t.Error("generateCacheKey() generated the same key with and without analyzers")
+	}
+}
+
+func BenchmarkProcessItemsAnalyzers(b *testing.B) {
+	var entries []string
+	for i := 0; i < 100; i++ {
+		entries = append(entries, fmt.Sprintf(`{"post": {"uri": "at://did:plc:abc/app.bsky.feed.post/%d", "record": {"text": "Post %d: I love how great and happy this amazing release is, though the docs are a bit terrible and sad"}}}`, i, i))
+	}
+	items, err := parseFeedItems([]byte(`{"feed": [` + strings.Join(entries, ",") + `]}`))
+	if err != nil {
+		b.Fatalf("parseFeedItems() unexpected error: %v", err)
+	}
+
+	for _, bench := range []struct {
+		name    string
+		skipped analyzerSet
+	}{
+		{name: "All", skipped: 0},
+		{name: "MetricsOnly", skipped: sentimentAnalyzer},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				processItems(items, "", len(items), "", 0, bench.skipped)
+			}
+		})
+	}
+}
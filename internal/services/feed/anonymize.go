@@ -0,0 +1,46 @@
+package feed
+
+import (
+	"github.com/littleironwaltz/bluesky-mcp/internal/anonymize"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+// withAnonymizedAuthors returns a copy of a feed response with every handle and
+// DID replaced by its pseudonym, so posts by one author can still be grouped.
+// The post URI, CID and raw feed name the author too and are dropped, as is
+// the profile's display name; post text is left as written.
+func withAnonymizedAuthors(result interface{}) interface{} {
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok {
+		return result
+	}
+
+	// Copy the posts, analyses and profiles so the cached response is left unchanged
+	feedResp.Raw = nil
+	feedResp.Posts = append([]models.Post(nil), feedResp.Posts...)
+	for i := range feedResp.Posts {
+		post := &feedResp.Posts[i]
+		post.URI = ""
+		post.CID = ""
+		post.Author = anonymize.Pseudonym(post.Author)
+		post.AuthorDID = anonymize.Pseudonym(post.AuthorDID)
+
+		if repostedBy, ok := post.Analysis["reposted_by"]; ok {
+			analysis := make(map[string]string, len(post.Analysis))
+			for k, v := range post.Analysis {
+				analysis[k] = v
+			}
+			analysis["reposted_by"] = anonymize.Pseudonym(repostedBy)
+			post.Analysis = analysis
+		}
+
+		if post.AuthorProfile != nil {
+			profile := *post.AuthorProfile
+			profile.DID = anonymize.Pseudonym(profile.DID)
+			profile.Handle = anonymize.Pseudonym(profile.Handle)
+			profile.DisplayName = ""
+			post.AuthorProfile = &profile
+		}
+	}
+	return feedResp
+}
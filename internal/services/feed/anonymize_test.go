@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/anonymize"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+func TestWithAnonymizedAuthors(t *testing.T) {
+	post := func(handle, did string) models.Post {
+		return models.Post{
+			ID:        "3k" + handle[:1],
+			URI:       "at://" + did + "/app.bsky.feed.post/3k",
+			CID:       "bafy" + handle[:1],
+			Text:      "hello",
+			Author:    handle,
+			AuthorDID: did,
+			Analysis:  map[string]string{"sentiment": "neutral"},
+		}
+	}
+	cached := models.FeedResponse{
+		Posts: []models.Post{
+			post("alice.bsky.social", "did:plc:alice"),
+			post("bob.bsky.social", "did:plc:bob"),
+			post("alice.bsky.social", "did:plc:alice"),
+		},
+		Count: 3,
+		Raw:   []byte(`{"feed":[]}`),
+	}
+	cached.Posts[1].Analysis["reposted_by"] = "alice.bsky.social"
+	cached.Posts[1].AuthorProfile = &models.AuthorProfile{DID: "did:plc:bob", Handle: "bob.bsky.social", DisplayName: "Bob", FollowersCount: 7}
+
+	result := withAnonymizedAuthors(cached).(models.FeedResponse)
+
+	// The same author gets the same pseudonyms, different authors different ones
+	alice, bob := result.Posts[0], result.Posts[1]
+	if alice.Author != result.Posts[2].Author || alice.AuthorDID != result.Posts[2].AuthorDID {
+		t.Errorf("Posts by one author got pseudonyms %s/%s and %s/%s",
+			alice.Author, alice.AuthorDID, result.Posts[2].Author, result.Posts[2].AuthorDID)
+	}
+	if alice.Author == bob.Author || alice.AuthorDID == bob.AuthorDID {
+		t.Errorf("Different authors got the same pseudonym %s", alice.Author)
+	}
+	if alice.Author != anonymize.Pseudonym("alice.bsky.social") || bob.Analysis["reposted_by"] != alice.Author {
+		t.Errorf("Author = %s, reposted_by = %s, want the pseudonym of alice", alice.Author, bob.Analysis["reposted_by"])
+	}
+	if profile := bob.AuthorProfile; profile.Handle != bob.Author || profile.DID != bob.AuthorDID ||
+		profile.DisplayName != "" || profile.FollowersCount != 7 {
+		t.Errorf("AuthorProfile = %+v, want pseudonyms and counts only", profile)
+	}
+
+	// Nothing else names the authors
+	for _, p := range result.Posts {
+		if p.URI != "" || p.CID != "" || strings.Contains(p.Author+p.AuthorDID, "alice") {
+			t.Errorf("Post still identifies its author: %+v", p)
+		}
+	}
+	if result.Raw != nil || result.Count != 3 {
+		t.Errorf("Raw = %s, Count = %d, want no raw feed and the count kept", result.Raw, result.Count)
+	}
+
+	// The cached response is left unchanged
+	if cached.Posts[0].Author != "alice.bsky.social" || cached.Posts[1].Analysis["reposted_by"] != "alice.bsky.social" ||
+		cached.Posts[1].AuthorProfile.Handle != "bob.bsky.social" || cached.Raw == nil {
+		t.Error("Expected the cached response to be left unchanged")
+	}
+}
@@ -0,0 +1,220 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// FeedIterator reads a hashtag search or the timeline one page at a time,
+// fetching the next page through the cursor only when the current one has been
+// consumed, so memory stays bounded to a page however many posts are read.
+// Iteration also stops at the configured Limits.MaxAnalyzedPosts; Truncated
+// reports whether the ceiling cut the feed short.
+//
+//	it, err := NewFeedIterator(ctx, cfg, params)
+//	for it.Next() {
+//		post := it.Post()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type FeedIterator struct {
+	ctx      context.Context
+	client   BlueskyAPIClient
+	hashtag  string
+	pageSize int
+	filter   itemFilter
+
+	page      []FeedItem
+	post      models.Post
+	cursor    string
+	pages     int  // Pages fetched so far
+	posts     int  // Posts returned so far
+	maxPosts  int  // Posts returned at most; 0 for no ceiling
+	truncated bool // Stopped at maxPosts before the feed ended
+	done      bool // No more pages to fetch
+	err       error
+}
+
+// NewFeedIterator creates an iterator over the feed selected by params, which
+// take the same hashtag, includeReposts and includeReplies as AnalyzeFeed; limit
+// sets the page size rather than the number of posts. Iteration stops when
+// ctx is done.
+func NewFeedIterator(ctx context.Context, cfg config.Config, params map[string]interface{}) (*FeedIterator, error) {
+	params, err := validateParams(params, cfg.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := defaultItemFilter
+	if include, ok := params["includeReposts"].(bool); ok {
+		filter.IncludeReposts = include
+	}
+	if include, ok := params["includeReplies"].(bool); ok {
+		filter.IncludeReplies = include
+	}
+
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, FetchError{Message: "Authentication error", Cause: err, Retryable: true}
+	}
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+
+	it := newFeedIterator(ctx, client, params["hashtag"].(string), int(params["limit"].(float64)), filter)
+	it.maxPosts = cfg.Limits.MaxAnalyzedPosts()
+	return it, nil
+}
+
+// newFeedIterator creates an iterator reading pages of pageSize items from client
+func newFeedIterator(ctx context.Context, client BlueskyAPIClient, hashtag string, pageSize int, filter itemFilter) *FeedIterator {
+	return &FeedIterator{
+		ctx:      ctx,
+		client:   client,
+		hashtag:  hashtag,
+		pageSize: pageSize,
+		filter:   filter,
+	}
+}
+
+// Next advances to the next post, fetching a page when needed. It returns false
+// when the feed is exhausted, the context is done, a fetch fails or the post
+// ceiling is reached; Err and Truncated tell which.
+func (it *FeedIterator) Next() bool {
+	if it.maxPosts > 0 && it.posts >= it.maxPosts {
+		it.truncated = len(it.page) > 0 || !it.done
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.err != nil || it.done {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		it.fetchPage()
+	}
+
+	it.post = analyzeItem(it.page[0], it.filter.Skipped)
+	it.page = it.page[1:]
+	it.posts++
+	return true
+}
+
+// Post returns the post Next advanced to, with its analysis
+func (it *FeedIterator) Post() models.Post {
+	return it.post
+}
+
+// Err returns the error that stopped iteration, or nil if the feed was read to the end
+func (it *FeedIterator) Err() error {
+	return it.err
+}
+
+// Truncated reports whether iteration stopped at the post ceiling while more
+// posts may have been available
+func (it *FeedIterator) Truncated() bool {
+	return it.truncated
+}
+
+// Pages returns the number of pages fetched so far
+func (it *FeedIterator) Pages() int {
+	return it.pages
+}
+
+// fetchPage loads the page after the current cursor
+func (it *FeedIterator) fetchPage() {
+	query := url.Values{}
+	query.Set("limit", fmt.Sprintf("%d", it.pageSize))
+	if it.cursor != "" {
+		query.Set("cursor", it.cursor)
+	}
+
+	endpoint := "app.bsky.feed.getTimeline"
+	if it.hashtag != "" {
+		endpoint = "app.bsky.feed.searchPosts"
+		query.Set("q", "#"+it.hashtag)
+	}
+
+	type pageResult struct {
+		data []byte
+		err  error
+	}
+	resultCh := make(chan pageResult, 1)
+	go func() {
+		data, err := it.client.Get(endpoint, query)
+		resultCh <- pageResult{data, err}
+	}()
+
+	var data []byte
+	select {
+	case <-it.ctx.Done():
+		it.err = it.ctx.Err()
+		return
+	case result := <-resultCh:
+		if result.err != nil {
+			it.err = FetchError{
+				Message:   fmt.Sprintf("%s API request failed", endpoint),
+				Cause:     result.err,
+				Retryable: isRetryableError(result.err),
+			}
+			return
+		}
+		data = result.data
+	}
+	it.pages++
+
+	items, cursor, err := parseFeedPage(data)
+	if err != nil {
+		it.err = malformedFeedError(err)
+		return
+	}
+
+	it.page = applyItemFilter(items, it.filter)
+	it.cursor = cursor
+	if cursor == "" || len(items) == 0 {
+		it.done = true
+	}
+}
+
+// StreamFeed sends the posts of a FeedIterator on a channel until the feed ends,
+// the post ceiling is reached or ctx is done. The error channel receives at most one error and both channels are
+// closed when streaming stops; pages are fetched only as fast as posts are received.
+func StreamFeed(ctx context.Context, cfg config.Config, params map[string]interface{}) (<-chan models.Post, <-chan error) {
+	posts := make(chan models.Post)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(posts)
+		defer close(errs)
+
+		it, err := NewFeedIterator(ctx, cfg, params)
+		if err != nil {
+			errs <- err
+			return
+		}
+		streamPosts(ctx, it, posts, errs)
+	}()
+
+	return posts, errs
+}
+
+// streamPosts sends the iterator's posts on posts, and its error, if any, on errs
+func streamPosts(ctx context.Context, it *FeedIterator, posts chan<- models.Post, errs chan<- error) {
+	for it.Next() {
+		select {
+		case posts <- it.Post():
+		case <-ctx.Done():
+			errs <- ctx.Err()
+			return
+		}
+	}
+	if err := it.Err(); err != nil {
+		errs <- err
+	}
+}
@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+// newPagedPosts creates n posts for a pagingClient, newest first
+func newPagedPosts(n int) []trendPost {
+	posts := make([]trendPost, n)
+	for i := range posts {
+		posts[i] = trendPost{CreatedAt: time.Now().Add(-time.Duration(i) * time.Minute), Text: fmt.Sprintf("post %d is great", i)}
+	}
+	return posts
+}
+
+func TestFeedIteratorFetchesPagesLazily(t *testing.T) {
+	tests := []struct {
+		name      string
+		consume   int
+		wantPages int
+	}{
+		{name: "First post", consume: 1, wantPages: 1},
+		{name: "Exactly one page", consume: 3, wantPages: 1},
+		{name: "Into the second page", consume: 4, wantPages: 2},
+		{name: "Three pages", consume: 7, wantPages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &pagingClient{pageSize: 3, posts: newPagedPosts(10)}
+			it := newFeedIterator(context.Background(), client, "golang", 3, defaultItemFilter)
+
+			for i := 0; i < tt.consume; i++ {
+				if !it.Next() {
+					t.Fatalf("Next() = false after %d posts: %v", i, it.Err())
+				}
+				post := it.Post()
+				if want := fmt.Sprintf("post %d is great", i); post.Text != want {
+					t.Errorf("Post %d text = %q, want %q", i, post.Text, want)
+				}
+				if post.Analysis["sentiment"] != "positive" {
+					t.Errorf("Post %d was not analyzed: %v", i, post.Analysis)
+				}
+			}
+
+			if len(client.queries) != tt.wantPages || it.Pages() != tt.wantPages {
+				t.Errorf("Fetched %d pages for %d posts, want %d", len(client.queries), tt.consume, tt.wantPages)
+			}
+			if tt.wantPages > 1 && client.queries[1].Get("cursor") != "3" {
+				t.Errorf("Second page cursor = %q, want the first page's cursor", client.queries[1].Get("cursor"))
+			}
+		})
+	}
+}
+
+func TestFeedIteratorReadsToTheEnd(t *testing.T) {
+	client := &pagingClient{pageSize: 4, posts: newPagedPosts(10)}
+	it := newFeedIterator(context.Background(), client, "golang", 4, defaultItemFilter)
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil at the end of the feed", err)
+	}
+	if count != 10 || len(client.queries) != 3 {
+		t.Errorf("Read %d posts in %d pages, want 10 in 3", count, len(client.queries))
+	}
+}
+
+func TestFeedIteratorPostCeiling(t *testing.T) {
+	client := &pagingClient{pageSize: 3, posts: newPagedPosts(10)}
+	it := newFeedIterator(context.Background(), client, "golang", 3, defaultItemFilter)
+	it.maxPosts = 5
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil at the ceiling", err)
+	}
+	if count != 5 || !it.Truncated() {
+		t.Errorf("Read %d posts, truncated %v; want 5 and truncated", count, it.Truncated())
+	}
+	// More pages were available, but none past the ceiling were fetched
+	if len(client.queries) != 2 {
+		t.Errorf("Fetched %d pages, want 2", len(client.queries))
+	}
+
+	// A feed ending exactly at the ceiling is not truncated
+	client = &pagingClient{pageSize: 5, posts: newPagedPosts(10)}
+	it = newFeedIterator(context.Background(), client, "golang", 5, defaultItemFilter)
+	it.maxPosts = 10
+	for it.Next() {
+	}
+	if it.Truncated() {
+		t.Error("Truncated() = true for a feed that ended at the ceiling")
+	}
+}
+
+func TestFeedIteratorCancellation(t *testing.T) {
+	client := &pagingClient{pageSize: 2, posts: newPagedPosts(10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	it := newFeedIterator(ctx, client, "golang", 2, defaultItemFilter)
+
+	for i := 0; i < 3; i++ {
+		if !it.Next() {
+			t.Fatalf("Next() = false after %d posts: %v", i, it.Err())
+		}
+	}
+	cancel()
+
+	// The rest of the current page is still returned, but no more pages are fetched
+	read := 0
+	for it.Next() {
+		read++
+	}
+	if read != 1 {
+		t.Errorf("Read %d posts after cancelling, want the 1 left in the fetched page", read)
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", it.Err())
+	}
+	if len(client.queries) != 2 {
+		t.Errorf("Fetched %d pages, want 2", len(client.queries))
+	}
+}
+
+func TestStreamPostsCancellation(t *testing.T) {
+	client := &pagingClient{pageSize: 2, posts: newPagedPosts(10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := newFeedIterator(ctx, client, "golang", 2, defaultItemFilter)
+
+	posts := make(chan models.Post)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(posts)
+		streamPosts(ctx, it, posts, errs)
+	}()
+
+	<-posts
+	<-posts
+	cancel()
+	for range posts {
+		// Drain anything sent before the cancellation was seen
+	}
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Stream error = %v, want context.Canceled", err)
+	}
+	if len(client.queries) > 2 {
+		t.Errorf("Fetched %d pages after cancelling early, want at most 2", len(client.queries))
+	}
+}
@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"sync"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+// Where a feed analysis result came from, as counted in SourceStats
+const (
+	SourceFresh    = "fresh"    // Fetched from the API for this request
+	SourceCached   = "cached"   // Served from the feed cache
+	SourceStale    = "stale"    // Served from the fallback cache after a failed fetch
+	SourceFallback = "fallback" // Fetched, but from the fallback system rather than the API
+)
+
+// SourceStats counts the feed analysis results served from each source
+type SourceStats struct {
+	Fresh    int64 `json:"fresh"`
+	Cached   int64 `json:"cached"`
+	Stale    int64 `json:"stale"`
+	Fallback int64 `json:"fallback"`
+}
+
+var (
+	sourceStatsMu sync.Mutex
+	sourceStats   SourceStats
+)
+
+// GetSourceStats returns how many feed analyses were served from each source
+// since the server started
+func GetSourceStats() SourceStats {
+	sourceStatsMu.Lock()
+	defer sourceStatsMu.Unlock()
+	return sourceStats
+}
+
+// GetCacheStats returns the feed cache statistics
+func GetCacheStats() cache.Stats {
+	return getFeedCache().GetStats()
+}
+
+// recordSource counts a result served from source
+func recordSource(source string) {
+	sourceStatsMu.Lock()
+	defer sourceStatsMu.Unlock()
+	switch source {
+	case SourceFresh:
+		sourceStats.Fresh++
+	case SourceCached:
+		sourceStats.Cached++
+	case SourceStale:
+		sourceStats.Stale++
+	case SourceFallback:
+		sourceStats.Fallback++
+	}
+}
+
+// fetchedSource tells a fetched result from the API apart from fallback data
+func fetchedSource(result interface{}) string {
+	if feedResp, ok := result.(models.FeedResponse); ok && feedResp.Source == "fallback" {
+		return SourceFallback
+	}
+	return SourceFresh
+}
@@ -0,0 +1,170 @@
+package feed
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// stubFeedResponse replaces the feed fetch with one returning resp, or err if set
+func stubFeedResponse(t *testing.T, resp models.FeedResponse, err *error) {
+	t.Helper()
+	original := fetchFeedResponse
+	fetchFeedResponse = func(cfg config.Config, hashtag string, limit int, filter itemFilter, includeRaw bool) (interface{}, error) {
+		if *err != nil {
+			return nil, *err
+		}
+		return resp, nil
+	}
+	t.Cleanup(func() { fetchFeedResponse = original })
+}
+
+// sourceDelta returns how much each counter grew since before
+func sourceDelta(before SourceStats) SourceStats {
+	after := GetSourceStats()
+	return SourceStats{
+		Fresh:    after.Fresh - before.Fresh,
+		Cached:   after.Cached - before.Cached,
+		Stale:    after.Stale - before.Stale,
+		Fallback: after.Fallback - before.Fallback,
+	}
+}
+
+func TestAnalyzeFeedCountsSources(t *testing.T) {
+	var fetchErr error
+	stubFeedResponse(t, models.FeedResponse{Count: 1, Source: "api_fresh"}, &fetchErr)
+	cacheKey := generateCacheKey("sourcetest", 10, defaultItemFilter)
+	feedCache.Delete(cacheKey)
+	defer feedCache.Delete(cacheKey)
+	params := map[string]interface{}{"hashtag": "sourcetest", "limit": float64(10)}
+
+	// A fetched result counts as fresh
+	before := GetSourceStats()
+	if _, err := AnalyzeFeed(config.Config{}, params); err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if delta := sourceDelta(before); delta != (SourceStats{Fresh: 1}) {
+		t.Errorf("Counters grew by %+v, want one fresh", delta)
+	}
+
+	// The same request again is served from the cache
+	before = GetSourceStats()
+	if _, err := AnalyzeFeed(config.Config{}, params); err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if delta := sourceDelta(before); delta != (SourceStats{Cached: 1}) {
+		t.Errorf("Counters grew by %+v, want one cached", delta)
+	}
+
+	// Once the entry expires and the fetch fails, the stale value is served
+	feedCache.Set(cacheKey, models.FeedResponse{Count: 1, Source: "api_fresh"}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	fetchErr = errors.New("upstream unavailable")
+
+	before = GetSourceStats()
+	result, err := AnalyzeFeed(config.Config{}, params)
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if delta := sourceDelta(before); delta != (SourceStats{Stale: 1}) {
+		t.Errorf("Counters grew by %+v, want one stale", delta)
+	}
+	if resp := result.(models.FeedResponse); resp.Source != "cache_stale" || resp.Warning == "" {
+		t.Errorf("AnalyzeFeed() = %+v, want a stale result with a warning", resp)
+	}
+}
+
+func TestAnalyzeFeedCountsFallback(t *testing.T) {
+	var fetchErr error
+	stubFeedResponse(t, models.FeedResponse{Count: 1, Source: "fallback"}, &fetchErr)
+	cacheKey := generateCacheKey("fallbacktest", 10, defaultItemFilter)
+	feedCache.Delete(cacheKey)
+	defer feedCache.Delete(cacheKey)
+
+	before := GetSourceStats()
+	if _, err := AnalyzeFeed(config.Config{}, map[string]interface{}{"hashtag": "fallbacktest", "limit": float64(10)}); err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if delta := sourceDelta(before); delta != (SourceStats{Fallback: 1}) {
+		t.Errorf("Counters grew by %+v, want one fallback", delta)
+	}
+}
+
+func TestBuildFeedResponseMarksFallback(t *testing.T) {
+	feedData := []byte(`{"feed":[{"post":{"uri":"at://fallback/1","record":{"text":"Service unavailable"},"author":{"handle":"fallback.system"}}}]}`)
+	if resp, err := buildFeedResponse(feedData, "", 10, defaultItemFilter, false); err != nil || resp.Source != "fallback" {
+		t.Errorf("Source = %q (err %v), want fallback", resp.Source, err)
+	}
+}
+
+func TestSetCacheOptions(t *testing.T) {
+	t.Cleanup(func() { SetCacheOptions(config.CacheSettings{Directory: config.CacheDirectoryOff}) })
+
+	// The package default keeps the feed cache in memory
+	if getFeedCache().Options().PersistOptions.Enabled {
+		t.Error("Expected the default feed cache to be in memory only")
+	}
+
+	dir := t.TempDir()
+	SetCacheOptions(config.CacheSettings{
+		MaxItems:     10,
+		TTL:          config.Duration(30 * time.Second),
+		StaleTimeout: config.Duration(5 * time.Minute),
+		Directory:    dir,
+	})
+	options := getFeedCache().Options()
+	if options.MaxItems != 10 || options.DefaultTTL != 30*time.Second || options.StaleTimeout != 5*time.Minute {
+		t.Errorf("Options = %+v, want the configured values", options)
+	}
+	if !options.PersistOptions.Enabled || options.PersistOptions.Directory != dir ||
+		options.PersistOptions.Filename != DefaultCacheOptions.PersistOptions.Filename {
+		t.Errorf("PersistOptions = %+v, want persistence to %s", options.PersistOptions, dir)
+	}
+
+	// Settings left out keep the defaults
+	SetCacheOptions(config.CacheSettings{TTL: config.Duration(time.Minute), Directory: config.CacheDirectoryOff})
+	options = getFeedCache().Options()
+	if options.DefaultTTL != time.Minute || options.MaxItems != DefaultCacheOptions.MaxItems ||
+		options.StaleTimeout != DefaultCacheOptions.StaleTimeout {
+		t.Errorf("Options = %+v, want the defaults besides the TTL", options)
+	}
+}
+
+func TestPersistedFeedCacheReloadsAsFeedResponse(t *testing.T) {
+	t.Cleanup(func() { SetCacheOptions(config.CacheSettings{Directory: config.CacheDirectoryOff}) })
+	dir := t.TempDir()
+	SetCacheOptions(config.CacheSettings{Directory: dir})
+
+	var fetchErr error
+	stubFeedResponse(t, models.FeedResponse{
+		Posts:  []models.Post{{ID: "post1", Text: "Persisted #golang post", Analysis: map[string]string{"sentiment": "neutral"}}},
+		Count:  1,
+		Source: "api_fresh",
+	}, &fetchErr)
+	params := func() map[string]interface{} {
+		return map[string]interface{}{"hashtag": "persisted", "limit": float64(5)}
+	}
+	if _, err := AnalyzeFeed(config.Config{}, params()); err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+
+	// A new cache saves the current one and loads the saved feed, which is
+	// served while the API is down
+	SetCacheOptions(config.CacheSettings{Directory: dir})
+	fetchErr = errors.New("API unavailable")
+
+	result, err := AnalyzeFeed(config.Config{}, params())
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() after reload unexpected error: %v", err)
+	}
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok {
+		t.Fatalf("AnalyzeFeed() after reload = %T, want models.FeedResponse", result)
+	}
+	if feedResp.Count != 1 || len(feedResp.Posts) != 1 || feedResp.Posts[0].Analysis["sentiment"] != "neutral" {
+		t.Errorf("Reloaded response = %+v, want the persisted feed", feedResp)
+	}
+}
@@ -0,0 +1,170 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Author profile lookup limits
+const (
+	profileBatchSize  = 25  // Actors per getProfiles call, the API maximum
+	MaxProfileLookups = 100 // Distinct authors looked up per request, so at most 4 calls
+	profileCacheTTL   = 30 * time.Minute
+)
+
+// profileCache keeps author profiles by DID, or by handle for posts without one,
+// across requests so repeated analyses of the same authors make no calls
+var profileCache = cache.NewWithOptions(cache.CacheOptions{
+	MaxItems:        5000,
+	DefaultTTL:      profileCacheTTL,
+	CleanupInterval: 5 * time.Minute,
+})
+
+// getProfiles fetches the profiles of up to profileBatchSize actors, can be
+// replaced for testing
+var getProfiles = func(cfg config.Config, actors []string) ([]byte, error) {
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("authentication error: %w", err)
+	}
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+
+	query := url.Values{}
+	for _, actor := range actors {
+		query.Add("actors", actor)
+	}
+	return client.Get("app.bsky.actor.getProfiles", query)
+}
+
+// profileView is the part of a getProfiles result kept for each author
+type profileView struct {
+	DID            string `json:"did"`
+	Handle         string `json:"handle"`
+	DisplayName    string `json:"displayName"`
+	FollowersCount int    `json:"followersCount"`
+	FollowsCount   int    `json:"followsCount"`
+	PostsCount     int    `json:"postsCount"`
+}
+
+// withAuthorProfiles returns a copy of a feed response with each post's author
+// profile attached. Authors are deduplicated by DID and looked up in batches,
+// using cached profiles first. If some profiles cannot be loaded the posts are
+// returned without them and the response carries a warning.
+func withAuthorProfiles(cfg config.Config, result interface{}) interface{} {
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok {
+		return result
+	}
+
+	// Copy the posts so the cached response is left unchanged
+	feedResp.Posts = append([]models.Post(nil), feedResp.Posts...)
+
+	profiles, err := loadAuthorProfiles(cfg, feedResp.Posts)
+	for i := range feedResp.Posts {
+		if profile, ok := profiles[authorKey(feedResp.Posts[i])]; ok {
+			feedResp.Posts[i].AuthorProfile = &profile
+		}
+	}
+
+	if err != nil {
+		return withWarning(feedResp, fmt.Sprintf("Author profiles may be incomplete: %v", err))
+	}
+	return feedResp
+}
+
+// authorKey identifies a post's author for profile lookups
+func authorKey(post models.Post) string {
+	if post.AuthorDID != "" {
+		return post.AuthorDID
+	}
+	return post.Author
+}
+
+// loadAuthorProfiles returns the profiles of the posts' authors by authorKey,
+// fetching those not cached. At most MaxProfileLookups authors are looked up.
+func loadAuthorProfiles(cfg config.Config, posts []models.Post) (map[string]models.AuthorProfile, error) {
+	profiles := make(map[string]models.AuthorProfile)
+	seen := make(map[string]bool)
+	var missing []string
+
+	for _, post := range posts {
+		key := authorKey(post)
+		if key == "" || seen[key] {
+			continue
+		}
+		if len(seen) >= MaxProfileLookups {
+			break
+		}
+		seen[key] = true
+
+		if cached, found := profileCache.Get("profile:" + key); found {
+			profiles[key] = cached.(models.AuthorProfile)
+			continue
+		}
+		missing = append(missing, key)
+	}
+
+	for start := 0; start < len(missing); start += profileBatchSize {
+		end := start + profileBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+
+		fetched, err := fetchProfileBatch(cfg, missing[start:end])
+		if err != nil {
+			return profiles, err
+		}
+		for key, profile := range fetched {
+			profiles[key] = profile
+			profileCache.Set("profile:"+key, profile, profileCacheTTL)
+		}
+	}
+	return profiles, nil
+}
+
+// fetchProfileBatch fetches one batch of profiles, keyed by both DID and
+// handle so authors requested either way are matched
+func fetchProfileBatch(cfg config.Config, actors []string) (map[string]models.AuthorProfile, error) {
+	data, err := getProfiles(cfg, actors)
+	if err != nil {
+		return nil, fmt.Errorf("app.bsky.actor.getProfiles request failed: %w", err)
+	}
+
+	var resp struct {
+		Profiles []profileView `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing profiles response: %w", err)
+	}
+
+	requested := make(map[string]bool, len(actors))
+	for _, actor := range actors {
+		requested[actor] = true
+	}
+
+	fetched := make(map[string]models.AuthorProfile, len(resp.Profiles))
+	for _, view := range resp.Profiles {
+		profile := models.AuthorProfile{
+			DID:            view.DID,
+			Handle:         view.Handle,
+			DisplayName:    view.DisplayName,
+			FollowersCount: view.FollowersCount,
+			FollowsCount:   view.FollowsCount,
+			PostsCount:     view.PostsCount,
+		}
+		for _, key := range []string{view.DID, view.Handle} {
+			if requested[key] {
+				fetched[key] = profile
+			}
+		}
+	}
+	return fetched, nil
+}
@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// stubProfiles replaces getProfiles with one answering from profiles by DID,
+// recording the actors of each call
+func stubProfiles(t *testing.T, profiles map[string]string) *[][]string {
+	t.Helper()
+	calls := &[][]string{}
+	original := getProfiles
+	getProfiles = func(cfg config.Config, actors []string) ([]byte, error) {
+		*calls = append(*calls, actors)
+		var views []string
+		for _, actor := range actors {
+			if view, ok := profiles[actor]; ok {
+				views = append(views, view)
+			}
+		}
+		return []byte(`{"profiles":[` + strings.Join(views, ",") + `]}`), nil
+	}
+	t.Cleanup(func() {
+		getProfiles = original
+		profileCache.Clear()
+	})
+	profileCache.Clear()
+	return calls
+}
+
+// seedFeed caches a feed response for hashtag so AnalyzeFeed is served without fetching
+func seedFeed(t *testing.T, hashtag string, posts []models.Post) {
+	t.Helper()
+	cacheKey := generateCacheKey(hashtag, 10, defaultItemFilter)
+	feedCache.Set(cacheKey, models.FeedResponse{Posts: posts, Count: len(posts), Source: "api_fresh"}, time.Minute)
+	t.Cleanup(func() { feedCache.Delete(cacheKey) })
+}
+
+func TestAnalyzeFeedIncludeAuthorProfile(t *testing.T) {
+	calls := stubProfiles(t, map[string]string{
+		"did:plc:alice": `{"did":"did:plc:alice","handle":"alice.bsky.social","displayName":"Alice","followersCount":120,"followsCount":30,"postsCount":450}`,
+		"did:plc:bob":   `{"did":"did:plc:bob","handle":"bob.bsky.social","followersCount":7}`,
+	})
+	seedFeed(t, "profiletest", []models.Post{
+		{ID: "1", Author: "alice.bsky.social", AuthorDID: "did:plc:alice"},
+		{ID: "2", Author: "bob.bsky.social", AuthorDID: "did:plc:bob"},
+		{ID: "3", Author: "alice.bsky.social", AuthorDID: "did:plc:alice"},
+	})
+
+	result, err := AnalyzeFeed(config.Config{}, map[string]interface{}{
+		"hashtag":              "profiletest",
+		"limit":                float64(10),
+		"includeAuthorProfile": true,
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	resp := result.(models.FeedResponse)
+
+	wantFollowers := map[string]int{"1": 120, "2": 7, "3": 120}
+	for _, post := range resp.Posts {
+		if post.AuthorProfile == nil {
+			t.Fatalf("Post %s has no author profile", post.ID)
+		}
+		if post.AuthorProfile.FollowersCount != wantFollowers[post.ID] {
+			t.Errorf("Post %s followers = %d, want %d", post.ID, post.AuthorProfile.FollowersCount, wantFollowers[post.ID])
+		}
+	}
+	if resp.Warning != "" {
+		t.Errorf("Unexpected warning: %s", resp.Warning)
+	}
+
+	// Authors are looked up once each, in a single batch
+	if len(*calls) != 1 || len((*calls)[0]) != 2 {
+		t.Errorf("getProfiles calls = %v, want one call for the two authors", *calls)
+	}
+
+	// Profiles are cached, and the cached feed itself is left without them
+	if _, err := AnalyzeFeed(config.Config{}, map[string]interface{}{
+		"hashtag":              "profiletest",
+		"limit":                float64(10),
+		"includeAuthorProfile": true,
+	}); err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("Expected cached profiles on the second request, got %d calls", len(*calls))
+	}
+	cached, _ := feedCache.Get(generateCacheKey("profiletest", 10, defaultItemFilter))
+	if cached.(models.FeedResponse).Posts[0].AuthorProfile != nil {
+		t.Error("Author profiles were stored in the feed cache")
+	}
+}
+
+func TestAnalyzeFeedWithoutAuthorProfile(t *testing.T) {
+	calls := stubProfiles(t, nil)
+	seedFeed(t, "noprofiletest", []models.Post{
+		{ID: "1", Author: "alice.bsky.social", AuthorDID: "did:plc:alice"},
+	})
+
+	result, err := AnalyzeFeed(config.Config{}, map[string]interface{}{
+		"hashtag": "noprofiletest",
+		"limit":   float64(10),
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if result.(models.FeedResponse).Posts[0].AuthorProfile != nil {
+		t.Error("Author profile attached without includeAuthorProfile")
+	}
+	if len(*calls) != 0 {
+		t.Errorf("Expected no profile calls, got %d", len(*calls))
+	}
+}
+
+func TestLoadAuthorProfilesBatches(t *testing.T) {
+	calls := stubProfiles(t, nil)
+
+	var posts []models.Post
+	for i := 0; i < MaxProfileLookups+20; i++ {
+		posts = append(posts, models.Post{AuthorDID: "did:plc:author" + strings.Repeat("x", i)})
+	}
+	if _, err := loadAuthorProfiles(config.Config{}, posts); err != nil {
+		t.Fatalf("loadAuthorProfiles() unexpected error: %v", err)
+	}
+
+	looked := 0
+	for _, actors := range *calls {
+		if len(actors) > profileBatchSize {
+			t.Errorf("Batch of %d actors exceeds %d", len(actors), profileBatchSize)
+		}
+		looked += len(actors)
+	}
+	if looked != MaxProfileLookups {
+		t.Errorf("Looked up %d authors, want %d", looked, MaxProfileLookups)
+	}
+}
+
+func TestWithAuthorProfilesFailure(t *testing.T) {
+	stubProfiles(t, nil)
+	getProfiles = func(cfg config.Config, actors []string) ([]byte, error) {
+		return nil, errors.New("upstream unavailable")
+	}
+
+	result := withAuthorProfiles(config.Config{}, models.FeedResponse{
+		Posts: []models.Post{{ID: "1", AuthorDID: "did:plc:alice"}},
+		Count: 1,
+	})
+	resp := result.(models.FeedResponse)
+	if resp.Posts[0].AuthorProfile != nil {
+		t.Error("Expected no profile when the lookup fails")
+	}
+	if !strings.Contains(resp.Warning, "Author profiles may be incomplete") {
+		t.Errorf("Warning = %q, want a profile warning", resp.Warning)
+	}
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Quote lookup limits
+const (
+	DefaultQuotesLimit = 50
+	MaxQuotesLimit     = 100 // The API maximum per page
+)
+
+// QuotesResult is a page of the posts quoting a post, analyzed like a feed
+type QuotesResult struct {
+	URI    string        `json:"uri"`           // The quoted post
+	CID    string        `json:"cid,omitempty"` // The quoted version, if one was given
+	Posts  []models.Post `json:"posts"`
+	Cursor string        `json:"cursor,omitempty"` // Empty when there are no more quotes
+}
+
+// GetQuotes returns a page of the posts quoting the post at uri, each analyzed
+// as in a feed analysis. A cid limits the quotes to that version of the post.
+// A limit of 0 uses DefaultQuotesLimit; pass the returned cursor to fetch the
+// next page.
+func GetQuotes(cfg config.Config, uri, cid string, limit int, cursor string) (*QuotesResult, error) {
+	if err := validatePostURI(uri); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultQuotesLimit
+	}
+	if limit < 1 || limit > MaxQuotesLimit {
+		return nil, fmt.Errorf("invalid parameter: limit must be between 1 and %d", MaxQuotesLimit)
+	}
+
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, FetchError{Message: "Authentication error", Cause: err, Retryable: true}
+	}
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+
+	return getQuotes(client, uri, cid, limit, cursor)
+}
+
+// getQuotes fetches one page of quotes and analyzes the quoting posts
+func getQuotes(client BlueskyAPIClient, uri, cid string, limit int, cursor string) (*QuotesResult, error) {
+	query := url.Values{}
+	query.Set("uri", uri)
+	if cid != "" {
+		query.Set("cid", cid)
+	}
+	query.Set("limit", fmt.Sprintf("%d", limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	data, err := client.Get("app.bsky.feed.getQuotes", query)
+	if err != nil {
+		return nil, FetchError{
+			Message:   "app.bsky.feed.getQuotes API request failed",
+			Cause:     err,
+			Retryable: isRetryableError(err),
+		}
+	}
+
+	var page struct {
+		Cursor string `json:"cursor"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("error parsing quotes response: %w", err)
+	}
+
+	// Quotes come as post views, the shape of a search response
+	items, err := parseFeedItems(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing quotes response: %w", err)
+	}
+
+	return &QuotesResult{
+		URI:    uri,
+		CID:    cid,
+		Posts:  processItems(items, "", len(items), "", 0, 0),
+		Cursor: page.Cursor,
+	}, nil
+}
+
+// validatePostURI checks that uri refers to a post record
+func validatePostURI(uri string) error {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if !strings.HasPrefix(uri, "at://") || len(parts) != 3 || parts[0] == "" || parts[1] != "app.bsky.feed.post" || parts[2] == "" {
+		return fmt.Errorf("invalid parameter: uri must be a post URI (at://<did>/app.bsky.feed.post/<rkey>)")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// quotesFixture is a getQuotes response with two quoting posts and a next page
+const quotesFixture = `{
+	"uri": "at://did:plc:alice/app.bsky.feed.post/3kabc",
+	"cursor": "page2",
+	"posts": [
+		{"uri": "at://did:plc:bob/app.bsky.feed.post/1", "cid": "cid1",
+		 "record": {"text": "This is a great idea!", "createdAt": "2025-04-04T10:00:00Z"},
+		 "author": {"did": "did:plc:bob", "handle": "bob.bsky.social"}},
+		{"uri": "at://did:plc:carol/app.bsky.feed.post/2", "cid": "cid2",
+		 "record": {"text": "Terrible take, honestly", "createdAt": "2025-04-04T11:00:00Z"},
+		 "author": {"did": "did:plc:carol", "handle": "carol.bsky.social"}}
+	]
+}`
+
+func TestGetQuotes(t *testing.T) {
+	client := &mockClient{mockResponse: []byte(quotesFixture)}
+
+	result, err := getQuotes(client, "at://did:plc:alice/app.bsky.feed.post/3kabc", "bafyquoted", 25, "")
+	if err != nil {
+		t.Fatalf("getQuotes() unexpected error: %v", err)
+	}
+	if client.LastEndpoint != "app.bsky.feed.getQuotes" {
+		t.Errorf("Endpoint = %q, want app.bsky.feed.getQuotes", client.LastEndpoint)
+	}
+	if client.LastQueryParams["uri"] != "at://did:plc:alice/app.bsky.feed.post/3kabc" ||
+		client.LastQueryParams["cid"] != "bafyquoted" || client.LastQueryParams["limit"] != "25" {
+		t.Errorf("Query = %v", client.LastQueryParams)
+	}
+	if _, ok := client.LastQueryParams["cursor"]; ok {
+		t.Error("Expected no cursor on the first page")
+	}
+
+	if result.Cursor != "page2" {
+		t.Errorf("Cursor = %q, want page2", result.Cursor)
+	}
+	if len(result.Posts) != 2 {
+		t.Fatalf("Got %d posts, want 2", len(result.Posts))
+	}
+	for _, post := range result.Posts {
+		if post.Author == "" || post.AuthorDID == "" || post.Analysis["sentiment"] == "" {
+			t.Errorf("Expected an analyzed post with its author, got %+v", post)
+		}
+	}
+
+	// The cursor is passed on, and the last page has none
+	client.mockResponse = []byte(`{"uri": "at://did:plc:alice/app.bsky.feed.post/3kabc", "posts": []}`)
+	result, err = getQuotes(client, "at://did:plc:alice/app.bsky.feed.post/3kabc", "", 25, "page2")
+	if err != nil {
+		t.Fatalf("getQuotes() unexpected error: %v", err)
+	}
+	if client.LastQueryParams["cursor"] != "page2" {
+		t.Errorf("Cursor param = %q, want page2", client.LastQueryParams["cursor"])
+	}
+	if _, ok := client.LastQueryParams["cid"]; ok {
+		t.Error("Expected no cid when none was given")
+	}
+	if result.Cursor != "" || len(result.Posts) != 0 {
+		t.Errorf("Last page = %+v, want no posts and no cursor", result)
+	}
+}
+
+func TestGetQuotesRequestFailure(t *testing.T) {
+	client := &mockClient{mockError: errors.New("status 502")}
+
+	_, err := getQuotes(client, "at://did:plc:alice/app.bsky.feed.post/3kabc", "", 25, "")
+	var fetchErr FetchError
+	if !errors.As(err, &fetchErr) || !fetchErr.Retryable {
+		t.Errorf("Expected a retryable FetchError, got %v", err)
+	}
+}
+
+func TestGetQuotesValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		limit   int
+		wantErr string
+	}{
+		{name: "Missing URI", uri: "", wantErr: "uri must be a post URI"},
+		{name: "Web URL", uri: "https://bsky.app/profile/alice/post/3kabc", wantErr: "uri must be a post URI"},
+		{name: "Not a post", uri: "at://did:plc:alice/app.bsky.graph.list/3kabc", wantErr: "uri must be a post URI"},
+		{name: "Limit too large", uri: "at://did:plc:alice/app.bsky.feed.post/3kabc", limit: 101, wantErr: "limit must be between 1 and 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetQuotes(config.Config{}, tt.uri, "", tt.limit, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package feed
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+// SentenceSentiment is the sentiment of one sentence of a post
+type SentenceSentiment struct {
+	Text  string  `json:"text"`
+	Label string  `json:"label"`
+	Score float64 `json:"score"` // -1 (negative) to 1 (positive)
+}
+
+// sentenceSentiments scores each sentence of text with the shared lexicon
+func sentenceSentiments(text string) []SentenceSentiment {
+	lexicon := getSentimentLexicon()
+	sentences := splitSentences(text)
+	results := make([]SentenceSentiment, 0, len(sentences))
+	for _, sentence := range sentences {
+		sentiment := lexicon.Analyze(sentence)
+		results = append(results, SentenceSentiment{
+			Text:  sentence,
+			Label: sentiment.Label,
+			Score: sentiment.Score,
+		})
+	}
+	return results
+}
+
+// withSentenceSentiment returns a copy of a feed response with each post's
+// per-sentence sentiment added to its analysis under "sentences", as a JSON array
+func withSentenceSentiment(result interface{}) interface{} {
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok {
+		return result
+	}
+
+	// Copy the posts and their analyses so the cached response is left unchanged
+	feedResp.Posts = append([]models.Post(nil), feedResp.Posts...)
+	for i, post := range feedResp.Posts {
+		encoded, err := json.Marshal(sentenceSentiments(post.Text))
+		if err != nil {
+			continue
+		}
+		analysis := make(map[string]string, len(post.Analysis)+1)
+		for k, v := range post.Analysis {
+			analysis[k] = v
+		}
+		analysis["sentences"] = string(encoded)
+		feedResp.Posts[i].Analysis = analysis
+	}
+	return feedResp
+}
+
+// splitSentences splits social text into sentences. A sentence ends at a line
+// break, or at a run of terminal punctuation such as "!?" or "..." followed by
+// a space. Closing quotes and emoji right after the punctuation stay with the
+// sentence they end, so "Great! 🎉 Next" is "Great! 🎉" and "Next". Punctuation
+// inside a word, as in "1.5" or "example.com", does not end a sentence.
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+
+	emit := func(end int) {
+		if sentence := strings.TrimSpace(string(runes[start:end])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+	}
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\n' {
+			emit(i + 1)
+			continue
+		}
+		if !isSentenceTerminator(runes[i]) {
+			continue
+		}
+
+		// Take the whole run of punctuation and closing quotes
+		end := i + 1
+		for end < len(runes) && (isSentenceTerminator(runes[end]) || isClosingQuote(runes[end])) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			i = end - 1
+			continue
+		}
+
+		// Keep emoji that follow the punctuation, on the same line, with it
+		next := end
+		for next < len(runes) && runes[next] != '\n' && unicode.IsSpace(runes[next]) {
+			next++
+		}
+		if next < len(runes) && isEmoji(runes[next]) {
+			for next < len(runes) && (isEmoji(runes[next]) || (runes[next] != '\n' && unicode.IsSpace(runes[next]))) {
+				next++
+			}
+			end = next
+		}
+
+		emit(end)
+		i = end - 1
+	}
+	emit(len(runes))
+	return sentences
+}
+
+// isSentenceTerminator reports whether r ends a sentence
+func isSentenceTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+// isClosingQuote reports whether r closes a quotation or parenthesis
+func isClosingQuote(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '”', '’', '»', '」':
+		return true
+	}
+	return false
+}
+
+// isEmoji reports whether r is part of an emoji: a symbol, a modifier such
+// as a skin tone, a variation selector or a zero width joiner
+func isEmoji(r rune) bool {
+	return unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) || r == '\uFE0F' || r == '\u200D'
+}
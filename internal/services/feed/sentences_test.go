@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "Punctuation", text: "First one. Second one! Third?", want: []string{"First one.", "Second one!", "Third?"}},
+		{name: "Ellipsis and runs", text: "Well... I don't know?! Maybe…  later", want: []string{"Well...", "I don't know?!", "Maybe…", "later"}},
+		{name: "Emoji stay with their sentence", text: "Great news! 🎉🎉 Shipping today 👍🏽", want: []string{"Great news! 🎉🎉", "Shipping today 👍🏽"}},
+		{name: "Line breaks", text: "headline\nbody text\n\n#golang", want: []string{"headline", "body text", "#golang"}},
+		{name: "Closing quotes", text: `She said "wow." Then left.`, want: []string{`She said "wow."`, "Then left."}},
+		{name: "Punctuation inside words", text: "Go 1.22 is on go.dev now", want: []string{"Go 1.22 is on go.dev now"}},
+		{name: "Empty", text: "   ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSentenceSentiment(t *testing.T) {
+	text := "I love the new release! The docs are terrible though... Anyway, shipping it 🚀"
+	cached := models.FeedResponse{Posts: []models.Post{AnalyzeText(text)}}
+
+	result := withSentenceSentiment(cached).(models.FeedResponse)
+
+	var sentences []SentenceSentiment
+	if err := json.Unmarshal([]byte(result.Posts[0].Analysis["sentences"]), &sentences); err != nil {
+		t.Fatalf("sentences is not a JSON array: %v", err)
+	}
+	wantLabels := []string{"positive", "negative", "neutral"}
+	if len(sentences) != len(wantLabels) {
+		t.Fatalf("Got %d sentences, want %d: %+v", len(sentences), len(wantLabels), sentences)
+	}
+	for i, sentence := range sentences {
+		if sentence.Label != wantLabels[i] {
+			t.Errorf("Sentence %d %q labeled %s, want %s", i, sentence.Text, sentence.Label, wantLabels[i])
+		}
+	}
+	if sentences[2].Text != "Anyway, shipping it 🚀" {
+		t.Errorf("Last sentence = %q", sentences[2].Text)
+	}
+
+	// The overall label is kept, and the cached post is left unchanged
+	if result.Posts[0].Analysis["sentiment"] == "" {
+		t.Error("Expected the overall sentiment to be kept")
+	}
+	if _, ok := cached.Posts[0].Analysis["sentences"]; ok {
+		t.Error("The cached post's analysis was modified")
+	}
+}
@@ -0,0 +1,87 @@
+package feed
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// SentimentLexicon defines the words and thresholds used for sentiment analysis
+type SentimentLexicon struct {
+	PositiveWords []string `json:"positive_words"`
+	NegativeWords []string `json:"negative_words"`
+	// NeutralBand is the minimum absolute score needed for a positive or
+	// negative label; weaker signals are reported as neutral
+	NeutralBand float64 `json:"neutral_band"`
+}
+
+// DefaultSentimentLexicon contains the default words and neutral band
+var DefaultSentimentLexicon = SentimentLexicon{
+	PositiveWords: []string{"good", "great", "happy", "excited", "love", "awesome"},
+	NegativeWords: []string{"bad", "sad", "angry", "hate", "terrible", "awful"},
+	NeutralBand:   0.2,
+}
+
+// SentimentResult is the outcome of analyzing a single text
+type SentimentResult struct {
+	Label      string  `json:"label"`
+	Score      float64 `json:"score"`      // -1 (negative) to 1 (positive)
+	Confidence float64 `json:"confidence"` // 0 to 1, confidence in Label
+}
+
+// Analyze scores text against the lexicon and labels it positive, negative or neutral
+func (l SentimentLexicon) Analyze(text string) SentimentResult {
+	text = strings.ToLower(text)
+
+	var positiveCount, negativeCount int
+
+	for _, word := range l.PositiveWords {
+		if strings.Contains(text, word) {
+			positiveCount++
+		}
+	}
+
+	for _, word := range l.NegativeWords {
+		if strings.Contains(text, word) {
+			negativeCount++
+		}
+	}
+
+	// The extra 1 in the denominator damps scores built from few matches,
+	// so a single word never yields full confidence
+	score := float64(positiveCount-negativeCount) / float64(positiveCount+negativeCount+1)
+	strength := math.Abs(score)
+
+	if score == 0 || strength < l.NeutralBand {
+		return SentimentResult{Label: "neutral", Score: score, Confidence: 1 - strength}
+	}
+	if score > 0 {
+		return SentimentResult{Label: "positive", Score: score, Confidence: strength}
+	}
+	return SentimentResult{Label: "negative", Score: score, Confidence: strength}
+}
+
+// Shared lexicon used by feed analysis
+var (
+	sentimentLexicon   = DefaultSentimentLexicon
+	sentimentLexiconMu sync.RWMutex
+)
+
+// SetSentimentLexicon replaces the lexicon used by feed analysis
+func SetSentimentLexicon(lexicon SentimentLexicon) {
+	sentimentLexiconMu.Lock()
+	defer sentimentLexiconMu.Unlock()
+	sentimentLexicon = lexicon
+}
+
+// getSentimentLexicon returns the lexicon used by feed analysis
+func getSentimentLexicon() SentimentLexicon {
+	sentimentLexiconMu.RLock()
+	defer sentimentLexiconMu.RUnlock()
+	return sentimentLexicon
+}
+
+// analyzeSentiment performs basic sentiment analysis
+func analyzeSentiment(text string) string {
+	return getSentimentLexicon().Analyze(text).Label
+}
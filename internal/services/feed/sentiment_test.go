@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"testing"
+)
+
+func TestSentimentNeutralBand(t *testing.T) {
+	// Three positive words against two negative ones: a one-word margin
+	text := "A good and great day, awesome, despite the bad and terrible traffic"
+
+	banded := DefaultSentimentLexicon
+	banded.NeutralBand = 0.25
+	result := banded.Analyze(text)
+	if result.Label != "neutral" {
+		t.Errorf("Analyze() with band %.2f = %q (score %.2f), want neutral", banded.NeutralBand, result.Label, result.Score)
+	}
+
+	unbanded := DefaultSentimentLexicon
+	unbanded.NeutralBand = 0
+	result = unbanded.Analyze(text)
+	if result.Label != "positive" {
+		t.Errorf("Analyze() with no band = %q (score %.2f), want positive", result.Label, result.Score)
+	}
+}
+
+func TestSentimentConfidence(t *testing.T) {
+	lexicon := DefaultSentimentLexicon
+
+	weak := lexicon.Analyze("This is good")
+	strong := lexicon.Analyze("Good, great and awesome, I love it")
+	if weak.Label != "positive" || strong.Label != "positive" {
+		t.Fatalf("Expected positive labels, got %q and %q", weak.Label, strong.Label)
+	}
+	if weak.Confidence >= strong.Confidence {
+		t.Errorf("Confidence for one word (%.2f) should be lower than for several (%.2f)", weak.Confidence, strong.Confidence)
+	}
+	if strong.Confidence > 1 {
+		t.Errorf("Confidence = %.2f, want at most 1", strong.Confidence)
+	}
+
+	// Text without sentiment words is confidently neutral
+	none := lexicon.Analyze("Just sharing some information")
+	if none.Label != "neutral" || none.Confidence != 1 {
+		t.Errorf("Analyze() = %+v, want neutral with confidence 1", none)
+	}
+}
+
+func TestSetSentimentLexicon(t *testing.T) {
+	defer SetSentimentLexicon(DefaultSentimentLexicon)
+
+	SetSentimentLexicon(SentimentLexicon{
+		PositiveWords: []string{"sunny"},
+		NegativeWords: []string{"rainy"},
+	})
+
+	if got := analyzeSentiment("A sunny afternoon"); got != "positive" {
+		t.Errorf("analyzeSentiment() = %q, want positive", got)
+	}
+	if got := analyzeSentiment("I am happy"); got != "neutral" {
+		t.Errorf("analyzeSentiment() = %q, want neutral for words outside the lexicon", got)
+	}
+}
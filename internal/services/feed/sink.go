@@ -0,0 +1,190 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+)
+
+// PostSink receives analyzed posts as feed analyses produce them, such as
+// for an ETL pipeline. Emit may be called from several goroutines at once.
+type PostSink interface {
+	Emit(ctx context.Context, post models.Post) error
+}
+
+// WriterSink writes each post to a writer as one line of JSON (NDJSON)
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing to w, such as os.Stdout
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Emit writes post as a line of JSON
+func (s *WriterSink) Emit(ctx context.Context, post models.Post) error {
+	line, err := json.Marshal(post)
+	if err != nil {
+		return fmt.Errorf("error encoding post: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// FileSink appends posts to a file as NDJSON
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{WriterSink: NewWriterSink(file), file: file}, nil
+}
+
+// Close closes the file; posts emitted afterwards fail
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// HTTPSink posts each post as a JSON body to an endpoint. Any status other
+// than 2xx is an error; failed posts are not retried.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// DefaultHTTPSinkTimeout bounds each post sent to an HTTPSink
+const DefaultHTTPSinkTimeout = 5 * time.Second
+
+// NewHTTPSink creates a sink posting to url with DefaultHTTPSinkTimeout
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{URL: url, Client: &http.Client{Timeout: DefaultHTTPSinkTimeout}}
+}
+
+// Emit posts post to the endpoint
+func (s *HTTPSink) Emit(ctx context.Context, post models.Post) error {
+	body, err := json.Marshal(post)
+	if err != nil {
+		return fmt.Errorf("error encoding post: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("post sink returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PostSinkFromEnv builds the sink named by BSKY_POST_SINK: "stdout", an http
+// or https URL, or the path of a file to append to. It returns nil when the
+// variable is unset. Only the server reads BSKY_POST_SINK: "stdout" shares
+// standard output with everything else printed there, such as the server's
+// request log, and must not be used by the CLI, whose results go to stdout.
+func PostSinkFromEnv() (PostSink, error) {
+	target := os.Getenv("BSKY_POST_SINK")
+	switch {
+	case target == "":
+		return nil, nil
+	case target == "stdout":
+		return NewWriterSink(os.Stdout), nil
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return NewHTTPSink(target), nil
+	default:
+		return NewFileSink(target)
+	}
+}
+
+// Shared post sink, nil when analyzed posts are not emitted
+var (
+	postSink   PostSink
+	postSinkMu sync.RWMutex
+)
+
+// SetPostSink configures the sink that receives every post feed-analysis
+// fetches and analyzes; nil stops emitting. Posts served from the cache were
+// emitted when they were analyzed and are not sent again.
+func SetPostSink(sink PostSink) {
+	postSinkMu.Lock()
+	defer postSinkMu.Unlock()
+	postSink = sink
+}
+
+// getPostSink returns the current post sink
+func getPostSink() PostSink {
+	postSinkMu.RLock()
+	defer postSinkMu.RUnlock()
+	return postSink
+}
+
+// ClosePostSink closes the post sink, if it needs closing, and stops emitting
+func ClosePostSink() error {
+	postSinkMu.Lock()
+	defer postSinkMu.Unlock()
+	sink := postSink
+	postSink = nil
+	if closer, ok := sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// postSinkTimeout bounds emitting the posts of one analysis
+const postSinkTimeout = 30 * time.Second
+
+// emitPosts sends the posts of a freshly analyzed feed to the post sink in
+// the background, in order, so the request is never delayed. Fallback data is
+// not real analysis and is not emitted. The first failure ends the batch.
+func emitPosts(result interface{}) {
+	sink := getPostSink()
+	if sink == nil {
+		return
+	}
+	feedResp, ok := result.(models.FeedResponse)
+	if !ok || feedResp.Source == "fallback" || len(feedResp.Posts) == 0 {
+		return
+	}
+
+	posts := feedResp.Posts
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), postSinkTimeout)
+		defer cancel()
+		for i, post := range posts {
+			if err := sink.Emit(ctx, post); err != nil {
+				log.Printf("Warning: Post sink failed, %d of %d analyzed posts not emitted: %v", len(posts)-i, len(posts), err)
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,200 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/models"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// capturingSink sends every emitted post to a channel
+type capturingSink struct {
+	posts chan models.Post
+}
+
+func (s *capturingSink) Emit(ctx context.Context, post models.Post) error {
+	s.posts <- post
+	return nil
+}
+
+// receivePosts waits for n posts from the sink
+func (s *capturingSink) receivePosts(t *testing.T, n int) []models.Post {
+	t.Helper()
+	var posts []models.Post
+	for len(posts) < n {
+		select {
+		case post := <-s.posts:
+			posts = append(posts, post)
+		case <-time.After(time.Second):
+			t.Fatalf("Got %d emitted posts, want %d", len(posts), n)
+		}
+	}
+	return posts
+}
+
+func TestAnalyzeFeedEmitsPosts(t *testing.T) {
+	sink := &capturingSink{posts: make(chan models.Post, 10)}
+	SetPostSink(sink)
+	t.Cleanup(func() { SetPostSink(nil) })
+
+	var fetchErr error
+	stubFeedResponse(t, models.FeedResponse{
+		Posts: []models.Post{
+			{ID: "post1", Text: "First #sinktest post"},
+			{ID: "post2", Text: "Second #sinktest post"},
+			{ID: "post3", Text: "Third #sinktest post"},
+		},
+		Count:  3,
+		Source: "api_fresh",
+	}, &fetchErr)
+	params := func() map[string]interface{} {
+		return map[string]interface{}{"hashtag": "sinktest", "limit": float64(3), "bypassCache": true}
+	}
+
+	result, err := AnalyzeFeed(config.Config{}, params())
+	if err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	if feedResp := result.(models.FeedResponse); len(feedResp.Posts) != 3 {
+		t.Errorf("AnalyzeFeed() returned %d posts, want them all as well as emitted", len(feedResp.Posts))
+	}
+
+	// Every analyzed post is emitted, in order
+	posts := sink.receivePosts(t, 3)
+	for i, want := range []string{"post1", "post2", "post3"} {
+		if posts[i].ID != want {
+			t.Errorf("Emitted post %d = %s, want %s", i, posts[i].ID, want)
+		}
+	}
+
+	// A cached result was emitted when it was analyzed
+	withCache := params()
+	delete(withCache, "bypassCache")
+	if _, err := AnalyzeFeed(config.Config{}, withCache); err != nil {
+		t.Fatalf("AnalyzeFeed() unexpected error: %v", err)
+	}
+	select {
+	case post := <-sink.posts:
+		t.Errorf("Cached post %s was emitted again", post.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.ndjson")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink() unexpected error: %v", err)
+	}
+	for _, id := range []string{"post1", "post2"} {
+		if err := sink.Emit(context.Background(), models.Post{ID: id, Text: "Text of " + id}); err != nil {
+			t.Fatalf("Emit() unexpected error: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	// One JSON post per line
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Opening the sink file: %v", err)
+	}
+	defer file.Close()
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var post models.Post
+		if err := json.Unmarshal(scanner.Bytes(), &post); err != nil {
+			t.Fatalf("Line %q is not a post: %v", scanner.Text(), err)
+		}
+		ids = append(ids, post.ID)
+	}
+	if len(ids) != 2 || ids[0] != "post1" || ids[1] != "post2" {
+		t.Errorf("File holds posts %v, want post1 and post2", ids)
+	}
+
+	if err := sink.Emit(context.Background(), models.Post{ID: "late"}); err == nil {
+		t.Error("Expected Emit() after Close() to fail")
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	received := make(chan models.Post, 1)
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var post models.Post
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Got %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
+			t.Errorf("Decoding the posted body: %v", err)
+		}
+		received <- post
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	if err := sink.Emit(context.Background(), models.Post{ID: "post1", Text: "Hello"}); err != nil {
+		t.Fatalf("Emit() unexpected error: %v", err)
+	}
+	if post := <-received; post.ID != "post1" || post.Text != "Hello" {
+		t.Errorf("Endpoint received %+v, want post1", post)
+	}
+
+	// A rejection is reported
+	status = http.StatusBadRequest
+	err := sink.Emit(context.Background(), models.Post{ID: "post2"})
+	<-received
+	if err == nil {
+		t.Error("Expected an error for a 400 response")
+	}
+}
+
+func TestPostSinkFromEnv(t *testing.T) {
+	t.Setenv("BSKY_POST_SINK", "")
+	if sink, err := PostSinkFromEnv(); sink != nil || err != nil {
+		t.Errorf("PostSinkFromEnv() unset = %v, %v, want no sink", sink, err)
+	}
+
+	t.Setenv("BSKY_POST_SINK", "stdout")
+	if sink, _ := PostSinkFromEnv(); sink == nil {
+		t.Error("Expected a stdout sink")
+	} else if _, ok := sink.(*WriterSink); !ok {
+		t.Errorf("PostSinkFromEnv() stdout = %T, want *WriterSink", sink)
+	}
+
+	t.Setenv("BSKY_POST_SINK", "https://example.com/posts")
+	if sink, _ := PostSinkFromEnv(); sink == nil {
+		t.Error("Expected an HTTP sink")
+	} else if httpSink, ok := sink.(*HTTPSink); !ok || httpSink.URL != "https://example.com/posts" {
+		t.Errorf("PostSinkFromEnv() URL = %#v, want an HTTPSink for the URL", sink)
+	}
+
+	path := filepath.Join(t.TempDir(), "posts.ndjson")
+	t.Setenv("BSKY_POST_SINK", path)
+	sink, err := PostSinkFromEnv()
+	if err != nil {
+		t.Fatalf("PostSinkFromEnv() file unexpected error: %v", err)
+	}
+	fileSink, ok := sink.(*FileSink)
+	if !ok {
+		t.Fatalf("PostSinkFromEnv() file = %T, want *FileSink", sink)
+	}
+	fileSink.Close()
+
+	t.Setenv("BSKY_POST_SINK", filepath.Join(t.TempDir(), "missing", "posts.ndjson"))
+	if _, err := PostSinkFromEnv(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("PostSinkFromEnv() in a missing directory error = %v, want not exist", err)
+	}
+}
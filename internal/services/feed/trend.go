@@ -0,0 +1,257 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Sentiment trend limits
+const (
+	DefaultTrendBuckets = 12
+	DefaultTrendWindow  = 24 * time.Hour
+	MaxTrendBuckets     = 48
+	MaxTrendWindow      = 7 * 24 * time.Hour
+	MinTrendBucketWidth = time.Second // Shortest window per bucket
+	MaxTrendPages       = 10  // Search pages read per trend; older posts are left out past this
+	trendPageSize       = 100 // Posts per search page, the API maximum
+	trendFetchTimeout   = 20 * time.Second
+)
+
+// SentimentBucket aggregates the sentiment of the posts created in one time slot.
+// Buckets without posts have a zero Count and no AverageScore or Sentiment.
+type SentimentBucket struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Count        int       `json:"count"`
+	Positive     int       `json:"positive"`
+	Negative     int       `json:"negative"`
+	Neutral      int       `json:"neutral"`
+	AverageScore *float64  `json:"average_score,omitempty"` // -1 (negative) to 1 (positive)
+	Sentiment    string    `json:"sentiment,omitempty"`     // Label of the average score
+}
+
+// SentimentTrend is the sentiment of a hashtag over time, oldest bucket first
+type SentimentTrend struct {
+	Hashtag string            `json:"hashtag"`
+	Start   time.Time         `json:"start"`
+	End     time.Time         `json:"end"`
+	Total   int               `json:"total"` // Posts counted across all buckets
+	Buckets []SentimentBucket `json:"buckets"`
+	Warning string            `json:"warning,omitempty"`
+}
+
+// trendPost is a post's creation time and text, all a trend needs
+type trendPost struct {
+	CreatedAt time.Time
+	Text      string
+}
+
+// SentimentTimeline pages through the posts with hashtag created within window
+// and splits the window into buckets of equal length, aggregating the sentiment
+// of the posts in each. Every bucket is returned, including those without posts.
+func SentimentTimeline(cfg config.Config, hashtag string, buckets int, window time.Duration) (*SentimentTrend, error) {
+	hashtag, err := normalizeHashtag(hashtag)
+	if err != nil {
+		return nil, err
+	}
+	if hashtag == "" {
+		return nil, fmt.Errorf("invalid parameter: hashtag is required")
+	}
+	if err := cfg.Limits.CheckLength(config.FieldHashtag, hashtag); err != nil {
+		return nil, err
+	}
+	if buckets < 1 || buckets > MaxTrendBuckets {
+		return nil, fmt.Errorf("invalid parameter: buckets must be between 1 and %d", MaxTrendBuckets)
+	}
+	if window <= 0 || window > MaxTrendWindow {
+		return nil, fmt.Errorf("invalid parameter: window must be positive and at most %s", MaxTrendWindow)
+	}
+	if window < time.Duration(buckets)*MinTrendBucketWidth {
+		return nil, fmt.Errorf("invalid parameter: window must be at least %s per bucket", MinTrendBucketWidth)
+	}
+
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, FetchError{Message: "Authentication error", Cause: err, Retryable: true}
+	}
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), trendFetchTimeout)
+	defer cancel()
+
+	end := time.Now().UTC()
+	return sentimentTimeline(ctx, client, hashtag, buckets, end.Add(-window), end, cfg.Limits.MaxAnalyzedPosts())
+}
+
+// sentimentTimeline fetches up to maxPosts posts between start and end and buckets them
+func sentimentTimeline(ctx context.Context, client BlueskyAPIClient, hashtag string, buckets int, start, end time.Time, maxPosts int) (*SentimentTrend, error) {
+	posts, complete, err := fetchPostsSince(ctx, client, hashtag, start, end, maxPosts)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := aggregateSentiment(posts, buckets, start, end)
+	trend.Hashtag = hashtag
+	if !complete {
+		trend.Warning = fmt.Sprintf("Only the latest %d posts were analyzed; earlier buckets may be undercounted", len(posts))
+	}
+	return trend, nil
+}
+
+// fetchPostsSince pages through the newest posts with hashtag until it reaches
+// posts older than start, returning those created between start and end. complete
+// is false if MaxTrendPages were read, or maxPosts posts gathered, before reaching start.
+func fetchPostsSince(ctx context.Context, client BlueskyAPIClient, hashtag string, start, end time.Time, maxPosts int) ([]trendPost, bool, error) {
+	var posts []trendPost
+	cursor := ""
+
+	for page := 0; page < MaxTrendPages; page++ {
+		query := url.Values{}
+		query.Set("q", "#"+hashtag)
+		query.Set("sort", "latest")
+		query.Set("since", start.Format(time.RFC3339))
+		query.Set("until", end.Format(time.RFC3339))
+		query.Set("limit", fmt.Sprintf("%d", trendPageSize))
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		type pageResult struct {
+			data []byte
+			err  error
+		}
+		resultCh := make(chan pageResult, 1)
+		go func() {
+			data, err := client.Get("app.bsky.feed.searchPosts", query)
+			resultCh <- pageResult{data, err}
+		}()
+
+		var data []byte
+		select {
+		case <-ctx.Done():
+			return nil, false, FetchError{Message: "Feed fetch timed out", Cause: ctx.Err(), Retryable: true}
+		case result := <-resultCh:
+			if result.err != nil {
+				return nil, false, FetchError{
+					Message:   "app.bsky.feed.searchPosts API request failed",
+					Cause:     result.err,
+					Retryable: isRetryableError(result.err),
+				}
+			}
+			data = result.data
+		}
+
+		var resp struct {
+			Cursor string `json:"cursor"`
+			Posts  []struct {
+				Record struct {
+					Text      string `json:"text"`
+					CreatedAt string `json:"createdAt"`
+				} `json:"record"`
+			} `json:"posts"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, false, FetchError{Message: "Invalid JSON response from API", Cause: err, Retryable: true}
+		}
+
+		reachedStart := false
+		for _, post := range resp.Posts {
+			createdAt, err := time.Parse(time.RFC3339Nano, post.Record.CreatedAt)
+			if err != nil {
+				continue // Posts without a usable timestamp cannot be placed in a bucket
+			}
+			if createdAt.Before(start) {
+				reachedStart = true
+				continue
+			}
+			if !createdAt.Before(end) {
+				continue
+			}
+			posts = append(posts, trendPost{CreatedAt: createdAt, Text: post.Record.Text})
+		}
+
+		// The server-wide ceiling stops paging however much of the window is left
+		if len(posts) >= maxPosts {
+			return posts[:maxPosts], false, nil
+		}
+
+		if reachedStart || resp.Cursor == "" || len(resp.Posts) == 0 {
+			return posts, true, nil
+		}
+		cursor = resp.Cursor
+	}
+
+	return posts, false, nil
+}
+
+// aggregateSentiment splits start to end into equal buckets and scores the posts in each
+func aggregateSentiment(posts []trendPost, buckets int, start, end time.Time) *SentimentTrend {
+	width := end.Sub(start) / time.Duration(buckets)
+	trend := &SentimentTrend{
+		Start:   start,
+		End:     end,
+		Buckets: make([]SentimentBucket, buckets),
+	}
+	for i := range trend.Buckets {
+		trend.Buckets[i].Start = start.Add(time.Duration(i) * width)
+		trend.Buckets[i].End = start.Add(time.Duration(i+1) * width)
+	}
+	trend.Buckets[buckets-1].End = end
+
+	lexicon := getSentimentLexicon()
+	scores := make([]float64, buckets)
+	for _, post := range posts {
+		if post.CreatedAt.Before(start) || !post.CreatedAt.Before(end) {
+			continue
+		}
+		i := int(post.CreatedAt.Sub(start) / width)
+		if i >= buckets {
+			i = buckets - 1 // Rounding leaves the last bucket slightly longer
+		}
+
+		sentiment := lexicon.Analyze(post.Text)
+		bucket := &trend.Buckets[i]
+		bucket.Count++
+		switch sentiment.Label {
+		case "positive":
+			bucket.Positive++
+		case "negative":
+			bucket.Negative++
+		default:
+			bucket.Neutral++
+		}
+		scores[i] += sentiment.Score
+		trend.Total++
+	}
+
+	for i := range trend.Buckets {
+		bucket := &trend.Buckets[i]
+		if bucket.Count == 0 {
+			continue
+		}
+		average := scores[i] / float64(bucket.Count)
+		bucket.AverageScore = &average
+		bucket.Sentiment = scoreLabel(average, lexicon.NeutralBand)
+	}
+
+	return trend
+}
+
+// scoreLabel labels a sentiment score the way SentimentLexicon.Analyze does
+func scoreLabel(score, neutralBand float64) string {
+	switch {
+	case score == 0 || (score < neutralBand && score > -neutralBand):
+		return "neutral"
+	case score > 0:
+		return "positive"
+	default:
+		return "negative"
+	}
+}
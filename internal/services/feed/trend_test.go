@@ -0,0 +1,200 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// pagingClient serves search results newest first, pageSize posts per page
+type pagingClient struct {
+	posts    []trendPost
+	pageSize int
+	queries  []url.Values
+}
+
+func (p *pagingClient) Get(endpoint string, query url.Values) ([]byte, error) {
+	p.queries = append(p.queries, query)
+
+	offset := 0
+	if cursor := query.Get("cursor"); cursor != "" {
+		fmt.Sscanf(cursor, "%d", &offset)
+	}
+	end := offset + p.pageSize
+	if end > len(p.posts) {
+		end = len(p.posts)
+	}
+
+	type record struct {
+		Text      string `json:"text"`
+		CreatedAt string `json:"createdAt"`
+	}
+	type post struct {
+		Record record `json:"record"`
+	}
+	resp := struct {
+		Cursor string `json:"cursor,omitempty"`
+		Posts  []post `json:"posts"`
+	}{Posts: []post{}}
+	for _, p := range p.posts[offset:end] {
+		resp.Posts = append(resp.Posts, post{Record: record{Text: p.Text, CreatedAt: p.CreatedAt.Format(time.RFC3339)}})
+	}
+	if end < len(p.posts) {
+		resp.Cursor = fmt.Sprintf("%d", end)
+	}
+	return json.Marshal(resp)
+}
+
+func (p *pagingClient) Post(endpoint string, body interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected post to %s", endpoint)
+}
+
+func (p *pagingClient) SetAuthToken(token string) {}
+
+func TestSentimentTimelineBuckets(t *testing.T) {
+	end := time.Date(2025, 4, 4, 12, 0, 0, 0, time.UTC)
+	start := end.Add(-4 * time.Hour)
+	at := func(hoursAgo float64) time.Time {
+		return end.Add(-time.Duration(hoursAgo * float64(time.Hour)))
+	}
+
+	// Newest first, as the search API returns them; the 2-3 hour bucket has no posts
+	client := &pagingClient{pageSize: 2, posts: []trendPost{
+		{CreatedAt: at(0.1), Text: "This is awful"},
+		{CreatedAt: at(0.5), Text: "terrible and bad"},
+		{CreatedAt: at(1.5), Text: "Just a post"},
+		{CreatedAt: at(3.2), Text: "I love this, great stuff"},
+		{CreatedAt: at(3.5), Text: "happy day"},
+		{CreatedAt: at(3.9), Text: "meh"},
+		{CreatedAt: at(5), Text: "Too old to count, and great"},
+	}}
+
+	trend, err := sentimentTimeline(context.Background(), client, "golang", 4, start, end, config.DefaultLimits.AnalyzedPosts)
+	if err != nil {
+		t.Fatalf("sentimentTimeline() unexpected error: %v", err)
+	}
+
+	if trend.Total != 6 {
+		t.Errorf("Total = %d, want 6 posts within the window", trend.Total)
+	}
+	if trend.Warning != "" {
+		t.Errorf("Unexpected warning: %s", trend.Warning)
+	}
+	if len(trend.Buckets) != 4 {
+		t.Fatalf("Expected 4 buckets, got %d", len(trend.Buckets))
+	}
+
+	tests := []struct {
+		count, positive, negative, neutral int
+		sentiment                          string
+	}{
+		{count: 3, positive: 2, neutral: 1, sentiment: "positive"},
+		{count: 0},
+		{count: 1, neutral: 1, sentiment: "neutral"},
+		{count: 2, negative: 2, sentiment: "negative"},
+	}
+	for i, want := range tests {
+		bucket := trend.Buckets[i]
+		if !bucket.Start.Equal(start.Add(time.Duration(i) * time.Hour)) {
+			t.Errorf("Bucket %d starts at %v, want %v", i, bucket.Start, start.Add(time.Duration(i)*time.Hour))
+		}
+		if bucket.Count != want.count || bucket.Positive != want.positive || bucket.Negative != want.negative || bucket.Neutral != want.neutral {
+			t.Errorf("Bucket %d = %d posts (%d+/%d-/%d=), want %d (%d+/%d-/%d=)", i,
+				bucket.Count, bucket.Positive, bucket.Negative, bucket.Neutral,
+				want.count, want.positive, want.negative, want.neutral)
+		}
+		if bucket.Sentiment != want.sentiment {
+			t.Errorf("Bucket %d sentiment = %q, want %q", i, bucket.Sentiment, want.sentiment)
+		}
+		if (bucket.AverageScore == nil) != (want.count == 0) {
+			t.Errorf("Bucket %d average score = %v, want one only when it has posts", i, bucket.AverageScore)
+		}
+	}
+
+	// Paging stops at the page reaching posts older than the window
+	if len(client.queries) != 4 {
+		t.Errorf("Expected 4 search pages, got %d", len(client.queries))
+	}
+	if q := client.queries[0]; q.Get("q") != "#golang" || q.Get("since") != start.Format(time.RFC3339) || q.Get("sort") != "latest" {
+		t.Errorf("Unexpected search query: %v", q)
+	}
+}
+
+func TestSentimentTimelinePageLimit(t *testing.T) {
+	end := time.Date(2025, 4, 4, 12, 0, 0, 0, time.UTC)
+	posts := make([]trendPost, MaxTrendPages*2+1)
+	for i := range posts {
+		posts[i] = trendPost{CreatedAt: end.Add(-time.Duration(i+1) * time.Minute), Text: "good"}
+	}
+	client := &pagingClient{pageSize: 2, posts: posts}
+
+	trend, err := sentimentTimeline(context.Background(), client, "golang", 2, end.Add(-24*time.Hour), end, config.DefaultLimits.AnalyzedPosts)
+	if err != nil {
+		t.Fatalf("sentimentTimeline() unexpected error: %v", err)
+	}
+	if len(client.queries) != MaxTrendPages {
+		t.Errorf("Expected %d search pages, got %d", MaxTrendPages, len(client.queries))
+	}
+	if trend.Total != MaxTrendPages*2 {
+		t.Errorf("Total = %d, want %d", trend.Total, MaxTrendPages*2)
+	}
+	if !strings.Contains(trend.Warning, "undercounted") {
+		t.Errorf("Warning = %q, want a note about the page limit", trend.Warning)
+	}
+}
+
+func TestSentimentTimelinePostCeiling(t *testing.T) {
+	end := time.Date(2025, 4, 4, 12, 0, 0, 0, time.UTC)
+	posts := make([]trendPost, 500)
+	for i := range posts {
+		posts[i] = trendPost{CreatedAt: end.Add(-time.Duration(i+1) * time.Second), Text: "good"}
+	}
+	client := &pagingClient{pageSize: 100, posts: posts}
+
+	trend, err := sentimentTimeline(context.Background(), client, "golang", 2, end.Add(-24*time.Hour), end, 250)
+	if err != nil {
+		t.Fatalf("sentimentTimeline() unexpected error: %v", err)
+	}
+	if trend.Total != 250 {
+		t.Errorf("Total = %d, want the ceiling of 250", trend.Total)
+	}
+	if len(client.queries) != 3 {
+		t.Errorf("Expected 3 search pages, got %d", len(client.queries))
+	}
+	if !strings.Contains(trend.Warning, "Only the latest 250 posts") {
+		t.Errorf("Warning = %q, want a note about the ceiling", trend.Warning)
+	}
+}
+
+func TestSentimentTimelineValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		hashtag string
+		buckets int
+		window  time.Duration
+		wantErr string
+	}{
+		{name: "Missing hashtag", hashtag: "#", buckets: 4, window: time.Hour, wantErr: "hashtag is required"},
+		{name: "Invalid hashtag", hashtag: "two words", buckets: 4, window: time.Hour, wantErr: "invalid hashtag"},
+		{name: "Zero buckets", hashtag: "go", buckets: 0, window: time.Hour, wantErr: "buckets must be between"},
+		{name: "Too many buckets", hashtag: "go", buckets: MaxTrendBuckets + 1, window: time.Hour, wantErr: "buckets must be between"},
+		{name: "Window too long", hashtag: "go", buckets: 4, window: MaxTrendWindow + time.Hour, wantErr: "window must be"},
+		{name: "Window shorter than its buckets", hashtag: "go", buckets: 2, window: time.Nanosecond, wantErr: "window must be at least 1s per bucket"},
+		{name: "Window under a second per bucket", hashtag: "go", buckets: 4, window: 3 * time.Second, wantErr: "window must be at least 1s per bucket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SentimentTimeline(config.Config{}, tt.hashtag, tt.buckets, tt.window)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// CacheOptions configures the cache of resolved identities
+type CacheOptions struct {
+	TTL       time.Duration // How long a resolution is trusted
+	Directory string        // Where resolutions are persisted; empty keeps them in memory only
+}
+
+// DefaultCacheOptions keeps resolutions for a day and persists them, since
+// handles rarely change
+var DefaultCacheOptions = CacheOptions{
+	TTL:       24 * time.Hour,
+	Directory: "./cache/identity",
+}
+
+// CacheOptionsFromEnv returns the default options adjusted by BSKY_IDENTITY_CACHE_TTL
+// (a duration such as "12h") and BSKY_IDENTITY_CACHE_DIR ("off" disables persistence).
+// Invalid TTLs are ignored.
+func CacheOptionsFromEnv() CacheOptions {
+	options := DefaultCacheOptions
+	if ttl, err := time.ParseDuration(os.Getenv("BSKY_IDENTITY_CACHE_TTL")); err == nil && ttl > 0 {
+		options.TTL = ttl
+	}
+	switch dir := os.Getenv("BSKY_IDENTITY_CACHE_DIR"); dir {
+	case "":
+	case "off":
+		options.Directory = ""
+	default:
+		options.Directory = dir
+	}
+	return options
+}
+
+// resolutionCache holds handle to DID mappings
+var (
+	resolutionMu    sync.RWMutex
+	resolutionTTL   = DefaultCacheOptions.TTL
+	resolutionCache = newResolutionCache(CacheOptions{TTL: DefaultCacheOptions.TTL})
+)
+
+// newResolutionCache creates the cache for options, loading persisted resolutions
+func newResolutionCache(options CacheOptions) *cache.Cache {
+	return cache.NewWithOptions(cache.CacheOptions{
+		MaxItems:         10000,
+		DefaultTTL:       options.TTL,
+		CleanupInterval:  10 * time.Minute,
+		AllowStaleOnFail: true,
+		StaleTimeout:     options.TTL,
+		PersistOptions: cache.PersistOptions{
+			Enabled:       options.Directory != "",
+			Directory:     options.Directory,
+			Filename:      "identity_cache.json",
+			SaveInterval:  10 * time.Minute,
+			LoadOnStartup: true,
+			DirMode:       cache.DefaultDirMode,
+		},
+	})
+}
+
+// SetCacheOptions replaces the resolution cache with one configured by options,
+// loading resolutions persisted by a previous run. The current cache is saved
+// and stopped first.
+func SetCacheOptions(options CacheOptions) {
+	if options.TTL <= 0 {
+		options.TTL = DefaultCacheOptions.TTL
+	}
+
+	resolutionMu.Lock()
+	defer resolutionMu.Unlock()
+	resolutionCache.Stop()
+	resolutionCache = newResolutionCache(options)
+	resolutionTTL = options.TTL
+}
+
+// StopCache saves the resolution cache, if persisted, and stops its timers
+func StopCache() {
+	resolutionMu.RLock()
+	defer resolutionMu.RUnlock()
+	resolutionCache.Stop()
+}
+
+// currentCache returns the resolution cache and its TTL
+func currentCache() (*cache.Cache, time.Duration) {
+	resolutionMu.RLock()
+	defer resolutionMu.RUnlock()
+	return resolutionCache, resolutionTTL
+}
+
+// handleKey is the cache key of a handle's resolution
+func handleKey(handle string) string { return "handle:" + strings.ToLower(handle) }
+
+// ResolveHandle returns the DID of a handle, from the cache when it was resolved recently
+func ResolveHandle(cfg config.Config, handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimPrefix(handle, "@"))
+	resolutions, ttl := currentCache()
+	did, err := resolutions.GetWithLoader(handleKey(handle), ttl, func() (interface{}, error) {
+		return lookupHandle(cfg, handle)
+	})
+	if err != nil {
+		return "", err
+	}
+	return did.(string), nil
+}
+
+// HandleChanged drops the cached DID of an account's previous handle. Nothing
+// calls it automatically: the cache does not watch for handle changes, so an
+// embedder that learns of one calls it, and otherwise the entry expires after
+// the cache TTL.
+func HandleChanged(oldHandle string) {
+	resolutions, _ := currentCache()
+	resolutions.Delete(handleKey(strings.TrimPrefix(oldHandle, "@")))
+}
+
+// lookupHandle resolves a handle through the API, can be replaced for testing
+var lookupHandle = func(cfg config.Config, handle string) (string, error) {
+	client := auth.GetTokenManager(cfg).GetClient()
+
+	query := url.Values{}
+	query.Set("handle", handle)
+
+	responseBody, err := client.Get("com.atproto.identity.resolveHandle", query)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve handle %s: %w", handle, err)
+	}
+
+	var result struct {
+		DID string `json:"did"`
+	}
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return "", fmt.Errorf("error parsing resolve handle response: %w", err)
+	}
+	if result.DID == "" {
+		return "", fmt.Errorf("handle %s not found", handle)
+	}
+
+	return result.DID, nil
+}
@@ -0,0 +1,126 @@
+package identity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// useCache configures the resolution cache for a test and counts lookups,
+// restoring an in-memory cache afterwards
+func useCache(t *testing.T, options CacheOptions) (handleLookups *int) {
+	t.Helper()
+	handleLookups = new(int)
+
+	originalHandle := lookupHandle
+	lookupHandle = func(cfg config.Config, handle string) (string, error) {
+		*handleLookups++
+		return "did:plc:" + handle[:5], nil
+	}
+	SetCacheOptions(options)
+
+	t.Cleanup(func() {
+		lookupHandle = originalHandle
+		SetCacheOptions(CacheOptions{TTL: DefaultCacheOptions.TTL})
+	})
+	return handleLookups
+}
+
+func TestResolveHandleCachesUntilTTL(t *testing.T) {
+	handleLookups := useCache(t, CacheOptions{TTL: 50 * time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		did, err := ResolveHandle(config.Config{}, "@Alice.bsky.social")
+		if err != nil {
+			t.Fatalf("ResolveHandle() unexpected error: %v", err)
+		}
+		if did != "did:plc:alice" {
+			t.Errorf("ResolveHandle() = %q, want did:plc:alice", did)
+		}
+	}
+	if *handleLookups != 1 {
+		t.Errorf("Handle lookups = %d, want 1 within the TTL", *handleLookups)
+	}
+
+	// Expired resolutions are looked up again
+	time.Sleep(80 * time.Millisecond)
+	if _, err := ResolveHandle(config.Config{}, "alice.bsky.social"); err != nil {
+		t.Fatalf("ResolveHandle() unexpected error: %v", err)
+	}
+	if *handleLookups != 2 {
+		t.Errorf("Handle lookups = %d, want 2 after the TTL", *handleLookups)
+	}
+}
+
+func TestResolutionCachePersistence(t *testing.T) {
+	dir := t.TempDir()
+	handleLookups := useCache(t, CacheOptions{TTL: time.Hour, Directory: dir})
+
+	if _, err := ResolveHandle(config.Config{}, "alice.bsky.social"); err != nil {
+		t.Fatalf("ResolveHandle() unexpected error: %v", err)
+	}
+
+	// A restart saves the resolutions and loads them again
+	SetCacheOptions(CacheOptions{TTL: time.Hour, Directory: dir})
+	if _, err := os.Stat(filepath.Join(dir, "identity_cache.json")); err != nil {
+		t.Fatalf("Resolutions were not persisted: %v", err)
+	}
+
+	did, err := ResolveHandle(config.Config{}, "alice.bsky.social")
+	if err != nil || did != "did:plc:alice" {
+		t.Errorf("ResolveHandle() = %q, %v after restart; want did:plc:alice", did, err)
+	}
+	if *handleLookups != 1 {
+		t.Errorf("Handle lookups = %d, want persisted resolutions to be used", *handleLookups)
+	}
+}
+
+func TestResolutionCachePersistenceSkipsExpired(t *testing.T) {
+	dir := t.TempDir()
+	handleLookups := useCache(t, CacheOptions{TTL: 30 * time.Millisecond, Directory: dir})
+
+	if _, err := ResolveHandle(config.Config{}, "alice.bsky.social"); err != nil {
+		t.Fatalf("ResolveHandle() unexpected error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	SetCacheOptions(CacheOptions{TTL: 30 * time.Millisecond, Directory: dir})
+	if _, err := ResolveHandle(config.Config{}, "alice.bsky.social"); err != nil {
+		t.Fatalf("ResolveHandle() unexpected error: %v", err)
+	}
+	if *handleLookups != 2 {
+		t.Errorf("Handle lookups = %d, want expired persisted resolutions to be looked up again", *handleLookups)
+	}
+}
+
+func TestHandleChangedInvalidates(t *testing.T) {
+	handleLookups := useCache(t, CacheOptions{TTL: time.Hour})
+
+	ResolveHandle(config.Config{}, "alice.bsky.social")
+	ResolveHandle(config.Config{}, "other.bsky.social")
+
+	HandleChanged("@Alice.bsky.social")
+
+	ResolveHandle(config.Config{}, "alice.bsky.social")
+	ResolveHandle(config.Config{}, "other.bsky.social")
+	if *handleLookups != 3 {
+		t.Errorf("Handle lookups = %d, want only the changed handle's resolution dropped", *handleLookups)
+	}
+}
+
+func TestCacheOptionsFromEnv(t *testing.T) {
+	t.Setenv("BSKY_IDENTITY_CACHE_TTL", "12h")
+	t.Setenv("BSKY_IDENTITY_CACHE_DIR", "off")
+	if options := CacheOptionsFromEnv(); options.TTL != 12*time.Hour || options.Directory != "" {
+		t.Errorf("CacheOptionsFromEnv() = %+v, want 12h in memory", options)
+	}
+
+	t.Setenv("BSKY_IDENTITY_CACHE_TTL", "soon")
+	t.Setenv("BSKY_IDENTITY_CACHE_DIR", "")
+	if options := CacheOptionsFromEnv(); options != DefaultCacheOptions {
+		t.Errorf("CacheOptionsFromEnv() = %+v, want the defaults", options)
+	}
+}
@@ -1,27 +1,27 @@
 package post
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"html"
-	"math/rand"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
-// Initialize random seed
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 // templateSelector is a function type for selecting templates
 type templateSelector func(templates []string) string
 
-// defaultTemplateSelector returns a random template
+// defaultTemplatePicker remembers recent picks so suggestions vary between calls
+var defaultTemplatePicker = newTemplatePicker()
+
+// defaultTemplateSelector returns a random template, avoiding the one used last time
 var defaultTemplateSelector templateSelector = func(templates []string) string {
-	return templates[rand.Intn(len(templates))]
+	return defaultTemplatePicker.Pick(templates)
 }
 
 // For testing, can be replaced with a deterministic selector
@@ -44,132 +44,84 @@ func GeneratePost(cfg config.Config, params map[string]interface{}) (interface{}
 	// Check if post should be submitted directly
 	submitPost, _ := params["submit"].(bool)
 
+	// Check if the suggestion should be trimmed to fit in a post
+	fitToLimit, _ := params["fitToLimit"].(bool)
+
 	// Validate inputs
-	if len(topic) > 200 {
-		return nil, fmt.Errorf("topic too long")
-	}
-
-	// Sanitize input to prevent XSS
-	topic = html.EscapeString(topic)
-
-	// Templates based on mood
-	happyTemplates := []string{
-		"Today is a great day!",
-		"Feeling so positive right now!",
-		"Nothing but blue skies today!",
-		"So happy I could burst!",
-		"What a wonderful day it's turning out to be!",
-	}
-
-	sadTemplates := []string{
-		"Feeling a bit down today.",
-		"Having one of those days...",
-		"Sometimes things don't go as planned.",
-		"Looking for a silver lining today.",
-		"When it rains, it pours.",
-	}
-
-	excitedTemplates := []string{
-		"I can't contain my excitement!",
-		"You won't believe what just happened!",
-		"This is absolutely incredible!",
-		"I'm literally bouncing with energy!",
-		"Big news coming your way!",
-	}
-
-	thoughtfulTemplates := []string{
-		"I've been pondering something interesting.",
-		"Here's a thought worth sharing:",
-		"Something to consider today:",
-		"Been reflecting on this lately:",
-		"Food for thought:",
-	}
-
-	// Topic templates
-	topicTemplates := []string{
-		" I want to talk about %s.",
-		" Let's discuss %s today.",
-		" Has anyone else been thinking about %s?",
-		" What are your thoughts on %s?",
-		" %s has been on my mind lately.",
-		" Anyone interested in %s?",
-		" %s is something we should all explore more.",
-		" I've been fascinated by %s recently.",
-	}
-
-	// Generic fallback templates
-	fallbackTemplates := []string{
-		"Let's post something interesting!",
-		"What's on everyone's mind today?",
-		"How's everyone doing?",
-		"Anything exciting happening?",
-		"Just wanted to check in!",
-		"Happy to connect with you all!",
-		"Thoughts?",
-		"Open to interesting conversations today!",
-	}
-
-	suggestion := ""
-	
-	// Select mood template
-	switch mood {
-	case "happy":
-		suggestion = getRandomTemplate(happyTemplates)
-	case "sad":
-		suggestion = getRandomTemplate(sadTemplates)
-	case "excited":
-		suggestion = getRandomTemplate(excitedTemplates)
-	case "thoughtful":
-		suggestion = getRandomTemplate(thoughtfulTemplates)
-	}
-
-	// Add topic if provided
-	if topic != "" {
-		if suggestion != "" {
-			// If we have a mood, add the topic with a template
-			topicFormat := getRandomTemplate(topicTemplates)
-			suggestion += fmt.Sprintf(topicFormat, topic)
-		} else {
-			// If no mood but we have a topic, start with the topic
-			topicFormat := getRandomTemplate(topicTemplates)
-			suggestion = fmt.Sprintf(topicFormat, topic)
-			// Remove leading space if present
-			if len(suggestion) > 0 && suggestion[0] == ' ' {
-				suggestion = suggestion[1:]
-			}
-		}
+	if err := cfg.Limits.CheckLength(config.FieldTopic, topic); err != nil {
+		return nil, err
+	}
+
+	// Posts are plain text, so the topic is cleaned up rather than HTML-escaped
+	topic = SanitizeText(topic)
+
+	// Ask the configured backend, templates by default, for a suggestion
+	suggestion, err := suggestPost(cfg, SuggestRequest{Mood: mood, Topic: topic})
+	if err != nil {
+		return nil, err
 	}
 
-	// Use fallback if no suggestion was generated
-	if suggestion == "" {
-		suggestion = getRandomTemplate(fallbackTemplates)
+	trimmed := false
+	if fitToLimit {
+		suggestion, trimmed = FitToLimit(suggestion)
 	}
 
 	// If submit is true, submit the post to Bluesky
 	if submitPost {
+		var result map[string]interface{}
 		postResult, err := SubmitPost(cfg, suggestion)
-		if err != nil {
-			return map[string]interface{}{
+		var queued *QueuedError
+		if errors.As(err, &queued) {
+			// The queue will send the post, so it is not reported as a failure
+			result = map[string]interface{}{
+				"suggestion": suggestion,
+				"submitted": false,
+				"queued": true,
+				"queue_id": queued.ID,
+				"warning": queued.Error(),
+			}
+		} else if err != nil {
+			result = map[string]interface{}{
 				"suggestion": suggestion,
 				"submitted": false,
 				"error": err.Error(),
-			}, nil
+			}
+		} else {
+			result = map[string]interface{}{
+				"suggestion": suggestion,
+				"submitted": true,
+				"post_uri": postResult.URI,
+				"post_cid": postResult.CID,
+			}
+		}
+		if fitToLimit {
+			result["trimmed"] = trimmed
 		}
-		return map[string]interface{}{
-			"suggestion": suggestion,
-			"submitted": true,
-			"post_uri": postResult.URI,
-			"post_cid": postResult.CID,
-		}, nil
+		return result, nil
 	}
 
+	if fitToLimit {
+		return map[string]interface{}{"suggestion": suggestion, "trimmed": trimmed}, nil
+	}
 	return map[string]string{"suggestion": suggestion}, nil
 }
 
 // Result contains information about a successfully created post
 type PostResult struct {
-	URI string `json:"uri"`
-	CID string `json:"cid"`
+	URI     string `json:"uri"`
+	CID     string `json:"cid"`
+	Warning string `json:"warning,omitempty"` // Set when the post was created but a follow-up step failed
+}
+
+// SubmittedPost is a created post with what was submitted, as an audit trail:
+// the exact record written, including any reply, embed, labels and tags, and
+// the account it was posted as
+type SubmittedPost struct {
+	PostResult
+	Record        map[string]interface{} `json:"record"`                   // The app.bsky.feed.post record as written
+	DID           string                 `json:"did"`                      // Repository the post was written to
+	Handle        string                 `json:"handle,omitempty"`         // Handle of that account, when known
+	CredentialSet string                 `json:"credential_set,omitempty"` // Whether the primary or backup credentials were used
 }
 
 // SubmitPostFunc defines the function signature for the SubmitPost function
@@ -177,51 +129,183 @@ type SubmitPostFunc func(cfg config.Config, text string) (*PostResult, error)
 
 // SubmitPost is the actual implementation that submits a post to Bluesky
 var SubmitPost SubmitPostFunc = func(cfg config.Config, text string) (*PostResult, error) {
-	// Get token manager
-	tokenManager := auth.GetTokenManager(cfg)
-	
-	// Get authenticated client
-	client := tokenManager.GetClient()
-	
-	// Get user DID
-	did := tokenManager.GetDID()
-	if did == "" {
-		// Force authentication if DID is empty
-		_, err := tokenManager.GetToken(cfg)
-		if err != nil {
-			return nil, fmt.Errorf("authentication failed: %w", err)
-		}
-		did = tokenManager.GetDID()
-		if did == "" {
-			return nil, fmt.Errorf("unable to get user DID")
+	return SubmitPostWithOptions(cfg, text, SubmitPostOptions{})
+}
+
+// ErrEmptyPostText is returned when the text to post is empty or only whitespace
+var ErrEmptyPostText = errors.New("invalid parameter: post text cannot be empty")
+
+// CheckPostText rejects text that has nothing to post once sanitized, so
+// callers can fail before authenticating or contacting the API
+func CheckPostText(text string) error {
+	if strings.TrimSpace(SanitizeText(text)) == "" {
+		return ErrEmptyPostText
+	}
+	return nil
+}
+
+// SubmitPostWithOptions submits a post to Bluesky, optionally as a reply and/or quote.
+// If the retry queue is enabled, posts that fail due to transient errors are queued.
+func SubmitPostWithOptions(cfg config.Config, text string, opts SubmitPostOptions) (*PostResult, error) {
+	submitted, err := SubmitPostDetailed(cfg, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &submitted.PostResult, nil
+}
+
+// SubmitPostDetailed submits a post as SubmitPostWithOptions does, also
+// returning the record that was written and the account it was posted as
+func SubmitPostDetailed(cfg config.Config, text string, opts SubmitPostOptions) (*SubmittedPost, error) {
+	return submitPostDetailed(context.Background(), cfg, text, opts)
+}
+
+// SubmitPostContext submits a post as SubmitPostWithOptions does. Waiting for
+// the write pacer and the write itself stop when ctx is done.
+func SubmitPostContext(ctx context.Context, cfg config.Config, text string, opts SubmitPostOptions) (*PostResult, error) {
+	submitted, err := submitPostDetailed(ctx, cfg, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &submitted.PostResult, nil
+}
+
+// submitPostDetailed submits a post, queueing it for retry on a transient failure
+func submitPostDetailed(ctx context.Context, cfg config.Config, text string, opts SubmitPostOptions) (*SubmittedPost, error) {
+	submitted, err := submitPost(ctx, cfg, text, opts)
+	if err != nil {
+		return nil, enqueueOnFailure(text, opts, err)
+	}
+	return submitted, nil
+}
+
+// createRecord writes a record to the user's repository, can be replaced for testing
+var createRecord = repo.CreateRecordContext
+
+// sessionInfo returns the account the session is for, can be replaced for testing
+var sessionInfo = func(cfg config.Config) auth.SessionInfo {
+	return auth.GetTokenManager(cfg).GetSessionInfo()
+}
+
+// DefaultSubmitTimeout bounds a single record write; it is shorter than the
+// handler's method timeout so the service reports the timeout itself
+const DefaultSubmitTimeout = 8 * time.Second
+
+// submitTimeout is the current per-write timeout
+var (
+	submitTimeoutMu sync.RWMutex
+	submitTimeout   = DefaultSubmitTimeout
+)
+
+// SetSubmitTimeout changes how long each record write may take; zero or less restores the default
+func SetSubmitTimeout(timeout time.Duration) {
+	submitTimeoutMu.Lock()
+	defer submitTimeoutMu.Unlock()
+	if timeout <= 0 {
+		timeout = DefaultSubmitTimeout
+	}
+	submitTimeout = timeout
+}
+
+// ErrWriteOutcomeUnknown is wrapped into the error returned when a write timed
+// out. The request may still have been applied, so it is never retried or queued.
+var ErrWriteOutcomeUnknown = errors.New("write outcome unknown")
+
+// writeWithTimeout runs a record write, cancelling it once the submit timeout
+// elapses or ctx is done
+func writeWithTimeout(ctx context.Context, operation string, write func(ctx context.Context) (*repo.CreateRecordResult, error)) (*repo.CreateRecordResult, error) {
+	submitTimeoutMu.RLock()
+	timeout := submitTimeout
+	submitTimeoutMu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	created, err := write(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("timeout %s after %s (%w): %w", operation, timeout, ErrWriteOutcomeUnknown, ctx.Err())
+	}
+	return created, err
+}
+
+// submitPost performs a single post submission attempt
+func submitPost(ctx context.Context, cfg config.Config, text string, opts SubmitPostOptions) (*SubmittedPost, error) {
+	if err := CheckPostText(text); err != nil {
+		return nil, err
+	}
+	text = SanitizeText(text)
+	if err := cfg.Limits.CheckLength(config.FieldText, text); err != nil {
+		return nil, err
+	}
+	if err := checkPostGraphemes(text); err != nil {
+		return nil, err
+	}
+
+	// Validate references before contacting the API
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Refuse to repeat a recent post unless forced
+	if err := checkDuplicate(cfg, text, opts); err != nil {
+		return nil, err
+	}
+
+	// Upload the link card thumbnail so the post can reference it
+	var thumb *repo.Blob
+	if opts.External != nil {
+		var err error
+		if thumb, err = opts.External.uploadThumb(cfg); err != nil {
+			return nil, err
 		}
 	}
 
 	// Create post record
-	record := map[string]interface{}{
-		"$type": "app.bsky.feed.post",
-		"text": text,
-		"createdAt": time.Now().UTC().Format(time.RFC3339),
-	}
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	record := buildPostRecord(text, createdAt, opts, thumb)
 
-	// Create repo request
-	request := map[string]interface{}{
-		"repo": did,
-		"collection": "app.bsky.feed.post",
-		"record": record,
+	// Wait for the write pacer so bulk submissions stay within write limits
+	if err := WaitForWrite(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
 	// Submit post
-	responseBody, err := client.Post("com.atproto.repo.createRecord", request)
+	writePost := func(ctx context.Context) (*repo.CreateRecordResult, error) {
+		return createRecord(ctx, cfg, repo.CollectionPost, record)
+	}
+	// An expired session is renewed and the write replayed once by the API client
+	created, err := writeWithTimeout(ctx, "creating post", writePost)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create post: %w", err)
+		return nil, err
 	}
 
-	// Parse response
-	var result PostResult
-	if err := json.Unmarshal(responseBody, &result); err != nil {
-		return nil, fmt.Errorf("error parsing create post response: %w", err)
+	// The post's URI names the repository it was written to, which is the
+	// session's account unless the session changed in the meantime
+	result := &SubmittedPost{
+		PostResult: PostResult{URI: created.URI, CID: created.CID},
+		Record:     record,
+		DID:        repo.RecordRepo(created.URI),
+	}
+	if info := sessionInfo(cfg); info.DID == result.DID {
+		result.Handle = info.Handle
+		result.CredentialSet = info.CredentialSet
 	}
+	notifyPostCreated(&result.PostResult, text, createdAt)
 
-	return &result, nil
-}
\ No newline at end of file
+	// The threadgate shares the post's record key. The post already exists, so a
+	// failure here is reported as a warning rather than an error that would be retried.
+	if opts.Threadgate != nil {
+		gate := buildThreadgateRecord(created.URI, createdAt, *opts.Threadgate)
+		err := WaitForWrite(ctx)
+		if err == nil {
+			_, err = writeWithTimeout(ctx, "creating threadgate", func(ctx context.Context) (*repo.CreateRecordResult, error) {
+				return createRecordWithKey(ctx, cfg, repo.CollectionThreadgate, repo.RecordKey(created.URI), gate)
+			})
+		}
+		if err != nil {
+			result.Warning = fmt.Sprintf("post created but threadgate was not applied: %v", err)
+		}
+	}
+
+	return result, nil
+}
@@ -1,10 +1,17 @@
 package post
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
 	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
 )
 
@@ -156,17 +163,31 @@ func TestGeneratePost(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "Topic with potential XSS",
+			name: "Topic with markup",
 			cfg:  config.Config{},
 			params: map[string]interface{}{
 				"mood":  "happy",
 				"topic": "<script>alert('xss')</script>",
 			},
 			check: func(suggestion string) bool {
-				// Should sanitize the XSS attempt
+				// Posts are plain text, so markup is kept literally
 				return strings.Contains(suggestion, "Today is a great day!") &&
-					strings.Contains(suggestion, "&lt;script&gt;") &&
-					!strings.Contains(suggestion, "<script>")
+					strings.Contains(suggestion, "<script>alert('xss')</script>") &&
+					!strings.Contains(suggestion, "&lt;")
+			},
+			wantErr: false,
+		},
+		{
+			name: "Topic with ampersand and control characters",
+			cfg:  config.Config{},
+			params: map[string]interface{}{
+				"mood":  "happy",
+				"topic": "AT&T\x00",
+			},
+			check: func(suggestion string) bool {
+				return strings.Contains(suggestion, "AT&T") &&
+					!strings.Contains(suggestion, "&amp;") &&
+					!strings.Contains(suggestion, "\x00")
 			},
 			wantErr: false,
 		},
@@ -357,4 +378,261 @@ func TestSubmitPost(t *testing.T) {
 	
 	// Restore original function
 	SubmitPost = originalSubmitPost
-}
\ No newline at end of file
+}
+func TestSubmitPostCreatesPostRecord(t *testing.T) {
+	// Disable pacing and capture the created record
+	SetWriteRate(0, DefaultWriteBurst)
+	defer SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+
+	var gotCollection string
+	var gotRecord map[string]interface{}
+	originalCreateRecord := createRecord
+	defer func() { createRecord = originalCreateRecord }()
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		gotCollection = collection
+		gotRecord = record
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	result, err := SubmitPost(config.Config{}, "Hello from a test")
+	if err != nil {
+		t.Fatalf("SubmitPost() unexpected error: %v", err)
+	}
+	if result.URI != "at://did:plc:me/app.bsky.feed.post/1" || result.CID != "bafyreipost" {
+		t.Errorf("SubmitPost() = %+v", result)
+	}
+	if gotCollection != repo.CollectionPost {
+		t.Errorf("Collection = %q, want %q", gotCollection, repo.CollectionPost)
+	}
+	if gotRecord["$type"] != repo.CollectionPost || gotRecord["text"] != "Hello from a test" {
+		t.Errorf("Unexpected post record: %v", gotRecord)
+	}
+}
+
+func TestSubmitPostDetailed(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	defer SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+
+	var gotRecord map[string]interface{}
+	originalCreateRecord := createRecord
+	originalSessionInfo := sessionInfo
+	defer func() {
+		createRecord = originalCreateRecord
+		sessionInfo = originalSessionInfo
+	}()
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		gotRecord = record
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+	sessionInfo = func(cfg config.Config) auth.SessionInfo {
+		return auth.SessionInfo{Handle: "me.bsky.social", DID: "did:plc:me", CredentialSet: auth.CredentialSetBackup}
+	}
+
+	quote := PostRef{URI: "at://did:plc:other/app.bsky.feed.post/3kquote", CID: "bafyreiquote"}
+	submitted, err := SubmitPostDetailed(config.Config{}, "Quoting this", SubmitPostOptions{
+		Quote:  &quote,
+		Labels: []string{"nudity"},
+		Tags:   []string{"#golang"},
+	})
+	if err != nil {
+		t.Fatalf("SubmitPostDetailed() unexpected error: %v", err)
+	}
+
+	// The result carries the post, the exact record that was written and the account
+	if submitted.URI != "at://did:plc:me/app.bsky.feed.post/1" || submitted.CID != "bafyreipost" {
+		t.Errorf("SubmitPostDetailed() = %+v", submitted.PostResult)
+	}
+	if !reflect.DeepEqual(submitted.Record, gotRecord) {
+		t.Errorf("Record = %v, want the submitted record %v", submitted.Record, gotRecord)
+	}
+	embed, _ := submitted.Record["embed"].(map[string]interface{})
+	if submitted.Record["text"] != "Quoting this" || embed["$type"] != "app.bsky.embed.record" ||
+		!reflect.DeepEqual(submitted.Record["tags"], []string{"golang"}) || submitted.Record["labels"] == nil {
+		t.Errorf("Record = %v, want the text, quote embed, labels and tags", submitted.Record)
+	}
+	if submitted.DID != "did:plc:me" || submitted.Handle != "me.bsky.social" || submitted.CredentialSet != auth.CredentialSetBackup {
+		t.Errorf("Identity = %s, %s, %s, want the session's account", submitted.DID, submitted.Handle, submitted.CredentialSet)
+	}
+
+	// A session for another account does not name the one posted as
+	sessionInfo = func(cfg config.Config) auth.SessionInfo {
+		return auth.SessionInfo{Handle: "someone.bsky.social", DID: "did:plc:someone"}
+	}
+	submitted, err = SubmitPostDetailed(config.Config{}, "Another post", SubmitPostOptions{})
+	if err != nil {
+		t.Fatalf("SubmitPostDetailed() unexpected error: %v", err)
+	}
+	if submitted.DID != "did:plc:me" || submitted.Handle != "" {
+		t.Errorf("Identity = %s, %s, want only the DID from the post URI", submitted.DID, submitted.Handle)
+	}
+}
+
+func TestSubmitPostTextIsNotEscaped(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalCreateRecord := createRecord
+	defer func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+	}()
+
+	var gotText interface{}
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		gotText = record["text"]
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Switching to AT&T", want: "Switching to AT&T"},
+		{text: "Use the <tag> element", want: "Use the <tag> element"},
+		{text: "Line one\r\nLine two\u0007", want: "Line one\nLine two"},
+	}
+	for _, tt := range tests {
+		if _, err := SubmitPost(config.Config{}, tt.text); err != nil {
+			t.Fatalf("SubmitPost(%q) unexpected error: %v", tt.text, err)
+		}
+		if gotText != tt.want {
+			t.Errorf("SubmitPost(%q) posted %q, want %q", tt.text, gotText, tt.want)
+		}
+	}
+}
+
+func TestPostLengthLimits(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalCreateRecord := createRecord
+	defer func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+	}()
+	writes := 0
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		writes++
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	cfg := config.Config{Limits: config.Limits{TopicLength: 10, TextLength: 20}}
+
+	_, err := GeneratePost(cfg, map[string]interface{}{"mood": "happy", "topic": "a topic that is too long"})
+	var lengthErr *config.LengthError
+	if !errors.As(err, &lengthErr) || lengthErr.Field != "topic" || lengthErr.Limit != 10 {
+		t.Errorf("GeneratePost() error = %v, want a topic length error with limit 10", err)
+	}
+
+	_, err = SubmitPostWithOptions(cfg, "This post is longer than twenty characters", SubmitPostOptions{})
+	if !errors.As(err, &lengthErr) || lengthErr.Field != "text" || lengthErr.Limit != 20 {
+		t.Errorf("SubmitPostWithOptions() error = %v, want a text length error with limit 20", err)
+	}
+	if writes != 0 {
+		t.Errorf("Expected no post to be written, got %d", writes)
+	}
+
+	if _, err := SubmitPostWithOptions(cfg, "Short enough", SubmitPostOptions{}); err != nil {
+		t.Errorf("SubmitPostWithOptions() unexpected error: %v", err)
+	}
+}
+
+func TestSubmitPostRejectsEmptyText(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalCreateRecord := createRecord
+	defer func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+	}()
+	writes := 0
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		writes++
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "Empty", text: "", wantErr: true},
+		{name: "Whitespace only", text: " \t\r\n ", wantErr: true},
+		{name: "Only control characters", text: "\u0007\u0000", wantErr: true},
+		{name: "Single character", text: "a", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckPostText(tt.text); (err != nil) != tt.wantErr {
+				t.Errorf("CheckPostText(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+
+			writes = 0
+			_, err := SubmitPostWithOptions(config.Config{}, tt.text, SubmitPostOptions{})
+			if tt.wantErr {
+				if !errors.Is(err, ErrEmptyPostText) {
+					t.Errorf("SubmitPostWithOptions(%q) error = %v, want ErrEmptyPostText", tt.text, err)
+				}
+				if writes != 0 {
+					t.Errorf("Expected no post to be written, got %d", writes)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("SubmitPostWithOptions(%q) unexpected error: %v", tt.text, err)
+			}
+			if writes != 1 {
+				t.Errorf("Expected the post to be written once, got %d", writes)
+			}
+		})
+	}
+}
+
+func TestSubmitPostUnauthorizedNotRetried(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalCreateRecord := createRecord
+	defer func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+	}()
+
+	// The API client has already re-authenticated and replayed the write once,
+	// so the service reports the rejection instead of submitting again
+	writes := 0
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		writes++
+		return nil, fmt.Errorf("failed to create record: API error: status 401")
+	}
+
+	if _, err := SubmitPost(config.Config{}, "Never accepted"); err == nil {
+		t.Fatal("SubmitPost() expected an error")
+	}
+	if writes != 1 {
+		t.Errorf("Got %d writes, want the service not to retry", writes)
+	}
+}
+
+func TestSubmitPostTimeout(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	SetSubmitTimeout(50 * time.Millisecond)
+	originalCreateRecord := createRecord
+	defer func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		SetSubmitTimeout(DefaultSubmitTimeout)
+		createRecord = originalCreateRecord
+	}()
+
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		<-ctx.Done()
+		return nil, fmt.Errorf("failed to create record: request failed: %w", ctx.Err())
+	}
+
+	start := time.Now()
+	_, err := SubmitPost(config.Config{}, "A slow post")
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "timeout creating post") {
+		t.Fatalf("SubmitPost() error = %v, want timeout error", err)
+	}
+	if !errors.Is(err, ErrWriteOutcomeUnknown) {
+		t.Errorf("SubmitPost() error = %v, want ErrWriteOutcomeUnknown", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("SubmitPost() took %v, want it to give up after the submit timeout", elapsed)
+	}
+}
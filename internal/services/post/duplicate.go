@@ -0,0 +1,141 @@
+package post
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// ErrDuplicatePost is wrapped into the error returned when a post repeats recent text
+var ErrDuplicatePost = errors.New("duplicate post")
+
+// DuplicateMatch selects how post texts are compared
+type DuplicateMatch string
+
+const (
+	// DuplicateMatchExact only treats identical text as a duplicate
+	DuplicateMatchExact DuplicateMatch = "exact"
+	// DuplicateMatchWhitespace ignores leading, trailing and repeated whitespace
+	DuplicateMatchWhitespace DuplicateMatch = "whitespace"
+)
+
+// DuplicateCheckOptions defines how posts are checked against recent posts
+type DuplicateCheckOptions struct {
+	Enabled  bool           `json:"enabled"`
+	Window   time.Duration  `json:"window"`    // How far back to look
+	Match    DuplicateMatch `json:"match"`     // How to compare texts
+	MaxPosts int            `json:"max_posts"` // Recent posts to fetch, at most repo.MaxListLimit
+}
+
+// DefaultDuplicateCheckOptions contains reasonable defaults; checking is off unless enabled
+var DefaultDuplicateCheckOptions = DuplicateCheckOptions{
+	Enabled:  false,
+	Window:   24 * time.Hour,
+	Match:    DuplicateMatchWhitespace,
+	MaxPosts: 50,
+}
+
+// DuplicateCheckOptionsFromEnv returns the default options adjusted by the
+// BSKY_DUPLICATE_CHECK ("true" to enable), BSKY_DUPLICATE_WINDOW (e.g. "12h")
+// and BSKY_DUPLICATE_MATCH ("exact" or "whitespace") environment variables
+func DuplicateCheckOptionsFromEnv() DuplicateCheckOptions {
+	options := DefaultDuplicateCheckOptions
+	options.Enabled = os.Getenv("BSKY_DUPLICATE_CHECK") == "true"
+
+	if window, err := time.ParseDuration(os.Getenv("BSKY_DUPLICATE_WINDOW")); err == nil && window > 0 {
+		options.Window = window
+	}
+
+	switch match := DuplicateMatch(os.Getenv("BSKY_DUPLICATE_MATCH")); match {
+	case DuplicateMatchExact, DuplicateMatchWhitespace:
+		options.Match = match
+	}
+
+	return options
+}
+
+// Shared duplicate check settings
+var (
+	duplicateCheck   = DefaultDuplicateCheckOptions
+	duplicateCheckMu sync.RWMutex
+)
+
+// SetDuplicateCheck configures duplicate detection for submitted posts
+func SetDuplicateCheck(options DuplicateCheckOptions) {
+	duplicateCheckMu.Lock()
+	defer duplicateCheckMu.Unlock()
+	duplicateCheck = options
+}
+
+// getDuplicateCheck returns the current duplicate check settings
+func getDuplicateCheck() DuplicateCheckOptions {
+	duplicateCheckMu.RLock()
+	defer duplicateCheckMu.RUnlock()
+	return duplicateCheck
+}
+
+// listRecentPosts returns the user's most recent post records, newest first.
+// Can be replaced for testing.
+var listRecentPosts = func(cfg config.Config, limit int) ([]repo.Record, error) {
+	result, err := repo.ListRecords(cfg, repo.CollectionPost, limit, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Records, nil
+}
+
+// checkDuplicate returns an error wrapping ErrDuplicatePost if text matches a post
+// made within the configured window. It does nothing when disabled or forced.
+func checkDuplicate(cfg config.Config, text string, opts SubmitPostOptions) error {
+	options := getDuplicateCheck()
+	if !options.Enabled || opts.Force {
+		return nil
+	}
+
+	limit := options.MaxPosts
+	if limit <= 0 || limit > repo.MaxListLimit {
+		limit = repo.MaxListLimit
+	}
+
+	records, err := listRecentPosts(cfg, limit)
+	if err != nil {
+		return fmt.Errorf("failed to check for duplicate posts: %w", err)
+	}
+
+	target := normalizeForMatch(text, options.Match)
+	cutoff := time.Now().Add(-options.Window)
+
+	for _, record := range records {
+		createdAt, _ := record.Value["createdAt"].(string)
+		postedAt, err := time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			// Skip records without a usable timestamp
+			continue
+		}
+		if options.Window > 0 && postedAt.Before(cutoff) {
+			continue
+		}
+
+		recordText, _ := record.Value["text"].(string)
+		if normalizeForMatch(recordText, options.Match) == target {
+			return fmt.Errorf("%w: same text was posted at %s (%s); use force to post anyway",
+				ErrDuplicatePost, postedAt.Format(time.RFC3339), record.URI)
+		}
+	}
+
+	return nil
+}
+
+// normalizeForMatch prepares text for comparison using the given match mode
+func normalizeForMatch(text string, match DuplicateMatch) string {
+	if match == DuplicateMatchExact {
+		return text
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,120 @@
+package post
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// stubRecentPosts enables duplicate checking against the given recent posts for the duration of a test
+func stubRecentPosts(t *testing.T, options DuplicateCheckOptions, records []repo.Record) {
+	originalList := listRecentPosts
+	originalOptions := getDuplicateCheck()
+	t.Cleanup(func() {
+		listRecentPosts = originalList
+		SetDuplicateCheck(originalOptions)
+	})
+
+	SetDuplicateCheck(options)
+	listRecentPosts = func(cfg config.Config, limit int) ([]repo.Record, error) {
+		return records, nil
+	}
+}
+
+// recentPost builds a post record created the given time ago
+func recentPost(text string, age time.Duration) repo.Record {
+	return repo.Record{
+		URI: "at://did:plc:me/app.bsky.feed.post/" + text,
+		CID: "bafyrei",
+		Value: map[string]interface{}{
+			"text":      text,
+			"createdAt": time.Now().Add(-age).UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+func TestCheckDuplicate(t *testing.T) {
+	options := DefaultDuplicateCheckOptions
+	options.Enabled = true
+	options.Window = time.Hour
+
+	stubRecentPosts(t, options, []repo.Record{
+		recentPost("Hello  Bluesky!", 10*time.Minute),
+		recentPost("An old post", 2*time.Hour),
+	})
+
+	tests := []struct {
+		name          string
+		text          string
+		opts          SubmitPostOptions
+		wantDuplicate bool
+	}{
+		{name: "Exact duplicate", text: "Hello  Bluesky!", wantDuplicate: true},
+		{name: "Whitespace-only difference", text: " Hello Bluesky!\n", wantDuplicate: true},
+		{name: "Distinct post", text: "Hello again, Bluesky!"},
+		{name: "Duplicate outside window", text: "An old post"},
+		{name: "Forced duplicate", text: "Hello  Bluesky!", opts: SubmitPostOptions{Force: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDuplicate(config.Config{}, tt.text, tt.opts)
+			if tt.wantDuplicate && !errors.Is(err, ErrDuplicatePost) {
+				t.Errorf("checkDuplicate(%q) = %v, want ErrDuplicatePost", tt.text, err)
+			}
+			if !tt.wantDuplicate && err != nil {
+				t.Errorf("checkDuplicate(%q) unexpected error: %v", tt.text, err)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateExactMatch(t *testing.T) {
+	options := DefaultDuplicateCheckOptions
+	options.Enabled = true
+	options.Match = DuplicateMatchExact
+
+	stubRecentPosts(t, options, []repo.Record{recentPost("Hello  Bluesky!", time.Minute)})
+
+	if err := checkDuplicate(config.Config{}, "Hello  Bluesky!", SubmitPostOptions{}); !errors.Is(err, ErrDuplicatePost) {
+		t.Errorf("Expected identical text to be a duplicate, got %v", err)
+	}
+	if err := checkDuplicate(config.Config{}, "Hello Bluesky!", SubmitPostOptions{}); err != nil {
+		t.Errorf("Expected whitespace difference to be allowed in exact mode, got %v", err)
+	}
+}
+
+func TestCheckDuplicateDisabled(t *testing.T) {
+	stubRecentPosts(t, DefaultDuplicateCheckOptions, []repo.Record{recentPost("Hello", time.Minute)})
+	listRecentPosts = func(cfg config.Config, limit int) ([]repo.Record, error) {
+		t.Fatal("Recent posts should not be fetched when the check is disabled")
+		return nil, nil
+	}
+
+	if err := checkDuplicate(config.Config{}, "Hello", SubmitPostOptions{}); err != nil {
+		t.Errorf("checkDuplicate() unexpected error: %v", err)
+	}
+}
+
+func TestDuplicateCheckOptionsFromEnv(t *testing.T) {
+	t.Setenv("BSKY_DUPLICATE_CHECK", "true")
+	t.Setenv("BSKY_DUPLICATE_WINDOW", "30m")
+	t.Setenv("BSKY_DUPLICATE_MATCH", "exact")
+
+	options := DuplicateCheckOptionsFromEnv()
+	if !options.Enabled || options.Window != 30*time.Minute || options.Match != DuplicateMatchExact {
+		t.Errorf("DuplicateCheckOptionsFromEnv() = %+v", options)
+	}
+
+	t.Setenv("BSKY_DUPLICATE_CHECK", "")
+	t.Setenv("BSKY_DUPLICATE_WINDOW", "soon")
+	t.Setenv("BSKY_DUPLICATE_MATCH", "fuzzy")
+
+	options = DuplicateCheckOptionsFromEnv()
+	if options != DefaultDuplicateCheckOptions {
+		t.Errorf("DuplicateCheckOptionsFromEnv() = %+v, want defaults for invalid values", options)
+	}
+}
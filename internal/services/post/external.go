@@ -0,0 +1,73 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// MaxThumbSize is the largest link card thumbnail accepted by Bluesky, in bytes
+const MaxThumbSize = 1000000
+
+// ExternalCard is a link preview card shown below the post text
+type ExternalCard struct {
+	URI         string `json:"uri"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Thumb       []byte `json:"thumb,omitempty"` // Image data uploaded as the card thumbnail
+}
+
+// uploadBlob uploads data to the user's repository, can be replaced for testing
+var uploadBlob = repo.UploadBlob
+
+// Validate checks the card's URI and thumbnail
+func (e ExternalCard) Validate() error {
+	parsed, err := url.Parse(e.URI)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid external card: uri must be an http or https URL, got %q", e.URI)
+	}
+
+	if len(e.Thumb) > 0 {
+		if len(e.Thumb) > MaxThumbSize {
+			return fmt.Errorf("invalid external card: thumbnail is %d bytes, maximum is %d", len(e.Thumb), MaxThumbSize)
+		}
+		if mimeType := http.DetectContentType(e.Thumb); !strings.HasPrefix(mimeType, "image/") {
+			return fmt.Errorf("invalid external card: thumbnail must be an image, got %s", mimeType)
+		}
+	}
+
+	return nil
+}
+
+// uploadThumb uploads the card's thumbnail, returning nil when it has none
+func (e ExternalCard) uploadThumb(cfg config.Config) (*repo.Blob, error) {
+	if len(e.Thumb) == 0 {
+		return nil, nil
+	}
+	blob, err := uploadBlob(cfg, e.Thumb, http.DetectContentType(e.Thumb))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload link card thumbnail: %w", err)
+	}
+	return blob, nil
+}
+
+// buildExternalEmbed creates the app.bsky.embed.external object, referencing thumb when set
+func buildExternalEmbed(card ExternalCard, thumb *repo.Blob) map[string]interface{} {
+	external := map[string]interface{}{
+		"uri":         card.URI,
+		"title":       card.Title,
+		"description": card.Description,
+	}
+	if thumb != nil {
+		external["thumb"] = thumb
+	}
+
+	return map[string]interface{}{
+		"$type":    "app.bsky.embed.external",
+		"external": external,
+	}
+}
@@ -0,0 +1,171 @@
+package post
+
+import (
+	"context"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// pngHeader is enough data for content sniffing to detect a PNG image
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestExternalCardValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    ExternalCard
+		wantErr string
+	}{
+		{name: "URI only", card: ExternalCard{URI: "https://go.dev/blog"}},
+		{name: "With thumbnail", card: ExternalCard{URI: "https://go.dev/blog", Title: "The Go Blog", Thumb: pngHeader}},
+		{name: "Missing URI", card: ExternalCard{Title: "The Go Blog"}, wantErr: "http or https URL"},
+		{name: "Non-HTTP URI", card: ExternalCard{URI: "ftp://go.dev/blog"}, wantErr: "http or https URL"},
+		{name: "Thumbnail not an image", card: ExternalCard{URI: "https://go.dev", Thumb: []byte("plain text")}, wantErr: "must be an image"},
+		{name: "Thumbnail too large", card: ExternalCard{URI: "https://go.dev", Thumb: append(pngHeader, make([]byte, MaxThumbSize)...)}, wantErr: "maximum is"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.card.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// capturedUpload records the blob uploads and post record of a submission
+type capturedUpload struct {
+	uploads   [][]byte
+	mimeTypes []string
+	record    map[string]interface{}
+}
+
+func stubExternalWriters(t *testing.T, uploadErr error) *capturedUpload {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalCreateRecord := createRecord
+	originalUploadBlob := uploadBlob
+	t.Cleanup(func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+		uploadBlob = originalUploadBlob
+	})
+
+	captured := &capturedUpload{}
+	uploadBlob = func(cfg config.Config, data []byte, mimeType string) (*repo.Blob, error) {
+		captured.uploads = append(captured.uploads, data)
+		captured.mimeTypes = append(captured.mimeTypes, mimeType)
+		if uploadErr != nil {
+			return nil, uploadErr
+		}
+		return &repo.Blob{Type: "blob", Ref: repo.BlobRef{Link: "bafkreithumb"}, MimeType: mimeType, Size: int64(len(data))}, nil
+	}
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		captured.record = record
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/3kpost", CID: "bafyreipost"}, nil
+	}
+	return captured
+}
+
+func TestSubmitPostWithExternalCard(t *testing.T) {
+	captured := stubExternalWriters(t, nil)
+
+	_, err := SubmitPostWithOptions(config.Config{}, "Worth a read https://go.dev/blog", SubmitPostOptions{
+		External: &ExternalCard{
+			URI:         "https://go.dev/blog",
+			Title:       "The Go Blog",
+			Description: "News from the Go team",
+			Thumb:       pngHeader,
+		},
+	})
+	if err != nil {
+		t.Fatalf("SubmitPostWithOptions() unexpected error: %v", err)
+	}
+
+	if len(captured.uploads) != 1 || !bytes.Equal(captured.uploads[0], pngHeader) {
+		t.Fatalf("Expected the thumbnail to be uploaded once, got %d uploads", len(captured.uploads))
+	}
+	if captured.mimeTypes[0] != "image/png" {
+		t.Errorf("Thumbnail MIME type = %q, want image/png", captured.mimeTypes[0])
+	}
+
+	embed, ok := captured.record["embed"].(map[string]interface{})
+	if !ok || embed["$type"] != "app.bsky.embed.external" {
+		t.Fatalf("Expected external embed, got %v", captured.record["embed"])
+	}
+	external := embed["external"].(map[string]interface{})
+	if external["uri"] != "https://go.dev/blog" || external["title"] != "The Go Blog" || external["description"] != "News from the Go team" {
+		t.Errorf("External card = %v, want the provided metadata", external)
+	}
+	thumb, ok := external["thumb"].(*repo.Blob)
+	if !ok || thumb.Ref.Link != "bafkreithumb" {
+		t.Errorf("Card thumb = %v, want the uploaded blob", external["thumb"])
+	}
+}
+
+func TestSubmitPostExternalCardWithoutThumb(t *testing.T) {
+	captured := stubExternalWriters(t, nil)
+
+	if _, err := SubmitPostWithOptions(config.Config{}, "Link", SubmitPostOptions{
+		External: &ExternalCard{URI: "https://go.dev", Title: "Go"},
+	}); err != nil {
+		t.Fatalf("SubmitPostWithOptions() unexpected error: %v", err)
+	}
+
+	if len(captured.uploads) != 0 {
+		t.Errorf("Expected no uploads, got %d", len(captured.uploads))
+	}
+	external := captured.record["embed"].(map[string]interface{})["external"].(map[string]interface{})
+	if _, ok := external["thumb"]; ok {
+		t.Errorf("Card should not reference a thumb: %v", external)
+	}
+}
+
+func TestSubmitPostExternalCardWithQuote(t *testing.T) {
+	captured := stubExternalWriters(t, nil)
+	quote := PostRef{URI: "at://did:plc:other/app.bsky.feed.post/3kq", CID: "bafyreiquote"}
+
+	if _, err := SubmitPostWithOptions(config.Config{}, "Quote with link", SubmitPostOptions{
+		Quote:    &quote,
+		External: &ExternalCard{URI: "https://go.dev", Title: "Go"},
+	}); err != nil {
+		t.Fatalf("SubmitPostWithOptions() unexpected error: %v", err)
+	}
+
+	embed := captured.record["embed"].(map[string]interface{})
+	if embed["$type"] != "app.bsky.embed.recordWithMedia" {
+		t.Fatalf("Embed type = %v, want app.bsky.embed.recordWithMedia", embed["$type"])
+	}
+	record := embed["record"].(map[string]interface{})["record"].(map[string]interface{})
+	if record["uri"] != quote.URI {
+		t.Errorf("Quoted record = %v, want %v", record, quote)
+	}
+	media := embed["media"].(map[string]interface{})
+	if media["$type"] != "app.bsky.embed.external" {
+		t.Errorf("Media type = %v, want app.bsky.embed.external", media["$type"])
+	}
+}
+
+func TestSubmitPostExternalThumbUploadFailure(t *testing.T) {
+	captured := stubExternalWriters(t, fmt.Errorf("failed to upload blob: status 400"))
+
+	_, err := SubmitPostWithOptions(config.Config{}, "Link", SubmitPostOptions{
+		External: &ExternalCard{URI: "https://go.dev", Title: "Go", Thumb: pngHeader},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to upload link card thumbnail") {
+		t.Errorf("Expected thumbnail upload error, got %v", err)
+	}
+	if captured.record != nil {
+		t.Error("Post should not be created when the thumbnail upload fails")
+	}
+}
@@ -0,0 +1,125 @@
+package post
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPostGraphemes is the most graphemes Bluesky accepts in a post's text,
+// used unless another limit is configured
+const MaxPostGraphemes = 300
+
+// FitToLimit trims text to the post limit, MaxPostGraphemes graphemes
+// by default, and reports whether it was trimmed. Trailing sentences are
+// dropped first; when even the first sentence is too long, the text is cut at
+// a word boundary and ends with an ellipsis.
+func FitToLimit(text string) (string, bool) {
+	limit := GetServiceLimits().MaxPostGraphemes
+	if graphemeCount(text) <= limit {
+		return text, false
+	}
+	if fitted := dropTrailingSentences(text, limit); fitted != "" {
+		return fitted, true
+	}
+	return ellipsize(text, limit), true
+}
+
+// dropTrailingSentences returns the longest run of whole leading sentences of
+// text that fits in limit graphemes, or "" if the first sentence does not fit
+func dropTrailingSentences(text string, limit int) string {
+	fitted := ""
+	for _, end := range sentenceEnds(text) {
+		candidate := strings.TrimSpace(text[:end])
+		if graphemeCount(candidate) > limit {
+			break
+		}
+		fitted = candidate
+	}
+	return fitted
+}
+
+// sentenceEnds returns the byte offsets in text where a sentence ends: after a
+// line break, or after terminal punctuation and closing quotes followed by a
+// space. Punctuation inside a word, as in "1.5", does not end a sentence.
+func sentenceEnds(text string) []int {
+	var ends []int
+	for i, r := range text {
+		if r == '\n' {
+			ends = append(ends, i)
+			continue
+		}
+		if !strings.ContainsRune(".!?…", r) {
+			continue
+		}
+		end := i + utf8.RuneLen(r)
+		for end < len(text) {
+			next, size := utf8.DecodeRuneInString(text[end:])
+			if !strings.ContainsRune(".!?…\"')”’", next) {
+				break
+			}
+			end += size
+		}
+		if next, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && !unicode.IsSpace(next) {
+			continue
+		}
+		if len(ends) == 0 || ends[len(ends)-1] != end {
+			ends = append(ends, end)
+		}
+	}
+	return ends
+}
+
+// ellipsize cuts text at the last word boundary that leaves room for an
+// ellipsis within limit graphemes
+func ellipsize(text string, limit int) string {
+	cut := text[:graphemeBounds(text)[limit-1]]
+	if space := strings.LastIndexFunc(cut, unicode.IsSpace); space > 0 {
+		cut = cut[:space]
+	}
+	trimmed := strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	if trimmed == "" {
+		trimmed = cut
+	}
+	return trimmed + "…"
+}
+
+// graphemeCount returns the number of user-perceived characters in text
+func graphemeCount(text string) int {
+	return len(graphemeBounds(text))
+}
+
+// graphemeBounds returns the byte offset where each grapheme of text starts.
+// It covers the clusters common in posts: combining marks, variation
+// selectors, emoji skin tones and tags, zero width joiner sequences, flags
+// made of regional indicator pairs, and CRLF.
+func graphemeBounds(text string) []int {
+	var bounds []int
+	prev := rune(-1)
+	openFlag := false // The previous grapheme is a lone regional indicator
+	for i, r := range text {
+		joinsFlag := openFlag && isRegionalIndicator(r)
+		continues := prev == '\u200D' || joinsFlag || isGraphemeExtender(r) || (prev == '\r' && r == '\n')
+		if i == 0 || !continues {
+			bounds = append(bounds, i)
+		}
+		openFlag = isRegionalIndicator(r) && !joinsFlag
+		prev = r
+	}
+	return bounds
+}
+
+// isGraphemeExtender reports whether r attaches to the grapheme before it
+func isGraphemeExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == '\u200D' ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // Skin tone modifiers
+		(r >= 0xE0020 && r <= 0xE007F) // Tags, as in subdivision flags
+}
+
+// isRegionalIndicator reports whether r is one half of a flag
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
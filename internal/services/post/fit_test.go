@@ -0,0 +1,131 @@
+package post
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestGraphemeCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "hello", want: 5},
+		{text: "café", want: 4},
+		{text: "cafe\u0301", want: 4},    // e + combining acute accent
+		{text: "👍🏽👍", want: 2},           // Skin tone modifier
+		{text: "👩\u200d💻 code", want: 6}, // Zero width joiner sequence
+		{text: "🇯🇵🇫🇷", want: 2},          // Flags
+		{text: "❤️", want: 1},            // Variation selector
+		{text: "a\r\nb", want: 3},
+		{text: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := graphemeCount(tt.text); got != tt.want {
+			t.Errorf("graphemeCount(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFitToLimit(t *testing.T) {
+	sentence := "This sentence is exactly fifty graphemes long ok. " // 50 graphemes
+	tests := []struct {
+		name        string
+		text        string
+		want        string
+		wantTrimmed bool
+	}{
+		{
+			name: "Short text is untouched",
+			text: "Feeling great about Go! #golang ",
+			want: "Feeling great about Go! #golang ",
+		},
+		{
+			name: "Exactly at the limit is untouched",
+			text: strings.Repeat("🎉", MaxPostGraphemes),
+			want: strings.Repeat("🎉", MaxPostGraphemes),
+		},
+		{
+			name:        "Trailing sentences are dropped",
+			text:        strings.Repeat(sentence, 7),
+			want:        strings.TrimSpace(strings.Repeat(sentence, 6)),
+			wantTrimmed: true,
+		},
+		{
+			name:        "One long sentence is ellipsized at a word",
+			text:        strings.Repeat("word ", 80),
+			want:        strings.TrimSpace(strings.Repeat("word ", 59)) + "…",
+			wantTrimmed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, trimmed := FitToLimit(tt.text)
+			if got != tt.want || trimmed != tt.wantTrimmed {
+				t.Errorf("FitToLimit() = %q, %v; want %q, %v", got, trimmed, tt.want, tt.wantTrimmed)
+			}
+			if n := graphemeCount(got); n > MaxPostGraphemes {
+				t.Errorf("Result has %d graphemes, over the limit", n)
+			}
+		})
+	}
+}
+
+func TestFitToLimitCountsGraphemesNotRunes(t *testing.T) {
+	// Each family emoji is one grapheme made of seven runes
+	family := "👨\u200d👩\u200d👧\u200d👦"
+	text := strings.Repeat(family+" ", 200)
+
+	got, trimmed := FitToLimit(text)
+	if !trimmed {
+		t.Fatal("Expected the text to be trimmed")
+	}
+	if n := graphemeCount(got); n > MaxPostGraphemes {
+		t.Errorf("Result has %d graphemes, over the limit", n)
+	}
+	if !strings.HasSuffix(got, family+"…") {
+		t.Errorf("Expected whole emoji before the ellipsis, got suffix %q", got[len(got)-40:])
+	}
+}
+
+func TestGeneratePostFitToLimit(t *testing.T) {
+	long := strings.Repeat("Go is a great language for tools. ", 12)
+	stub := &stubSuggester{suggestion: long}
+	registerStubSuggester(t, "stub", stub)
+	cfg := config.Config{Suggester: "stub"}
+
+	result, err := GeneratePost(cfg, map[string]interface{}{"topic": "Go", "fitToLimit": true})
+	if err != nil {
+		t.Fatalf("GeneratePost() unexpected error: %v", err)
+	}
+	resultMap := result.(map[string]interface{})
+	suggestion := resultMap["suggestion"].(string)
+	if resultMap["trimmed"] != true || graphemeCount(suggestion) > MaxPostGraphemes {
+		t.Errorf("Got %d graphemes, trimmed = %v; want a trimmed suggestion within the limit", graphemeCount(suggestion), resultMap["trimmed"])
+	}
+
+	// A short suggestion is returned as is
+	stub.suggestion = "Short and sweet."
+	result, err = GeneratePost(cfg, map[string]interface{}{"topic": "Go", "fitToLimit": true})
+	if err != nil {
+		t.Fatalf("GeneratePost() unexpected error: %v", err)
+	}
+	resultMap = result.(map[string]interface{})
+	if resultMap["suggestion"] != "Short and sweet." || resultMap["trimmed"] != false {
+		t.Errorf("Result = %v, want the suggestion untouched", resultMap)
+	}
+
+	// Without the option the suggestion is not trimmed
+	stub.suggestion = long
+	result, err = GeneratePost(cfg, map[string]interface{}{"topic": "Go"})
+	if err != nil {
+		t.Fatalf("GeneratePost() unexpected error: %v", err)
+	}
+	if got := result.(map[string]string)["suggestion"]; got != long {
+		t.Errorf("Suggestion was changed without fitToLimit")
+	}
+}
@@ -0,0 +1,201 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/cache"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Hashtag suggestion limits
+const (
+	DefaultHashtagSuggestions = 5
+	MaxHashtagSuggestions     = 20
+	hashtagSearchLimit        = 100 // Recent posts searched per topic, the API maximum
+	hashtagCacheTTL           = 15 * time.Minute
+)
+
+// hashtagCache keeps the ranked hashtags of each topic, so repeated suggestions
+// for a topic while composing do not search again
+var hashtagCache = cache.NewWithOptions(cache.CacheOptions{
+	MaxItems:        500,
+	DefaultTTL:      hashtagCacheTTL,
+	CleanupInterval: 5 * time.Minute,
+})
+
+// searchPosts runs a post search, can be replaced for testing
+var searchPosts = func(cfg config.Config, query url.Values) ([]byte, error) {
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("authentication error: %w", err)
+	}
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+	return client.Get("app.bsky.feed.searchPosts", query)
+}
+
+// hashtagPattern finds hashtags in post text: a '#' at the start or after
+// whitespace, followed by letters, marks, digits or underscores
+var hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{M}\p{N}_]+)`)
+
+// tagFacetType is the rich text facet feature marking a hashtag
+const tagFacetType = "app.bsky.richtext.facet#tag"
+
+// searchedPost is the part of a search result used to find its hashtags
+type searchedPost struct {
+	Record struct {
+		Text   string   `json:"text"`
+		Tags   []string `json:"tags"`
+		Facets []struct {
+			Features []struct {
+				Type string `json:"$type"`
+				Tag  string `json:"tag"`
+			} `json:"features"`
+		} `json:"facets"`
+	} `json:"record"`
+}
+
+// SuggestHashtags searches recent posts about topic and returns up to n of the
+// hashtags used most often alongside it, most frequent first. The topic's own
+// hashtags are left out. Rankings are cached by topic.
+func SuggestHashtags(cfg config.Config, topic string, n int) ([]string, error) {
+	topic = strings.TrimSpace(SanitizeText(topic))
+	if topic == "" {
+		return nil, fmt.Errorf("invalid parameter: topic is required")
+	}
+	if err := cfg.Limits.CheckLength(config.FieldTopic, topic); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > MaxHashtagSuggestions {
+		return nil, fmt.Errorf("invalid parameter: count must be between 1 and %d", MaxHashtagSuggestions)
+	}
+
+	cacheKey := "hashtags:" + strings.ToLower(topic)
+	ranked, err := hashtagCache.GetWithLoader(cacheKey, hashtagCacheTTL, func() (interface{}, error) {
+		return rankTopicHashtags(cfg, topic)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hashtag suggestion failed: %w", err)
+	}
+
+	hashtags := ranked.([]string)
+	if len(hashtags) > n {
+		hashtags = hashtags[:n]
+	}
+	return append([]string(nil), hashtags...), nil
+}
+
+// rankTopicHashtags searches the latest posts about topic and ranks every
+// hashtag found in them, keeping MaxHashtagSuggestions
+func rankTopicHashtags(cfg config.Config, topic string) ([]string, error) {
+	query := url.Values{}
+	query.Set("q", topic)
+	query.Set("sort", "latest")
+	query.Set("limit", fmt.Sprintf("%d", hashtagSearchLimit))
+
+	data, err := searchPosts(cfg, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Posts []searchedPost `json:"posts"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing search response: %w", err)
+	}
+
+	ranked := rankHashtags(resp.Posts, topicHashtags(topic))
+	if len(ranked) > MaxHashtagSuggestions {
+		ranked = ranked[:MaxHashtagSuggestions]
+	}
+	return ranked, nil
+}
+
+// rankHashtags counts the posts using each hashtag, skipping those in exclude,
+// and orders them by count, then alphabetically
+func rankHashtags(posts []searchedPost, exclude map[string]bool) []string {
+	counts := make(map[string]int)
+	for _, p := range posts {
+		for tag := range postHashtags(p) {
+			if !exclude[tag] {
+				counts[tag]++
+			}
+		}
+	}
+
+	ranked := make([]string, 0, len(counts))
+	for tag := range counts {
+		ranked = append(ranked, tag)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if counts[ranked[i]] != counts[ranked[j]] {
+			return counts[ranked[i]] > counts[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	return ranked
+}
+
+// postHashtags returns the distinct hashtags of a post from its tag facets,
+// its tags field and its text, so each post counts once per hashtag
+func postHashtags(p searchedPost) map[string]bool {
+	tags := make(map[string]bool)
+	add := func(tag string) {
+		if tag = normalizeTag(tag); tag != "" {
+			tags[tag] = true
+		}
+	}
+
+	for _, facet := range p.Record.Facets {
+		for _, feature := range facet.Features {
+			if feature.Type == tagFacetType {
+				add(feature.Tag)
+			}
+		}
+	}
+	for _, tag := range p.Record.Tags {
+		add(tag)
+	}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(p.Record.Text, -1) {
+		add(match[1])
+	}
+	return tags
+}
+
+// topicHashtags returns the hashtags a topic already stands for: each word of
+// the topic and the words run together
+func topicHashtags(topic string) map[string]bool {
+	tags := make(map[string]bool)
+	words := strings.Fields(topic)
+	for _, word := range words {
+		if tag := normalizeTag(word); tag != "" {
+			tags[tag] = true
+		}
+	}
+	if tag := normalizeTag(strings.Join(words, "")); tag != "" {
+		tags[tag] = true
+	}
+	return tags
+}
+
+// normalizeTag lowercases a hashtag without its '#', returning "" for tags
+// that are only digits, which Bluesky does not treat as hashtags
+func normalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+	tag = strings.TrimFunc(tag, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) && r != '_'
+	})
+	if strings.IndexFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	return tag
+}
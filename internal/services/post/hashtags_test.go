@@ -0,0 +1,129 @@
+package post
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// hashtagSearchFixture is a search response mixing tag facets, the tags field
+// and hashtags only present in the text
+const hashtagSearchFixture = `{"posts": [
+	{"record": {"text": "Learning Go today #golang #gophers", "facets": [
+		{"features": [{"$type": "app.bsky.richtext.facet#tag", "tag": "golang"}]},
+		{"features": [{"$type": "app.bsky.richtext.facet#tag", "tag": "gophers"}]}
+	]}},
+	{"record": {"text": "Go 1.22 is out! #GoLang #Programming #gophers #gophers"}},
+	{"record": {"text": "Generics in go", "tags": ["programming", "backend"]}},
+	{"record": {"text": "#Gophers meetup tonight, bring #1 snacks"}},
+	{"record": {"text": "No tags here, just a url.com/#anchor"}}
+]}`
+
+// stubSearch replaces searchPosts with a fixture, recording the queries
+func stubSearch(t *testing.T, response string) *[]url.Values {
+	t.Helper()
+	original := searchPosts
+	t.Cleanup(func() {
+		searchPosts = original
+		hashtagCache.Clear()
+	})
+	hashtagCache.Clear()
+
+	var queries []url.Values
+	searchPosts = func(cfg config.Config, query url.Values) ([]byte, error) {
+		queries = append(queries, query)
+		return []byte(response), nil
+	}
+	return &queries
+}
+
+func TestSuggestHashtags(t *testing.T) {
+	queries := stubSearch(t, hashtagSearchFixture)
+
+	got, err := SuggestHashtags(config.Config{}, "golang", 3)
+	if err != nil {
+		t.Fatalf("SuggestHashtags() unexpected error: %v", err)
+	}
+
+	// gophers is in three posts and programming in two; the topic itself and
+	// digit-only tags are left out, and ties are ordered alphabetically
+	want := []string{"gophers", "programming", "backend"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestHashtags() = %v, want %v", got, want)
+	}
+
+	if len(*queries) != 1 {
+		t.Fatalf("Expected 1 search, got %d", len(*queries))
+	}
+	if q := (*queries)[0]; q.Get("q") != "golang" || q.Get("sort") != "latest" {
+		t.Errorf("Unexpected search query: %v", q)
+	}
+
+	// The ranking is cached by topic, whatever the count or case
+	got, err = SuggestHashtags(config.Config{}, "GoLang", 1)
+	if err != nil {
+		t.Fatalf("SuggestHashtags() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"gophers"}) {
+		t.Errorf("SuggestHashtags() = %v, want [gophers]", got)
+	}
+	if len(*queries) != 1 {
+		t.Errorf("Expected the cached ranking to be used, got %d searches", len(*queries))
+	}
+}
+
+func TestSuggestHashtagsExcludesTopicWords(t *testing.T) {
+	stubSearch(t, hashtagSearchFixture)
+
+	got, err := SuggestHashtags(config.Config{}, "gophers programming", 5)
+	if err != nil {
+		t.Fatalf("SuggestHashtags() unexpected error: %v", err)
+	}
+	if want := []string{"golang", "backend"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestHashtags() = %v, want %v", got, want)
+	}
+}
+
+func TestSuggestHashtagsValidation(t *testing.T) {
+	stubSearch(t, `{"posts": []}`)
+
+	tests := []struct {
+		name    string
+		topic   string
+		n       int
+		wantErr string
+	}{
+		{name: "Empty topic", topic: "  ", n: 5, wantErr: "topic is required"},
+		{name: "Zero count", topic: "golang", n: 0, wantErr: "count must be between"},
+		{name: "Count too high", topic: "golang", n: MaxHashtagSuggestions + 1, wantErr: "count must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SuggestHashtags(config.Config{}, tt.topic, tt.n)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// A topic without related hashtags gives an empty list
+	got, err := SuggestHashtags(config.Config{}, "obscure", 5)
+	if err != nil || len(got) != 0 {
+		t.Errorf("SuggestHashtags() = %v, %v; want no suggestions", got, err)
+	}
+}
+
+func TestSuggestHashtagsSearchError(t *testing.T) {
+	stubSearch(t, "")
+	searchPosts = func(cfg config.Config, query url.Values) ([]byte, error) {
+		return nil, fmt.Errorf("API error (status 503)")
+	}
+
+	if _, err := SuggestHashtags(config.Config{}, "golang", 5); err == nil || !strings.Contains(err.Error(), "hashtag suggestion failed") {
+		t.Errorf("Expected a suggestion error, got %v", err)
+	}
+}
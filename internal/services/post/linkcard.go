@@ -0,0 +1,304 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Link card fetch limits
+const (
+	LinkCardTimeout     = 10 * time.Second // Used when the context has no deadline
+	MaxLinkCardPageSize = 1 << 20          // Only the start of larger pages is parsed
+)
+
+// ErrLinkCardAddressBlocked is returned when a link card page or image is on
+// an address that is not on the public internet
+var ErrLinkCardAddressBlocked = errors.New("link card address not allowed")
+
+// allowPrivateLinkCardAddresses lets link cards be fetched from any address,
+// can be replaced for testing
+var allowPrivateLinkCardAddresses = false
+
+// linkCardClient fetches link card pages and images. URLs come from clients
+// and the pages they name, so the dialer refuses addresses in
+// blockedLinkCardNets, which are not on the public internet. The check runs on every connection, after DNS
+// resolution, so redirects and names resolving to such addresses are covered.
+var linkCardClient = &http.Client{
+	Timeout: LinkCardTimeout,
+	Transport: &http.Transport{
+		// No proxy, as it would connect on our behalf and bypass the check
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: checkLinkCardAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// checkLinkCardAddress rejects connections to addresses that are not public
+func checkLinkCardAddress(network, address string, _ syscall.RawConn) error {
+	if allowPrivateLinkCardAddresses {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrLinkCardAddressBlocked, host)
+	}
+	return nil
+}
+
+// blockedLinkCardNets are the special-purpose ranges that are not on the
+// public internet, from the IANA IPv4 and IPv6 special-purpose registries
+var blockedLinkCardNets = parseCIDRs(
+	"0.0.0.0/8",       // "This network"
+	"10.0.0.0/8",      // Private
+	"100.64.0.0/10",   // Shared address space (CGNAT)
+	"127.0.0.0/8",     // Loopback
+	"169.254.0.0/16",  // Link-local, including cloud metadata services
+	"172.16.0.0/12",   // Private
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // Documentation
+	"192.88.99.0/24",  // 6to4 relay anycast
+	"192.168.0.0/16",  // Private
+	"198.18.0.0/15",   // Benchmarking
+	"198.51.100.0/24", // Documentation
+	"203.0.113.0/24",  // Documentation
+	"224.0.0.0/4",     // Multicast
+	"240.0.0.0/4",     // Reserved, including broadcast
+	"::/96",           // Unspecified, loopback and deprecated IPv4-compatible
+	"64:ff9b:1::/48",  // Local-use NAT64
+	"100::/64",        // Discard-only
+	"2001::/23",       // IETF protocol assignments, including Teredo
+	"2001:db8::/32",   // Documentation
+	"2002::/16",       // 6to4, which embeds an IPv4 address
+	"fc00::/7",        // Unique local
+	"fe80::/10",       // Link-local
+	"fec0::/10",       // Deprecated site-local
+	"ff00::/8",        // Multicast
+)
+
+// nat64Net is the well-known NAT64 prefix, whose addresses reach the IPv4
+// address in their last 32 bits
+var nat64Net = parseCIDRs("64:ff9b::/96")[0]
+
+// parseCIDRs parses a fixed list of ranges, panicking on a malformed one
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = network
+	}
+	return nets
+}
+
+// isPublicIP reports whether ip is a routable address on the public internet.
+// IPv4-mapped and NAT64 addresses are judged by the IPv4 address they reach.
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if nat64Net.Contains(ip) {
+		ip = ip[net.IPv6len-net.IPv4len:]
+	}
+	for _, network := range blockedLinkCardNets {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// LinkCard is the preview metadata of a web page
+type LinkCard struct {
+	URI         string `json:"uri"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Image       []byte `json:"image,omitempty"` // Downloaded og:image, omitted if missing, too large or not an image
+}
+
+// ExternalCard converts the metadata to a post's link card, using the image as its thumbnail
+func (l LinkCard) ExternalCard() ExternalCard {
+	return ExternalCard{URI: l.URI, Title: l.Title, Description: l.Description, Thumb: l.Image}
+}
+
+var (
+	metaTagPattern    = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrPattern       = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	titleTagPattern   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// FetchLinkCard fetches the page at pageURL and reads its OpenGraph title,
+// description and image, falling back to the <title> tag and the description
+// meta tag. Missing metadata is left empty rather than reported as an error.
+func FetchLinkCard(ctx context.Context, pageURL string) (LinkCard, error) {
+	card := LinkCard{URI: pageURL}
+	if err := (ExternalCard{URI: pageURL}).Validate(); err != nil {
+		return card, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, LinkCardTimeout)
+		defer cancel()
+	}
+
+	page, contentType, err := fetchLimited(ctx, pageURL, MaxLinkCardPageSize)
+	if err != nil {
+		return card, fmt.Errorf("failed to fetch link card: %w", err)
+	}
+	if !strings.Contains(contentType, "html") {
+		// Not a web page, so there is no metadata to read
+		return card, nil
+	}
+
+	meta := parseMetaTags(string(page))
+	card.Title = firstNonEmpty(meta["og:title"], meta["twitter:title"], parseTitleTag(string(page)))
+	card.Description = firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"])
+
+	if image := firstNonEmpty(meta["og:image"], meta["og:image:url"], meta["twitter:image"]); image != "" {
+		if imageURL, err := resolveURL(pageURL, image); err == nil {
+			card.ImageURL = imageURL
+			card.Image = fetchCardImage(ctx, imageURL)
+		}
+	}
+
+	return card, nil
+}
+
+// CompleteExternalCard fills in a card given only a URI from the page's metadata.
+// Cards with a title, description or thumbnail are returned unchanged. If the
+// page cannot be fetched, the URI-only card is returned along with the error so
+// callers can still post it.
+func CompleteExternalCard(ctx context.Context, card ExternalCard) (ExternalCard, error) {
+	if card.Title != "" || card.Description != "" || len(card.Thumb) > 0 {
+		return card, nil
+	}
+
+	linkCard, err := FetchLinkCard(ctx, card.URI)
+	if err != nil {
+		return card, err
+	}
+	return linkCard.ExternalCard(), nil
+}
+
+// fetchLimited GETs rawURL and reads at most limit bytes of the body
+func fetchLimited(ctx context.Context, rawURL string, limit int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "bluesky-mcp link card fetcher")
+
+	resp, err := linkCardClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("status %d from %s", resp.StatusCode, rawURL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, "", err
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// fetchCardImage downloads the card image, returning nil if it fails, is larger
+// than MaxThumbSize or is not an image; the card is still usable without it
+func fetchCardImage(ctx context.Context, imageURL string) []byte {
+	data, _, err := fetchLimited(ctx, imageURL, MaxThumbSize+1)
+	if err != nil || len(data) == 0 || len(data) > MaxThumbSize {
+		return nil
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		return nil
+	}
+	return data
+}
+
+// parseMetaTags maps each meta tag's property or name, lowercased, to its content.
+// The first tag wins when a page repeats one.
+func parseMetaTags(page string) map[string]string {
+	meta := make(map[string]string)
+	for _, tag := range metaTagPattern.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, match := range attrPattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(match[1])] = match[2] + match[3]
+		}
+
+		key := strings.ToLower(firstNonEmpty(attrs["property"], attrs["name"]))
+		content := cleanText(attrs["content"])
+		if key == "" || content == "" {
+			continue
+		}
+		if _, seen := meta[key]; !seen {
+			meta[key] = content
+		}
+	}
+	return meta
+}
+
+// parseTitleTag returns the text of the page's <title> tag
+func parseTitleTag(page string) string {
+	match := titleTagPattern.FindStringSubmatch(page)
+	if match == nil {
+		return ""
+	}
+	return cleanText(match[1])
+}
+
+// cleanText decodes HTML entities and collapses whitespace
+func cleanText(text string) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(html.UnescapeString(text), " "))
+}
+
+// resolveURL resolves ref, which may be relative, against the page URL
+func resolveURL(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	resolved := baseURL.ResolveReference(refURL)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return "", fmt.Errorf("unsupported image URL %q", ref)
+	}
+	return resolved.String(), nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package post
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newLinkCardServer serves pages with and without OpenGraph tags and their images
+func newLinkCardServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/og", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html><html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="Go 1.22 is released">
+<meta content='Range over integers &amp; more' property='og:description'>
+<meta property="og:image" content="/images/card.png">
+</head><body>Release notes</body></html>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><TITLE>
+  A page without   OpenGraph
+</TITLE><meta name="description" content="Just a description"></head></html>`))
+	})
+	mux.HandleFunc("/bare", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>No metadata at all</body></html>`))
+	})
+	mux.HandleFunc("/large-image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<meta property="og:title" content="Big"><meta property="og:image" content="/images/huge.png">`))
+	})
+	mux.HandleFunc("/file.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	})
+	mux.HandleFunc("/images/card.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngHeader)
+	})
+	mux.HandleFunc("/images/huge.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(append(pngHeader, make([]byte, MaxThumbSize)...))
+	})
+
+	// The test server listens on loopback, which link cards normally refuse
+	allowPrivateLinkCardAddresses = true
+	t.Cleanup(func() { allowPrivateLinkCardAddresses = false })
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchLinkCardOpenGraph(t *testing.T) {
+	server := newLinkCardServer(t)
+
+	card, err := FetchLinkCard(context.Background(), server.URL+"/og")
+	if err != nil {
+		t.Fatalf("FetchLinkCard() unexpected error: %v", err)
+	}
+	if card.URI != server.URL+"/og" {
+		t.Errorf("URI = %q, want the page URL", card.URI)
+	}
+	if card.Title != "Go 1.22 is released" {
+		t.Errorf("Title = %q, want the og:title", card.Title)
+	}
+	if card.Description != "Range over integers & more" {
+		t.Errorf("Description = %q, want the decoded og:description", card.Description)
+	}
+	if card.ImageURL != server.URL+"/images/card.png" {
+		t.Errorf("ImageURL = %q, want the resolved og:image", card.ImageURL)
+	}
+	if !bytes.Equal(card.Image, pngHeader) {
+		t.Errorf("Image = %v, want the downloaded image", card.Image)
+	}
+
+	external := card.ExternalCard()
+	if external.Title != card.Title || !bytes.Equal(external.Thumb, pngHeader) {
+		t.Errorf("ExternalCard() = %+v, want the metadata with the image as thumb", external)
+	}
+}
+
+func TestFetchLinkCardFallbacks(t *testing.T) {
+	server := newLinkCardServer(t)
+
+	tests := []struct {
+		name            string
+		path            string
+		wantTitle       string
+		wantDescription string
+	}{
+		{name: "Title tag and description", path: "/plain", wantTitle: "A page without OpenGraph", wantDescription: "Just a description"},
+		{name: "No metadata", path: "/bare"},
+		{name: "Not HTML", path: "/file.pdf"},
+		{name: "Image too large", path: "/large-image", wantTitle: "Big"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := FetchLinkCard(context.Background(), server.URL+tt.path)
+			if err != nil {
+				t.Fatalf("FetchLinkCard() unexpected error: %v", err)
+			}
+			if card.Title != tt.wantTitle || card.Description != tt.wantDescription {
+				t.Errorf("FetchLinkCard() = %q / %q, want %q / %q", card.Title, card.Description, tt.wantTitle, tt.wantDescription)
+			}
+			if card.Image != nil {
+				t.Errorf("Expected no image, got %d bytes", len(card.Image))
+			}
+		})
+	}
+}
+
+func TestFetchLinkCardErrors(t *testing.T) {
+	server := newLinkCardServer(t)
+
+	if _, err := FetchLinkCard(context.Background(), server.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Expected status 404 error, got %v", err)
+	}
+	if _, err := FetchLinkCard(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("Expected error for a non-HTTP URL")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FetchLinkCard(ctx, server.URL+"/og"); err == nil {
+		t.Error("Expected error for a cancelled context")
+	}
+}
+
+func TestFetchLinkCardBlockedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL)
+	}))
+	defer server.Close()
+
+	for _, pageURL := range []string{
+		server.URL + "/og",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://[::1]/",
+		"http://0.0.0.0/",
+	} {
+		if _, err := FetchLinkCard(context.Background(), pageURL); !errors.Is(err, ErrLinkCardAddressBlocked) {
+			t.Errorf("FetchLinkCard(%s) error = %v, want ErrLinkCardAddressBlocked", pageURL, err)
+		}
+	}
+}
+
+func TestCheckLinkCardAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"127.0.0.1:80", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"0.0.0.0:80", false},
+		{"[::1]:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"100.64.0.1:80", false},
+		{"100.127.255.254:80", false},
+		{"0.1.2.3:80", false},
+		{"198.18.0.1:80", false},
+		{"198.19.255.255:80", false},
+		{"240.0.0.1:80", false},
+		{"255.255.255.255:80", false},
+		{"224.0.0.1:80", false},
+		{"[::ffff:10.0.0.1]:80", false},
+		{"[::ffff:100.64.0.1]:80", false},
+		{"[64:ff9b::a9fe:a9fe]:80", false},
+		{"[64:ff9b::c0a8:101]:80", false},
+		{"[64:ff9b::5db8:d822]:443", true},
+		{"[64:ff9b:1::1]:80", false},
+		{"[2002:a00:1::1]:80", false},
+		{"[2001:db8::1]:80", false},
+		{"[ff02::1]:80", false},
+		{"100.128.0.1:443", true},
+		{"198.20.0.1:443", true},
+	}
+	for _, tt := range tests {
+		err := checkLinkCardAddress("tcp", tt.address, nil)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("checkLinkCardAddress(%s) error = %v, want allowed %v", tt.address, err, tt.allowed)
+		}
+	}
+}
+
+func TestCompleteExternalCard(t *testing.T) {
+	server := newLinkCardServer(t)
+
+	// A URI-only card is filled in from the page
+	card, err := CompleteExternalCard(context.Background(), ExternalCard{URI: server.URL + "/og"})
+	if err != nil {
+		t.Fatalf("CompleteExternalCard() unexpected error: %v", err)
+	}
+	if card.Title != "Go 1.22 is released" || len(card.Thumb) == 0 {
+		t.Errorf("CompleteExternalCard() = %+v, want the page metadata", card)
+	}
+
+	// Cards with metadata are left alone
+	given := ExternalCard{URI: server.URL + "/og", Title: "My own title"}
+	if card, _ := CompleteExternalCard(context.Background(), given); card.Title != "My own title" || card.Thumb != nil {
+		t.Errorf("CompleteExternalCard() = %+v, want the card unchanged", card)
+	}
+
+	// On failure the URI-only card is still returned
+	card, err = CompleteExternalCard(context.Background(), ExternalCard{URI: server.URL + "/missing"})
+	if err == nil {
+		t.Error("Expected fetch error")
+	}
+	if card.URI != server.URL+"/missing" {
+		t.Errorf("CompleteExternalCard() = %+v, want the URI-only card", card)
+	}
+}
@@ -0,0 +1,214 @@
+package post
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+)
+
+// PostRef is a strong reference to an existing post
+type PostRef struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+// ReplyRef identifies the thread a reply belongs to
+type ReplyRef struct {
+	Root   PostRef `json:"root"`
+	Parent PostRef `json:"parent"`
+}
+
+// SubmitPostOptions contains optional settings for a submitted post
+type SubmitPostOptions struct {
+	Reply      *ReplyRef     `json:"reply,omitempty"`
+	Quote      *PostRef      `json:"quote,omitempty"`
+	Force      bool          `json:"force,omitempty"`      // Skip the duplicate post check
+	Labels     []string      `json:"labels,omitempty"`     // Self-labels, see SelfLabelValues
+	Threadgate *Threadgate   `json:"threadgate,omitempty"` // Limits who can reply
+	External   *ExternalCard `json:"external,omitempty"`   // Link preview card
+	Tags       []string      `json:"tags,omitempty"`       // Searchable tags kept out of the text, see MaxPostTags
+}
+
+// Limits on the record-level tags of a post, from the app.bsky.feed.post lexicon
+const (
+	MaxPostTags     = 8   // Tags per post
+	MaxTagGraphemes = 64  // Graphemes per tag
+	MaxTagBytes     = 640 // UTF-8 bytes per tag
+)
+
+// SelfLabelValues are the label values authors may apply to their own posts
+var SelfLabelValues = []string{
+	"!warn",
+	"!no-unauthenticated",
+	"sexual",
+	"nudity",
+	"porn",
+	"graphic-media",
+}
+
+// isSelfLabel reports whether value is a known self-label
+func isSelfLabel(value string) bool {
+	for _, known := range SelfLabelValues {
+		if value == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that all provided references are complete and consistent
+func (o SubmitPostOptions) Validate() error {
+	var missing []string
+
+	if o.Reply != nil {
+		missing = append(missing, missingRefFields("reply.root", o.Reply.Root)...)
+		missing = append(missing, missingRefFields("reply.parent", o.Reply.Parent)...)
+	}
+	if o.Quote != nil {
+		missing = append(missing, missingRefFields("quote", *o.Quote)...)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("invalid post options: missing or invalid %s", strings.Join(missing, ", "))
+	}
+
+	for _, label := range o.Labels {
+		if !isSelfLabel(label) {
+			return fmt.Errorf("invalid post options: unknown self-label %q (allowed: %s)", label, strings.Join(SelfLabelValues, ", "))
+		}
+	}
+
+	tags := normalizeTags(o.Tags)
+	if len(tags) > MaxPostTags {
+		return fmt.Errorf("invalid post options: at most %d tags are allowed, got %d", MaxPostTags, len(tags))
+	}
+	for _, tag := range tags {
+		if tag == "" {
+			return fmt.Errorf("invalid post options: tags cannot be empty")
+		}
+		if graphemeCount(tag) > MaxTagGraphemes || len(tag) > MaxTagBytes {
+			return fmt.Errorf("invalid post options: tag %q is longer than %d characters", tag, MaxTagGraphemes)
+		}
+	}
+
+	if o.Threadgate != nil {
+		if err := o.Threadgate.Validate(); err != nil {
+			return fmt.Errorf("invalid post options: %w", err)
+		}
+	}
+
+	if o.External != nil {
+		if err := o.External.Validate(); err != nil {
+			return fmt.Errorf("invalid post options: %w", err)
+		}
+	}
+
+	// Root and parent must agree when they point at the same post
+	if o.Reply != nil && o.Reply.Root.URI == o.Reply.Parent.URI && o.Reply.Root.CID != o.Reply.Parent.CID {
+		return fmt.Errorf("invalid post options: reply root and parent reference the same post with different CIDs")
+	}
+
+	return nil
+}
+
+// missingRefFields returns the names of the fields of ref that are empty or malformed
+func missingRefFields(name string, ref PostRef) []string {
+	var missing []string
+	if !strings.HasPrefix(ref.URI, "at://") {
+		missing = append(missing, name+".uri")
+	}
+	if strings.TrimSpace(ref.CID) == "" {
+		missing = append(missing, name+".cid")
+	}
+	return missing
+}
+
+// buildPostRecord creates the app.bsky.feed.post record for the given text and options.
+// thumb is the uploaded thumbnail of the external card, if any.
+func buildPostRecord(text string, createdAt string, opts SubmitPostOptions, thumb *repo.Blob) map[string]interface{} {
+	record := map[string]interface{}{
+		"$type":     "app.bsky.feed.post",
+		"text":      text,
+		"createdAt": createdAt,
+	}
+
+	if opts.Reply != nil {
+		record["reply"] = map[string]interface{}{
+			"root":   refToMap(opts.Reply.Root),
+			"parent": refToMap(opts.Reply.Parent),
+		}
+	}
+
+	switch {
+	case opts.Quote != nil && opts.External != nil:
+		// A quote with a link card embeds both as record with media
+		record["embed"] = map[string]interface{}{
+			"$type": "app.bsky.embed.recordWithMedia",
+			"record": map[string]interface{}{
+				"$type":  "app.bsky.embed.record",
+				"record": refToMap(*opts.Quote),
+			},
+			"media": buildExternalEmbed(*opts.External, thumb),
+		}
+	case opts.Quote != nil:
+		record["embed"] = map[string]interface{}{
+			"$type":  "app.bsky.embed.record",
+			"record": refToMap(*opts.Quote),
+		}
+	case opts.External != nil:
+		record["embed"] = buildExternalEmbed(*opts.External, thumb)
+	}
+
+	if len(opts.Labels) > 0 {
+		record["labels"] = buildSelfLabels(opts.Labels)
+	}
+
+	if tags := normalizeTags(opts.Tags); len(tags) > 0 {
+		record["tags"] = tags
+	}
+
+	return record
+}
+
+// normalizeTags trims tags and their leading '#', dropping repeats so that
+// "#golang" and "golang" count as one tag
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// buildSelfLabels creates the com.atproto.label.defs#selfLabels object, skipping repeated values
+func buildSelfLabels(labels []string) map[string]interface{} {
+	seen := make(map[string]bool, len(labels))
+	values := make([]map[string]interface{}, 0, len(labels))
+	for _, label := range labels {
+		if seen[label] {
+			continue
+		}
+		seen[label] = true
+		values = append(values, map[string]interface{}{"val": label})
+	}
+
+	return map[string]interface{}{
+		"$type":  "com.atproto.label.defs#selfLabels",
+		"values": values,
+	}
+}
+
+// refToMap converts a PostRef to its record representation
+func refToMap(ref PostRef) map[string]interface{} {
+	return map[string]interface{}{
+		"uri": ref.URI,
+		"cid": ref.CID,
+	}
+}
@@ -0,0 +1,193 @@
+package post
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubmitPostOptionsValidate(t *testing.T) {
+	root := PostRef{URI: "at://did:plc:abc/app.bsky.feed.post/root", CID: "bafyreiroot"}
+	parent := PostRef{URI: "at://did:plc:abc/app.bsky.feed.post/parent", CID: "bafyreiparent"}
+	quote := PostRef{URI: "at://did:plc:xyz/app.bsky.feed.post/quoted", CID: "bafyreiquoted"}
+
+	tests := []struct {
+		name    string
+		opts    SubmitPostOptions
+		wantErr []string
+	}{
+		{
+			name: "No options",
+			opts: SubmitPostOptions{},
+		},
+		{
+			name: "Valid reply and quote",
+			opts: SubmitPostOptions{
+				Reply: &ReplyRef{Root: root, Parent: parent},
+				Quote: &quote,
+			},
+		},
+		{
+			name: "Reply to the root post",
+			opts: SubmitPostOptions{
+				Reply: &ReplyRef{Root: root, Parent: root},
+			},
+		},
+		{
+			name: "Reply missing parent CID",
+			opts: SubmitPostOptions{
+				Reply: &ReplyRef{Root: root, Parent: PostRef{URI: parent.URI}},
+			},
+			wantErr: []string{"reply.parent.cid"},
+		},
+		{
+			name: "Quote with invalid URI and missing CID",
+			opts: SubmitPostOptions{
+				Quote: &PostRef{URI: "https://bsky.app/profile/x"},
+			},
+			wantErr: []string{"quote.uri", "quote.cid"},
+		},
+		{
+			name: "Known self-labels",
+			opts: SubmitPostOptions{Labels: []string{"!warn", "graphic-media"}},
+		},
+		{
+			name:    "Unknown self-label",
+			opts:    SubmitPostOptions{Labels: []string{"nudity", "spoiler"}},
+			wantErr: []string{"spoiler"},
+		},
+		{
+			name: "Tags within the limits",
+			opts: SubmitPostOptions{Tags: []string{"golang", "#opensource", strings.Repeat("t", MaxTagGraphemes)}},
+		},
+		{
+			name:    "Too many tags",
+			opts:    SubmitPostOptions{Tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}},
+			wantErr: []string{"at most 8 tags", "got 9"},
+		},
+		{
+			name:    "Tag too long",
+			opts:    SubmitPostOptions{Tags: []string{strings.Repeat("t", MaxTagGraphemes+1)}},
+			wantErr: []string{"longer than 64 characters"},
+		},
+		{
+			name:    "Empty tag",
+			opts:    SubmitPostOptions{Tags: []string{"golang", " # "}},
+			wantErr: []string{"tags cannot be empty"},
+		},
+		{
+			name: "Inconsistent root and parent",
+			opts: SubmitPostOptions{
+				Reply: &ReplyRef{Root: root, Parent: PostRef{URI: root.URI, CID: "bafyreiother"}},
+			},
+			wantErr: []string{"root and parent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() expected error mentioning %v, got nil", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %q, want it to mention %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPostRecord(t *testing.T) {
+	root := PostRef{URI: "at://did:plc:abc/app.bsky.feed.post/root", CID: "bafyreiroot"}
+	parent := PostRef{URI: "at://did:plc:abc/app.bsky.feed.post/parent", CID: "bafyreiparent"}
+	quote := PostRef{URI: "at://did:plc:xyz/app.bsky.feed.post/quoted", CID: "bafyreiquoted"}
+
+	// Plain post has no reply or embed
+	record := buildPostRecord("hello", "2025-01-01T00:00:00Z", SubmitPostOptions{}, nil)
+	if _, ok := record["reply"]; ok {
+		t.Error("Plain post should not have a reply field")
+	}
+	if _, ok := record["embed"]; ok {
+		t.Error("Plain post should not have an embed field")
+	}
+	if _, ok := record["labels"]; ok {
+		t.Error("Plain post should not have a labels field")
+	}
+	if _, ok := record["tags"]; ok {
+		t.Error("Plain post should not have a tags field")
+	}
+
+	// Reply and quote are built side by side without conflicting
+	record = buildPostRecord("hello", "2025-01-01T00:00:00Z", SubmitPostOptions{
+		Reply: &ReplyRef{Root: root, Parent: parent},
+		Quote: &quote,
+	}, nil)
+
+	reply, ok := record["reply"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected reply field, got %v", record["reply"])
+	}
+	if reply["root"].(map[string]interface{})["cid"] != root.CID {
+		t.Errorf("Reply root = %v, want %v", reply["root"], root)
+	}
+	if reply["parent"].(map[string]interface{})["uri"] != parent.URI {
+		t.Errorf("Reply parent = %v, want %v", reply["parent"], parent)
+	}
+
+	embed, ok := record["embed"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected embed field, got %v", record["embed"])
+	}
+	if embed["$type"] != "app.bsky.embed.record" {
+		t.Errorf("Embed type = %v, want app.bsky.embed.record", embed["$type"])
+	}
+	if embed["record"].(map[string]interface{})["uri"] != quote.URI {
+		t.Errorf("Embed record = %v, want %v", embed["record"], quote)
+	}
+}
+
+func TestBuildPostRecordLabels(t *testing.T) {
+	record := buildPostRecord("sensitive", "2025-01-01T00:00:00Z", SubmitPostOptions{
+		Labels: []string{"graphic-media", "!warn", "graphic-media"},
+	}, nil)
+
+	labels, ok := record["labels"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected labels field, got %v", record["labels"])
+	}
+	if labels["$type"] != "com.atproto.label.defs#selfLabels" {
+		t.Errorf("Labels type = %v, want com.atproto.label.defs#selfLabels", labels["$type"])
+	}
+
+	values, ok := labels["values"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected label values, got %v", labels["values"])
+	}
+	if len(values) != 2 || values[0]["val"] != "graphic-media" || values[1]["val"] != "!warn" {
+		t.Errorf("Label values = %v, want graphic-media and !warn once each", values)
+	}
+}
+
+func TestBuildPostRecordTags(t *testing.T) {
+	record := buildPostRecord("hello", "2025-01-01T00:00:00Z", SubmitPostOptions{
+		Tags: []string{"golang", "#bluesky", " golang "},
+	}, nil)
+
+	tags, ok := record["tags"].([]string)
+	if !ok {
+		t.Fatalf("Expected tags field, got %v", record["tags"])
+	}
+	if len(tags) != 2 || tags[0] != "golang" || tags[1] != "bluesky" {
+		t.Errorf("Tags = %v, want golang and bluesky once each, without '#'", tags)
+	}
+	if record["text"] != "hello" {
+		t.Errorf("Text = %v, want the tags kept out of it", record["text"])
+	}
+}
@@ -0,0 +1,128 @@
+package post
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default write pacing, kept below Bluesky's createRecord budget of roughly
+// 1,666 records per hour
+const (
+	DefaultWriteRate  = 0.4 // writes per second
+	DefaultWriteBurst = 5
+)
+
+// WriteRateOptions configure the shared write pacer
+type WriteRateOptions struct {
+	Rate  float64 `json:"rate"`  // Writes per second, 0 disables pacing
+	Burst int     `json:"burst"` // Writes allowed at once before pacing starts
+}
+
+// DefaultWriteRateOptions pace writes at DefaultWriteRate with DefaultWriteBurst
+var DefaultWriteRateOptions = WriteRateOptions{
+	Rate:  DefaultWriteRate,
+	Burst: DefaultWriteBurst,
+}
+
+// WriteRateOptionsFromEnv returns the default options adjusted by the
+// BSKY_WRITE_RATE (writes per second, 0 to disable pacing) and
+// BSKY_WRITE_BURST environment variables
+func WriteRateOptionsFromEnv() WriteRateOptions {
+	options := DefaultWriteRateOptions
+	if rate, err := strconv.ParseFloat(os.Getenv("BSKY_WRITE_RATE"), 64); err == nil && rate >= 0 && !math.IsInf(rate, 1) {
+		options.Rate = rate
+	}
+	if burst, err := strconv.Atoi(os.Getenv("BSKY_WRITE_BURST")); err == nil && burst > 0 {
+		options.Burst = burst
+	}
+	return options
+}
+
+// WritePacer is a token bucket that spaces out write operations so bulk
+// actions stay within the server-side write limits. It is separate from the
+// inbound request rate limiter in the handlers package.
+type WritePacer struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second, <= 0 disables pacing
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewWritePacer creates a pacer allowing ratePerSecond writes with the given burst
+func NewWritePacer(ratePerSecond float64, burst int) *WritePacer {
+	if burst < 1 {
+		burst = 1
+	}
+	return &WritePacer{
+		rate:   ratePerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a write is allowed or the context is cancelled
+func (p *WritePacer) Wait(ctx context.Context) error {
+	p.mu.Lock()
+	if p.rate <= 0 {
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+
+	// Refill tokens for the time elapsed since the last call
+	now := time.Now()
+	p.tokens += now.Sub(p.last).Seconds() * p.rate
+	if p.tokens > p.burst {
+		p.tokens = p.burst
+	}
+	p.last = now
+
+	// Reserve a token; a negative balance is the queue of waiting writers
+	p.tokens--
+	if p.tokens >= 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-p.tokens / p.rate * float64(time.Second))
+	p.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back
+		p.mu.Lock()
+		p.tokens++
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Shared pacer for record writes, used by the post and community services
+var (
+	writePacer   = NewWritePacer(DefaultWriteRate, DefaultWriteBurst)
+	writePacerMu sync.RWMutex
+)
+
+// SetWriteRate configures the shared write pacer. A rate of 0 disables pacing.
+func SetWriteRate(ratePerSecond float64, burst int) {
+	writePacerMu.Lock()
+	defer writePacerMu.Unlock()
+	writePacer = NewWritePacer(ratePerSecond, burst)
+}
+
+// WaitForWrite blocks until the shared pacer allows another write or ctx is done
+func WaitForWrite(ctx context.Context) error {
+	writePacerMu.RLock()
+	pacer := writePacer
+	writePacerMu.RUnlock()
+	return pacer.Wait(ctx)
+}
@@ -0,0 +1,142 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestWritePacerRate(t *testing.T) {
+	// 50 writes per second with no burst beyond a single token
+	pacer := NewWritePacer(50, 1)
+
+	const operations = 6
+	start := time.Now()
+	for i := 0; i < operations; i++ {
+		if err := pacer.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() unexpected error: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// The first write uses the initial token, the rest are spaced 20ms apart
+	minimum := time.Duration(operations-1) * 20 * time.Millisecond
+	if elapsed < minimum {
+		t.Errorf("%d writes completed in %v, want at least %v", operations, elapsed, minimum)
+	}
+}
+
+func TestWritePacerBurst(t *testing.T) {
+	pacer := NewWritePacer(1, 3)
+
+	// A full bucket allows the burst through immediately
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := pacer.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Burst of 3 took %v, expected no waiting", elapsed)
+	}
+}
+
+func TestWritePacerCancellation(t *testing.T) {
+	pacer := NewWritePacer(0.5, 1)
+
+	// Use up the only token
+	if err := pacer.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() unexpected error: %v", err)
+	}
+
+	// The next write would wait two seconds; cancellation must stop it early
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := pacer.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Wait() returned after %v, expected prompt cancellation", elapsed)
+	}
+}
+
+func TestWritePacerDisabled(t *testing.T) {
+	pacer := NewWritePacer(0, 1)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := pacer.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Disabled pacer took %v, expected no waiting", elapsed)
+	}
+}
+
+func TestWriteRateOptionsFromEnv(t *testing.T) {
+	t.Setenv("BSKY_WRITE_RATE", "0.1")
+	t.Setenv("BSKY_WRITE_BURST", "2")
+
+	options := WriteRateOptionsFromEnv()
+	if options.Rate != 0.1 || options.Burst != 2 {
+		t.Errorf("WriteRateOptionsFromEnv() = %+v", options)
+	}
+
+	// Zero turns pacing off
+	t.Setenv("BSKY_WRITE_RATE", "0")
+	if options := WriteRateOptionsFromEnv(); options.Rate != 0 {
+		t.Errorf("WriteRateOptionsFromEnv() rate = %v, want 0", options.Rate)
+	}
+
+	t.Setenv("BSKY_WRITE_RATE", "-1")
+	t.Setenv("BSKY_WRITE_BURST", "none")
+	if options := WriteRateOptionsFromEnv(); options != DefaultWriteRateOptions {
+		t.Errorf("WriteRateOptionsFromEnv() = %+v, want defaults for invalid values", options)
+	}
+	t.Setenv("BSKY_WRITE_RATE", "Inf")
+	if options := WriteRateOptionsFromEnv(); options != DefaultWriteRateOptions {
+		t.Errorf("WriteRateOptionsFromEnv() = %+v, want defaults for an infinite rate", options)
+	}
+}
+
+func TestSubmitPostContextStopsWaitingForPacer(t *testing.T) {
+	// A slow pacer with its only token already spent
+	SetWriteRate(0.001, 1)
+	originalCreateRecord := createRecord
+	defer func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+	}()
+	if err := WaitForWrite(context.Background()); err != nil {
+		t.Fatalf("WaitForWrite() unexpected error: %v", err)
+	}
+
+	writes := 0
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		writes++
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := SubmitPostContext(ctx, config.Config{}, "Waiting for the pacer", SubmitPostOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SubmitPostContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SubmitPostContext() took %v, want it to stop when ctx is done", elapsed)
+	}
+	if writes != 0 {
+		t.Errorf("Got %d writes, want none once the request gave up", writes)
+	}
+}
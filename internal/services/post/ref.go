@@ -0,0 +1,183 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/identity"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// ATURI is a canonical AT URI of the form at://<did>/<collection>/<rkey>
+type ATURI string
+
+// postCollection is the collection used when the input does not name one
+const postCollection = "app.bsky.feed.post"
+
+// resolveHandle resolves a handle to a DID through the identity cache, can be replaced for testing
+var resolveHandle = identity.ResolveHandle
+
+// NormalizeRef converts a bsky.app post URL, an at:// URI or a handle/rkey
+// pair into a canonical AT URI, resolving handles to DIDs with cfg.
+//
+// Accepted forms:
+//   - https://bsky.app/profile/alice.bsky.social/post/3k2a4b
+//   - at://alice.bsky.social/app.bsky.feed.post/3k2a4b
+//   - at://did:plc:abc123/app.bsky.feed.post/3k2a4b
+//   - alice.bsky.social/3k2a4b or @alice.bsky.social/3k2a4b
+func NormalizeRef(cfg config.Config, input string) (ATURI, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("invalid reference: empty input")
+	}
+
+	actor, collection, rkey, err := splitRef(input)
+	if err != nil {
+		return "", err
+	}
+
+	if collection == "" || !strings.Contains(collection, ".") {
+		return "", fmt.Errorf("invalid reference %q: invalid collection", input)
+	}
+	if rkey == "" || strings.ContainsAny(rkey, "/?#") {
+		return "", fmt.Errorf("invalid reference %q: invalid record key", input)
+	}
+
+	did, err := resolveActor(cfg, actor)
+	if err != nil {
+		return "", err
+	}
+
+	return ATURI(fmt.Sprintf("at://%s/%s/%s", did, collection, rkey)), nil
+}
+
+// splitRef extracts the actor, collection and record key from any accepted form
+func splitRef(input string) (actor, collection, rkey string, err error) {
+	switch {
+	case strings.HasPrefix(input, "at://"):
+		parts := strings.Split(strings.TrimPrefix(input, "at://"), "/")
+		if len(parts) != 3 {
+			return "", "", "", fmt.Errorf("invalid reference %q: expected at://<actor>/<collection>/<rkey>", input)
+		}
+		return parts[0], parts[1], parts[2], nil
+
+	case strings.HasPrefix(input, "https://") || strings.HasPrefix(input, "http://"):
+		u, parseErr := url.Parse(input)
+		if parseErr != nil {
+			return "", "", "", fmt.Errorf("invalid reference %q: %w", input, parseErr)
+		}
+		if u.Host != "bsky.app" && u.Host != "www.bsky.app" {
+			return "", "", "", fmt.Errorf("invalid reference %q: unsupported host %s", input, u.Host)
+		}
+
+		// Expected path: /profile/<actor>/post/<rkey>
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) != 4 || parts[0] != "profile" || parts[2] != "post" {
+			return "", "", "", fmt.Errorf("invalid reference %q: expected https://bsky.app/profile/<actor>/post/<rkey>", input)
+		}
+		return parts[1], postCollection, parts[3], nil
+
+	default:
+		parts := strings.Split(input, "/")
+		if len(parts) != 2 {
+			return "", "", "", fmt.Errorf("invalid reference %q: expected <handle>/<rkey>", input)
+		}
+		return parts[0], postCollection, parts[1], nil
+	}
+}
+
+// resolveActor returns the DID for a DID or handle
+func resolveActor(cfg config.Config, actor string) (string, error) {
+	actor = strings.TrimPrefix(actor, "@")
+
+	if strings.HasPrefix(actor, "did:") {
+		return actor, nil
+	}
+
+	handle := strings.ToLower(actor)
+	if !strings.Contains(handle, ".") {
+		return "", fmt.Errorf("invalid reference: invalid handle %q", actor)
+	}
+
+	return resolveHandle(cfg, handle)
+}
+
+// postView is the part of a post returned by app.bsky.feed.getPosts used to build references
+type postView struct {
+	URI    string `json:"uri"`
+	CID    string `json:"cid"`
+	Record struct {
+		Reply *ReplyRef `json:"reply,omitempty"`
+	} `json:"record"`
+}
+
+// getPostView fetches a single post, can be replaced for testing
+var getPostView = func(cfg config.Config, uri ATURI) (*postView, error) {
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	client := auth.GetTokenManager(cfg).GetClient()
+	client.SetAuthToken(token)
+
+	query := url.Values{}
+	query.Set("uris", string(uri))
+
+	responseBody, err := client.Get("app.bsky.feed.getPosts", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch post: %w", err)
+	}
+
+	var result struct {
+		Posts []postView `json:"posts"`
+	}
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return nil, fmt.Errorf("error parsing get posts response: %w", err)
+	}
+	if len(result.Posts) == 0 {
+		return nil, fmt.Errorf("post not found: %s", uri)
+	}
+
+	return &result.Posts[0], nil
+}
+
+// ResolvePostRef normalizes input and looks up the post's CID
+func ResolvePostRef(cfg config.Config, input string) (*PostRef, error) {
+	uri, err := NormalizeRef(cfg, input)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := getPostView(cfg, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostRef{URI: view.URI, CID: view.CID}, nil
+}
+
+// ResolveReplyRef builds the reply references for replying to the post named by input,
+// keeping the thread root when the post is itself a reply
+func ResolveReplyRef(cfg config.Config, input string) (*ReplyRef, error) {
+	uri, err := NormalizeRef(cfg, input)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := getPostView(cfg, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := PostRef{URI: view.URI, CID: view.CID}
+	root := parent
+	if view.Record.Reply != nil {
+		root = view.Record.Reply.Root
+	}
+
+	return &ReplyRef{Root: root, Parent: parent}, nil
+}
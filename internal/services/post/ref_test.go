@@ -0,0 +1,116 @@
+package post
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// stubResolveHandle replaces handle resolution with a fixed table for the duration of a test
+func stubResolveHandle(t *testing.T, handles map[string]string) {
+	original := resolveHandle
+	t.Cleanup(func() { resolveHandle = original })
+
+	resolveHandle = func(cfg config.Config, handle string) (string, error) {
+		if did, ok := handles[handle]; ok {
+			return did, nil
+		}
+		return "", fmt.Errorf("handle %s not found", handle)
+	}
+}
+
+func TestNormalizeRef(t *testing.T) {
+	stubResolveHandle(t, map[string]string{"alice.bsky.social": "did:plc:alice123"})
+
+	const want = ATURI("at://did:plc:alice123/app.bsky.feed.post/3k2a4b")
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "bsky.app URL", input: "https://bsky.app/profile/alice.bsky.social/post/3k2a4b"},
+		{name: "bsky.app URL with DID", input: "https://bsky.app/profile/did:plc:alice123/post/3k2a4b"},
+		{name: "AT URI with DID", input: "at://did:plc:alice123/app.bsky.feed.post/3k2a4b"},
+		{name: "AT URI with handle", input: "at://Alice.bsky.social/app.bsky.feed.post/3k2a4b"},
+		{name: "Handle and rkey", input: "@alice.bsky.social/3k2a4b"},
+		{name: "Empty input", input: " ", wantErr: true},
+		{name: "Unsupported host", input: "https://example.com/profile/alice.bsky.social/post/3k2a4b", wantErr: true},
+		{name: "Profile URL without post", input: "https://bsky.app/profile/alice.bsky.social", wantErr: true},
+		{name: "AT URI without rkey", input: "at://did:plc:alice123/app.bsky.feed.post", wantErr: true},
+		{name: "Unknown handle", input: "bob.bsky.social/3k2a4b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRef(config.Config{}, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeRef(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeRef(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != want {
+				t.Errorf("NormalizeRef(%q) = %q, want %q", tt.input, got, want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRefUsesCallerConfig(t *testing.T) {
+	original := resolveHandle
+	t.Cleanup(func() { resolveHandle = original })
+	var host string
+	resolveHandle = func(cfg config.Config, handle string) (string, error) {
+		host = cfg.BskyHost
+		return "did:plc:alice123", nil
+	}
+
+	if _, err := NormalizeRef(config.Config{BskyHost: "https://pds.example"}, "alice.bsky.social/3k2a4b"); err != nil {
+		t.Fatalf("NormalizeRef() unexpected error: %v", err)
+	}
+	if host != "https://pds.example" {
+		t.Errorf("Handle resolved with host %q, want the caller's config", host)
+	}
+}
+
+func TestResolveReplyRef(t *testing.T) {
+	stubResolveHandle(t, map[string]string{"alice.bsky.social": "did:plc:alice123"})
+
+	root := PostRef{URI: "at://did:plc:bob456/app.bsky.feed.post/root", CID: "bafyreiroot"}
+
+	originalGetPostView := getPostView
+	defer func() { getPostView = originalGetPostView }()
+	getPostView = func(cfg config.Config, uri ATURI) (*postView, error) {
+		view := &postView{URI: string(uri), CID: "bafyreiparent"}
+		if uri == "at://did:plc:alice123/app.bsky.feed.post/reply" {
+			view.Record.Reply = &ReplyRef{Root: root, Parent: root}
+		}
+		return view, nil
+	}
+
+	// Replying to a top-level post uses it as both root and parent
+	ref, err := ResolveReplyRef(config.Config{}, "https://bsky.app/profile/alice.bsky.social/post/top")
+	if err != nil {
+		t.Fatalf("ResolveReplyRef() unexpected error: %v", err)
+	}
+	if ref.Root != ref.Parent || ref.Parent.CID != "bafyreiparent" {
+		t.Errorf("ResolveReplyRef() = %+v, want root and parent to be the post", ref)
+	}
+
+	// Replying to a reply keeps the thread root
+	ref, err = ResolveReplyRef(config.Config{}, "alice.bsky.social/reply")
+	if err != nil {
+		t.Fatalf("ResolveReplyRef() unexpected error: %v", err)
+	}
+	if ref.Root != root {
+		t.Errorf("Root = %+v, want %+v", ref.Root, root)
+	}
+	if ref.Parent.URI != "at://did:plc:alice123/app.bsky.feed.post/reply" {
+		t.Errorf("Parent = %+v, want the reply post", ref.Parent)
+	}
+}
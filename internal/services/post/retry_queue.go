@@ -0,0 +1,430 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// ErrQueuedForRetry is matched by the error returned when a failed post was queued
+var ErrQueuedForRetry = errors.New("queued for retry")
+
+// QueuedError is returned when a failed post was queued for retry. The queue
+// will send the post, so it must not be submitted again.
+type QueuedError struct {
+	ID  string // ID of the queued post
+	Err error  // Why the submission failed
+}
+
+func (e *QueuedError) Error() string {
+	return fmt.Sprintf("%v (%v as %s)", e.Err, ErrQueuedForRetry, e.ID)
+}
+
+func (e *QueuedError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes the error match ErrQueuedForRetry
+func (e *QueuedError) Is(target error) bool {
+	return target == ErrQueuedForRetry
+}
+
+// QueuedPost is a failed submission waiting to be retried
+type QueuedPost struct {
+	ID          string            `json:"id"`
+	Text        string            `json:"text"`
+	Options     SubmitPostOptions `json:"options"`
+	EnqueuedAt  time.Time         `json:"enqueued_at"`
+	Attempts    int               `json:"attempts"`
+	NextAttempt time.Time         `json:"next_attempt"`
+	LastError   string            `json:"last_error,omitempty"`
+
+	// inFlight is set while the item is being submitted. The item stays in the
+	// queue, and in the persisted file, until the outcome is known.
+	inFlight bool
+}
+
+// RetryQueueOptions defines how the retry queue persists and retries posts
+type RetryQueueOptions struct {
+	Directory       string        `json:"directory"`
+	Filename        string        `json:"filename"`
+	MaxAge          time.Duration `json:"max_age"`
+	InitialInterval time.Duration `json:"initial_interval"`
+	MaxInterval     time.Duration `json:"max_interval"`
+	PollInterval    time.Duration `json:"poll_interval"`
+}
+
+// DefaultRetryQueueOptions contains reasonable defaults
+var DefaultRetryQueueOptions = RetryQueueOptions{
+	Directory:       "./cache/post",
+	Filename:        "retry_queue.json",
+	MaxAge:          24 * time.Hour,
+	InitialInterval: 30 * time.Second,
+	MaxInterval:     30 * time.Minute,
+	PollInterval:    15 * time.Second,
+}
+
+// RetryQueueStatus reports the state of the retry queue
+type RetryQueueStatus struct {
+	Depth     int          `json:"depth"`
+	Delivered int64        `json:"delivered"`
+	Dropped   int64        `json:"dropped"`
+	Items     []QueuedPost `json:"items"`
+}
+
+// RetrySubmitFunc submits a queued post
+type RetrySubmitFunc func(text string, opts SubmitPostOptions) (*PostResult, error)
+
+// RetryQueue is a durable queue of failed submissions retried in the background
+type RetryQueue struct {
+	mu        sync.Mutex
+	items     []QueuedPost
+	delivered int64
+	dropped   int64
+	options   RetryQueueOptions
+	submit    RetrySubmitFunc
+	persistMu sync.Mutex
+	stop      chan bool
+	stopOnce  sync.Once
+	worker    sync.WaitGroup
+	lastID    uint64 // Sequence number of the last queued item
+}
+
+// NewRetryQueue creates a retry queue and loads any items persisted by a previous run
+func NewRetryQueue(options RetryQueueOptions, submit RetrySubmitFunc) (*RetryQueue, error) {
+	if err := os.MkdirAll(options.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("error creating retry queue directory: %w", err)
+	}
+
+	q := &RetryQueue{
+		options: options,
+		submit:  submit,
+		stop:    make(chan bool),
+	}
+
+	if err := q.loadFromDisk(); err != nil {
+		return nil, fmt.Errorf("error loading retry queue: %w", err)
+	}
+
+	return q, nil
+}
+
+// Enqueue adds a failed post to the queue and returns its ID
+func (q *RetryQueue) Enqueue(text string, opts SubmitPostOptions, cause error) string {
+	now := time.Now()
+	item := QueuedPost{
+		Text:        text,
+		Options:     opts,
+		EnqueuedAt:  now,
+		NextAttempt: now.Add(q.options.InitialInterval),
+	}
+	if cause != nil {
+		item.LastError = cause.Error()
+	}
+
+	// The sequence number keeps IDs unique when posts are queued at the same time
+	q.mu.Lock()
+	q.lastID++
+	item.ID = fmt.Sprintf("%d-%d", now.UnixNano(), q.lastID)
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+
+	q.save()
+	return item.ID
+}
+
+// Process attempts every due item once, dropping items older than MaxAge
+func (q *RetryQueue) Process() {
+	now := time.Now()
+
+	// Mark the due items in flight so submissions run without the lock while
+	// the items are still persisted
+	q.mu.Lock()
+	var due []QueuedPost
+	remaining := q.items[:0]
+	for _, item := range q.items {
+		switch {
+		case item.inFlight:
+			remaining = append(remaining, item)
+		case q.options.MaxAge > 0 && now.Sub(item.EnqueuedAt) > q.options.MaxAge:
+			q.dropped++
+		default:
+			if !now.Before(item.NextAttempt) {
+				item.inFlight = true
+				due = append(due, item)
+			}
+			remaining = append(remaining, item)
+		}
+	}
+	q.items = remaining
+	q.mu.Unlock()
+
+	for _, item := range due {
+		_, err := q.submit(item.Text, item.Options)
+
+		q.mu.Lock()
+		switch {
+		case err == nil:
+			q.delivered++
+			q.remove(item.ID)
+		case !isTransientError(err):
+			// Retrying will not help, e.g. the post was rejected
+			q.dropped++
+			q.remove(item.ID)
+		default:
+			q.update(item.ID, func(queued *QueuedPost) {
+				queued.inFlight = false
+				queued.Attempts++
+				queued.LastError = err.Error()
+				queued.NextAttempt = time.Now().Add(q.backoffFor(queued.Attempts))
+			})
+		}
+		q.mu.Unlock()
+	}
+
+	q.save()
+}
+
+// remove deletes the item with the given ID; q.mu must be held
+func (q *RetryQueue) remove(id string) {
+	for i, item := range q.items {
+		if item.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// update applies change to the item with the given ID; q.mu must be held
+func (q *RetryQueue) update(id string, change func(item *QueuedPost)) {
+	for i := range q.items {
+		if q.items[i].ID == id {
+			change(&q.items[i])
+			return
+		}
+	}
+}
+
+// backoffFor returns the delay before the next attempt after the given number of failures
+func (q *RetryQueue) backoffFor(attempts int) time.Duration {
+	delay := q.options.InitialInterval
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if q.options.MaxInterval > 0 && delay >= q.options.MaxInterval {
+			return q.options.MaxInterval
+		}
+	}
+	return delay
+}
+
+// Status returns the queue depth, counters and pending items
+func (q *RetryQueue) Status() RetryQueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := make([]QueuedPost, len(q.items))
+	copy(items, q.items)
+
+	return RetryQueueStatus{
+		Depth:     len(q.items),
+		Delivered: q.delivered,
+		Dropped:   q.dropped,
+		Items:     items,
+	}
+}
+
+// Start runs the background worker that periodically processes the queue
+func (q *RetryQueue) Start() {
+	q.worker.Add(1)
+	go func() {
+		defer q.worker.Done()
+		ticker := time.NewTicker(q.options.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				q.Process()
+			case <-q.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background worker, waiting for any submissions in progress
+// so their outcome is saved, and saves the queue
+func (q *RetryQueue) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.stop)
+	})
+	q.worker.Wait()
+	q.save()
+}
+
+// save persists the queue, logging a failure since the queue keeps running
+// from memory
+func (q *RetryQueue) save() {
+	if err := q.persistToDisk(); err != nil {
+		log.Printf("Warning: Could not save retry queue, queued posts may be lost on restart: %v", err)
+	}
+}
+
+// persistToDisk saves the queue to disk, replacing the previous file in a
+// single rename so a crash while writing leaves it intact
+func (q *RetryQueue) persistToDisk() error {
+	q.persistMu.Lock()
+	defer q.persistMu.Unlock()
+
+	// Create a snapshot of the queue
+	q.mu.Lock()
+	snapshot := make([]QueuedPost, len(q.items))
+	copy(snapshot, q.items)
+	q.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(q.options.Directory, q.options.Filename)
+	tmp := filePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filePath)
+}
+
+// loadFromDisk loads a previously persisted queue
+func (q *RetryQueue) loadFromDisk() error {
+	q.persistMu.Lock()
+	defer q.persistMu.Unlock()
+
+	filePath := filepath.Join(q.options.Directory, q.options.Filename)
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist, not an error
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var items []QueuedPost
+	if err := json.NewDecoder(file).Decode(&items); err != nil {
+		if err != io.EOF {
+			return err
+		}
+		// Empty file, not an error
+		return nil
+	}
+
+	q.mu.Lock()
+	q.items = items
+	q.mu.Unlock()
+	return nil
+}
+
+// Shared retry queue, nil when disabled
+var (
+	retryQueue   *RetryQueue
+	retryQueueMu sync.RWMutex
+)
+
+// EnableRetryQueue creates and starts the shared retry queue for failed submissions
+func EnableRetryQueue(cfg config.Config, options RetryQueueOptions) error {
+	q, err := NewRetryQueue(options, func(text string, opts SubmitPostOptions) (*PostResult, error) {
+		submitted, err := submitPost(context.Background(), cfg, text, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &submitted.PostResult, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	retryQueueMu.Lock()
+	previous := retryQueue
+	retryQueue = q
+	retryQueueMu.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+	}
+	q.Start()
+	return nil
+}
+
+// DisableRetryQueue stops the shared retry queue; pending items stay on disk
+func DisableRetryQueue() {
+	retryQueueMu.Lock()
+	q := retryQueue
+	retryQueue = nil
+	retryQueueMu.Unlock()
+
+	if q != nil {
+		q.Stop()
+	}
+}
+
+// GetRetryQueueStatus returns the status of the shared retry queue
+func GetRetryQueueStatus() (RetryQueueStatus, bool) {
+	retryQueueMu.RLock()
+	q := retryQueue
+	retryQueueMu.RUnlock()
+
+	if q == nil {
+		return RetryQueueStatus{}, false
+	}
+	return q.Status(), true
+}
+
+// enqueueOnFailure queues a post that failed with a transient error when the
+// retry queue is enabled, returning the error to report to the caller
+func enqueueOnFailure(text string, opts SubmitPostOptions, err error) error {
+	retryQueueMu.RLock()
+	q := retryQueue
+	retryQueueMu.RUnlock()
+
+	if q == nil || !isTransientError(err) {
+		return err
+	}
+
+	return &QueuedError{ID: q.Enqueue(text, opts, err), Err: err}
+}
+
+// isTransientError reports whether a submission failed in a way that retrying
+// cannot post twice: before the request was sent, or refused by an unavailable
+// PDS. A dropped connection or a timeout may come after the PDS applied the
+// write, so such failures are not retried.
+func isTransientError(err error) bool {
+	if errors.Is(err, ErrWriteOutcomeUnknown) {
+		return false
+	}
+	if errors.Is(err, apiclient.ErrCircuitOpen) {
+		return true
+	}
+
+	var apiErr *apiclient.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusBadGateway || apiErr.StatusCode == http.StatusServiceUnavailable
+	}
+
+	// A failed dial, such as a refused connection or an unknown host, sent nothing
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,297 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+)
+
+// refusedErr is a connection refused before the request was sent
+var refusedErr = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+// newTestRetryQueue creates a queue in a temporary directory with immediate retries
+func newTestRetryQueue(t *testing.T, dir string, submit RetrySubmitFunc) *RetryQueue {
+	options := DefaultRetryQueueOptions
+	options.Directory = dir
+	options.InitialInterval = 0
+	options.MaxInterval = 0
+
+	q, err := NewRetryQueue(options, submit)
+	if err != nil {
+		t.Fatalf("NewRetryQueue() unexpected error: %v", err)
+	}
+	return q
+}
+
+func TestEnqueueOnFailure(t *testing.T) {
+	q := newTestRetryQueue(t, t.TempDir(), func(text string, opts SubmitPostOptions) (*PostResult, error) {
+		return &PostResult{}, nil
+	})
+
+	retryQueueMu.Lock()
+	retryQueue = q
+	retryQueueMu.Unlock()
+	defer func() {
+		retryQueueMu.Lock()
+		retryQueue = nil
+		retryQueueMu.Unlock()
+	}()
+
+	// A network failure is queued and reported as such
+	networkErr := fmt.Errorf("failed to create record: request failed: %w", refusedErr)
+	err := enqueueOnFailure("hello", SubmitPostOptions{}, networkErr)
+	if !errors.Is(err, ErrQueuedForRetry) {
+		t.Errorf("enqueueOnFailure() error = %v, want ErrQueuedForRetry", err)
+	}
+	if !errors.Is(err, networkErr) {
+		t.Errorf("enqueueOnFailure() error = %v, want it to wrap the original error", err)
+	}
+
+	// A rejected post is not queued
+	validationErr := errors.New("invalid post options: missing quote.cid")
+	if err := enqueueOnFailure("hello", SubmitPostOptions{}, validationErr); errors.Is(err, ErrQueuedForRetry) {
+		t.Errorf("enqueueOnFailure() queued a non-transient error: %v", err)
+	}
+
+	// A write that timed out may have been applied, so it is not queued either
+	timeoutErr := fmt.Errorf("timeout creating post after 8s (%w): %w", ErrWriteOutcomeUnknown, context.DeadlineExceeded)
+	if err := enqueueOnFailure("hello", SubmitPostOptions{}, timeoutErr); errors.Is(err, ErrQueuedForRetry) {
+		t.Errorf("enqueueOnFailure() queued a write of unknown outcome: %v", err)
+	}
+
+	status := q.Status()
+	if status.Depth != 1 {
+		t.Fatalf("Depth = %d, want 1", status.Depth)
+	}
+	if status.Items[0].Text != "hello" || status.Items[0].LastError == "" {
+		t.Errorf("Unexpected queued item: %+v", status.Items[0])
+	}
+}
+
+func TestRetryQueueDrain(t *testing.T) {
+	dir := t.TempDir()
+	attempts := 0
+	submit := func(text string, opts SubmitPostOptions) (*PostResult, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, &apiclient.APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return &PostResult{URI: "at://did:plc:test/app.bsky.feed.post/1"}, nil
+	}
+
+	q := newTestRetryQueue(t, dir, submit)
+	q.Enqueue("retry me", SubmitPostOptions{}, refusedErr)
+
+	// The queue survives a restart
+	q.Stop()
+	q = newTestRetryQueue(t, dir, submit)
+	defer q.Stop()
+	if depth := q.Status().Depth; depth != 1 {
+		t.Fatalf("Depth after reload = %d, want 1", depth)
+	}
+
+	// First attempt fails and the item stays queued
+	q.Process()
+	status := q.Status()
+	if status.Depth != 1 || status.Items[0].Attempts != 1 {
+		t.Fatalf("After failed attempt: %+v", status)
+	}
+
+	// Second attempt succeeds and drains the queue
+	q.Process()
+	status = q.Status()
+	if status.Depth != 0 {
+		t.Errorf("Depth after successful retry = %d, want 0", status.Depth)
+	}
+	if status.Delivered != 1 {
+		t.Errorf("Delivered = %d, want 1", status.Delivered)
+	}
+}
+
+func TestRetryQueueMaxAge(t *testing.T) {
+	submitted := false
+	q := newTestRetryQueue(t, t.TempDir(), func(text string, opts SubmitPostOptions) (*PostResult, error) {
+		submitted = true
+		return &PostResult{}, nil
+	})
+	defer q.Stop()
+	q.options.MaxAge = time.Hour
+
+	q.Enqueue("too old", SubmitPostOptions{}, nil)
+	q.mu.Lock()
+	q.items[0].EnqueuedAt = time.Now().Add(-2 * time.Hour)
+	q.mu.Unlock()
+
+	q.Process()
+
+	status := q.Status()
+	if status.Depth != 0 {
+		t.Errorf("Depth = %d, want expired item to be dropped", status.Depth)
+	}
+	if status.Dropped != 1 {
+		t.Errorf("Dropped = %d, want 1", status.Dropped)
+	}
+	if submitted {
+		t.Error("Expired item should not be submitted")
+	}
+}
+
+func TestRetryQueueKeepsInFlightItemsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	started, release := make(chan bool), make(chan bool)
+	q := newTestRetryQueue(t, dir, func(text string, opts SubmitPostOptions) (*PostResult, error) {
+		started <- true
+		<-release
+		return &PostResult{}, nil
+	})
+	q.Enqueue("in flight", SubmitPostOptions{}, nil)
+
+	done := make(chan bool)
+	go func() {
+		q.Process()
+		done <- true
+	}()
+	<-started
+
+	// A post queued while another is being submitted saves both
+	q.Enqueue("queued meanwhile", SubmitPostOptions{}, nil)
+	reloaded := newTestRetryQueue(t, dir, nil)
+	if depth := reloaded.Status().Depth; depth != 2 {
+		t.Errorf("Persisted depth while submitting = %d, want the in-flight item kept", depth)
+	}
+
+	close(release)
+	<-done
+	status := q.Status()
+	if status.Depth != 1 || status.Items[0].Text != "queued meanwhile" || status.Delivered != 1 {
+		t.Errorf("After delivery: %+v, want only the later item left", status)
+	}
+}
+
+func TestRetryQueueStopWaitsForWorker(t *testing.T) {
+	dir := t.TempDir()
+	started, release := make(chan bool), make(chan bool)
+	options := DefaultRetryQueueOptions
+	options.Directory = dir
+	options.InitialInterval = 0
+	options.PollInterval = time.Millisecond
+	q, err := NewRetryQueue(options, func(text string, opts SubmitPostOptions) (*PostResult, error) {
+		started <- true
+		<-release
+		return &PostResult{}, nil
+	})
+	if err != nil {
+		t.Fatalf("NewRetryQueue() unexpected error: %v", err)
+	}
+	q.Enqueue("deliver me", SubmitPostOptions{}, nil)
+	q.Start()
+	<-started
+
+	stopped := make(chan bool)
+	go func() {
+		q.Stop()
+		stopped <- true
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop() returned while a submission was in progress")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-stopped
+
+	// The delivered post is not left on disk to be sent again
+	if depth := newTestRetryQueue(t, dir, nil).Status().Depth; depth != 0 {
+		t.Errorf("Persisted depth after Stop() = %d, want 0", depth)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Connection refused", err: fmt.Errorf("request failed: %w", refusedErr), want: true},
+		{name: "Unknown host", err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "pds.example", IsNotFound: true}}, want: true},
+		{name: "Circuit breaker open", err: fmt.Errorf("failed to create record: %w", apiclient.ErrCircuitOpen), want: true},
+		{name: "Bad gateway", err: fmt.Errorf("failed to create record: %w", &apiclient.APIError{StatusCode: http.StatusBadGateway}), want: true},
+		{name: "Service unavailable", err: &apiclient.APIError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "Server error", err: &apiclient.APIError{StatusCode: http.StatusInternalServerError}, want: false},
+		{name: "Gateway timeout", err: &apiclient.APIError{StatusCode: http.StatusGatewayTimeout}, want: false},
+		{name: "Rejected record", err: &apiclient.APIError{StatusCode: http.StatusBadRequest}, want: false},
+		{name: "Connection dropped after sending", err: fmt.Errorf("request failed: %w", io.ErrUnexpectedEOF), want: false},
+		{name: "Read failed after sending", err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, want: false},
+		{name: "Timeout", err: errors.New("request failed: timeout awaiting response headers"), want: false},
+		{name: "Write outcome unknown", err: fmt.Errorf("timeout creating post (%w): %w", ErrWriteOutcomeUnknown, context.DeadlineExceeded), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryQueueUniqueIDs(t *testing.T) {
+	q := newTestRetryQueue(t, t.TempDir(), nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.Enqueue("same moment", SubmitPostOptions{}, nil)
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, item := range q.Status().Items {
+		if seen[item.ID] {
+			t.Fatalf("Duplicate queue ID %q", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	if len(seen) != 20 {
+		t.Errorf("Queued %d items, want 20", len(seen))
+	}
+}
+
+func TestRetryQueuePersistReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	q := newTestRetryQueue(t, dir, nil)
+	q.Enqueue("keep me", SubmitPostOptions{}, nil)
+
+	// A write interrupted by a crash leaves only the temporary file damaged
+	path := filepath.Join(dir, DefaultRetryQueueOptions.Filename)
+	if err := os.WriteFile(path+".tmp", []byte(`[{"id":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := newTestRetryQueue(t, dir, nil)
+	if depth := reloaded.Status().Depth; depth != 1 {
+		t.Fatalf("Depth after reload = %d, want 1", depth)
+	}
+
+	q.Enqueue("and me", SubmitPostOptions{}, nil)
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Temporary file left after saving: %v", err)
+	}
+	if depth := newTestRetryQueue(t, dir, nil).Status().Depth; depth != 2 {
+		t.Errorf("Depth after second save = %d, want 2", depth)
+	}
+}
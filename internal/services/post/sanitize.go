@@ -0,0 +1,23 @@
+package post
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SanitizeText prepares text for a post. Posts are plain text rather than HTML,
+// so characters such as & and < are kept as written; only invalid UTF-8 and
+// control characters other than newlines and tabs are removed, and Windows
+// and old Mac line endings become "\n".
+func SanitizeText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
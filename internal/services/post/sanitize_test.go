@@ -0,0 +1,28 @@
+package post
+
+import "testing"
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "Plain text", text: "Hello Bluesky", want: "Hello Bluesky"},
+		{name: "HTML characters kept", text: `AT&T <tag> "quoted" 'single'`, want: `AT&T <tag> "quoted" 'single'`},
+		{name: "Windows line endings", text: "one\r\ntwo", want: "one\ntwo"},
+		{name: "Old Mac line endings", text: "one\rtwo", want: "one\ntwo"},
+		{name: "Tabs kept", text: "a\tb", want: "a\tb"},
+		{name: "Control characters removed", text: "bell\u0007 null\u0000 escape\u001b[0m", want: "bell null escape[0m"},
+		{name: "Invalid UTF-8 removed", text: "bad\xffbyte", want: "badbyte"},
+		{name: "Emoji sequence kept", text: "family 👩‍👩‍👧", want: "family 👩‍👩‍👧"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.text); got != tt.want {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package post
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ServiceLimits are the limits enforced on posts before they are submitted
+type ServiceLimits struct {
+	MaxPostGraphemes int `json:"maxPostGraphemes"` // Longest post text, in graphemes
+}
+
+// DefaultServiceLimits are Bluesky's own limits, enforced unless configured otherwise
+var DefaultServiceLimits = ServiceLimits{
+	MaxPostGraphemes: MaxPostGraphemes,
+}
+
+// withDefaults fills in zero limits from DefaultServiceLimits
+func (l ServiceLimits) withDefaults() ServiceLimits {
+	if l.MaxPostGraphemes <= 0 {
+		l.MaxPostGraphemes = DefaultServiceLimits.MaxPostGraphemes
+	}
+	return l
+}
+
+// Limits currently enforced on submissions
+var (
+	serviceLimits   = DefaultServiceLimits
+	serviceLimitsMu sync.RWMutex
+)
+
+// SetServiceLimits changes the limits enforced on submissions; zero fields use the defaults
+func SetServiceLimits(limits ServiceLimits) {
+	serviceLimitsMu.Lock()
+	defer serviceLimitsMu.Unlock()
+	serviceLimits = limits.withDefaults()
+}
+
+// GetServiceLimits returns the limits currently enforced on submissions
+func GetServiceLimits() ServiceLimits {
+	serviceLimitsMu.RLock()
+	defer serviceLimitsMu.RUnlock()
+	return serviceLimits
+}
+
+// ServiceLimitsFromEnv returns the default limits with BSKY_MAX_POST_GRAPHEMES
+// applied, for a PDS that accepts longer posts than Bluesky. Servers do not
+// declare their post limit, so it is configured rather than discovered.
+// Invalid values are ignored.
+func ServiceLimitsFromEnv() ServiceLimits {
+	limits := DefaultServiceLimits
+	if graphemes, err := strconv.Atoi(os.Getenv("BSKY_MAX_POST_GRAPHEMES")); err == nil && graphemes > 0 {
+		limits.MaxPostGraphemes = graphemes
+	}
+	return limits
+}
+
+// checkPostGraphemes rejects text longer than the post limit
+func checkPostGraphemes(text string) error {
+	limit := GetServiceLimits().MaxPostGraphemes
+	if count := graphemeCount(text); count > limit {
+		return fmt.Errorf("invalid parameter: text has %d graphemes, more than the limit of %d", count, limit)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package post
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestServiceLimitsFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: MaxPostGraphemes},
+		{value: "500", want: 500},
+		{value: "0", want: MaxPostGraphemes},
+		{value: "-1", want: MaxPostGraphemes},
+		{value: "lots", want: MaxPostGraphemes},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("BSKY_MAX_POST_GRAPHEMES", tt.value)
+		if got := ServiceLimitsFromEnv().MaxPostGraphemes; got != tt.want {
+			t.Errorf("ServiceLimitsFromEnv() with %q = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSubmitPostUsesServiceLimits(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	defer SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+
+	writes := 0
+	originalCreateRecord := createRecord
+	defer func() { createRecord = originalCreateRecord }()
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		writes++
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	text := strings.Repeat("a", 400)
+
+	// Over the default limit, the post is refused before it is written
+	if _, err := SubmitPost(config.Config{}, text); err == nil || !strings.Contains(err.Error(), "limit of 300") {
+		t.Errorf("Expected the default 300 grapheme limit to refuse the post, got %v", err)
+	}
+
+	// A higher configured limit accepts it
+	SetServiceLimits(ServiceLimits{MaxPostGraphemes: 500})
+	defer SetServiceLimits(DefaultServiceLimits)
+	if _, err := SubmitPost(config.Config{}, text); err != nil {
+		t.Errorf("SubmitPost() unexpected error with the configured limit: %v", err)
+	}
+	if writes != 1 {
+		t.Errorf("Records written = %d, want 1", writes)
+	}
+
+	// Fitting a suggestion follows the configured limit too
+	if _, trimmed := FitToLimit(text); trimmed {
+		t.Error("FitToLimit() trimmed text within the configured limit")
+	}
+}
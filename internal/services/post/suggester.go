@@ -0,0 +1,208 @@
+package post
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// SuggestTimeout bounds how long a suggester may take for one suggestion
+const SuggestTimeout = 10 * time.Second
+
+// DefaultSuggester is the name of the built-in template suggester
+const DefaultSuggester = "template"
+
+// SuggestRequest describes the post to suggest
+type SuggestRequest struct {
+	Mood  string // happy, sad, excited, thoughtful or empty
+	Topic string // Already length-checked and sanitized
+}
+
+// SuggestResult is a suggested post
+type SuggestResult struct {
+	Suggestion string
+}
+
+// Suggester generates post suggestions. The template engine is the default;
+// other backends, such as a local model or an external API, can be registered
+// with RegisterSuggester and selected by the config's Suggester name.
+type Suggester interface {
+	Suggest(ctx context.Context, req SuggestRequest) (SuggestResult, error)
+}
+
+var (
+	suggestersMu sync.RWMutex
+	suggesters   = map[string]Suggester{DefaultSuggester: TemplateSuggester{}}
+)
+
+// RegisterSuggester makes a suggester available under name, replacing any
+// suggester already registered with that name
+func RegisterSuggester(name string, suggester Suggester) {
+	suggestersMu.Lock()
+	defer suggestersMu.Unlock()
+	suggesters[name] = suggester
+}
+
+// lookupSuggester returns the suggester registered under name; empty selects the default
+func lookupSuggester(name string) (Suggester, error) {
+	if name == "" {
+		name = DefaultSuggester
+	}
+
+	suggestersMu.RLock()
+	defer suggestersMu.RUnlock()
+	suggester, ok := suggesters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown post suggester %q", name)
+	}
+	return suggester, nil
+}
+
+// suggestPost asks the configured suggester for a post. If another backend fails
+// or returns nothing, the template suggester is used so callers still get a suggestion.
+func suggestPost(cfg config.Config, req SuggestRequest) (string, error) {
+	suggester, err := lookupSuggester(cfg.Suggester)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SuggestTimeout)
+	defer cancel()
+
+	result, err := suggester.Suggest(ctx, req)
+	if err == nil && strings.TrimSpace(result.Suggestion) != "" {
+		return result.Suggestion, nil
+	}
+	if _, isTemplate := suggester.(TemplateSuggester); isTemplate {
+		return "", err
+	}
+	if err != nil {
+		log.Printf("Warning: Suggester %q failed, using templates: %v", cfg.Suggester, err)
+	}
+
+	result, err = TemplateSuggester{}.Suggest(ctx, req)
+	return result.Suggestion, err
+}
+
+// TemplateSuggester builds suggestions from built-in templates for the mood and topic.
+// The zero value shares the package's template selection, which SetSeed reseeds.
+type TemplateSuggester struct {
+	picker *templatePicker // Own selection when created by NewTemplateSuggester
+}
+
+// NewTemplateSuggester creates a template suggester with its own selection from
+// seed, so suggesters with the same seed give the same suggestions in turn
+func NewTemplateSuggester(seed int64) TemplateSuggester {
+	return TemplateSuggester{picker: newSeededTemplatePicker(seed)}
+}
+
+// pick selects one of templates with the suggester's own picker, if it has one
+func (s TemplateSuggester) pick(templates []string) string {
+	if s.picker != nil {
+		return s.picker.Pick(templates)
+	}
+	return getRandomTemplate(templates)
+}
+
+// Suggest picks templates for the mood and topic, falling back to a generic template
+func (s TemplateSuggester) Suggest(ctx context.Context, req SuggestRequest) (SuggestResult, error) {
+	// Templates based on mood
+	happyTemplates := []string{
+		"Today is a great day!",
+		"Feeling so positive right now!",
+		"Nothing but blue skies today!",
+		"So happy I could burst!",
+		"What a wonderful day it's turning out to be!",
+	}
+
+	sadTemplates := []string{
+		"Feeling a bit down today.",
+		"Having one of those days...",
+		"Sometimes things don't go as planned.",
+		"Looking for a silver lining today.",
+		"When it rains, it pours.",
+	}
+
+	excitedTemplates := []string{
+		"I can't contain my excitement!",
+		"You won't believe what just happened!",
+		"This is absolutely incredible!",
+		"I'm literally bouncing with energy!",
+		"Big news coming your way!",
+	}
+
+	thoughtfulTemplates := []string{
+		"I've been pondering something interesting.",
+		"Here's a thought worth sharing:",
+		"Something to consider today:",
+		"Been reflecting on this lately:",
+		"Food for thought:",
+	}
+
+	// Topic templates
+	topicTemplates := []string{
+		" I want to talk about %s.",
+		" Let's discuss %s today.",
+		" Has anyone else been thinking about %s?",
+		" What are your thoughts on %s?",
+		" %s has been on my mind lately.",
+		" Anyone interested in %s?",
+		" %s is something we should all explore more.",
+		" I've been fascinated by %s recently.",
+	}
+
+	// Generic fallback templates
+	fallbackTemplates := []string{
+		"Let's post something interesting!",
+		"What's on everyone's mind today?",
+		"How's everyone doing?",
+		"Anything exciting happening?",
+		"Just wanted to check in!",
+		"Happy to connect with you all!",
+		"Thoughts?",
+		"Open to interesting conversations today!",
+	}
+
+	suggestion := ""
+
+	// Select mood template
+	switch req.Mood {
+	case "happy":
+		suggestion = s.pick(happyTemplates)
+	case "sad":
+		suggestion = s.pick(sadTemplates)
+	case "excited":
+		suggestion = s.pick(excitedTemplates)
+	case "thoughtful":
+		suggestion = s.pick(thoughtfulTemplates)
+	}
+
+	// Add topic if provided
+	if req.Topic != "" {
+		if suggestion != "" {
+			// If we have a mood, add the topic with a template
+			topicFormat := s.pick(topicTemplates)
+			suggestion += fmt.Sprintf(topicFormat, req.Topic)
+		} else {
+			// If no mood but we have a topic, start with the topic
+			topicFormat := s.pick(topicTemplates)
+			suggestion = fmt.Sprintf(topicFormat, req.Topic)
+			// Remove leading space if present
+			if len(suggestion) > 0 && suggestion[0] == ' ' {
+				suggestion = suggestion[1:]
+			}
+		}
+	}
+
+	// Use fallback if no suggestion was generated
+	if suggestion == "" {
+		suggestion = s.pick(fallbackTemplates)
+	}
+
+	return SuggestResult{Suggestion: suggestion}, nil
+}
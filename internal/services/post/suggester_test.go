@@ -0,0 +1,111 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Both the built-in and pluggable backends satisfy the interface
+var (
+	_ Suggester = TemplateSuggester{}
+	_ Suggester = (*stubSuggester)(nil)
+)
+
+// stubSuggester returns a fixed suggestion or error and records its requests
+type stubSuggester struct {
+	suggestion string
+	err        error
+	requests   []SuggestRequest
+}
+
+func (s *stubSuggester) Suggest(ctx context.Context, req SuggestRequest) (SuggestResult, error) {
+	s.requests = append(s.requests, req)
+	if s.err != nil {
+		return SuggestResult{}, s.err
+	}
+	return SuggestResult{Suggestion: s.suggestion}, nil
+}
+
+// registerStubSuggester registers stub under name for the duration of the test
+func registerStubSuggester(t *testing.T, name string, stub *stubSuggester) {
+	t.Helper()
+	RegisterSuggester(name, stub)
+	t.Cleanup(func() {
+		suggestersMu.Lock()
+		delete(suggesters, name)
+		suggestersMu.Unlock()
+	})
+}
+
+func TestTemplateSuggester(t *testing.T) {
+	original := getRandomTemplate
+	getRandomTemplate = func(templates []string) string { return templates[0] }
+	defer func() { getRandomTemplate = original }()
+
+	var suggester Suggester = TemplateSuggester{}
+	result, err := suggester.Suggest(context.Background(), SuggestRequest{Mood: "happy", Topic: "Go"})
+	if err != nil {
+		t.Fatalf("Suggest() unexpected error: %v", err)
+	}
+	if want := "Today is a great day! I want to talk about Go."; result.Suggestion != want {
+		t.Errorf("Suggestion = %q, want %q", result.Suggestion, want)
+	}
+}
+
+func TestGeneratePostUsesConfiguredSuggester(t *testing.T) {
+	stub := &stubSuggester{suggestion: "From the model"}
+	registerStubSuggester(t, "stub", stub)
+
+	result, err := GeneratePost(config.Config{Suggester: "stub"}, map[string]interface{}{
+		"mood":  "excited",
+		"topic": "line one\r\nline two",
+	})
+	if err != nil {
+		t.Fatalf("GeneratePost() unexpected error: %v", err)
+	}
+	if got := result.(map[string]string)["suggestion"]; got != "From the model" {
+		t.Errorf("Suggestion = %q, want the stub's suggestion", got)
+	}
+
+	if len(stub.requests) != 1 {
+		t.Fatalf("Expected 1 suggest request, got %d", len(stub.requests))
+	}
+	if req := stub.requests[0]; req.Mood != "excited" || req.Topic != "line one\nline two" {
+		t.Errorf("Request = %+v, want the mood and sanitized topic", req)
+	}
+}
+
+func TestGeneratePostFallsBackToTemplates(t *testing.T) {
+	tests := []struct {
+		name string
+		stub *stubSuggester
+	}{
+		{name: "Backend error", stub: &stubSuggester{err: errors.New("model unavailable")}},
+		{name: "Empty suggestion", stub: &stubSuggester{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerStubSuggester(t, "stub", tt.stub)
+
+			result, err := GeneratePost(config.Config{Suggester: "stub"}, map[string]interface{}{"topic": "Go"})
+			if err != nil {
+				t.Fatalf("GeneratePost() unexpected error: %v", err)
+			}
+			if got := result.(map[string]string)["suggestion"]; !strings.Contains(got, "Go") {
+				t.Errorf("Suggestion = %q, want a template suggestion about the topic", got)
+			}
+		})
+	}
+}
+
+func TestGeneratePostUnknownSuggester(t *testing.T) {
+	_, err := GeneratePost(config.Config{Suggester: "missing"}, map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), `unknown post suggester "missing"`) {
+		t.Errorf("Expected unknown suggester error, got %v", err)
+	}
+}
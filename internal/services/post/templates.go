@@ -0,0 +1,120 @@
+package post
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// templatePicker selects templates at random, optionally weighted, and avoids
+// returning the same template twice in a row for a template category
+type templatePicker struct {
+	mu       sync.Mutex
+	rng      *rand.Rand         // Guarded by mu, since rand.Rand is not safe for concurrent use
+	last     map[string]string  // Last template returned, keyed by category
+	weights  map[string]float64 // Relative weight per template; missing templates weigh 1
+	noRepeat bool
+}
+
+// newTemplatePicker creates a picker that avoids immediate repeats, seeded from the clock
+func newTemplatePicker() *templatePicker {
+	return newSeededTemplatePicker(time.Now().UnixNano())
+}
+
+// newSeededTemplatePicker creates a picker whose picks are the same for the same seed
+func newSeededTemplatePicker(seed int64) *templatePicker {
+	return &templatePicker{
+		rng:      rand.New(rand.NewSource(seed)),
+		last:     make(map[string]string),
+		noRepeat: true,
+	}
+}
+
+// SetSeed reseeds the template selection used by GeneratePost and forgets recent
+// picks, so the suggestions that follow are the same each time for the same seed
+func SetSeed(seed int64) {
+	defaultTemplatePicker.mu.Lock()
+	defer defaultTemplatePicker.mu.Unlock()
+	defaultTemplatePicker.rng = rand.New(rand.NewSource(seed))
+	defaultTemplatePicker.last = make(map[string]string)
+}
+
+// SetTemplateNoRepeat enables or disables avoiding the previously suggested template
+func SetTemplateNoRepeat(enabled bool) {
+	defaultTemplatePicker.mu.Lock()
+	defer defaultTemplatePicker.mu.Unlock()
+	defaultTemplatePicker.noRepeat = enabled
+}
+
+// SetTemplateWeights sets relative weights for templates by their text. Templates
+// without a weight weigh 1, and a weight of 0 excludes a template unless every
+// candidate is excluded. Passing nil restores uniform selection.
+func SetTemplateWeights(weights map[string]float64) {
+	copied := make(map[string]float64, len(weights))
+	for template, weight := range weights {
+		copied[template] = weight
+	}
+
+	defaultTemplatePicker.mu.Lock()
+	defer defaultTemplatePicker.mu.Unlock()
+	defaultTemplatePicker.weights = copied
+}
+
+// Pick returns a template from templates
+func (p *templatePicker) Pick(templates []string) string {
+	if len(templates) == 0 {
+		return ""
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The templates themselves identify the category
+	category := strings.Join(templates, "\x00")
+
+	candidates := templates
+	if last, ok := p.last[category]; ok && p.noRepeat && len(templates) > 1 {
+		candidates = make([]string, 0, len(templates)-1)
+		for _, template := range templates {
+			if template != last {
+				candidates = append(candidates, template)
+			}
+		}
+	}
+
+	choice := p.weightedChoice(candidates)
+	p.last[category] = choice
+	return choice
+}
+
+// weightedChoice picks one of candidates according to the configured weights
+func (p *templatePicker) weightedChoice(candidates []string) string {
+	total := 0.0
+	for _, template := range candidates {
+		total += p.weight(template)
+	}
+	if total <= 0 {
+		return candidates[p.rng.Intn(len(candidates))]
+	}
+
+	target := p.rng.Float64() * total
+	for _, template := range candidates {
+		target -= p.weight(template)
+		if target < 0 {
+			return template
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// weight returns the relative weight of template
+func (p *templatePicker) weight(template string) float64 {
+	if weight, ok := p.weights[template]; ok {
+		if weight < 0 {
+			return 0
+		}
+		return weight
+	}
+	return 1
+}
@@ -0,0 +1,145 @@
+package post
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestTemplatePickerNoImmediateRepeat(t *testing.T) {
+	picker := newTemplatePicker()
+	templates := []string{"first", "second", "third"}
+
+	previous := picker.Pick(templates)
+	for i := 0; i < 200; i++ {
+		next := picker.Pick(templates)
+		if next == previous {
+			t.Fatalf("Pick() repeated %q on call %d", next, i+1)
+		}
+		previous = next
+	}
+}
+
+func TestTemplatePickerSingleTemplate(t *testing.T) {
+	picker := newTemplatePicker()
+	templates := []string{"only"}
+
+	for i := 0; i < 3; i++ {
+		if got := picker.Pick(templates); got != "only" {
+			t.Errorf("Pick() = %q, want only", got)
+		}
+	}
+}
+
+func TestTemplatePickerCategoriesAreIndependent(t *testing.T) {
+	picker := newTemplatePicker()
+	happy := []string{"happy one", "happy two"}
+	sad := []string{"sad one", "sad two"}
+
+	firstHappy := picker.Pick(happy)
+	picker.Pick(sad)
+	if got := picker.Pick(happy); got == firstHappy {
+		t.Errorf("Pick() repeated %q after picking from another category", got)
+	}
+}
+
+func TestTemplatePickerWeights(t *testing.T) {
+	picker := newTemplatePicker()
+	picker.noRepeat = false
+	picker.weights = map[string]float64{"never": 0}
+	templates := []string{"never", "always"}
+
+	for i := 0; i < 50; i++ {
+		if got := picker.Pick(templates); got != "always" {
+			t.Fatalf("Pick() = %q, want templates with weight 0 to be skipped", got)
+		}
+	}
+
+	// When every candidate is excluded, selection falls back to uniform
+	picker.weights = map[string]float64{"never": 0, "always": 0}
+	if got := picker.Pick(templates); got != "never" && got != "always" {
+		t.Errorf("Pick() = %q, want one of the templates", got)
+	}
+}
+
+func TestTemplatePickerRepeatsWhenDisabled(t *testing.T) {
+	picker := newTemplatePicker()
+	picker.noRepeat = false
+	picker.weights = map[string]float64{"b": 0}
+	templates := []string{"a", "b"}
+
+	picker.Pick(templates)
+	if got := picker.Pick(templates); got != "a" {
+		t.Errorf("Pick() = %q, want a to be repeatable when no-repeat is disabled", got)
+	}
+}
+
+func TestGeneratePostVariesSuggestions(t *testing.T) {
+	params := map[string]interface{}{"mood": "happy"}
+
+	previous := ""
+	for i := 0; i < 20; i++ {
+		result, err := GeneratePost(config.Config{}, params)
+		if err != nil {
+			t.Fatalf("GeneratePost() unexpected error: %v", err)
+		}
+		suggestion := result.(map[string]string)["suggestion"]
+		if suggestion == previous {
+			t.Fatalf("GeneratePost() repeated %q on consecutive calls", suggestion)
+		}
+		previous = suggestion
+	}
+}
+
+func TestTemplateSuggesterSameSeed(t *testing.T) {
+	first := NewTemplateSuggester(42)
+	second := NewTemplateSuggester(42)
+	requests := []SuggestRequest{
+		{Mood: "happy"},
+		{Mood: "happy", Topic: "golang"},
+		{Topic: "gardening"},
+		{},
+		{Mood: "happy"},
+	}
+
+	for i, req := range requests {
+		a, err := first.Suggest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Suggest() unexpected error: %v", err)
+		}
+		b, err := second.Suggest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Suggest() unexpected error: %v", err)
+		}
+		if a.Suggestion != b.Suggestion {
+			t.Errorf("Request %d: suggestions differ for the same seed: %q and %q", i, a.Suggestion, b.Suggestion)
+		}
+	}
+}
+
+func TestSetSeedReproducesGeneratePost(t *testing.T) {
+	defer SetSeed(time.Now().UnixNano())
+	params := map[string]interface{}{"mood": "excited", "topic": "space"}
+
+	generate := func() []string {
+		SetSeed(7)
+		var suggestions []string
+		for i := 0; i < 5; i++ {
+			result, err := GeneratePost(config.Config{}, params)
+			if err != nil {
+				t.Fatalf("GeneratePost() unexpected error: %v", err)
+			}
+			suggestions = append(suggestions, result.(map[string]string)["suggestion"])
+		}
+		return suggestions
+	}
+
+	first, second := generate(), generate()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Suggestion %d = %q after reseeding, want %q", i, second[i], first[i])
+		}
+	}
+}
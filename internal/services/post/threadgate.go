@@ -0,0 +1,154 @@
+package post
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Threadgate allow rules
+const (
+	ThreadgateNobody    = "nobody"
+	ThreadgateMentioned = "mentioned"
+	ThreadgateFollowing = "following"
+	ThreadgateList      = "list"
+)
+
+// threadgateRuleTypes maps allow rules to their lexicon types
+var threadgateRuleTypes = map[string]string{
+	ThreadgateMentioned: "app.bsky.feed.threadgate#mentionRule",
+	ThreadgateFollowing: "app.bsky.feed.threadgate#followingRule",
+	ThreadgateList:      "app.bsky.feed.threadgate#listRule",
+}
+
+// Threadgate limits who can reply to a post.
+// Allow "nobody" on its own to disable replies; the "list" rule allows members of Lists.
+type Threadgate struct {
+	Allow []string `json:"allow"`
+	Lists []string `json:"lists,omitempty"` // List URIs for the "list" rule
+}
+
+// createRecordWithKey writes a record with a chosen key, can be replaced for testing
+var createRecordWithKey = repo.CreateRecordWithKeyContext
+
+// putRecord creates or replaces a record with a chosen key, can be replaced for testing
+var putRecord = repo.PutRecordContext
+
+// sessionDID returns the authenticated user's DID, can be replaced for testing
+var sessionDID = func(cfg config.Config) (string, error) {
+	if _, err := auth.GetToken(cfg); err != nil {
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+	did := auth.GetTokenManager(cfg).GetDID()
+	if did == "" {
+		return "", fmt.Errorf("unable to get user DID")
+	}
+	return did, nil
+}
+
+// Validate checks the allow rules and list URIs
+func (g Threadgate) Validate() error {
+	if len(g.Allow) == 0 {
+		return fmt.Errorf("invalid threadgate: at least one allow rule is required")
+	}
+
+	allowsList := false
+	for _, rule := range g.Allow {
+		switch {
+		case rule == ThreadgateNobody:
+			if len(g.Allow) > 1 {
+				return fmt.Errorf("invalid threadgate: %q cannot be combined with other rules", ThreadgateNobody)
+			}
+		case rule == ThreadgateList:
+			allowsList = true
+		case threadgateRuleTypes[rule] == "":
+			return fmt.Errorf("invalid threadgate: unknown allow rule %q (allowed: nobody, mentioned, following, list)", rule)
+		}
+	}
+
+	if allowsList && len(g.Lists) == 0 {
+		return fmt.Errorf("invalid threadgate: the list rule requires at least one list URI")
+	}
+	if !allowsList && len(g.Lists) > 0 {
+		return fmt.Errorf("invalid threadgate: lists given without the list rule")
+	}
+	for _, list := range g.Lists {
+		parts := strings.Split(strings.TrimPrefix(list, "at://"), "/")
+		if !strings.HasPrefix(list, "at://") || len(parts) != 3 || parts[1] != "app.bsky.graph.list" || parts[2] == "" {
+			return fmt.Errorf("invalid threadgate: invalid list URI %q", list)
+		}
+	}
+
+	return nil
+}
+
+// buildThreadgateRecord creates the app.bsky.feed.threadgate record for postURI
+func buildThreadgateRecord(postURI string, createdAt string, gate Threadgate) map[string]interface{} {
+	allow := make([]map[string]interface{}, 0, len(gate.Allow)+len(gate.Lists))
+	for _, rule := range gate.Allow {
+		switch rule {
+		case ThreadgateNobody:
+			// An empty allow list disables replies
+		case ThreadgateList:
+			for _, list := range gate.Lists {
+				allow = append(allow, map[string]interface{}{
+					"$type": threadgateRuleTypes[ThreadgateList],
+					"list":  list,
+				})
+			}
+		default:
+			allow = append(allow, map[string]interface{}{"$type": threadgateRuleTypes[rule]})
+		}
+	}
+
+	return map[string]interface{}{
+		"$type":     repo.CollectionThreadgate,
+		"post":      postURI,
+		"allow":     allow,
+		"createdAt": createdAt,
+	}
+}
+
+// UpdatePostGate sets who can reply to one of the authenticated user's
+// existing posts, creating the post's threadgate or replacing its current one.
+// postRef is a bsky.app URL, an AT URI or a handle/rkey pair.
+func UpdatePostGate(cfg config.Config, postRef string, gate Threadgate) (*repo.CreateRecordResult, error) {
+	return UpdatePostGateContext(context.Background(), cfg, postRef, gate)
+}
+
+// UpdatePostGateContext is UpdatePostGate, giving up on the write when ctx is done
+func UpdatePostGateContext(ctx context.Context, cfg config.Config, postRef string, gate Threadgate) (*repo.CreateRecordResult, error) {
+	if err := gate.Validate(); err != nil {
+		return nil, err
+	}
+	uri, err := NormalizeRef(cfg, postRef)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(strings.TrimPrefix(string(uri), "at://"), "/")
+	if parts[1] != postCollection {
+		return nil, fmt.Errorf("invalid parameter: %s is not a post", uri)
+	}
+
+	// Only the author can gate a post, as the threadgate lives in their repository
+	did, err := sessionDID(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if parts[0] != did {
+		return nil, fmt.Errorf("invalid parameter: %s is not one of your posts", uri)
+	}
+
+	record := buildThreadgateRecord(string(uri), time.Now().UTC().Format(time.RFC3339), gate)
+	if err := WaitForWrite(ctx); err != nil {
+		return nil, fmt.Errorf("failed to update threadgate: %w", err)
+	}
+	return writeWithTimeout(ctx, "updating threadgate", func(ctx context.Context) (*repo.CreateRecordResult, error) {
+		return putRecord(ctx, cfg, repo.CollectionThreadgate, parts[2], record)
+	})
+}
@@ -0,0 +1,256 @@
+package post
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+func TestThreadgateValidate(t *testing.T) {
+	list := "at://did:plc:me/app.bsky.graph.list/3kl"
+
+	tests := []struct {
+		name    string
+		gate    Threadgate
+		wantErr string
+	}{
+		{name: "Nobody", gate: Threadgate{Allow: []string{"nobody"}}},
+		{name: "Mentioned and following", gate: Threadgate{Allow: []string{"mentioned", "following"}}},
+		{name: "List", gate: Threadgate{Allow: []string{"list"}, Lists: []string{list}}},
+		{name: "No rules", gate: Threadgate{}, wantErr: "at least one allow rule"},
+		{name: "Unknown rule", gate: Threadgate{Allow: []string{"friends"}}, wantErr: "unknown allow rule"},
+		{name: "Nobody combined", gate: Threadgate{Allow: []string{"nobody", "following"}}, wantErr: "cannot be combined"},
+		{name: "List rule without lists", gate: Threadgate{Allow: []string{"list"}}, wantErr: "requires at least one list URI"},
+		{name: "Lists without list rule", gate: Threadgate{Allow: []string{"following"}, Lists: []string{list}}, wantErr: "without the list rule"},
+		{name: "Invalid list URI", gate: Threadgate{Allow: []string{"list"}, Lists: []string{"at://did:plc:me/app.bsky.feed.post/1"}}, wantErr: "invalid list URI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gate.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// capturedThreadgate records the threadgate write made during a submission
+type capturedThreadgate struct {
+	calls      int
+	collection string
+	rkey       string
+	record     map[string]interface{}
+}
+
+func stubPostRecordWriters(t *testing.T, gateErr error) *capturedThreadgate {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalCreateRecord := createRecord
+	originalCreateRecordWithKey := createRecordWithKey
+	t.Cleanup(func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		createRecord = originalCreateRecord
+		createRecordWithKey = originalCreateRecordWithKey
+	})
+
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/3kpost", CID: "bafyreipost"}, nil
+	}
+
+	captured := &capturedThreadgate{}
+	createRecordWithKey = func(ctx context.Context, cfg config.Config, collection, rkey string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		captured.calls++
+		captured.collection = collection
+		captured.rkey = rkey
+		captured.record = record
+		if gateErr != nil {
+			return nil, gateErr
+		}
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.threadgate/3kpost", CID: "bafyreigate"}, nil
+	}
+	return captured
+}
+
+func TestSubmitPostWithThreadgate(t *testing.T) {
+	captured := stubPostRecordWriters(t, nil)
+	list := "at://did:plc:me/app.bsky.graph.list/3kl"
+
+	result, err := SubmitPostWithOptions(config.Config{}, "Replies limited", SubmitPostOptions{
+		Threadgate: &Threadgate{Allow: []string{"mentioned", "list"}, Lists: []string{list}},
+	})
+	if err != nil {
+		t.Fatalf("SubmitPostWithOptions() unexpected error: %v", err)
+	}
+	if result.Warning != "" {
+		t.Errorf("Unexpected warning: %s", result.Warning)
+	}
+
+	if captured.calls != 1 {
+		t.Fatalf("Expected 1 threadgate record, got %d", captured.calls)
+	}
+	if captured.collection != repo.CollectionThreadgate || captured.rkey != "3kpost" {
+		t.Errorf("Threadgate written to %s/%s, want %s/3kpost", captured.collection, captured.rkey, repo.CollectionThreadgate)
+	}
+	if captured.record["post"] != "at://did:plc:me/app.bsky.feed.post/3kpost" {
+		t.Errorf("Threadgate subject = %v, want the new post", captured.record["post"])
+	}
+
+	allow := captured.record["allow"].([]map[string]interface{})
+	if len(allow) != 2 {
+		t.Fatalf("Expected 2 allow rules, got %v", allow)
+	}
+	if allow[0]["$type"] != "app.bsky.feed.threadgate#mentionRule" {
+		t.Errorf("First rule = %v, want mentionRule", allow[0])
+	}
+	if allow[1]["$type"] != "app.bsky.feed.threadgate#listRule" || allow[1]["list"] != list {
+		t.Errorf("Second rule = %v, want listRule for %s", allow[1], list)
+	}
+}
+
+func TestSubmitPostThreadgateNobody(t *testing.T) {
+	captured := stubPostRecordWriters(t, nil)
+
+	if _, err := SubmitPostWithOptions(config.Config{}, "No replies", SubmitPostOptions{
+		Threadgate: &Threadgate{Allow: []string{"nobody"}},
+	}); err != nil {
+		t.Fatalf("SubmitPostWithOptions() unexpected error: %v", err)
+	}
+
+	allow, ok := captured.record["allow"].([]map[string]interface{})
+	if !ok || len(allow) != 0 {
+		t.Errorf("Expected an empty allow list, got %v", captured.record["allow"])
+	}
+}
+
+func TestSubmitPostWithoutThreadgate(t *testing.T) {
+	captured := stubPostRecordWriters(t, nil)
+
+	if _, err := SubmitPost(config.Config{}, "Open to replies"); err != nil {
+		t.Fatalf("SubmitPost() unexpected error: %v", err)
+	}
+	if captured.calls != 0 {
+		t.Errorf("Expected no threadgate record, got %d", captured.calls)
+	}
+}
+
+func TestSubmitPostThreadgateFailure(t *testing.T) {
+	stubPostRecordWriters(t, fmt.Errorf("failed to create record: status 400"))
+
+	result, err := SubmitPostWithOptions(config.Config{}, "Replies limited", SubmitPostOptions{
+		Threadgate: &Threadgate{Allow: []string{"following"}},
+	})
+	if err != nil {
+		t.Fatalf("SubmitPostWithOptions() should report the created post, got error: %v", err)
+	}
+	if result.URI != "at://did:plc:me/app.bsky.feed.post/3kpost" {
+		t.Errorf("URI = %q, want the created post", result.URI)
+	}
+	if !strings.Contains(result.Warning, "threadgate was not applied") {
+		t.Errorf("Warning = %q, want threadgate failure", result.Warning)
+	}
+}
+
+func TestSubmitPostInvalidThreadgate(t *testing.T) {
+	captured := stubPostRecordWriters(t, nil)
+
+	_, err := SubmitPostWithOptions(config.Config{}, "Replies limited", SubmitPostOptions{
+		Threadgate: &Threadgate{Allow: []string{"everyone"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid post options") {
+		t.Errorf("Expected invalid post options error, got %v", err)
+	}
+	if captured.calls != 0 {
+		t.Errorf("Expected no threadgate record, got %d", captured.calls)
+	}
+}
+
+// stubThreadgatePut records the threadgate writes of UpdatePostGate for a user with DID did:plc:me
+func stubThreadgatePut(t *testing.T) *[]capturedThreadgate {
+	SetWriteRate(0, DefaultWriteBurst)
+	originalPutRecord := putRecord
+	originalSessionDID := sessionDID
+	t.Cleanup(func() {
+		SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+		putRecord = originalPutRecord
+		sessionDID = originalSessionDID
+	})
+
+	sessionDID = func(cfg config.Config) (string, error) {
+		return "did:plc:me", nil
+	}
+	var writes []capturedThreadgate
+	putRecord = func(ctx context.Context, cfg config.Config, collection, rkey string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		writes = append(writes, capturedThreadgate{calls: len(writes) + 1, collection: collection, rkey: rkey, record: record})
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.threadgate/" + rkey, CID: "bafyreigate"}, nil
+	}
+	return &writes
+}
+
+func TestUpdatePostGate(t *testing.T) {
+	writes := stubThreadgatePut(t)
+	post := "at://did:plc:me/app.bsky.feed.post/3kpost"
+
+	// Gating an open post creates its threadgate, and gating it again replaces it
+	result, err := UpdatePostGate(config.Config{}, post, Threadgate{Allow: []string{"following"}})
+	if err != nil {
+		t.Fatalf("UpdatePostGate() unexpected error: %v", err)
+	}
+	if result.URI != "at://did:plc:me/app.bsky.feed.threadgate/3kpost" {
+		t.Errorf("URI = %q, want the post's threadgate", result.URI)
+	}
+	if _, err := UpdatePostGate(config.Config{}, post, Threadgate{Allow: []string{"nobody"}}); err != nil {
+		t.Fatalf("UpdatePostGate() unexpected error: %v", err)
+	}
+
+	if len(*writes) != 2 {
+		t.Fatalf("Expected 2 threadgate writes, got %d", len(*writes))
+	}
+	for _, write := range *writes {
+		if write.collection != repo.CollectionThreadgate || write.rkey != "3kpost" || write.record["post"] != post {
+			t.Errorf("Threadgate written to %s/%s for %v, want %s/3kpost for %s",
+				write.collection, write.rkey, write.record["post"], repo.CollectionThreadgate, post)
+		}
+	}
+	first := (*writes)[0].record["allow"].([]map[string]interface{})
+	if len(first) != 1 || first[0]["$type"] != "app.bsky.feed.threadgate#followingRule" {
+		t.Errorf("First rules = %v, want followingRule", first)
+	}
+	if second := (*writes)[1].record["allow"].([]map[string]interface{}); len(second) != 0 {
+		t.Errorf("Second rules = %v, want none so nobody can reply", second)
+	}
+}
+
+func TestUpdatePostGateRejected(t *testing.T) {
+	writes := stubThreadgatePut(t)
+
+	tests := []struct {
+		name    string
+		post    string
+		gate    Threadgate
+		wantErr string
+	}{
+		{name: "Another user's post", post: "at://did:plc:other/app.bsky.feed.post/3kpost", gate: Threadgate{Allow: []string{"nobody"}}, wantErr: "not one of your posts"},
+		{name: "Not a post", post: "at://did:plc:me/app.bsky.graph.list/3kl", gate: Threadgate{Allow: []string{"nobody"}}, wantErr: "is not a post"},
+		{name: "Invalid rules", post: "at://did:plc:me/app.bsky.feed.post/3kpost", gate: Threadgate{Allow: []string{"everyone"}}, wantErr: "unknown allow rule"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UpdatePostGate(config.Config{}, tt.post, tt.gate); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("UpdatePostGate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+	if len(*writes) != 0 {
+		t.Errorf("Expected no threadgate writes, got %d", len(*writes))
+	}
+}
@@ -0,0 +1,167 @@
+package post
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+// request body, keyed with the webhook secret
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+// WebhookEventPostCreated is the event sent after a post is created
+const WebhookEventPostCreated = "post.created"
+
+// WebhookOptions defines where successful submissions are announced and how
+// deliveries are retried
+type WebhookOptions struct {
+	URL             string        `json:"url"`              // Empty disables the webhook
+	Secret          string        `json:"-"`                // Signs each delivery when set
+	MaxAttempts     int           `json:"max_attempts"`     // Deliveries tried before giving up
+	InitialInterval time.Duration `json:"initial_interval"` // Delay before the first retry, doubling after each
+	MaxInterval     time.Duration `json:"max_interval"`
+	Timeout         time.Duration `json:"timeout"` // How long each delivery may take
+}
+
+// DefaultWebhookOptions contains reasonable defaults; the webhook is off until a URL is set
+var DefaultWebhookOptions = WebhookOptions{
+	MaxAttempts:     4,
+	InitialInterval: time.Second,
+	MaxInterval:     30 * time.Second,
+	Timeout:         5 * time.Second,
+}
+
+// WebhookOptionsFromEnv returns the default options adjusted by the
+// BSKY_WEBHOOK_URL, BSKY_WEBHOOK_SECRET and BSKY_WEBHOOK_MAX_ATTEMPTS
+// environment variables
+func WebhookOptionsFromEnv() WebhookOptions {
+	options := DefaultWebhookOptions
+	options.URL = os.Getenv("BSKY_WEBHOOK_URL")
+	options.Secret = os.Getenv("BSKY_WEBHOOK_SECRET")
+
+	if attempts, err := strconv.Atoi(os.Getenv("BSKY_WEBHOOK_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		options.MaxAttempts = attempts
+	}
+
+	return options
+}
+
+// WebhookPayload is the JSON body delivered to the webhook
+type WebhookPayload struct {
+	Event     string `json:"event"`
+	URI       string `json:"uri"`
+	CID       string `json:"cid"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"` // The post's createdAt, RFC 3339
+}
+
+// Shared webhook settings
+var (
+	webhook   = DefaultWebhookOptions
+	webhookMu sync.RWMutex
+)
+
+// SetWebhook configures the webhook notified after each successful submission
+func SetWebhook(options WebhookOptions) {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	webhook = options
+}
+
+// getWebhook returns the current webhook settings
+func getWebhook() WebhookOptions {
+	webhookMu.RLock()
+	defer webhookMu.RUnlock()
+	return webhook
+}
+
+// notifyPostCreated delivers a created post to the webhook in the background.
+// It does nothing when no webhook is configured, and never delays the caller.
+func notifyPostCreated(result *PostResult, text, createdAt string) {
+	options := getWebhook()
+	if options.URL == "" {
+		return
+	}
+
+	payload := WebhookPayload{
+		Event:     WebhookEventPostCreated,
+		URI:       result.URI,
+		CID:       result.CID,
+		Text:      text,
+		CreatedAt: createdAt,
+	}
+	go func() {
+		if err := deliverWebhook(options, payload); err != nil {
+			log.Printf("Warning: Webhook delivery for %s failed: %v", payload.URI, err)
+		}
+	}()
+}
+
+// deliverWebhook posts the payload to the webhook, retrying network errors
+// and 5xx or 429 responses with exponential backoff up to MaxAttempts times
+func deliverWebhook(options WebhookOptions, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = options.InitialInterval
+	expBackoff.MaxInterval = options.MaxInterval
+	expBackoff.MaxElapsedTime = 0 // Bounded by attempts instead
+
+	var bOff backoff.BackOff = expBackoff
+	if options.MaxAttempts > 1 {
+		bOff = backoff.WithMaxRetries(expBackoff, uint64(options.MaxAttempts-1))
+	} else {
+		bOff = &backoff.StopBackOff{}
+	}
+
+	client := &http.Client{Timeout: options.Timeout}
+	return backoff.Retry(func() error {
+		req, err := http.NewRequest(http.MethodPost, options.URL, bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if options.Secret != "" {
+			req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(options.Secret, body))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		default:
+			// The receiver rejected the delivery; sending it again will not help
+			return backoff.Permanent(fmt.Errorf("webhook returned status %d", resp.StatusCode))
+		}
+	}, bOff)
+}
+
+// SignWebhookPayload returns the WebhookSignatureHeader value for body, so
+// receivers can check a delivery came from this server
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
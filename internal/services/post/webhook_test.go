@@ -0,0 +1,125 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/services/repo"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// webhookDelivery is one request received by a stub webhook
+type webhookDelivery struct {
+	body      []byte
+	signature string
+}
+
+// newStubWebhook starts a webhook server answering with the given statuses in
+// turn, then 200, and sends each request it receives on the returned channel
+func newStubWebhook(t *testing.T, statuses ...int) (*httptest.Server, <-chan webhookDelivery, *int32) {
+	t.Helper()
+	deliveries := make(chan webhookDelivery, 10)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		call := int(atomic.AddInt32(&calls, 1))
+		deliveries <- webhookDelivery{body: body, signature: r.Header.Get(WebhookSignatureHeader)}
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, deliveries, &calls
+}
+
+func TestSubmitPostNotifiesWebhook(t *testing.T) {
+	SetWriteRate(0, DefaultWriteBurst)
+	defer SetWriteRate(DefaultWriteRate, DefaultWriteBurst)
+
+	originalCreateRecord := createRecord
+	defer func() { createRecord = originalCreateRecord }()
+	createRecord = func(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*repo.CreateRecordResult, error) {
+		return &repo.CreateRecordResult{URI: "at://did:plc:me/app.bsky.feed.post/1", CID: "bafyreipost"}, nil
+	}
+
+	server, deliveries, _ := newStubWebhook(t)
+	options := DefaultWebhookOptions
+	options.URL = server.URL
+	options.Secret = "shh"
+	SetWebhook(options)
+	defer SetWebhook(DefaultWebhookOptions)
+
+	if _, err := SubmitPost(config.Config{}, "Hello webhook"); err != nil {
+		t.Fatalf("SubmitPost() unexpected error: %v", err)
+	}
+
+	select {
+	case delivery := <-deliveries:
+		var payload WebhookPayload
+		if err := json.Unmarshal(delivery.body, &payload); err != nil {
+			t.Fatalf("Payload is not JSON: %v", err)
+		}
+		if payload.Event != WebhookEventPostCreated || payload.URI != "at://did:plc:me/app.bsky.feed.post/1" ||
+			payload.CID != "bafyreipost" || payload.Text != "Hello webhook" {
+			t.Errorf("Unexpected payload: %+v", payload)
+		}
+		if _, err := time.Parse(time.RFC3339, payload.CreatedAt); err != nil {
+			t.Errorf("created_at = %q, want an RFC 3339 timestamp", payload.CreatedAt)
+		}
+		if want := SignWebhookPayload("shh", delivery.body); delivery.signature != want {
+			t.Errorf("Signature = %q, want %q", delivery.signature, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("The webhook was not called")
+	}
+}
+
+func TestDeliverWebhookRetries(t *testing.T) {
+	options := WebhookOptions{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Timeout: time.Second}
+	payload := WebhookPayload{Event: WebhookEventPostCreated, URI: "at://did:plc:me/app.bsky.feed.post/1"}
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "Server errors are retried", statuses: []int{503, 500}, wantCalls: 3},
+		{name: "Attempts are bounded", statuses: []int{503, 503, 503, 503}, wantErr: true, wantCalls: 3},
+		{name: "Rejections are not retried", statuses: []int{400}, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _, calls := newStubWebhook(t, tt.statuses...)
+			options.URL = server.URL
+
+			err := deliverWebhook(options, payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("deliverWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("Webhook called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDeliverWebhookUnsigned(t *testing.T) {
+	server, deliveries, _ := newStubWebhook(t)
+	options := DefaultWebhookOptions
+	options.URL = server.URL
+
+	if err := deliverWebhook(options, WebhookPayload{Event: WebhookEventPostCreated}); err != nil {
+		t.Fatalf("deliverWebhook() unexpected error: %v", err)
+	}
+	if delivery := <-deliveries; delivery.signature != "" {
+		t.Errorf("Signature = %q, want none without a secret", delivery.signature)
+	}
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// BlobUploader defines the API client method needed to upload blobs
+type BlobUploader interface {
+	Upload(endpoint string, contentType string, body []byte) ([]byte, error)
+}
+
+// BlobRef is the content link of an uploaded blob
+type BlobRef struct {
+	Link string `json:"$link"`
+}
+
+// Blob references uploaded data from a record, such as an image or a link card thumbnail
+type Blob struct {
+	Type     string  `json:"$type"`
+	Ref      BlobRef `json:"ref"`
+	MimeType string  `json:"mimeType"`
+	Size     int64   `json:"size"`
+}
+
+// UploadBlob uploads data to the authenticated user's repository so records can reference it.
+// The blob is only kept by the server once a record references it.
+func UploadBlob(cfg config.Config, data []byte, mimeType string) (*Blob, error) {
+	client, _, err := authenticatedRepo(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadBlob(client, data, mimeType)
+}
+
+// uploadBlob uploads data with the given MIME type
+func uploadBlob(client BlobUploader, data []byte, mimeType string) (*Blob, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("invalid blob: data is required")
+	}
+	if !strings.Contains(mimeType, "/") {
+		return nil, fmt.Errorf("invalid blob: invalid MIME type %q", mimeType)
+	}
+
+	responseBody, err := client.Upload("com.atproto.repo.uploadBlob", mimeType, data)
+	if err != nil {
+		return nil, writeError("upload blob", err)
+	}
+
+	var result struct {
+		Blob Blob `json:"blob"`
+	}
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return nil, fmt.Errorf("error parsing upload blob response: %w", err)
+	}
+	if result.Blob.Ref.Link == "" {
+		return nil, fmt.Errorf("error parsing upload blob response: missing blob reference")
+	}
+	if result.Blob.Type == "" {
+		result.Blob.Type = "blob"
+	}
+
+	return &result.Blob, nil
+}
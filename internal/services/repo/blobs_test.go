@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// mockBlobUploader records uploads and returns a fixed response
+type mockBlobUploader struct {
+	contentTypes []string
+	bodies       [][]byte
+	response     string
+	err          error
+}
+
+func (m *mockBlobUploader) Upload(endpoint string, contentType string, body []byte) ([]byte, error) {
+	if endpoint != "com.atproto.repo.uploadBlob" {
+		return nil, fmt.Errorf("unexpected endpoint %s", endpoint)
+	}
+	m.contentTypes = append(m.contentTypes, contentType)
+	m.bodies = append(m.bodies, body)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []byte(m.response), nil
+}
+
+func TestUploadBlob(t *testing.T) {
+	client := &mockBlobUploader{
+		response: `{"blob": {"$type": "blob", "ref": {"$link": "bafkreithumb"}, "mimeType": "image/jpeg", "size": 3}}`,
+	}
+	data := []byte{0xff, 0xd8, 0xff}
+
+	blob, err := uploadBlob(client, data, "image/jpeg")
+	if err != nil {
+		t.Fatalf("uploadBlob() unexpected error: %v", err)
+	}
+	if blob.Type != "blob" || blob.Ref.Link != "bafkreithumb" || blob.MimeType != "image/jpeg" || blob.Size != 3 {
+		t.Errorf("uploadBlob() = %+v", blob)
+	}
+	if len(client.bodies) != 1 || !bytes.Equal(client.bodies[0], data) || client.contentTypes[0] != "image/jpeg" {
+		t.Errorf("Unexpected upload: types %v, bodies %v", client.contentTypes, client.bodies)
+	}
+}
+
+func TestUploadBlobErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		client   *mockBlobUploader
+	}{
+		{name: "No data", data: nil, mimeType: "image/png", client: &mockBlobUploader{}},
+		{name: "Invalid MIME type", data: []byte("x"), mimeType: "png", client: &mockBlobUploader{}},
+		{name: "API error", data: []byte("x"), mimeType: "image/png", client: &mockBlobUploader{err: fmt.Errorf("API error: status 400")}},
+		{name: "Missing reference", data: []byte("x"), mimeType: "image/png", client: &mockBlobUploader{response: `{"blob": {}}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uploadBlob(tt.client, tt.data, tt.mimeType); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
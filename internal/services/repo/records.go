@@ -0,0 +1,372 @@
+// Package repo provides low-level access to records in the authenticated user's repository
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/littleironwaltz/bluesky-mcp/internal/auth"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+	"github.com/littleironwaltz/bluesky-mcp/pkg/config"
+)
+
+// Record limits accepted by com.atproto.repo.listRecords
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 100
+)
+
+// Common collections
+const (
+	CollectionPost   = "app.bsky.feed.post"
+	CollectionLike   = "app.bsky.feed.like"
+	CollectionRepost = "app.bsky.feed.repost"
+	CollectionFollow = "app.bsky.graph.follow"
+	// CollectionThreadgate records must use the rkey of the post they gate
+	CollectionThreadgate = "app.bsky.feed.threadgate"
+)
+
+// nsidPattern matches a namespaced identifier such as app.bsky.feed.like
+var nsidPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*(\.[a-zA-Z][a-zA-Z0-9-]*){2,}$`)
+
+// RecordsClient defines the API client methods needed to read records
+type RecordsClient interface {
+	Get(endpoint string, params url.Values) ([]byte, error)
+}
+
+// RecordWriter defines the API client methods needed to write records
+type RecordWriter interface {
+	Post(endpoint string, body interface{}) ([]byte, error)
+}
+
+// contextWriter is a RecordWriter whose writes can be cancelled
+type contextWriter interface {
+	PostContext(ctx context.Context, endpoint string, body interface{}) ([]byte, error)
+}
+
+// postContext posts with ctx when the client supports it; a plain RecordWriter
+// is only checked for a done ctx before the write
+func postContext(ctx context.Context, client RecordWriter, endpoint string, body interface{}) ([]byte, error) {
+	if writer, ok := client.(contextWriter); ok {
+		return writer.PostContext(ctx, endpoint, body)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.Post(endpoint, body)
+}
+
+// Record is a single record in a repository collection
+type Record struct {
+	URI   string                 `json:"uri"`
+	CID   string                 `json:"cid"`
+	Value map[string]interface{} `json:"value"`
+}
+
+// ListRecordsResult contains a page of records and the cursor for the next page
+type ListRecordsResult struct {
+	Records []Record `json:"records"`
+	Cursor  string   `json:"cursor,omitempty"` // Empty when there are no more records
+}
+
+// CreateRecordResult identifies a newly created or replaced record
+type CreateRecordResult struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+// ValidateCollection checks that collection is a valid NSID
+func ValidateCollection(collection string) error {
+	if len(collection) > 317 || !nsidPattern.MatchString(collection) {
+		return fmt.Errorf("invalid collection: %q is not a valid NSID", collection)
+	}
+	return nil
+}
+
+// ListRecords lists records of a collection in the authenticated user's repository.
+// A limit of 0 uses DefaultListLimit; pass the returned cursor to fetch the next page.
+func ListRecords(cfg config.Config, collection string, limit int, cursor string) (*ListRecordsResult, error) {
+	if err := ValidateCollection(collection); err != nil {
+		return nil, err
+	}
+
+	client, did, err := authenticatedRepo(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return listRecords(client, did, collection, limit, cursor)
+}
+
+// CreateRecord creates a record in a collection of the authenticated user's repository.
+// The record's $type is set to the collection if missing and must match it otherwise.
+func CreateRecord(cfg config.Config, collection string, record map[string]interface{}) (*CreateRecordResult, error) {
+	return CreateRecordContext(context.Background(), cfg, collection, record)
+}
+
+// CreateRecordContext is CreateRecord with a context that cancels the write
+func CreateRecordContext(ctx context.Context, cfg config.Config, collection string, record map[string]interface{}) (*CreateRecordResult, error) {
+	return CreateRecordWithKeyContext(ctx, cfg, collection, "", record)
+}
+
+// CreateRecordWithKey creates a record with the given record key; an empty rkey lets the server choose one
+func CreateRecordWithKey(cfg config.Config, collection, rkey string, record map[string]interface{}) (*CreateRecordResult, error) {
+	return CreateRecordWithKeyContext(context.Background(), cfg, collection, rkey, record)
+}
+
+// CreateRecordWithKeyContext is CreateRecordWithKey with a context that cancels
+// the write. A write cancelled after it was sent may still have been applied.
+func CreateRecordWithKeyContext(ctx context.Context, cfg config.Config, collection, rkey string, record map[string]interface{}) (*CreateRecordResult, error) {
+	if err := validateRecord(collection, record); err != nil {
+		return nil, err
+	}
+
+	client, did, err := authenticatedRepo(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return createRecord(ctx, client, did, collection, rkey, record)
+}
+
+// PutRecord creates the record with the given record key, or replaces the one already there
+func PutRecord(cfg config.Config, collection, rkey string, record map[string]interface{}) (*CreateRecordResult, error) {
+	return PutRecordContext(context.Background(), cfg, collection, rkey, record)
+}
+
+// PutRecordContext is PutRecord with a context that cancels the write
+func PutRecordContext(ctx context.Context, cfg config.Config, collection, rkey string, record map[string]interface{}) (*CreateRecordResult, error) {
+	if err := validateRecord(collection, record); err != nil {
+		return nil, err
+	}
+
+	client, did, err := authenticatedRepo(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return putRecord(ctx, client, did, collection, rkey, record)
+}
+
+// authenticatedClient is the API client used for the authenticated user's repository
+type authenticatedClient interface {
+	RecordsClient
+	RecordWriter
+	BlobUploader
+}
+
+// authenticatedRepo returns an authenticated client and the user's DID
+func authenticatedRepo(cfg config.Config) (authenticatedClient, string, error) {
+	// Get auth token from Bluesky API
+	token, err := auth.GetToken(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	tokenManager := auth.GetTokenManager(cfg)
+	did := tokenManager.GetDID()
+	if did == "" {
+		return nil, "", fmt.Errorf("unable to get user DID")
+	}
+
+	client := tokenManager.GetClient()
+	client.SetAuthToken(token)
+
+	return client, did, nil
+}
+
+// validateRecord checks the collection and fills in or verifies the record's $type
+func validateRecord(collection string, record map[string]interface{}) error {
+	if err := ValidateCollection(collection); err != nil {
+		return err
+	}
+	if record == nil {
+		return fmt.Errorf("invalid record: record is required")
+	}
+
+	switch recordType := record["$type"].(type) {
+	case nil:
+		record["$type"] = collection
+	case string:
+		if recordType != collection {
+			return fmt.Errorf("invalid record: $type %q does not match collection %q", recordType, collection)
+		}
+	default:
+		return fmt.Errorf("invalid record: $type must be a string")
+	}
+
+	return nil
+}
+
+// createRecord writes a record to the given repository
+func createRecord(ctx context.Context, client RecordWriter, repo, collection, rkey string, record map[string]interface{}) (*CreateRecordResult, error) {
+	if err := validateRecord(collection, record); err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(rkey, "/?#") {
+		return nil, fmt.Errorf("invalid record key: %q", rkey)
+	}
+
+	request := map[string]interface{}{
+		"repo":       repo,
+		"collection": collection,
+		"record":     record,
+	}
+	if rkey != "" {
+		request["rkey"] = rkey
+	}
+
+	responseBody, err := postContext(ctx, client, "com.atproto.repo.createRecord", request)
+	if err != nil {
+		return nil, writeError("create record", err)
+	}
+
+	var result CreateRecordResult
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return nil, fmt.Errorf("error parsing create record response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// putRecord writes a record to the given repository under rkey, replacing any existing one
+func putRecord(ctx context.Context, client RecordWriter, repo, collection, rkey string, record map[string]interface{}) (*CreateRecordResult, error) {
+	if err := validateRecord(collection, record); err != nil {
+		return nil, err
+	}
+	if rkey == "" || strings.ContainsAny(rkey, "/?#") {
+		return nil, fmt.Errorf("invalid record key: %q", rkey)
+	}
+
+	request := map[string]interface{}{
+		"repo":       repo,
+		"collection": collection,
+		"rkey":       rkey,
+		"record":     record,
+	}
+
+	responseBody, err := postContext(ctx, client, "com.atproto.repo.putRecord", request)
+	if err != nil {
+		return nil, writeError("put record", err)
+	}
+
+	var result CreateRecordResult
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return nil, fmt.Errorf("error parsing put record response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// listRecords fetches a single page of records from the given repository
+func listRecords(client RecordsClient, repo, collection string, limit int, cursor string) (*ListRecordsResult, error) {
+	if err := ValidateCollection(collection); err != nil {
+		return nil, err
+	}
+	if limit < 0 || limit > MaxListLimit {
+		return nil, fmt.Errorf("invalid limit: must be between 1 and %d", MaxListLimit)
+	}
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	query := url.Values{}
+	query.Set("repo", repo)
+	query.Set("collection", collection)
+	query.Set("limit", fmt.Sprintf("%d", limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	responseBody, err := client.Get("com.atproto.repo.listRecords", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records: %w", err)
+	}
+
+	var result ListRecordsResult
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return nil, fmt.Errorf("error parsing list records response: %w", err)
+	}
+	if result.Records == nil {
+		result.Records = []Record{}
+	}
+
+	return &result, nil
+}
+
+// DeleteRecord deletes a record from a collection of the authenticated user's repository
+func DeleteRecord(cfg config.Config, collection, rkey string) error {
+	return DeleteRecordContext(context.Background(), cfg, collection, rkey)
+}
+
+// DeleteRecordContext is DeleteRecord with a context that cancels the write
+func DeleteRecordContext(ctx context.Context, cfg config.Config, collection, rkey string) error {
+	if err := ValidateCollection(collection); err != nil {
+		return err
+	}
+
+	client, did, err := authenticatedRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	return deleteRecord(ctx, client, did, collection, rkey)
+}
+
+// deleteRecord removes a record from the given repository
+func deleteRecord(ctx context.Context, client RecordWriter, repo, collection, rkey string) error {
+	if err := ValidateCollection(collection); err != nil {
+		return err
+	}
+	if rkey == "" || strings.ContainsAny(rkey, "/?#") {
+		return fmt.Errorf("invalid record key: %q", rkey)
+	}
+
+	request := map[string]interface{}{
+		"repo":       repo,
+		"collection": collection,
+		"rkey":       rkey,
+	}
+
+	if _, err := postContext(ctx, client, "com.atproto.repo.deleteRecord", request); err != nil {
+		return writeError("delete record", err)
+	}
+
+	return nil
+}
+
+// RecordKey returns the record key from an at://<repo>/<collection>/<rkey> URI
+func RecordKey(uri string) string {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// RecordRepo returns the repository, the DID of its account, from an at://<repo>/<collection>/<rkey> URI
+func RecordRepo(uri string) string {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[0]
+}
+
+// ErrAppPasswordScope is wrapped into the error returned when a write is
+// refused because the app password in use does not allow it
+var ErrAppPasswordScope = errors.New("the app password in use lacks permission for this action")
+
+// writeError describes a failed write. A refusal caused by a restricted app
+// password says so, since it is not fixed by signing in again.
+func writeError(action string, err error) error {
+	if errors.Is(err, apiclient.ErrInsufficientScope) {
+		return fmt.Errorf("failed to %s: %w; use an app password with the needed access or the account password (%w)", action, ErrAppPasswordScope, err)
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
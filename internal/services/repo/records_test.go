@@ -0,0 +1,338 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/littleironwaltz/bluesky-mcp/pkg/apiclient"
+)
+
+// mockRecordsClient serves pages of records keyed by cursor
+type mockRecordsClient struct {
+	pages   map[string]ListRecordsResult
+	queries []url.Values
+}
+
+func (m *mockRecordsClient) Get(endpoint string, params url.Values) ([]byte, error) {
+	if endpoint != "com.atproto.repo.listRecords" {
+		return nil, fmt.Errorf("unexpected endpoint %s", endpoint)
+	}
+	m.queries = append(m.queries, params)
+
+	page, ok := m.pages[params.Get("cursor")]
+	if !ok {
+		return nil, fmt.Errorf("API error: status 400")
+	}
+	return json.Marshal(page)
+}
+
+func TestValidateCollection(t *testing.T) {
+	valid := []string{CollectionPost, CollectionLike, CollectionRepost, CollectionFollow, "com.example.my-records"}
+	for _, collection := range valid {
+		if err := ValidateCollection(collection); err != nil {
+			t.Errorf("ValidateCollection(%q) unexpected error: %v", collection, err)
+		}
+	}
+
+	invalid := []string{"", "posts", "app.bsky", "app..bsky.like", "app.bsky.feed.like?x=1", "app.bsky.feed.like/../x"}
+	for _, collection := range invalid {
+		if err := ValidateCollection(collection); err == nil {
+			t.Errorf("ValidateCollection(%q) expected error", collection)
+		}
+	}
+}
+
+func TestListRecords(t *testing.T) {
+	client := &mockRecordsClient{
+		pages: map[string]ListRecordsResult{
+			"": {
+				Records: []Record{
+					{URI: "at://did:plc:me/app.bsky.feed.like/1", CID: "cid1", Value: map[string]interface{}{"$type": CollectionLike}},
+					{URI: "at://did:plc:me/app.bsky.feed.like/2", CID: "cid2", Value: map[string]interface{}{"$type": CollectionLike}},
+				},
+				Cursor: "page2",
+			},
+			"page2": {
+				Records: []Record{
+					{URI: "at://did:plc:me/app.bsky.feed.like/3", CID: "cid3", Value: map[string]interface{}{"$type": CollectionLike}},
+				},
+			},
+		},
+	}
+
+	// First page
+	result, err := listRecords(client, "did:plc:me", CollectionLike, 2, "")
+	if err != nil {
+		t.Fatalf("listRecords() unexpected error: %v", err)
+	}
+	if len(result.Records) != 2 || result.Cursor != "page2" {
+		t.Fatalf("First page = %d records, cursor %q; want 2 records, cursor page2", len(result.Records), result.Cursor)
+	}
+
+	query := client.queries[0]
+	if query.Get("repo") != "did:plc:me" || query.Get("collection") != CollectionLike || query.Get("limit") != "2" {
+		t.Errorf("Unexpected query: %v", query)
+	}
+	if query.Has("cursor") {
+		t.Errorf("First page should not send a cursor, got %q", query.Get("cursor"))
+	}
+
+	// Follow the cursor to the last page
+	result, err = listRecords(client, "did:plc:me", CollectionLike, 2, result.Cursor)
+	if err != nil {
+		t.Fatalf("listRecords() unexpected error: %v", err)
+	}
+	if len(result.Records) != 1 || result.Records[0].CID != "cid3" {
+		t.Errorf("Second page = %+v, want the third record", result.Records)
+	}
+	if result.Cursor != "" {
+		t.Errorf("Last page cursor = %q, want empty", result.Cursor)
+	}
+	if client.queries[1].Get("cursor") != "page2" {
+		t.Errorf("Second request cursor = %q, want page2", client.queries[1].Get("cursor"))
+	}
+}
+
+func TestListRecordsInvalidParams(t *testing.T) {
+	client := &mockRecordsClient{}
+
+	if _, err := listRecords(client, "did:plc:me", "likes", 10, ""); err == nil {
+		t.Error("Expected error for invalid collection")
+	}
+	if _, err := listRecords(client, "did:plc:me", CollectionLike, MaxListLimit+1, ""); err == nil {
+		t.Error("Expected error for limit above maximum")
+	}
+	if len(client.queries) != 0 {
+		t.Errorf("Invalid params should not reach the API, got %d requests", len(client.queries))
+	}
+}
+
+// mockRecordWriter records createRecord and deleteRecord requests, failing them with err if set
+type mockRecordWriter struct {
+	endpoints []string
+	requests  []map[string]interface{}
+	err       error
+}
+
+func (m *mockRecordWriter) Post(endpoint string, body interface{}) ([]byte, error) {
+	m.endpoints = append(m.endpoints, endpoint)
+	m.requests = append(m.requests, body.(map[string]interface{}))
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	switch endpoint {
+	case "com.atproto.repo.createRecord":
+		return []byte(`{"uri": "at://did:plc:me/app.bsky.graph.list/1", "cid": "bafyreilist"}`), nil
+	case "com.atproto.repo.putRecord":
+		return []byte(`{"uri": "at://did:plc:me/app.bsky.feed.threadgate/3k2a", "cid": "bafyreigate"}`), nil
+	case "com.atproto.repo.deleteRecord":
+		return []byte(`{}`), nil
+	}
+	return nil, fmt.Errorf("unexpected endpoint %s", endpoint)
+}
+
+func TestCreateRecord(t *testing.T) {
+	client := &mockRecordWriter{}
+	record := map[string]interface{}{
+		"purpose":   "app.bsky.graph.defs#curatelist",
+		"name":      "Go developers",
+		"createdAt": "2025-01-01T00:00:00Z",
+	}
+
+	result, err := createRecord(context.Background(), client, "did:plc:me", "app.bsky.graph.list", "", record)
+	if err != nil {
+		t.Fatalf("createRecord() unexpected error: %v", err)
+	}
+	if result.URI != "at://did:plc:me/app.bsky.graph.list/1" || result.CID != "bafyreilist" {
+		t.Errorf("createRecord() = %+v", result)
+	}
+
+	if len(client.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(client.requests))
+	}
+	request := client.requests[0]
+	if request["repo"] != "did:plc:me" || request["collection"] != "app.bsky.graph.list" {
+		t.Errorf("Unexpected repo/collection in request: %v", request)
+	}
+	sent := request["record"].(map[string]interface{})
+	if sent["$type"] != "app.bsky.graph.list" {
+		t.Errorf("Record $type = %v, want it filled in from the collection", sent["$type"])
+	}
+	if sent["name"] != "Go developers" {
+		t.Errorf("Record name = %v, want Go developers", sent["name"])
+	}
+	if _, ok := request["rkey"]; ok {
+		t.Errorf("Request should not set rkey when none is given: %v", request)
+	}
+}
+
+func TestCreateRecordWithKey(t *testing.T) {
+	client := &mockRecordWriter{}
+	record := map[string]interface{}{"post": "at://did:plc:me/app.bsky.feed.post/3k2a"}
+
+	if _, err := createRecord(context.Background(), client, "did:plc:me", CollectionThreadgate, "3k2a", record); err != nil {
+		t.Fatalf("createRecord() unexpected error: %v", err)
+	}
+	if len(client.requests) != 1 || client.requests[0]["rkey"] != "3k2a" {
+		t.Errorf("Expected request with rkey 3k2a, got %v", client.requests)
+	}
+
+	if _, err := createRecord(context.Background(), client, "did:plc:me", CollectionThreadgate, "a/b", record); err == nil {
+		t.Error("Expected error for invalid record key, got nil")
+	}
+}
+
+func TestPutRecord(t *testing.T) {
+	client := &mockRecordWriter{}
+	record := map[string]interface{}{"post": "at://did:plc:me/app.bsky.feed.post/3k2a", "allow": []interface{}{}}
+
+	result, err := putRecord(context.Background(), client, "did:plc:me", CollectionThreadgate, "3k2a", record)
+	if err != nil {
+		t.Fatalf("putRecord() unexpected error: %v", err)
+	}
+	if result.URI != "at://did:plc:me/app.bsky.feed.threadgate/3k2a" || result.CID != "bafyreigate" {
+		t.Errorf("putRecord() = %+v", result)
+	}
+	if client.endpoints[0] != "com.atproto.repo.putRecord" {
+		t.Errorf("Endpoint = %q, want com.atproto.repo.putRecord", client.endpoints[0])
+	}
+	request := client.requests[0]
+	if request["repo"] != "did:plc:me" || request["collection"] != CollectionThreadgate || request["rkey"] != "3k2a" {
+		t.Errorf("Unexpected put request: %v", request)
+	}
+	if sent := request["record"].(map[string]interface{}); sent["$type"] != CollectionThreadgate {
+		t.Errorf("Record $type = %v, want it filled in from the collection", sent["$type"])
+	}
+
+	// A record key is required to know which record to replace
+	for _, rkey := range []string{"", "a/b"} {
+		if _, err := putRecord(context.Background(), client, "did:plc:me", CollectionThreadgate, rkey, record); err == nil {
+			t.Errorf("Expected error for record key %q, got nil", rkey)
+		}
+	}
+}
+
+func TestCreateRecordInvalid(t *testing.T) {
+	client := &mockRecordWriter{}
+
+	tests := []struct {
+		name       string
+		collection string
+		record     map[string]interface{}
+	}{
+		{name: "Invalid collection", collection: "lists", record: map[string]interface{}{}},
+		{name: "Missing record", collection: "app.bsky.graph.list", record: nil},
+		{name: "Mismatched type", collection: "app.bsky.graph.list", record: map[string]interface{}{"$type": CollectionPost}},
+		{name: "Non-string type", collection: "app.bsky.graph.list", record: map[string]interface{}{"$type": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := createRecord(context.Background(), client, "did:plc:me", tt.collection, "", tt.record); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+
+	if len(client.requests) != 0 {
+		t.Errorf("Invalid records should not reach the API, got %d requests", len(client.requests))
+	}
+}
+
+func TestCreateRecordScopeDenied(t *testing.T) {
+	client := &mockRecordWriter{err: &apiclient.APIError{
+		StatusCode: 400,
+		Response:   map[string]interface{}{"error": "InvalidToken", "message": "Bad token scope"},
+	}}
+	record := map[string]interface{}{"text": "hi", "createdAt": "2025-01-01T00:00:00Z"}
+
+	_, err := createRecord(context.Background(), client, "did:plc:me", CollectionPost, "", record)
+	if !errors.Is(err, ErrAppPasswordScope) || !errors.Is(err, apiclient.ErrInsufficientScope) {
+		t.Fatalf("Expected an app password scope error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "app password in use lacks permission") {
+		t.Errorf("Error = %q, want it to explain the app password lacks permission", err)
+	}
+	if apiclient.IsUnauthorizedError(err) {
+		t.Error("A scope refusal was treated as an expired session")
+	}
+
+	// Other failures keep their usual message
+	client.err = &apiclient.APIError{StatusCode: 401, Response: map[string]interface{}{"error": "ExpiredToken"}}
+	if _, err := createRecord(context.Background(), client, "did:plc:me", CollectionPost, "", record); errors.Is(err, ErrAppPasswordScope) || !apiclient.IsUnauthorizedError(err) {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestCreateRecordPersistentUnauthorized(t *testing.T) {
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/xrpc/com.atproto.repo.createRecord" {
+			posts++
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"ExpiredToken"}`))
+	}))
+	defer server.Close()
+
+	client := apiclient.NewClient(server.URL)
+	reauths := 0
+	client.SetReauthFunc(func() (string, error) {
+		reauths++
+		return "still-rejected", nil
+	})
+	record := map[string]interface{}{"text": "hi", "createdAt": "2025-01-01T00:00:00Z"}
+
+	// The write is replayed once after re-authenticating, and no more
+	if _, err := createRecord(context.Background(), client, "did:plc:me", CollectionPost, "", record); !apiclient.IsUnauthorizedError(err) {
+		t.Fatalf("Expected an unauthorized error, got %v", err)
+	}
+	if posts != 2 || reauths != 1 {
+		t.Errorf("Got %d POSTs and %d re-authentications, want 2 and 1", posts, reauths)
+	}
+}
+
+func TestDeleteRecord(t *testing.T) {
+	client := &mockRecordWriter{}
+
+	if err := deleteRecord(context.Background(), client, "did:plc:me", "app.bsky.graph.listitem", "3kitem"); err != nil {
+		t.Fatalf("deleteRecord() unexpected error: %v", err)
+	}
+	if client.endpoints[0] != "com.atproto.repo.deleteRecord" {
+		t.Errorf("Endpoint = %q, want com.atproto.repo.deleteRecord", client.endpoints[0])
+	}
+	request := client.requests[0]
+	if request["repo"] != "did:plc:me" || request["collection"] != "app.bsky.graph.listitem" || request["rkey"] != "3kitem" {
+		t.Errorf("Unexpected delete request: %v", request)
+	}
+
+	if err := deleteRecord(context.Background(), client, "did:plc:me", "app.bsky.graph.listitem", "a/b"); err == nil {
+		t.Error("Expected error for invalid record key")
+	}
+}
+
+func TestRecordKey(t *testing.T) {
+	if got := RecordKey("at://did:plc:me/app.bsky.graph.listitem/3kitem"); got != "3kitem" {
+		t.Errorf("RecordKey() = %q, want 3kitem", got)
+	}
+	if got := RecordKey("at://did:plc:me"); got != "" {
+		t.Errorf("RecordKey() = %q, want empty for a repo URI", got)
+	}
+}
+
+func TestRecordRepo(t *testing.T) {
+	if got := RecordRepo("at://did:plc:me/app.bsky.feed.post/3kpost"); got != "did:plc:me" {
+		t.Errorf("RecordRepo() = %q, want did:plc:me", got)
+	}
+	if got := RecordRepo("https://bsky.app/profile/me"); got != "" {
+		t.Errorf("RecordRepo() = %q, want empty for a web URL", got)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,9 +21,10 @@ import (
 	"github.com/cenkalti/backoff/v4"
 )
 
-// RetryConfig defines retry behavior
+// RetryConfig defines retry behavior. Retrying stops at whichever of
+// MaxRetries or MaxElapsedTime is reached first; a zero value disables that limit.
 type RetryConfig struct {
-	MaxRetries      int
+	MaxRetries      int // Retries after the first attempt
 	InitialInterval time.Duration
 	MaxInterval     time.Duration
 	Multiplier      float64
@@ -48,11 +51,88 @@ type BlueskyClient struct {
 	isCircuitOpen      bool
 	circuitLastChecked time.Time
 	FallbackResponses  map[string][]byte
+	reauth             ReauthFunc
+	inflightMu         sync.Mutex
+	inflight           map[string]*inflightCall // GETs in flight, by URL and token, while coalescing
 }
 
+// ReauthFunc returns a fresh access token after a request is rejected as unauthorized
+type ReauthFunc func() (string, error)
+
 // ErrCircuitOpen is returned when the circuit breaker is open
 var ErrCircuitOpen = errors.New("circuit breaker is open")
 
+// ErrUnauthorized matches, with errors.Is, requests rejected because the
+// credentials or token were not accepted. Retrying such a request cannot
+// succeed, so it neither retries nor counts toward opening the circuit breaker.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrInsufficientScope matches, with errors.Is, requests refused because the
+// session's app password does not allow them, such as a write or a direct
+// message with a restricted app password. A new session would have the same
+// scope, so it is not treated as ErrUnauthorized.
+var ErrInsufficientScope = errors.New("insufficient scope")
+
+// ErrAccountUnavailable matches, with errors.Is, requests refused because the
+// account they read is suspended, taken down or deactivated. The account is
+// not coming back on a retry, so this is not a network or upstream failure.
+var ErrAccountUnavailable = errors.New("account unavailable")
+
+// accountStatuses maps the error codes the API answers with for unavailable
+// accounts to the status they report
+var accountStatuses = map[string]string{
+	"AccountTakedown":    "suspended",
+	"RepoTakendown":      "suspended",
+	"RepoSuspended":      "suspended",
+	"AccountDeactivated": "deactivated",
+	"RepoDeactivated":    "deactivated",
+}
+
+// APIError is an error status returned by the API
+type APIError struct {
+	StatusCode int
+	Response   map[string]interface{} // Decoded error body, nil if it was not JSON
+}
+
+func (e *APIError) Error() string {
+	if e.Response != nil {
+		return fmt.Sprintf("API error (status %d): %v", e.StatusCode, e.Response)
+	}
+	return fmt.Sprintf("API error (status %d)", e.StatusCode)
+}
+
+// Is makes 401 responses match ErrUnauthorized, scope refusals
+// ErrInsufficientScope, and refusals to read an unavailable account
+// ErrAccountUnavailable
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized && !e.isScopeDenied()
+	case ErrInsufficientScope:
+		return e.isScopeDenied()
+	case ErrAccountUnavailable:
+		return e.AccountStatus() != ""
+	}
+	return false
+}
+
+// AccountStatus returns "suspended" or "deactivated" when the request was
+// refused because the account it reads is, and "" otherwise
+func (e *APIError) AccountStatus() string {
+	code, _ := e.Response["error"].(string)
+	return accountStatuses[code]
+}
+
+// isScopeDenied reports whether the response says the token's scope does not
+// allow the request. Servers answer with an InsufficientScope or
+// ScopeMissingError code, or an InvalidToken code and "Bad token scope".
+func (e *APIError) isScopeDenied() bool {
+	code, _ := e.Response["error"].(string)
+	message, _ := e.Response["message"].(string)
+	return code == "InsufficientScope" || code == "ScopeMissingError" ||
+		strings.Contains(strings.ToLower(message), "bad token scope")
+}
+
 // Default configurations
 var (
 	DefaultRetryConfig = RetryConfig{
@@ -97,6 +177,12 @@ func (c *BlueskyClient) SetCircuitBreakerConfig(config CircuitBreakerConfig) {
 	c.CircuitBreaker = config
 }
 
+// SetReauthFunc sets the function used to re-authenticate once when a request
+// is rejected with an expired or invalid token
+func (c *BlueskyClient) SetReauthFunc(reauth ReauthFunc) {
+	c.reauth = reauth
+}
+
 // RegisterFallbackResponse registers a fallback response for an endpoint
 func (c *BlueskyClient) RegisterFallbackResponse(endpoint string, response []byte) {
 	c.FallbackResponses[endpoint] = response
@@ -104,30 +190,45 @@ func (c *BlueskyClient) RegisterFallbackResponse(endpoint string, response []byt
 
 // Get performs a GET request to the specified API endpoint
 func (c *BlueskyClient) Get(endpoint string, params url.Values) ([]byte, error) {
+	return c.GetContext(context.Background(), endpoint, params)
+}
+
+// GetContext performs a GET request that is abandoned, retries included, once ctx is done
+func (c *BlueskyClient) GetContext(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
 	// Construct full URL
 	apiURL := fmt.Sprintf("%s/xrpc/%s", c.BaseURL, endpoint)
 	if len(params) > 0 {
 		apiURL = fmt.Sprintf("%s?%s", apiURL, params.Encode())
 	}
 
-	// Create request
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set auth token if available
-	if c.AuthToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	}
-
-	// Execute request with retries
-	ctx := context.Background()
-	return c.executeRequestWithRetries(ctx, req, endpoint)
+	// Identical GETs in flight at once may share one request; the token is part
+	// of the key so different sessions never share a response
+	key := apiURL + "\x00" + c.AuthToken
+	return c.coalesce(ctx, key, func() ([]byte, error) {
+		return c.executeWithReauth(ctx, endpoint, func() (*http.Request, error) {
+			// Create request
+			req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create request: %w", err)
+			}
+
+			// Set auth token if available
+			if c.AuthToken != "" {
+				req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+			}
+			return req, nil
+		})
+	})
 }
 
 // Post performs a POST request to the specified API endpoint
 func (c *BlueskyClient) Post(endpoint string, body interface{}) ([]byte, error) {
+	return c.PostContext(context.Background(), endpoint, body)
+}
+
+// PostContext performs a POST request that is abandoned, retries included, once
+// ctx is done. A POST cancelled after it was sent may still have been applied.
+func (c *BlueskyClient) PostContext(ctx context.Context, endpoint string, body interface{}) ([]byte, error) {
 	// Marshal request body
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -137,23 +238,89 @@ func (c *BlueskyClient) Post(endpoint string, body interface{}) ([]byte, error)
 	// Construct full URL
 	apiURL := fmt.Sprintf("%s/xrpc/%s", c.BaseURL, endpoint)
 
-	// Create request
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonBody))
+	return c.executeWithReauth(ctx, endpoint, func() (*http.Request, error) {
+		// Create request
+		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		req.Header.Set("Content-Type", "application/json")
+		if c.AuthToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+		}
+		return req, nil
+	})
+}
+
+// Upload performs a POST request with a raw, non-JSON body such as a blob
+func (c *BlueskyClient) Upload(endpoint string, contentType string, body []byte) ([]byte, error) {
+	// Construct full URL
+	apiURL := fmt.Sprintf("%s/xrpc/%s", c.BaseURL, endpoint)
+
+	return c.executeWithReauth(context.Background(), endpoint, func() (*http.Request, error) {
+		// Create request
+		req, err := http.NewRequest("POST", apiURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		req.Header.Set("Content-Type", contentType)
+		if c.AuthToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+		}
+		return req, nil
+	})
+}
+
+// executeWithReauth executes the request built by newRequest. If the token is
+// rejected, it re-authenticates once and replays a freshly built request.
+func (c *BlueskyClient) executeWithReauth(ctx context.Context, endpoint string, newRequest func() (*http.Request, error)) ([]byte, error) {
+	req, err := newRequest()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	if c.AuthToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	// Execute request with retries
+	responseBody, err := c.executeRequestWithRetries(ctx, req, endpoint)
+	if err == nil || c.reauth == nil || !IsUnauthorizedError(err) || isSessionEndpoint(endpoint) {
+		return responseBody, err
 	}
 
-	// Execute request with retries
-	ctx := context.Background()
+	token, reauthErr := c.reauth()
+	if reauthErr != nil {
+		return nil, fmt.Errorf("%w (re-authentication failed: %v)", err, reauthErr)
+	}
+	c.SetAuthToken(token)
+
+	req, err = newRequest()
+	if err != nil {
+		return nil, err
+	}
 	return c.executeRequestWithRetries(ctx, req, endpoint)
 }
 
+// IsUnauthorizedError reports whether err means the access token was rejected
+func IsUnauthorizedError(err error) bool {
+	if errors.Is(err, ErrInsufficientScope) {
+		return false
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		return true
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "status 401") ||
+		strings.Contains(errStr, "ExpiredToken") ||
+		strings.Contains(errStr, "InvalidToken")
+}
+
+// isSessionEndpoint reports whether endpoint creates or refreshes sessions; these are never re-authenticated
+func isSessionEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "com.atproto.server.")
+}
+
 // executeRequestWithRetries executes an HTTP request with built-in retries and circuit breaking
 func (c *BlueskyClient) executeRequestWithRetries(ctx context.Context, req *http.Request, endpoint string) ([]byte, error) {
 	// Check if circuit breaker is open
@@ -166,14 +333,24 @@ func (c *BlueskyClient) executeRequestWithRetries(ctx context.Context, req *http
 	}
 
 	// Create exponential backoff
-	bOff := backoff.NewExponentialBackOff()
-	bOff.InitialInterval = c.RetryConfig.InitialInterval
-	bOff.MaxInterval = c.RetryConfig.MaxInterval
-	bOff.Multiplier = c.RetryConfig.Multiplier
-	bOff.MaxElapsedTime = c.RetryConfig.MaxElapsedTime
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = c.RetryConfig.InitialInterval
+	expBackoff.MaxInterval = c.RetryConfig.MaxInterval
+	expBackoff.Multiplier = c.RetryConfig.Multiplier
+	expBackoff.MaxElapsedTime = c.RetryConfig.MaxElapsedTime
+
+	// Cap the number of retries as well as the elapsed time
+	var bOff backoff.BackOff = expBackoff
+	if c.RetryConfig.MaxRetries > 0 {
+		bOff = backoff.WithMaxRetries(expBackoff, uint64(c.RetryConfig.MaxRetries))
+	}
+
+	// Stop retrying once the caller gives up
+	bOff = backoff.WithContext(bOff, ctx)
 
+	tracer := NewRetryTracer(endpoint)
 	var responseBody []byte
-	err := backoff.Retry(func() error {
+	err := backoff.RetryNotify(func() error {
 		var err error
 		responseBody, err = c.executeRequest(req.Clone(ctx))
 
@@ -183,18 +360,22 @@ func (c *BlueskyClient) executeRequestWithRetries(ctx context.Context, req *http
 			return nil
 		}
 
-		// If error occurred, record failure
-		c.recordFailure()
-
-		// Check if the error is retryable (network error or 5xx)
+		// Only transient failures (network errors or 5xx) mean the upstream is
+		// down, so only they count toward opening the circuit and are retried
 		if isRetryableError(err) {
+			c.recordFailure()
 			return err // Return the error to retry
 		}
-		return backoff.Permanent(err) // Don't retry other errors
-	}, bOff)
 
-	// If all retries failed but we have a fallback, use it
-	if err != nil && c.FallbackResponses[endpoint] != nil {
+		// Other errors, such as rejected credentials, come from a working
+		// upstream and cannot succeed on retry
+		return backoff.Permanent(err)
+	}, bOff, tracer.Notify)
+	err = tracer.Finish(err)
+
+	// If all retries failed but we have a fallback, use it; a caller that gave
+	// up gets its own error instead
+	if err != nil && ctx.Err() == nil && c.FallbackResponses[endpoint] != nil {
 		return c.FallbackResponses[endpoint], nil
 	}
 
@@ -293,49 +474,119 @@ func (c *BlueskyClient) executeRequest(req *http.Request) ([]byte, error) {
 
 	// Check for error status codes
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
 		var errorResponse map[string]interface{}
 		if err := json.Unmarshal(responseBody, &errorResponse); err == nil {
-			return nil, fmt.Errorf("API error (status %d): %v", resp.StatusCode, errorResponse)
+			apiErr.Response = errorResponse
 		}
-		return nil, fmt.Errorf("API error (status %d)", resp.StatusCode)
+		return nil, apiErr
 	}
 
 	return responseBody, nil
 }
 
-// Singleton HTTP client
+// ClientOptions tune the shared HTTP client's connection pool and protocol
+type ClientOptions struct {
+	MaxIdleConns        int           // Idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // Idle connections kept to each host
+	IdleConnTimeout     time.Duration // How long an idle connection is kept
+	DisableHTTP2        bool          // Use HTTP/1.1 only, for proxies that break HTTP/2
+}
+
+// DefaultClientOptions are used for fields left unset
+var DefaultClientOptions = ClientOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 20,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// withDefaults fills in zero or negative fields from DefaultClientOptions
+func (o ClientOptions) withDefaults() ClientOptions {
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = DefaultClientOptions.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = DefaultClientOptions.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = DefaultClientOptions.IdleConnTimeout
+	}
+	return o
+}
+
+// ClientOptionsFromEnv reads the HTTP client options from BSKY_HTTP_MAX_IDLE_CONNS,
+// BSKY_HTTP_MAX_IDLE_CONNS_PER_HOST, BSKY_HTTP_IDLE_CONN_TIMEOUT and BSKY_HTTP2,
+// falling back to DefaultClientOptions
+func ClientOptionsFromEnv() ClientOptions {
+	options := DefaultClientOptions
+	if conns, err := strconv.Atoi(os.Getenv("BSKY_HTTP_MAX_IDLE_CONNS")); err == nil && conns > 0 {
+		options.MaxIdleConns = conns
+	}
+	if conns, err := strconv.Atoi(os.Getenv("BSKY_HTTP_MAX_IDLE_CONNS_PER_HOST")); err == nil && conns > 0 {
+		options.MaxIdleConnsPerHost = conns
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("BSKY_HTTP_IDLE_CONN_TIMEOUT")); err == nil && timeout > 0 {
+		options.IdleConnTimeout = timeout
+	}
+	options.DisableHTTP2 = os.Getenv("BSKY_HTTP2") == "false"
+	return options
+}
+
+// Shared HTTP client, built from clientOptions on first use
 var (
-	client *http.Client
-	once   sync.Once
+	client        *http.Client
+	clientOptions = DefaultClientOptions
+	clientMu      sync.Mutex
 )
 
-// getHTTPClient returns the shared HTTP client instance
-func getHTTPClient() *http.Client {
-	once.Do(func() {
-		transport := &http.Transport{
-			// Security settings
-			TLSClientConfig: &tls.Config{
-				MinVersion: tls.VersionTLS12,
-			},
-			// Connection pooling settings
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 20,
-			IdleConnTimeout:     90 * time.Second,
-			// Additional performance settings
-			DisableCompression: false,
-			ForceAttemptHTTP2:  true,
-			// Timeouts
-			ResponseHeaderTimeout: 10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-		}
+// SetClientOptions changes the options of the shared HTTP client; zero fields
+// use the defaults. Clients created afterwards use a new shared HTTP client,
+// so it should be called at startup before any client is created.
+func SetClientOptions(options ClientOptions) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	clientOptions = options.withDefaults()
+	client = nil
+}
 
-		// Create the client with the configured transport
-		client = &http.Client{
-			Transport: transport,
-			Timeout:   10 * time.Second,
-		}
-	})
+// NewHTTPClient creates an HTTP client with the given connection pool and protocol options
+func NewHTTPClient(options ClientOptions) *http.Client {
+	options = options.withDefaults()
+	transport := &http.Transport{
+		// Security settings
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+		// Connection pooling settings
+		MaxIdleConns:        options.MaxIdleConns,
+		MaxIdleConnsPerHost: options.MaxIdleConnsPerHost,
+		IdleConnTimeout:     options.IdleConnTimeout,
+		// Additional performance settings
+		DisableCompression: false,
+		ForceAttemptHTTP2:  !options.DisableHTTP2,
+		// Timeouts
+		ResponseHeaderTimeout: 10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if options.DisableHTTP2 {
+		// A non-nil empty map keeps the transport from upgrading to HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
 
+	// Create the client with the configured transport
+	return &http.Client{
+		Transport: transport,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// getHTTPClient returns the shared HTTP client instance
+func getHTTPClient() *http.Client {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client == nil {
+		client = NewHTTPClient(clientOptions)
+	}
 	return client
 }
 
@@ -1,12 +1,18 @@
 package apiclient
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -198,6 +204,233 @@ func TestPost(t *testing.T) {
 	}
 }
 
+func TestMaxRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// No elapsed time limit, so only MaxRetries can stop the retries
+	client := NewClient(server.URL)
+	client.SetRetryConfig(RetryConfig{
+		MaxRetries:      2,
+		InitialInterval: time.Microsecond,
+		MaxInterval:     time.Microsecond,
+		Multiplier:      1,
+		MaxElapsedTime:  0,
+	})
+
+	if _, err := client.Get("com.example.test", nil); err == nil {
+		t.Error("Expected error from failing server")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 requests (1 attempt + 2 retries), got %d", calls)
+	}
+}
+
+func TestPostContextCancelled(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetRetryConfig(RetryConfig{
+		MaxRetries:      5,
+		InitialInterval: time.Microsecond,
+		MaxInterval:     time.Microsecond,
+		Multiplier:      1,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.PostContext(ctx, "com.example.test", map[string]string{"key": "value"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PostContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("PostContext() took %v, want it to stop when ctx is done", elapsed)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 request after cancellation, got %d", n)
+	}
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	var requests int32
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		<-release
+		w.Write([]byte(`{"feed":[]}`))
+	}))
+	defer server.Close()
+
+	SetRequestCoalescing(true)
+	defer SetRequestCoalescing(false)
+
+	client := NewClient(server.URL)
+	params := url.Values{"actor": {"alice.bsky.social"}}
+
+	const callers = 20
+	var wg sync.WaitGroup
+	bodies := make([][]byte, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i], errs[i] = client.Get("app.bsky.feed.getAuthorFeed", params)
+		}(i)
+	}
+
+	// Hold the first request until the other callers have joined it
+	<-arrived
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Got %d upstream requests for %d identical GETs, want 1", got, callers)
+	}
+	for i := range bodies {
+		if errs[i] != nil || string(bodies[i]) != `{"feed":[]}` {
+			t.Errorf("Caller %d got %q, %v, want the shared response", i, bodies[i], errs[i])
+		}
+	}
+
+	// Requests that differ, or that come after, are sent on their own
+	client.Get("app.bsky.feed.getAuthorFeed", url.Values{"actor": {"bob.bsky.social"}})
+	client.Get("app.bsky.feed.getAuthorFeed", params)
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Got %d upstream requests in all, want 3", got)
+	}
+}
+
+func TestRetryTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetRetryConfig(RetryConfig{
+		MaxRetries:      2,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1,
+	})
+
+	// Off by default
+	_, err := client.Get("com.example.test", nil)
+	if _, ok := RetryTraceFromError(err); ok {
+		t.Errorf("Expected no retry trace while tracing is off, got %v", err)
+	}
+
+	SetRetryTracing(true)
+	defer SetRetryTracing(false)
+
+	_, err = client.Get("com.example.test", nil)
+	trace, ok := RetryTraceFromError(err)
+	if !ok {
+		t.Fatalf("Expected a retry trace, got %v", err)
+	}
+	if len(trace) != 3 {
+		t.Fatalf("Expected 3 attempts (1 attempt + 2 retries), got %d: %s", len(trace), trace)
+	}
+	for i, attempt := range trace {
+		if attempt.Attempt != i+1 || !strings.Contains(attempt.Error, "status 503") {
+			t.Errorf("Attempt %d = %+v, want the 503 response", i+1, attempt)
+		}
+		if last := i == len(trace)-1; last != (attempt.DelayBeforeNext == 0) {
+			t.Errorf("Attempt %d delay = %s, want a delay before every retry only", i+1, attempt.DelayBeforeNext)
+		}
+	}
+	if err.Error() != trace[2].Error {
+		t.Errorf("Expected the final error to keep its message, got %q", err.Error())
+	}
+}
+
+// newBreakerTestClient returns a client that retries once without delay and
+// opens its circuit after two failures
+func newBreakerTestClient(url string) *BlueskyClient {
+	client := NewClient(url)
+	client.SetRetryConfig(RetryConfig{
+		MaxRetries:      1,
+		InitialInterval: time.Microsecond,
+		MaxInterval:     time.Microsecond,
+		Multiplier:      1,
+	})
+	client.SetCircuitBreakerConfig(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		ResetTimeout:     time.Minute,
+	})
+	return client
+}
+
+func TestCircuitBreakerIgnoresAuthFailures(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"AuthenticationRequired"}`))
+	}))
+	defer server.Close()
+
+	client := newBreakerTestClient(server.URL)
+	for i := 0; i < 5; i++ {
+		_, err := client.Get("app.bsky.feed.getTimeline", nil)
+		if !errors.Is(err, ErrUnauthorized) || !IsUnauthorizedError(err) {
+			t.Fatalf("Request %d: expected an unauthorized error, got %v", i+1, err)
+		}
+	}
+
+	// Every call reached the server once: no retries and no open circuit
+	if requests != 5 {
+		t.Errorf("Expected 5 requests, got %d", requests)
+	}
+	if client.isCircuitBreakerOpen() {
+		t.Error("Expected the circuit to stay closed after auth failures")
+	}
+}
+
+func TestCircuitBreakerOpensOnUpstreamFailures(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newBreakerTestClient(server.URL)
+	if _, err := client.Get("app.bsky.feed.getTimeline", nil); err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected an upstream error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests (1 attempt + 1 retry), got %d", requests)
+	}
+
+	// The retried 503s opened the circuit, so the next call fails fast
+	if _, err := client.Get("app.bsky.feed.getTimeline", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected no request while the circuit is open, got %d in total", requests)
+	}
+}
+
 func TestIsRetryableError(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -285,3 +518,238 @@ func (e *testError) Timeout() bool {
 func (e *testError) Temporary() bool {
 	return e.temp
 }
+
+func TestReauthOnUnauthorized(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"ExpiredToken"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetAuthToken("expired-token")
+	reauths := 0
+	client.SetReauthFunc(func() (string, error) {
+		reauths++
+		return "fresh-token", nil
+	})
+
+	// GET and POST requests are both replayed with the new token
+	if _, err := client.Get("app.bsky.feed.getTimeline", nil); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	client.SetAuthToken("expired-token")
+	if _, err := client.Post("com.atproto.repo.createRecord", map[string]string{"text": "hi"}); err != nil {
+		t.Fatalf("Post() unexpected error: %v", err)
+	}
+
+	if reauths != 2 {
+		t.Errorf("Expected 2 re-authentications, got %d", reauths)
+	}
+	if requests != 4 {
+		t.Errorf("Expected 4 requests (two rejected, two replayed), got %d", requests)
+	}
+}
+
+func TestInsufficientScopeIsNotReauthenticated(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "Bad token scope", status: http.StatusBadRequest, body: `{"error":"InvalidToken","message":"Bad token scope"}`},
+		{name: "Insufficient scope code", status: http.StatusForbidden, body: `{"error":"InsufficientScope","message":"Missing scope"}`},
+		{name: "Scope missing as unauthorized", status: http.StatusUnauthorized, body: `{"error":"ScopeMissingError"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+			reauths := 0
+			client.SetReauthFunc(func() (string, error) {
+				reauths++
+				return "fresh-token", nil
+			})
+
+			_, err := client.Post("com.atproto.repo.createRecord", map[string]string{"text": "hi"})
+			if !errors.Is(err, ErrInsufficientScope) {
+				t.Fatalf("Expected ErrInsufficientScope, got %v", err)
+			}
+			if errors.Is(err, ErrUnauthorized) || IsUnauthorizedError(err) {
+				t.Error("A scope refusal was treated as unauthorized")
+			}
+			if reauths != 0 || requests != 1 {
+				t.Errorf("Got %d re-authentications and %d requests, want 0 and 1", reauths, requests)
+			}
+		})
+	}
+}
+
+func TestAccountUnavailable(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus string
+	}{
+		{name: "Taken down", body: `{"error":"AccountTakedown","message":"Account has been suspended"}`, wantStatus: "suspended"},
+		{name: "Deactivated", body: `{"error":"AccountDeactivated","message":"Account is deactivated"}`, wantStatus: "deactivated"},
+		{name: "Other bad request", body: `{"error":"InvalidRequest","message":"actor must be a valid did or a handle"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewClient(server.URL).Get("app.bsky.feed.getAuthorFeed", nil)
+			if got := errors.Is(err, ErrAccountUnavailable); got != (tt.wantStatus != "") {
+				t.Fatalf("errors.Is(%v, ErrAccountUnavailable) = %v", err, got)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.AccountStatus() != tt.wantStatus {
+				t.Errorf("AccountStatus() of %v, want %q", err, tt.wantStatus)
+			}
+			if requests != 1 {
+				t.Errorf("Got %d requests, want 1 as the refusal is not retried", requests)
+			}
+		})
+	}
+}
+
+func TestReauthOnlyOnce(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	reauths := 0
+	client.SetReauthFunc(func() (string, error) {
+		reauths++
+		return "still-rejected", nil
+	})
+
+	if _, err := client.Get("app.bsky.feed.getTimeline", nil); err == nil {
+		t.Fatal("Get() expected error, got nil")
+	}
+	if reauths != 1 || requests != 2 {
+		t.Errorf("Expected 1 re-authentication and 2 requests, got %d and %d", reauths, requests)
+	}
+
+	// Session endpoints are never re-authenticated
+	if _, err := client.Post("com.atproto.server.refreshSession", map[string]string{}); err == nil {
+		t.Fatal("Post() expected error, got nil")
+	}
+	if reauths != 1 {
+		t.Errorf("Session endpoint should not trigger re-authentication, got %d", reauths)
+	}
+}
+
+func TestReauthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetReauthFunc(func() (string, error) {
+		return "", errors.New("invalid credentials")
+	})
+
+	_, err := client.Get("app.bsky.feed.getTimeline", nil)
+	if err == nil || !strings.Contains(err.Error(), "status 401") || !strings.Contains(err.Error(), "re-authentication failed") {
+		t.Errorf("Get() error = %v, want the 401 and the re-authentication failure", err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	blob := []byte{0x89, 'P', 'N', 'G'}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/xrpc/com.atproto.repo.uploadBlob" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "image/png" {
+			t.Errorf("Expected Content-Type image/png, got %s", contentType)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("Expected bearer token, got %q", auth)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil || !bytes.Equal(body, blob) {
+			t.Errorf("Body = %v (err %v), want the raw blob", body, err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"blob": {"$type": "blob"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetAuthToken("token")
+
+	response, err := client.Upload("com.atproto.repo.uploadBlob", "image/png", blob)
+	if err != nil {
+		t.Fatalf("Upload() unexpected error: %v", err)
+	}
+	if !strings.Contains(string(response), "blob") {
+		t.Errorf("Upload() response = %s", response)
+	}
+}
+
+func TestNewHTTPClientOptions(t *testing.T) {
+	httpClient := NewHTTPClient(ClientOptions{
+		MaxIdleConns:        200,
+		MaxIdleConnsPerHost: 64,
+		IdleConnTimeout:     30 * time.Second,
+		DisableHTTP2:        true,
+	})
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport = %T, want *http.Transport", httpClient.Transport)
+	}
+	if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 64 || transport.IdleConnTimeout != 30*time.Second {
+		t.Errorf("Pool = %d/%d/%v, want 200/64/30s", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("HTTP/2 is still enabled")
+	}
+
+	// Unset fields keep the defaults
+	transport = NewHTTPClient(ClientOptions{MaxIdleConnsPerHost: 64}).Transport.(*http.Transport)
+	if transport.MaxIdleConns != 100 || transport.MaxIdleConnsPerHost != 64 || transport.IdleConnTimeout != 90*time.Second {
+		t.Errorf("Pool = %d/%d/%v, want 100/64/90s", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("HTTP/2 is disabled by default")
+	}
+
+	// Clients created after SetClientOptions share a client with the new options
+	SetClientOptions(ClientOptions{MaxIdleConnsPerHost: 64})
+	defer SetClientOptions(DefaultClientOptions)
+	if got := NewClient("https://example.com").HTTPClient.Transport.(*http.Transport).MaxIdleConnsPerHost; got != 64 {
+		t.Errorf("Shared client MaxIdleConnsPerHost = %d, want 64", got)
+	}
+}
@@ -0,0 +1,62 @@
+package apiclient
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+)
+
+// requestCoalescing makes concurrent identical GETs share one upstream
+// request; off by default
+var requestCoalescing atomic.Bool
+
+// SetRequestCoalescing turns request coalescing on or off. While on, a GET
+// made while an identical one (same URL and token) is in flight waits for it
+// and returns its response instead of calling the API again. Nothing is kept
+// once the request completes; this is not a cache.
+func SetRequestCoalescing(enabled bool) {
+	requestCoalescing.Store(enabled)
+}
+
+// RequestCoalescingFromEnv reports whether BSKY_COALESCE_REQUESTS asks for request coalescing
+func RequestCoalescingFromEnv() bool {
+	return os.Getenv("BSKY_COALESCE_REQUESTS") == "true"
+}
+
+// inflightCall is a GET in progress that identical GETs wait for
+type inflightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// coalesce runs request for key, or, with coalescing on and a request for key
+// already in flight, waits for that one and returns a copy of its response.
+// A request that can be cancelled is never shared, since its cancellation
+// would fail every request waiting on it.
+func (c *BlueskyClient) coalesce(ctx context.Context, key string, request func() ([]byte, error)) ([]byte, error) {
+	if !requestCoalescing.Load() || ctx.Done() != nil {
+		return request()
+	}
+
+	c.inflightMu.Lock()
+	if call, ok := c.inflight[key]; ok {
+		c.inflightMu.Unlock()
+		<-call.done
+		return append([]byte(nil), call.body...), call.err
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	if c.inflight == nil {
+		c.inflight = make(map[string]*inflightCall)
+	}
+	c.inflight[key] = call
+	c.inflightMu.Unlock()
+
+	call.body, call.err = request()
+
+	c.inflightMu.Lock()
+	delete(c.inflight, key)
+	c.inflightMu.Unlock()
+	close(call.done)
+	return call.body, call.err
+}
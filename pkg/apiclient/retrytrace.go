@@ -0,0 +1,105 @@
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RetryAttempt is one failed attempt of a retried operation
+type RetryAttempt struct {
+	Attempt         int           `json:"attempt"`
+	Error           string        `json:"error"`
+	DelayBeforeNext time.Duration `json:"delayBeforeNext"` // Zero when no retry followed
+}
+
+// RetryTrace lists the failed attempts of one retried operation in order
+type RetryTrace []RetryAttempt
+
+func (t RetryTrace) String() string {
+	parts := make([]string, len(t))
+	for i, attempt := range t {
+		parts[i] = fmt.Sprintf("attempt %d: %s", attempt.Attempt, attempt.Error)
+		if attempt.DelayBeforeNext > 0 {
+			parts[i] += fmt.Sprintf(" (retrying in %s)", attempt.DelayBeforeNext)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// RetryTraceError carries the retry trace of an operation that failed while
+// retry tracing was enabled. Its message is that of the final error.
+type RetryTraceError struct {
+	Err   error
+	Trace RetryTrace
+}
+
+func (e *RetryTraceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the operation's final error
+func (e *RetryTraceError) Unwrap() error {
+	return e.Err
+}
+
+// RetryTraceFromError returns the retry trace attached to err, if there is one
+func RetryTraceFromError(err error) (RetryTrace, bool) {
+	var traceErr *RetryTraceError
+	if errors.As(err, &traceErr) {
+		return traceErr.Trace, true
+	}
+	return nil, false
+}
+
+// retryTracing turns on retry traces for every retried operation; off by default
+var retryTracing atomic.Bool
+
+// SetRetryTracing turns retry traces on or off. While on, each retried
+// operation that fails logs its attempts and returns them in a RetryTraceError.
+func SetRetryTracing(enabled bool) {
+	retryTracing.Store(enabled)
+}
+
+// RetryTracingFromEnv reports whether BSKY_RETRY_TRACE asks for retry traces
+func RetryTracingFromEnv() bool {
+	return os.Getenv("BSKY_RETRY_TRACE") == "true"
+}
+
+// RetryTracer records the attempts of one retried operation. A nil tracer,
+// returned while tracing is off, records nothing.
+type RetryTracer struct {
+	operation string
+	trace     RetryTrace
+}
+
+// NewRetryTracer returns a tracer for operation, or nil if retry tracing is off
+func NewRetryTracer(operation string) *RetryTracer {
+	if !retryTracing.Load() {
+		return nil
+	}
+	return &RetryTracer{operation: operation}
+}
+
+// Notify records a failed attempt that will be retried after delay; it is a backoff.Notify
+func (t *RetryTracer) Notify(err error, delay time.Duration) {
+	if t == nil {
+		return
+	}
+	t.trace = append(t.trace, RetryAttempt{Attempt: len(t.trace) + 1, Error: err.Error(), DelayBeforeNext: delay})
+}
+
+// Finish records the final attempt's error, logs the trace and returns err
+// wrapped in a RetryTraceError. Successes and nil tracers return err unchanged.
+func (t *RetryTracer) Finish(err error) error {
+	if t == nil || err == nil {
+		return err
+	}
+	t.trace = append(t.trace, RetryAttempt{Attempt: len(t.trace) + 1, Error: err.Error()})
+	log.Printf("Retry trace for %s: %s", t.operation, t.trace)
+	return &RetryTraceError{Err: err, Trace: t.trace}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// Access restricts which client IPs may call the MCP endpoints.
+// Entries are IP addresses or CIDR ranges.
+type Access struct {
+	AllowIPs       []string `json:"allow_ips"`       // Only these sources are allowed; empty allows all
+	DenyIPs        []string `json:"deny_ips"`        // Always rejected, even when also allowed
+	TrustedProxies []string `json:"trusted_proxies"` // Proxies whose X-Forwarded-For header is believed
+}
+
+// Enabled reports whether any allow or deny rule is configured
+func (a Access) Enabled() bool {
+	return len(a.AllowIPs) > 0 || len(a.DenyIPs) > 0
+}
+
+// accessFromEnv reads comma-separated lists from BSKY_ALLOW_IPS,
+// BSKY_DENY_IPS and BSKY_TRUSTED_PROXIES
+func accessFromEnv() Access {
+	return Access{
+		AllowIPs:       splitList(os.Getenv("BSKY_ALLOW_IPS")),
+		DenyIPs:        splitList(os.Getenv("BSKY_DENY_IPS")),
+		TrustedProxies: splitList(os.Getenv("BSKY_TRUSTED_PROXIES")),
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
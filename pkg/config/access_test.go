@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigAccess(t *testing.T) {
+	t.Setenv("BSKY_CONFIG_FILE", "")
+	t.Setenv("BSKY_ALLOW_IPS", "10.0.0.0/8, 192.0.2.1,")
+	t.Setenv("BSKY_DENY_IPS", "")
+	t.Setenv("BSKY_TRUSTED_PROXIES", "127.0.0.1")
+
+	cfg := LoadConfig()
+	if want := []string{"10.0.0.0/8", "192.0.2.1"}; !reflect.DeepEqual(cfg.Access.AllowIPs, want) {
+		t.Errorf("AllowIPs = %v, want %v", cfg.Access.AllowIPs, want)
+	}
+	if len(cfg.Access.DenyIPs) != 0 {
+		t.Errorf("DenyIPs = %v, want none", cfg.Access.DenyIPs)
+	}
+	if !cfg.Access.Enabled() {
+		t.Error("Expected access rules to be enabled")
+	}
+
+	// File lists replace the environment's
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"Access": {"deny_ips": ["198.51.100.0/24"]}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("BSKY_CONFIG_FILE", configFile)
+
+	cfg = LoadConfig()
+	if want := []string{"198.51.100.0/24"}; !reflect.DeepEqual(cfg.Access.DenyIPs, want) {
+		t.Errorf("DenyIPs = %v, want %v", cfg.Access.DenyIPs, want)
+	}
+	if want := []string{"127.0.0.1"}; !reflect.DeepEqual(cfg.Access.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %v, want %v from the environment", cfg.Access.TrustedProxies, want)
+	}
+}
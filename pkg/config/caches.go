@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Caches tunes the caches the services keep. Zero fields keep each service's defaults.
+type Caches struct {
+	Feed      CacheSettings `json:"feed"`      // feed-analysis results
+	Community CacheSettings `json:"community"` // community-manage user feeds
+}
+
+// CacheSettings tunes one service cache. A zero field keeps the service's default.
+type CacheSettings struct {
+	MaxItems     int      `json:"max_items"`     // Most entries kept in memory
+	TTL          Duration `json:"ttl"`           // How long an entry is fresh
+	StaleTimeout Duration `json:"stale_timeout"` // How long an expired entry may be served when a refresh fails
+	Directory    string   `json:"directory"`     // Where entries are persisted; "off" keeps them in memory only
+}
+
+// CacheDirectoryOff as a CacheSettings Directory disables persistence
+const CacheDirectoryOff = "off"
+
+// Duration is a time.Duration written in config files as a string such as "90s" or "5m"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// overriddenBy returns s with the non-zero fields of override applied
+func (s CacheSettings) overriddenBy(override CacheSettings) CacheSettings {
+	if override.MaxItems > 0 {
+		s.MaxItems = override.MaxItems
+	}
+	if override.TTL > 0 {
+		s.TTL = override.TTL
+	}
+	if override.StaleTimeout > 0 {
+		s.StaleTimeout = override.StaleTimeout
+	}
+	if override.Directory != "" {
+		s.Directory = override.Directory
+	}
+	return s
+}
+
+// overriddenBy returns c with the non-zero settings of override applied
+func (c Caches) overriddenBy(override Caches) Caches {
+	return Caches{
+		Feed:      c.Feed.overriddenBy(override.Feed),
+		Community: c.Community.overriddenBy(override.Community),
+	}
+}
+
+// cachesFromEnv reads each cache's settings from BSKY_<NAME>_CACHE_MAX_ITEMS,
+// BSKY_<NAME>_CACHE_TTL, BSKY_<NAME>_CACHE_STALE_TIMEOUT and BSKY_<NAME>_CACHE_DIR,
+// where NAME is FEED or COMMUNITY. Unset or invalid values are left at zero so
+// the defaults apply.
+func cachesFromEnv() Caches {
+	return Caches{
+		Feed:      cacheSettingsFromEnv("BSKY_FEED_CACHE_"),
+		Community: cacheSettingsFromEnv("BSKY_COMMUNITY_CACHE_"),
+	}
+}
+
+// cacheSettingsFromEnv reads one cache's settings from the variables starting with prefix
+func cacheSettingsFromEnv(prefix string) CacheSettings {
+	envDuration := func(key string) Duration {
+		value, err := time.ParseDuration(os.Getenv(key))
+		if err != nil || value < 0 {
+			return 0
+		}
+		return Duration(value)
+	}
+
+	var settings CacheSettings
+	if maxItems, err := strconv.Atoi(os.Getenv(prefix + "MAX_ITEMS")); err == nil && maxItems > 0 {
+		settings.MaxItems = maxItems
+	}
+	settings.TTL = envDuration(prefix + "TTL")
+	settings.StaleTimeout = envDuration(prefix + "STALE_TIMEOUT")
+	settings.Directory = os.Getenv(prefix + "DIR")
+	return settings
+}
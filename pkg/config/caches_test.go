@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigCaches(t *testing.T) {
+	t.Setenv("BSKY_CONFIG_FILE", "")
+	t.Setenv("BSKY_FEED_CACHE_MAX_ITEMS", "500")
+	t.Setenv("BSKY_FEED_CACHE_TTL", "90s")
+	t.Setenv("BSKY_FEED_CACHE_STALE_TIMEOUT", "not-a-duration")
+	t.Setenv("BSKY_FEED_CACHE_DIR", "off")
+	t.Setenv("BSKY_COMMUNITY_CACHE_TTL", "10m")
+
+	cfg := LoadConfig()
+	want := CacheSettings{MaxItems: 500, TTL: Duration(90 * time.Second), Directory: CacheDirectoryOff}
+	if cfg.Caches.Feed != want {
+		t.Errorf("Feed cache = %+v, want %+v from the environment", cfg.Caches.Feed, want)
+	}
+	if cfg.Caches.Community != (CacheSettings{TTL: Duration(10 * time.Minute)}) {
+		t.Errorf("Community cache = %+v, want only the TTL from the environment", cfg.Caches.Community)
+	}
+
+	// File settings override the environment field by field
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	content := `{"Caches": {
+		"feed": {"ttl": "3m", "stale_timeout": "2h", "directory": "/var/cache/feed"},
+		"community": {"max_items": 200}
+	}}`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("BSKY_CONFIG_FILE", configFile)
+
+	cfg = LoadConfig()
+	want = CacheSettings{MaxItems: 500, TTL: Duration(3 * time.Minute), StaleTimeout: Duration(2 * time.Hour), Directory: "/var/cache/feed"}
+	if cfg.Caches.Feed != want {
+		t.Errorf("Feed cache = %+v, want %+v", cfg.Caches.Feed, want)
+	}
+	if cfg.Caches.Community != (CacheSettings{MaxItems: 200, TTL: Duration(10 * time.Minute)}) {
+		t.Errorf("Community cache = %+v", cfg.Caches.Community)
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	var d Duration
+	if err := d.UnmarshalJSON([]byte(`"1h30m"`)); err != nil || time.Duration(d) != 90*time.Minute {
+		t.Errorf("UnmarshalJSON() = %v, %v; want 1h30m", time.Duration(d), err)
+	}
+	if err := d.UnmarshalJSON([]byte(`300`)); err == nil {
+		t.Error("Expected an error for a number, which has no unit")
+	}
+	if data, err := Duration(5 * time.Minute).MarshalJSON(); err != nil || string(data) != `"5m0s"` {
+		t.Errorf("MarshalJSON() = %s, %v", data, err)
+	}
+}
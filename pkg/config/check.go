@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// CheckError lists every problem Check found with a configuration
+type CheckError struct {
+	Problems []string
+}
+
+func (e *CheckError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Check loads the configuration as LoadConfig does, reading configFile in
+// place of BSKY_CONFIG_FILE when it is set, and validates it without starting
+// anything or contacting Bluesky. Where LoadConfig ignores a config file it
+// cannot read or values it cannot use, Check reports them, along with unknown
+// fields in the file, so every problem is returned in a *CheckError at once.
+// The configuration is returned even when it is invalid.
+func Check(configFile string) (Config, error) {
+	if configFile == "" {
+		configFile = os.Getenv("BSKY_CONFIG_FILE")
+	}
+
+	var problems []string
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("config file %s: %v", configFile, err))
+	} else if configFile != "" {
+		problems = append(problems, checkConfigFile(configFile)...)
+	}
+
+	if _, err := ResolveMode(cfg); err != nil {
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, checkHost("host", cfg.BskyHost)...)
+	if cfg.AuthHost != "" {
+		problems = append(problems, checkHost("auth host", cfg.AuthHost)...)
+	}
+	for _, list := range []struct {
+		name    string
+		entries []string
+	}{
+		{"allowed IPs", cfg.Access.AllowIPs},
+		{"denied IPs", cfg.Access.DenyIPs},
+		{"trusted proxies", cfg.Access.TrustedProxies},
+	} {
+		for _, entry := range list.entries {
+			if !validIPEntry(entry) {
+				problems = append(problems, fmt.Sprintf("%s: %q is not an IP address or CIDR range", list.name, entry))
+			}
+		}
+	}
+	if cfg.SessionCache.File != "" && cfg.SessionCache.Key == "" {
+		problems = append(problems, "session cache file is set without a key, so sessions would not be persisted")
+	}
+
+	if len(problems) > 0 {
+		return cfg, &CheckError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// checkConfigFile reports fields of the config file that are not used, such
+// as misspelled ones, and values that loading ignores
+func checkConfigFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{fmt.Sprintf("config file %s: %v", path, err)}
+	}
+	var fileCfg Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fileCfg); err != nil {
+		return []string{fmt.Sprintf("config file %s: %v", path, err)}
+	}
+
+	var problems []string
+	negative := func(name string, isNegative bool) {
+		if isNegative {
+			problems = append(problems, fmt.Sprintf("%s must not be negative", name))
+		}
+	}
+	negative("Limits.hashtag_length", fileCfg.Limits.HashtagLength < 0)
+	negative("Limits.handle_length", fileCfg.Limits.HandleLength < 0)
+	negative("Limits.topic_length", fileCfg.Limits.TopicLength < 0)
+	negative("Limits.text_length", fileCfg.Limits.TextLength < 0)
+	negative("Limits.analyzed_posts", fileCfg.Limits.AnalyzedPosts < 0)
+	for _, cache := range []struct {
+		name     string
+		settings CacheSettings
+	}{
+		{"Caches.feed", fileCfg.Caches.Feed},
+		{"Caches.community", fileCfg.Caches.Community},
+	} {
+		negative(cache.name+".max_items", cache.settings.MaxItems < 0)
+		negative(cache.name+".ttl", cache.settings.TTL < 0)
+		negative(cache.name+".stale_timeout", cache.settings.StaleTimeout < 0)
+	}
+	return problems
+}
+
+// checkHost reports a host that is not an absolute http or https URL
+func checkHost(name, host string) []string {
+	if host == "" {
+		return []string{fmt.Sprintf("missing Bluesky %s in configuration", name)}
+	}
+	parsed, err := url.Parse(host)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return []string{fmt.Sprintf("%s %q must be an http or https URL such as https://bsky.social", name, host)}
+	}
+	return nil
+}
+
+// validIPEntry reports whether entry is an IP address or CIDR range
+func validIPEntry(entry string) bool {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		_, _, err := net.ParseCIDR(entry)
+		return err == nil
+	}
+	return net.ParseIP(entry) != nil
+}
+
+// redacted replaces a secret with a marker that only tells whether it is set
+func redacted(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	return "(redacted)"
+}
+
+// Summary describes the configuration for operators, one setting per line,
+// with the effective limits and without the password or session cache key
+func (c Config) Summary() string {
+	var b strings.Builder
+	line := func(name, format string, args ...interface{}) {
+		fmt.Fprintf(&b, "%-15s %s\n", name+":", fmt.Sprintf(format, args...))
+	}
+
+	line("Account", "%s", c.BskyID)
+	line("Password", "%s", redacted(c.BskyPassword))
+	line("Mode", "%s", c.Mode)
+	line("Host", "%s", strings.TrimRight(c.BskyHost, "/"))
+	line("Session host", "%s", c.SessionHost())
+	line("Limits", "hashtag %d, handle %d, topic %d, text %d characters; %d analyzed posts",
+		c.Limits.Max(FieldHashtag), c.Limits.Max(FieldHandle), c.Limits.Max(FieldTopic),
+		c.Limits.Max(FieldText), c.Limits.MaxAnalyzedPosts())
+	line("Access", "%d allowed, %d denied, %d trusted proxies",
+		len(c.Access.AllowIPs), len(c.Access.DenyIPs), len(c.Access.TrustedProxies))
+	if c.Suggester != "" {
+		line("Suggester", "%s", c.Suggester)
+	}
+	if c.SessionCache.File != "" {
+		line("Session cache", "%s (key %s)", c.SessionCache.File, redacted(c.SessionCache.Key))
+	}
+	return b.String()
+}
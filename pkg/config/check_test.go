@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearConfigEnv unsets the variables Check reads for the duration of a test
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"BSKY_ID", "BSKY_PASSWORD", "BSKY_HOST", "BSKY_AUTH_HOST", "BSKY_MODE", "MOCK_MODE",
+		"BSKY_CONFIG_FILE", "BSKY_ALLOW_IPS", "BSKY_DENY_IPS", "BSKY_TRUSTED_PROXIES",
+		"BSKY_SESSION_CACHE_FILE", "BSKY_SESSION_CACHE_KEY"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Writing the config file: %v", err)
+	}
+	return path
+}
+
+func TestCheck(t *testing.T) {
+	clearConfigEnv(t)
+
+	path := writeConfigFile(t, `{
+		"BskyID": "alice.bsky.social",
+		"BskyPassword": "app-password",
+		"AuthHost": "https://pds.example.com/",
+		"Mode": "live",
+		"Limits": {"text_length": 500},
+		"Access": {"allow_ips": ["10.0.0.0/8", "192.168.1.5"]}
+	}`)
+	cfg, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	if cfg.BskyID != "alice.bsky.social" || cfg.Limits.TextLength != 500 {
+		t.Errorf("Check() = %+v, want the file's settings", cfg)
+	}
+
+	// The summary shows the effective settings but never the password
+	summary := cfg.Summary()
+	for _, want := range []string{"alice.bsky.social", "https://pds.example.com\n", "text 500 characters", "hashtag 64", "2 allowed"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", summary, want)
+		}
+	}
+	if strings.Contains(summary, "app-password") || !strings.Contains(summary, "(redacted)") {
+		t.Errorf("Summary() = %q, want the password redacted", summary)
+	}
+}
+
+func TestCheckInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "Unreadable JSON",
+			content: `{"BskyID": `,
+			want:    []string{"config file", "unexpected end of JSON input"},
+		},
+		{
+			name:    "Misspelled field",
+			content: `{"BskyID": "alice.bsky.social", "BskyPasword": "secret"}`,
+			want:    []string{`unknown field "BskyPasword"`, "missing Bluesky credentials"},
+		},
+		{
+			name:    "Bad values",
+			content: `{"Mode": "test", "BskyHost": "bsky.social", "Limits": {"text_length": -1}, "Access": {"deny_ips": ["10.0.0.300"]}}`,
+			want: []string{`invalid mode "test"`, `host "bsky.social" must be an http or https URL`,
+				"Limits.text_length must not be negative", `denied IPs: "10.0.0.300" is not an IP address`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+
+			_, err := Check(writeConfigFile(t, tt.content))
+			var checkErr *CheckError
+			if !errors.As(err, &checkErr) {
+				t.Fatalf("Check() error = %v, want a *CheckError", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Check() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+
+	// A missing file is reported rather than ignored as LoadConfig does
+	clearConfigEnv(t)
+	t.Setenv("BSKY_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := Check(""); err == nil || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("Check() with a missing file error = %v", err)
+	}
+}
@@ -6,44 +6,138 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Config struct {
 	BskyID       string
 	BskyPassword string
 	BskyHost     string
+	AuthHost     string // Host for createSession and refreshSession, may include a path prefix; empty uses BskyHost
+	Mode         Mode
+	Limits       Limits       // Input length limits; zero fields use DefaultLimits
+	Access       Access       // Client IP allow and deny lists for the server
+	Suggester    string       // Name of the post suggestion backend; empty uses templates
+	Caches       Caches       // Service cache sizes, lifetimes and persistence; zero fields use each service's defaults
+	SessionCache SessionCache // Encrypted on-disk copy of the session; disabled unless both fields are set
 }
 
+// Mode selects whether the service talks to the Bluesky API or serves mock data
+type Mode string
+
+const (
+	// ModeAuto uses live mode when credentials are configured and mock mode otherwise
+	ModeAuto Mode = "auto"
+	// ModeLive requires credentials and talks to the Bluesky API
+	ModeLive Mode = "live"
+	// ModeMock serves mock data in the CLI. The server runs read-only instead:
+	// it refuses writes, and reads still go to the Bluesky API.
+	ModeMock Mode = "mock"
+)
+
 func LoadConfig() Config {
+	cfg, _ := loadConfig(os.Getenv("BSKY_CONFIG_FILE"))
+	return cfg
+}
+
+// loadConfig reads the environment and then configFile, if set, whose values
+// take precedence. A config file that cannot be read or parsed is returned as
+// an error along with the configuration from the environment.
+func loadConfig(configFile string) (Config, error) {
 	// Load from environment variables or use defaults
 	bskyID := getEnv("BSKY_ID", "")
 	bskyPassword := getEnv("BSKY_PASSWORD", "")
 	bskyHost := getEnv("BSKY_HOST", "https://bsky.social")
+	authHost := getEnv("BSKY_AUTH_HOST", "")
+
+	// BSKY_MODE takes precedence over the legacy MOCK_MODE flag
+	mode := Mode(getEnv("BSKY_MODE", ""))
+	if mode == "" {
+		mode = ModeAuto
+		if mockMode := os.Getenv("MOCK_MODE"); mockMode == "1" || mockMode == "true" {
+			mode = ModeMock
+		}
+	}
 
 	// Create config
 	cfg := Config{
 		BskyID:       bskyID,
 		BskyPassword: bskyPassword,
 		BskyHost:     bskyHost,
+		AuthHost:     authHost,
+		Mode:         mode,
+		Limits:       limitsFromEnv(),
+		Access:       accessFromEnv(),
+		Suggester:    getEnv("BSKY_SUGGESTER", ""),
+		Caches:       cachesFromEnv(),
+		SessionCache: sessionCacheFromEnv(),
 	}
 
-	// Try to load config from file if BSKY_CONFIG_FILE is set
-	if configFile := os.Getenv("BSKY_CONFIG_FILE"); configFile != "" {
-		if fileCfg, err := loadConfigFromFile(configFile); err == nil {
-			// Override with file values if they exist
-			if fileCfg.BskyID != "" {
-				cfg.BskyID = fileCfg.BskyID
-			}
-			if fileCfg.BskyPassword != "" {
-				cfg.BskyPassword = fileCfg.BskyPassword
-			}
-			if fileCfg.BskyHost != "" {
-				cfg.BskyHost = fileCfg.BskyHost
-			}
+	// Try to load config from file if one is set
+	if configFile != "" {
+		fileCfg, err := loadConfigFromFile(configFile)
+		if err != nil {
+			return cfg, err
+		}
+
+		// Override with file values if they exist
+		if fileCfg.BskyID != "" {
+			cfg.BskyID = fileCfg.BskyID
+		}
+		if fileCfg.BskyPassword != "" {
+			cfg.BskyPassword = fileCfg.BskyPassword
+		}
+		if fileCfg.BskyHost != "" {
+			cfg.BskyHost = fileCfg.BskyHost
+		}
+		if fileCfg.AuthHost != "" {
+			cfg.AuthHost = fileCfg.AuthHost
+		}
+		if fileCfg.Mode != "" {
+			cfg.Mode = fileCfg.Mode
+		}
+		if fileCfg.Limits.HashtagLength > 0 {
+			cfg.Limits.HashtagLength = fileCfg.Limits.HashtagLength
+		}
+		if fileCfg.Limits.HandleLength > 0 {
+			cfg.Limits.HandleLength = fileCfg.Limits.HandleLength
+		}
+		if fileCfg.Limits.TopicLength > 0 {
+			cfg.Limits.TopicLength = fileCfg.Limits.TopicLength
+		}
+		if fileCfg.Limits.TextLength > 0 {
+			cfg.Limits.TextLength = fileCfg.Limits.TextLength
 		}
+		if fileCfg.Limits.AnalyzedPosts > 0 {
+			cfg.Limits.AnalyzedPosts = fileCfg.Limits.AnalyzedPosts
+		}
+		if fileCfg.Suggester != "" {
+			cfg.Suggester = fileCfg.Suggester
+		}
+		if len(fileCfg.Access.AllowIPs) > 0 {
+			cfg.Access.AllowIPs = fileCfg.Access.AllowIPs
+		}
+		if len(fileCfg.Access.DenyIPs) > 0 {
+			cfg.Access.DenyIPs = fileCfg.Access.DenyIPs
+		}
+		if len(fileCfg.Access.TrustedProxies) > 0 {
+			cfg.Access.TrustedProxies = fileCfg.Access.TrustedProxies
+		}
+		cfg.Caches = cfg.Caches.overriddenBy(fileCfg.Caches)
+		cfg.SessionCache = cfg.SessionCache.overriddenBy(fileCfg.SessionCache)
 	}
 
-	return cfg
+	return cfg, nil
+}
+
+// SessionHost returns the host that authenticates the account: AuthHost when
+// set, otherwise BskyHost, without a trailing slash
+func (c Config) SessionHost() string {
+	host := c.AuthHost
+	if host == "" {
+		host = c.BskyHost
+	}
+	return strings.TrimRight(host, "/")
 }
 
 // loadConfigFromFile loads configuration from a JSON file
@@ -83,6 +177,35 @@ func ValidateConfig(cfg Config) error {
 	return nil
 }
 
+// ResolveMode decides whether to run in live or mock mode.
+// Live mode requires a valid configuration; auto mode falls back to mock mode
+// when neither BskyID nor BskyPassword is configured.
+func ResolveMode(cfg Config) (Mode, error) {
+	switch cfg.Mode {
+	case ModeLive:
+		if err := ValidateConfig(cfg); err != nil {
+			return "", err
+		}
+		return ModeLive, nil
+
+	case ModeMock:
+		return ModeMock, nil
+
+	case ModeAuto, "":
+		// Partial credentials are a mistake rather than a request for mock mode
+		if cfg.BskyID == "" && cfg.BskyPassword == "" {
+			return ModeMock, nil
+		}
+		if err := ValidateConfig(cfg); err != nil {
+			return "", err
+		}
+		return ModeLive, nil
+
+	default:
+		return "", fmt.Errorf("invalid mode %q: must be live, mock or auto", cfg.Mode)
+	}
+}
+
 // Helper function to get environment variable or default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -252,6 +252,85 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestResolveMode(t *testing.T) {
+	valid := Config{
+		BskyID:       "test-id",
+		BskyPassword: "test-password",
+		BskyHost:     "https://bsky.social",
+	}
+	withMode := func(cfg Config, mode Mode) Config {
+		cfg.Mode = mode
+		return cfg
+	}
+
+	tests := []struct {
+		name      string
+		config    Config
+		want      Mode
+		wantError bool
+	}{
+		{name: "Live with credentials", config: withMode(valid, ModeLive), want: ModeLive},
+		{name: "Live without credentials", config: Config{BskyHost: "https://bsky.social", Mode: ModeLive}, wantError: true},
+		{name: "Mock with credentials", config: withMode(valid, ModeMock), want: ModeMock},
+		{name: "Mock without credentials", config: Config{Mode: ModeMock}, want: ModeMock},
+		{name: "Auto with credentials", config: withMode(valid, ModeAuto), want: ModeLive},
+		{name: "Auto without credentials", config: Config{BskyHost: "https://bsky.social", Mode: ModeAuto}, want: ModeMock},
+		{name: "Auto with partial credentials", config: Config{BskyID: "test-id", BskyHost: "https://bsky.social", Mode: ModeAuto}, wantError: true},
+		{name: "Unset mode behaves as auto", config: valid, want: ModeLive},
+		{name: "Invalid mode", config: withMode(valid, "offline"), wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveMode(tt.config)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ResolveMode() error = %v, wantError %v", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigModePrecedence(t *testing.T) {
+	t.Setenv("BSKY_CONFIG_FILE", "")
+
+	tests := []struct {
+		name     string
+		bskyMode string
+		mockMode string
+		fileMode Mode
+		want     Mode
+	}{
+		{name: "Default is auto", want: ModeAuto},
+		{name: "MOCK_MODE selects mock", mockMode: "1", want: ModeMock},
+		{name: "MOCK_MODE true selects mock", mockMode: "true", want: ModeMock},
+		{name: "BSKY_MODE overrides MOCK_MODE", bskyMode: "live", mockMode: "1", want: ModeLive},
+		{name: "Config file overrides BSKY_MODE", bskyMode: "live", fileMode: ModeMock, want: ModeMock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BSKY_MODE", tt.bskyMode)
+			t.Setenv("MOCK_MODE", tt.mockMode)
+
+			if tt.fileMode != "" {
+				configFile := filepath.Join(t.TempDir(), "config.json")
+				data, _ := json.Marshal(Config{Mode: tt.fileMode})
+				if err := os.WriteFile(configFile, data, 0600); err != nil {
+					t.Fatalf("Failed to write config file: %v", err)
+				}
+				t.Setenv("BSKY_CONFIG_FILE", configFile)
+			}
+
+			if got := LoadConfig().Mode; got != tt.want {
+				t.Errorf("LoadConfig().Mode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	// Save original environment variable
 	origValue := os.Getenv("TEST_ENV_VAR")
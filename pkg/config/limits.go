@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Limits are the maximum lengths, in characters, of user-supplied input, and
+// the most posts one request may analyze. A zero field uses the value from DefaultLimits.
+type Limits struct {
+	HashtagLength int `json:"hashtag_length"` // feed-analysis hashtag, without the leading '#'
+	HandleLength  int `json:"handle_length"`  // community-manage user handle or DID
+	TopicLength   int `json:"topic_length"`   // post-assist topic
+	TextLength    int `json:"text_length"`    // Text of a post to submit
+	AnalyzedPosts int `json:"analyzed_posts"` // Posts fetched and analyzed per request, across pages
+}
+
+// DefaultLimits match Bluesky's own limits where it has them
+var DefaultLimits = Limits{
+	HashtagLength: 64,
+	HandleLength:  253,
+	TopicLength:   200,
+	TextLength:    3000,
+	AnalyzedPosts: 1000,
+}
+
+// LengthError reports an input longer than its configured limit
+type LengthError struct {
+	Field string
+	Limit int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("invalid parameter: %s exceeds %d characters", e.Field, e.Limit)
+}
+
+// Input fields with a length limit
+const (
+	FieldHashtag = "hashtag"
+	FieldHandle  = "userHandle"
+	FieldTopic   = "topic"
+	FieldText    = "text"
+)
+
+// withDefaults fills in zero limits from DefaultLimits
+func (l Limits) withDefaults() Limits {
+	if l.HashtagLength <= 0 {
+		l.HashtagLength = DefaultLimits.HashtagLength
+	}
+	if l.HandleLength <= 0 {
+		l.HandleLength = DefaultLimits.HandleLength
+	}
+	if l.TopicLength <= 0 {
+		l.TopicLength = DefaultLimits.TopicLength
+	}
+	if l.TextLength <= 0 {
+		l.TextLength = DefaultLimits.TextLength
+	}
+	if l.AnalyzedPosts <= 0 {
+		l.AnalyzedPosts = DefaultLimits.AnalyzedPosts
+	}
+	return l
+}
+
+// Max returns the limit for field, or 0 if the field is not limited
+func (l Limits) Max(field string) int {
+	l = l.withDefaults()
+	switch field {
+	case FieldHashtag:
+		return l.HashtagLength
+	case FieldHandle:
+		return l.HandleLength
+	case FieldTopic:
+		return l.TopicLength
+	case FieldText:
+		return l.TextLength
+	}
+	return 0
+}
+
+// MaxAnalyzedPosts returns the most posts one request may fetch and analyze,
+// however many pages they span
+func (l Limits) MaxAnalyzedPosts() int {
+	return l.withDefaults().AnalyzedPosts
+}
+
+// CheckLength returns a *LengthError if value is longer than the limit for field
+func (l Limits) CheckLength(field, value string) error {
+	limit := l.Max(field)
+	if limit > 0 && utf8.RuneCountInString(value) > limit {
+		return &LengthError{Field: field, Limit: limit}
+	}
+	return nil
+}
+
+// limitsFromEnv reads limit overrides from BSKY_MAX_HASHTAG_LENGTH,
+// BSKY_MAX_HANDLE_LENGTH, BSKY_MAX_TOPIC_LENGTH, BSKY_MAX_TEXT_LENGTH and
+// BSKY_MAX_ANALYZED_POSTS.
+// Unset or invalid values are left at zero so the defaults apply.
+func limitsFromEnv() Limits {
+	envInt := func(key string) int {
+		value, err := strconv.Atoi(os.Getenv(key))
+		if err != nil || value < 0 {
+			return 0
+		}
+		return value
+	}
+	return Limits{
+		HashtagLength: envInt("BSKY_MAX_HASHTAG_LENGTH"),
+		HandleLength:  envInt("BSKY_MAX_HANDLE_LENGTH"),
+		TopicLength:   envInt("BSKY_MAX_TOPIC_LENGTH"),
+		TextLength:    envInt("BSKY_MAX_TEXT_LENGTH"),
+		AnalyzedPosts: envInt("BSKY_MAX_ANALYZED_POSTS"),
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLimitsCheckLength(t *testing.T) {
+	custom := Limits{HashtagLength: 3, HandleLength: 4, TopicLength: 5, TextLength: 6}
+
+	tests := []struct {
+		name      string
+		limits    Limits
+		field     string
+		value     string
+		wantLimit int // 0 when the value is within the limit
+	}{
+		{name: "Default hashtag within limit", field: FieldHashtag, value: strings.Repeat("a", 64)},
+		{name: "Default hashtag over limit", field: FieldHashtag, value: strings.Repeat("a", 65), wantLimit: 64},
+		{name: "Default topic over limit", field: FieldTopic, value: strings.Repeat("a", 201), wantLimit: 200},
+		{name: "Custom hashtag", limits: custom, field: FieldHashtag, value: "abcd", wantLimit: 3},
+		{name: "Custom handle", limits: custom, field: FieldHandle, value: "a.b.c", wantLimit: 4},
+		{name: "Custom topic", limits: custom, field: FieldTopic, value: "abcdef", wantLimit: 5},
+		{name: "Custom text", limits: custom, field: FieldText, value: "abcdefg", wantLimit: 6},
+		{name: "Characters, not bytes", limits: custom, field: FieldTopic, value: "日本語です"},
+		{name: "Partial limits use defaults", limits: Limits{TopicLength: 5}, field: FieldHashtag, value: strings.Repeat("a", 64)},
+		{name: "Unknown field", limits: custom, field: "other", value: strings.Repeat("a", 1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.CheckLength(tt.field, tt.value)
+			if tt.wantLimit == 0 {
+				if err != nil {
+					t.Errorf("CheckLength() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var lengthErr *LengthError
+			if !errors.As(err, &lengthErr) {
+				t.Fatalf("CheckLength() error = %v, want *LengthError", err)
+			}
+			if lengthErr.Field != tt.field || lengthErr.Limit != tt.wantLimit {
+				t.Errorf("CheckLength() = %+v, want field %s and limit %d", lengthErr, tt.field, tt.wantLimit)
+			}
+			if !strings.Contains(err.Error(), tt.field) || !strings.Contains(err.Error(), "invalid parameter") {
+				t.Errorf("Error message %q should name the field as an invalid parameter", err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadConfigLimits(t *testing.T) {
+	t.Setenv("BSKY_CONFIG_FILE", "")
+	t.Setenv("BSKY_MAX_TOPIC_LENGTH", "50")
+	t.Setenv("BSKY_MAX_HASHTAG_LENGTH", "not-a-number")
+	t.Setenv("BSKY_MAX_ANALYZED_POSTS", "250")
+
+	cfg := LoadConfig()
+	if got := cfg.Limits.Max(FieldTopic); got != 50 {
+		t.Errorf("Topic limit = %d, want 50 from the environment", got)
+	}
+	if got := cfg.Limits.Max(FieldHashtag); got != DefaultLimits.HashtagLength {
+		t.Errorf("Hashtag limit = %d, want the default for an invalid value", got)
+	}
+	if got := cfg.Limits.MaxAnalyzedPosts(); got != 250 {
+		t.Errorf("Analyzed posts limit = %d, want 250 from the environment", got)
+	}
+
+	// File limits override the environment, like the other settings
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"Limits": {"topic_length": 80, "text_length": 500, "analyzed_posts": 300}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("BSKY_CONFIG_FILE", configFile)
+
+	cfg = LoadConfig()
+	if got := cfg.Limits.Max(FieldTopic); got != 80 {
+		t.Errorf("Topic limit = %d, want 80 from the file", got)
+	}
+	if got := cfg.Limits.Max(FieldText); got != 500 {
+		t.Errorf("Text limit = %d, want 500 from the file", got)
+	}
+	if got := cfg.Limits.MaxAnalyzedPosts(); got != 300 {
+		t.Errorf("Analyzed posts limit = %d, want 300 from the file", got)
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import "os"
+
+// SessionCache keeps the authenticated session on disk, encrypted, so that a
+// restart refreshes it instead of logging in again
+type SessionCache struct {
+	File string `json:"file"` // Where the session is kept; empty keeps it in memory only
+	Key  string `json:"key"`  // Secret the session is encrypted with, required with File
+}
+
+// Enabled reports whether sessions are persisted, which needs both a file and a key
+func (s SessionCache) Enabled() bool {
+	return s.File != "" && s.Key != ""
+}
+
+// sessionCacheFromEnv reads BSKY_SESSION_CACHE_FILE and BSKY_SESSION_CACHE_KEY
+func sessionCacheFromEnv() SessionCache {
+	return SessionCache{
+		File: os.Getenv("BSKY_SESSION_CACHE_FILE"),
+		Key:  os.Getenv("BSKY_SESSION_CACHE_KEY"),
+	}
+}
+
+// overriddenBy returns s with the non-empty fields of override applied
+func (s SessionCache) overriddenBy(override SessionCache) SessionCache {
+	if override.File != "" {
+		s.File = override.File
+	}
+	if override.Key != "" {
+		s.Key = override.Key
+	}
+	return s
+}
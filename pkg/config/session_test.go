@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigSessionCache(t *testing.T) {
+	t.Setenv("BSKY_CONFIG_FILE", "")
+	t.Setenv("BSKY_SESSION_CACHE_FILE", "/tmp/session")
+	t.Setenv("BSKY_SESSION_CACHE_KEY", "")
+
+	// A file without a key does not enable the cache
+	cfg := LoadConfig()
+	if cfg.SessionCache.File != "/tmp/session" || cfg.SessionCache.Enabled() {
+		t.Errorf("SessionCache = %+v, want the file set but the cache disabled", cfg.SessionCache)
+	}
+
+	// The config file's key completes the environment's file
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"SessionCache": {"key": "secret"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("BSKY_CONFIG_FILE", configFile)
+
+	cfg = LoadConfig()
+	if want := (SessionCache{File: "/tmp/session", Key: "secret"}); cfg.SessionCache != want || !cfg.SessionCache.Enabled() {
+		t.Errorf("SessionCache = %+v, want %+v enabled", cfg.SessionCache, want)
+	}
+}